@@ -219,6 +219,27 @@ func SetDefaultsUnitedDeployment(obj *v1alpha1.UnitedDeployment, injectTemplateD
 		obj.Spec.UpdateStrategy.ManualUpdate = &v1alpha1.ManualUpdate{}
 	}
 
+	if obj.Spec.UpdateStrategy.Type == v1alpha1.AutoUpdateStrategyType {
+		if obj.Spec.UpdateStrategy.AutoUpdate == nil {
+			obj.Spec.UpdateStrategy.AutoUpdate = &v1alpha1.AutoUpdate{}
+		}
+		if obj.Spec.UpdateStrategy.AutoUpdate.MaxParallelSubsets == nil {
+			obj.Spec.UpdateStrategy.AutoUpdate.MaxParallelSubsets = utilpointer.Int32Ptr(1)
+		}
+	}
+
+	if obj.Spec.Topology.ScheduleStrategy.IsAdaptive() {
+		if obj.Spec.Topology.ScheduleStrategy.Adaptive == nil {
+			obj.Spec.Topology.ScheduleStrategy.Adaptive = &v1alpha1.AdaptiveUnitedDeploymentStrategy{}
+		}
+		if obj.Spec.Topology.ScheduleStrategy.Adaptive.RescheduleCriticalSeconds == nil {
+			obj.Spec.Topology.ScheduleStrategy.Adaptive.RescheduleCriticalSeconds = utilpointer.Int32Ptr(int32(v1alpha1.DefaultRescheduleCriticalDuration.Seconds()))
+		}
+		if obj.Spec.Topology.ScheduleStrategy.Adaptive.UnschedulableLastSeconds == nil {
+			obj.Spec.Topology.ScheduleStrategy.Adaptive.UnschedulableLastSeconds = utilpointer.Int32Ptr(int32(v1alpha1.DefaultUnschedulableStatusLastDuration.Seconds()))
+		}
+	}
+
 	if obj.Spec.Template.StatefulSetTemplate != nil {
 		if injectTemplateDefaults {
 			SetDefaultPodSpec(&obj.Spec.Template.StatefulSetTemplate.Spec.Template.Spec)
@@ -333,6 +354,20 @@ func SetDefaultsDaemonSet(obj *v1alpha1.DaemonSet) {
 			MaxSurge := intstr.FromInt(0)
 			obj.Spec.UpdateStrategy.RollingUpdate.MaxSurge = &MaxSurge
 		}
+
+		if obj.Spec.UpdateStrategy.RollingUpdate.NodeDrain != nil && obj.Spec.UpdateStrategy.RollingUpdate.NodeDrain.TaintKey == "" {
+			obj.Spec.UpdateStrategy.RollingUpdate.NodeDrain.TaintKey = v1alpha1.DefaultDaemonSetNodeDrainTaintKey
+		}
+
+		if obj.Spec.UpdateStrategy.RollingUpdate.FailureBudget != nil {
+			if obj.Spec.UpdateStrategy.RollingUpdate.FailureBudget.MaxFailed == nil {
+				maxFailed := intstr.FromInt(0)
+				obj.Spec.UpdateStrategy.RollingUpdate.FailureBudget.MaxFailed = &maxFailed
+			}
+			if obj.Spec.UpdateStrategy.RollingUpdate.FailureBudget.ProgressDeadlineSeconds == nil {
+				obj.Spec.UpdateStrategy.RollingUpdate.FailureBudget.ProgressDeadlineSeconds = utilpointer.Int32Ptr(600)
+			}
+		}
 	}
 
 	if obj.Spec.RevisionHistoryLimit == nil {
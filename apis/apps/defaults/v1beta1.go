@@ -54,6 +54,10 @@ func SetDefaultsStatefulSet(obj *v1beta1.StatefulSet, injectTemplateDefaults boo
 		if obj.Spec.UpdateStrategy.RollingUpdate.MinReadySeconds == nil {
 			obj.Spec.UpdateStrategy.RollingUpdate.MinReadySeconds = utilpointer.Int32Ptr(0)
 		}
+		if obj.Spec.UpdateStrategy.RollingUpdate.SnapshotBeforeUpdate != nil &&
+			obj.Spec.UpdateStrategy.RollingUpdate.SnapshotBeforeUpdate.SnapshotsToKeep == nil {
+			obj.Spec.UpdateStrategy.RollingUpdate.SnapshotBeforeUpdate.SnapshotsToKeep = utilpointer.Int32Ptr(1)
+		}
 	}
 
 	if utilfeature.DefaultFeatureGate.Enabled(features.StatefulSetAutoDeletePVC) {
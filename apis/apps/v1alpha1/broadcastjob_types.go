@@ -48,6 +48,29 @@ type BroadcastJobSpec struct {
 	// FailurePolicy indicates the behavior of the job, when failed pod is found.
 	// +optional
 	FailurePolicy FailurePolicy `json:"failurePolicy,omitempty" protobuf:"bytes,5,opt,name=failurePolicy"`
+
+	// TopologyParallelism specifies the maximum number of pods the job should run at any given time
+	// in each topology domain, such as at most 2 nodes per rack. It works together with Parallelism.
+	// +optional
+	TopologyParallelism []BroadcastJobTopologyParallelism `json:"topologyParallelism,omitempty" protobuf:"bytes,6,rep,name=topologyParallelism"`
+
+	// SkipNotReadyNodes indicates the job will not run pods on the nodes which are not ready,
+	// and the pods on the nodes that went NotReady will not block the job from completion.
+	// +optional
+	SkipNotReadyNodes bool `json:"skipNotReadyNodes,omitempty" protobuf:"varint,7,opt,name=skipNotReadyNodes"`
+}
+
+// BroadcastJobTopologyParallelism limits the number of running pods in each topology domain.
+type BroadcastJobTopologyParallelism struct {
+	// TopologyKey is the key of node labels. Nodes that have a label with this key
+	// and identical values are considered to be in the same topology domain,
+	// and nodes without this label are considered to be in one domain.
+	TopologyKey string `json:"topologyKey" protobuf:"bytes,1,opt,name=topologyKey"`
+
+	// MaxParallelism is the maximum number of pods running in each topology domain at any given time.
+	// Value can be an absolute number (ex: 2) or a percentage of the desired nodes in the domain (ex: 10%),
+	// and the percentage is rounded up.
+	MaxParallelism intstr.IntOrString `json:"maxParallelism" protobuf:"bytes,2,opt,name=maxParallelism"`
 }
 
 // CompletionPolicy indicates the completion policy for the job
@@ -131,8 +154,54 @@ type BroadcastJobStatus struct {
 	// The phase of the job.
 	// +optional
 	Phase BroadcastJobPhase `json:"phase" protobuf:"varint,8,opt,name=phase"`
+
+	// NodeResults are the results of the job on each desired or skipped node which has not succeeded, sorted by node name.
+	// At most 1000 results are recorded, in which the failed, retrying and skipped nodes are kept first.
+	// +optional
+	NodeResults []BroadcastJobNodeResult `json:"nodeResults,omitempty" protobuf:"bytes,9,rep,name=nodeResults"`
 }
 
+// BroadcastJobNodeResult is the result of the job on a node.
+type BroadcastJobNodeResult struct {
+	// NodeName is the name of the node.
+	NodeName string `json:"nodeName" protobuf:"bytes,1,opt,name=nodeName"`
+
+	// PodName is the name of the latest pod on the node.
+	// +optional
+	PodName string `json:"podName,omitempty" protobuf:"bytes,2,opt,name=podName"`
+
+	// Phase is the phase of the job on the node.
+	Phase BroadcastJobNodePhase `json:"phase" protobuf:"bytes,3,opt,name=phase,casttype=BroadcastJobNodePhase"`
+
+	// Retries is the number of failed pods that have been recreated on the node.
+	// +optional
+	Retries int32 `json:"retries,omitempty" protobuf:"varint,4,opt,name=retries"`
+
+	// Message is a human readable message indicating details about the phase.
+	// +optional
+	Message string `json:"message,omitempty" protobuf:"bytes,5,opt,name=message"`
+}
+
+// BroadcastJobNodePhase indicates the phase of the job on a node.
+type BroadcastJobNodePhase string
+
+const (
+	// NodePhasePending means the pod has not been created on the node.
+	NodePhasePending BroadcastJobNodePhase = "Pending"
+
+	// NodePhaseRunning means the pod on the node is active.
+	NodePhaseRunning BroadcastJobNodePhase = "Running"
+
+	// NodePhaseFailed means the pod on the node has failed.
+	NodePhaseFailed BroadcastJobNodePhase = "Failed"
+
+	// NodePhaseRetrying means the failed pod on the node is waiting to be recreated.
+	NodePhaseRetrying BroadcastJobNodePhase = "Retrying"
+
+	// NodePhaseSkipped means the node is skipped because it is not ready.
+	NodePhaseSkipped BroadcastJobNodePhase = "Skipped"
+)
+
 // BroadcastJobPhase indicates the phase of the job.
 type BroadcastJobPhase string
 
@@ -157,7 +226,20 @@ type FailurePolicy struct {
 	Type FailurePolicyType `json:"type,omitempty" protobuf:"bytes,1,opt,name=type,casttype=FailurePolicyType"`
 
 	// RestartLimit specifies the number of retries before marking the pod failed.
+	// If RecreateFailedPods is true, it is also the number of times a failed pod can be recreated on the same node.
 	RestartLimit int32 `json:"restartLimit,omitempty" protobuf:"varint,2,opt,name=restartLimit"`
+
+	// RecreateFailedPods indicates the pod failed with restartPolicy Never will be recreated on the same node
+	// after a backoff and then deleted, until it has been recreated RestartLimit times on the node.
+	// The number of retries is recorded by the annotation apps.kruise.io/broadcastjob-retries of the new pod.
+	// It requires the pod restartPolicy to be Never and RestartLimit to be positive.
+	// +optional
+	RecreateFailedPods bool `json:"recreateFailedPods,omitempty" protobuf:"varint,3,opt,name=recreateFailedPods"`
+
+	// BackoffSeconds is the delay before recreating a failed pod, which is doubled for each retry on the same node
+	// and capped at 10 minutes. Defaults to 10.
+	// +optional
+	BackoffSeconds *int32 `json:"backoffSeconds,omitempty" protobuf:"varint,4,opt,name=backoffSeconds"`
 }
 
 // FailurePolicyType indicates the type of FailurePolicyType.
@@ -21,6 +21,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -115,6 +116,119 @@ type RollingUpdateDaemonSet struct {
 	// daemon set controller.
 	// +optional
 	Paused *bool `json:"paused,omitempty"`
+
+	// Waves are the ordered batches of nodes to roll the update through.
+	// The controller automatically moves to the next wave once all daemon pods in the current wave
+	// are updated and available, and the soak duration of the wave has passed.
+	// It can not be set together with Selector or Partition.
+	// +optional
+	Waves []DaemonSetUpdateWave `json:"waves,omitempty"`
+
+	// NodeDrain coordinates with the workloads depending on the daemon (such as CSI, CNI and log agents)
+	// when the daemon pod on a node is replaced.
+	// +optional
+	NodeDrain *DaemonSetNodeDrainPolicy `json:"nodeDrain,omitempty"`
+
+	// FailureBudget makes the controller pause the rolling update automatically,
+	// when too many updated daemon pods fail to become available.
+	// +optional
+	FailureBudget *DaemonSetUpdateFailureBudget `json:"failureBudget,omitempty"`
+}
+
+// DaemonSetUpdateFailureBudget defines when the rolling update of a DaemonSet should be paused automatically.
+type DaemonSetUpdateFailureBudget struct {
+	// MaxFailed is the maximum number of updated daemon pods that are allowed to fail.
+	// Once more updated daemon pods fail, the controller sets Paused of the rolling update to true,
+	// and adds an UpdateFailed condition listing the failing nodes.
+	// Value can be an absolute number (ex: 5) or a percentage of desired pods (ex: 10%).
+	// Absolute number is calculated from percentage by rounding down.
+	// Defaults to 0.
+	// +optional
+	MaxFailed *intstr.IntOrString `json:"maxFailed,omitempty"`
+
+	// ProgressDeadlineSeconds is the maximum duration for an updated daemon pod to become available,
+	// after which it is considered failed.
+	// Defaults to 600.
+	// +optional
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+
+	// RollbackFailedNodes indicates whether to roll the daemon pods on the failing nodes back to
+	// the previous revision, after the rolling update is paused.
+	// +optional
+	RollbackFailedNodes bool `json:"rollbackFailedNodes,omitempty"`
+}
+
+const (
+	// DaemonSetConditionTypeUpdateFailed means the rolling update has been paused, because too many updated
+	// daemon pods failed to become available.
+	DaemonSetConditionTypeUpdateFailed appsv1.DaemonSetConditionType = "UpdateFailed"
+)
+
+const (
+	// DefaultDaemonSetNodeDrainTaintKey is the default key of the taint added to nodes during node drain.
+	DefaultDaemonSetNodeDrainTaintKey = "apps.kruise.io/daemonset-updating"
+
+	// DaemonSetNodeDrainReadyAnnotation can be set to "true" on dependent pods, to signal that they are ready
+	// for the daemon pod on the same node to be replaced.
+	DaemonSetNodeDrainReadyAnnotation = "apps.kruise.io/daemonset-drain-ready"
+)
+
+// DaemonSetNodeDrainPolicy defines how to drain a node before the old daemon pod on it is deleted.
+// The node is tainted NoSchedule before the old daemon pod is deleted, and untainted once the new daemon pod is ready.
+// All the taints are also removed if the policy is removed, the rolling update is paused or the DaemonSet is deleted.
+type DaemonSetNodeDrainPolicy struct {
+	// TaintKey is the key of the NoSchedule taint added to the node.
+	// Daemon pods always tolerate this taint.
+	// Defaults to apps.kruise.io/daemonset-updating.
+	// +optional
+	TaintKey string `json:"taintKey,omitempty"`
+
+	// DependentPodSelector is a label query over the pods on the node that depend on the daemon.
+	// If it is set, the old daemon pod will not be deleted until all the dependent pods on the node are evicted,
+	// or annotated with apps.kruise.io/daemonset-drain-ready=true.
+	// +optional
+	DependentPodSelector *metav1.LabelSelector `json:"dependentPodSelector,omitempty"`
+
+	// TimeoutSeconds is the maximum duration to wait for the dependent pods after the node is tainted,
+	// then the old daemon pod will be deleted anyway.
+	// Defaults to 0, which means waiting until the dependent pods are drained.
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// DaemonSetUpdateWave defines a batch of nodes in which daemon pods can be updated.
+// Nodes of a wave are accumulated with all the waves before it.
+type DaemonSetUpdateWave struct {
+	// Name is the name of the wave.
+	Name string `json:"name"`
+
+	// NodeSelector is a label query over nodes that can be updated in this wave.
+	// If it is nil, all nodes can be updated in this wave, limited by MaxUpdated.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// MaxUpdated is the cumulative number of nodes that can be updated when this wave is reached.
+	// Value can be an absolute number (ex: 5) or a percentage of total nodes (ex: 10%).
+	// Absolute number is calculated from percentage by rounding up.
+	// Defaults to no limit.
+	// +optional
+	MaxUpdated *intstr.IntOrString `json:"maxUpdated,omitempty"`
+
+	// SoakSeconds is the duration to wait after all daemon pods in this wave are updated and available,
+	// before moving to the next wave.
+	// +optional
+	SoakSeconds int32 `json:"soakSeconds,omitempty"`
+}
+
+// DaemonSetNodeOverride defines a patch to the pod template for the matching nodes.
+type DaemonSetNodeOverride struct {
+	// NodeSelector is a label query over nodes that the patch applies to.
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector"`
+
+	// Patch is the strategic merge patch to the pod template.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Patch runtime.RawExtension `json:"patch"`
 }
 
 // DaemonSetSpec defines the desired state of DaemonSet
@@ -137,6 +251,13 @@ type DaemonSetSpec struct {
 	// +kubebuilder:validation:Schemaless
 	Template corev1.PodTemplateSpec `json:"template"`
 
+	// NodeOverrides are the patches to the pod template for the matching nodes,
+	// which are applied in order when the daemon pods are created.
+	// They take part in the revision of DaemonSet, so changing them rolls the daemon pods as template does.
+	// Note that the scheduling fields in patches do not change which nodes the daemon pods should run on.
+	// +optional
+	NodeOverrides []DaemonSetNodeOverride `json:"nodeOverrides,omitempty"`
+
 	// An update strategy to replace existing DaemonSet pods with new pods.
 	// +optional
 	UpdateStrategy DaemonSetUpdateStrategy `json:"updateStrategy,omitempty"`
@@ -221,6 +342,26 @@ type DaemonSetStatus struct {
 
 	// DaemonSetHash is the controller-revision-hash, which represents the latest version of the DaemonSet.
 	DaemonSetHash string `json:"daemonSetHash"`
+
+	// CurrentWave is the wave that the rolling update is in, only if waves are set in the update strategy.
+	// +optional
+	CurrentWave *DaemonSetUpdateWaveStatus `json:"currentWave,omitempty"`
+}
+
+// DaemonSetUpdateWaveStatus defines the observed state of the rolling update waves.
+type DaemonSetUpdateWaveStatus struct {
+	// Revision is the controller-revision-hash that the waves are rolling to.
+	Revision string `json:"revision"`
+
+	// Index is the index of the current wave in waves.
+	Index int32 `json:"index"`
+
+	// Name is the name of the current wave.
+	Name string `json:"name"`
+
+	// CompletedTime is the time when all daemon pods in the current wave became updated and available.
+	// +optional
+	CompletedTime *metav1.Time `json:"completedTime,omitempty"`
 }
 
 // +genclient
@@ -236,6 +377,7 @@ type DaemonSetStatus struct {
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp",description="CreationTimestamp is a timestamp representing the server time when this object was created. It is not guaranteed to be set in happens-before order across separate operations. Clients may not set this value. It is represented in RFC3339 form and is in UTC."
 // +kubebuilder:printcolumn:name="CONTAINERS",type="string",priority=1,JSONPath=".spec.template.spec.containers[*].name",description="The containers of currently  daemonset."
 // +kubebuilder:printcolumn:name="IMAGES",type="string",priority=1,JSONPath=".spec.template.spec.containers[*].image",description="The images of currently advanced daemonset."
+// +kubebuilder:printcolumn:name="WAVE",type="string",priority=1,JSONPath=".status.currentWave.name",description="The current wave of the rolling update."
 
 // DaemonSet is the Schema for the daemonsets API
 type DaemonSet struct {
@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"time"
+
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -35,6 +37,11 @@ const (
 	// The update progress is able to be controlled by updating the partitions
 	// of each subset.
 	ManualUpdateStrategyType UpdateStrategyType = "Manual"
+	// AutoUpdateStrategyType indicates the subsets are updated one by one (or several in parallel)
+	// automatically. The next subsets will not be updated until the updating ones are all updated and ready.
+	// It relies on the partitions of subsets, so it is not supported for Deployment and the custom workloads
+	// without partitionPath.
+	AutoUpdateStrategyType UpdateStrategyType = "Auto"
 )
 
 // UnitedDeploymentConditionType indicates valid conditions type of a UnitedDeployment.
@@ -97,6 +104,13 @@ type SubsetTemplate struct {
 	// Deployment template
 	// +optional
 	DeploymentTemplate *DeploymentTemplateSpec `json:"deploymentTemplate,omitempty"`
+
+	// CustomWorkload template, whose kind must be registered in the
+	// UnitedDeployment_Custom_Workload_WhiteList of kruise-configuration.
+	// The white list is only loaded when kruise-manager starts, and kruise-manager has to be
+	// granted the permissions to manage the custom workload.
+	// +optional
+	CustomWorkloadTemplate *CustomWorkloadTemplateSpec `json:"customWorkloadTemplate,omitempty"`
 }
 
 // StatefulSetTemplateSpec defines the subset template of StatefulSet.
@@ -135,6 +149,22 @@ type DeploymentTemplateSpec struct {
 	Spec appsv1.DeploymentSpec `json:"spec"`
 }
 
+// CustomWorkloadTemplateSpec defines the subset template of custom workload.
+type CustomWorkloadTemplateSpec struct {
+	// APIVersion of the custom workload.
+	APIVersion string `json:"apiVersion"`
+	// Kind of the custom workload.
+	Kind string `json:"kind"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// Spec of the custom workload. The replicas, partition, selector and pod template in it
+	// are located by the paths registered in the white list.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Spec runtime.RawExtension `json:"spec"`
+}
+
 // UnitedDeploymentUpdateStrategy defines the update performance
 // when template of UnitedDeployment is changed.
 type UnitedDeploymentUpdateStrategy struct {
@@ -145,6 +175,9 @@ type UnitedDeploymentUpdateStrategy struct {
 	// Includes all of the parameters a Manual update strategy needs.
 	// +optional
 	ManualUpdate *ManualUpdate `json:"manualUpdate,omitempty"`
+	// Includes all of the parameters an Auto update strategy needs.
+	// +optional
+	AutoUpdate *AutoUpdate `json:"autoUpdate,omitempty"`
 }
 
 // ManualUpdate is a update strategy which allows users to control the update progress
@@ -155,6 +188,20 @@ type ManualUpdate struct {
 	Partitions map[string]int32 `json:"partitions,omitempty"`
 }
 
+// AutoUpdate is a update strategy which updates the subsets automatically in order.
+// The subsets are ordered by their priorities, and the ones with the same priority
+// keep the order in topology.
+type AutoUpdate struct {
+	// MaxParallelSubsets indicates the max number of subsets which can be updated at the same time.
+	// Defaults to 1.
+	// +optional
+	MaxParallelSubsets *int32 `json:"maxParallelSubsets,omitempty"`
+	// Priorities indicates the update priority of each subset. The subset with higher priority
+	// will be updated earlier. The priority of subsets not listed is 0.
+	// +optional
+	Priorities map[string]int32 `json:"priorities,omitempty"`
+}
+
 // Topology defines the spread detail of each subset under UnitedDeployment.
 // A UnitedDeployment manages multiple homogeneous workloads which are called subset.
 // Each of subsets under the UnitedDeployment is described in Topology.
@@ -165,8 +212,80 @@ type Topology struct {
 	// +patchStrategy=merge
 	// +optional
 	Subsets []Subset `json:"subsets,omitempty" patchStrategy:"merge" patchMergeKey:"name"`
+
+	// ScheduleStrategy indicates the strategy the UnitedDeployment used to allocate replicas to subsets.
+	// +optional
+	ScheduleStrategy UnitedDeploymentScheduleStrategy `json:"scheduleStrategy,omitempty"`
+}
+
+// UnitedDeploymentScheduleStrategyType is a string enumeration type that enumerates
+// all possible schedule strategies for the UnitedDeployment controller.
+// +kubebuilder:validation:Enum=Adaptive;Fixed;""
+type UnitedDeploymentScheduleStrategyType string
+
+const (
+	// FixedUnitedDeploymentScheduleStrategyType indicates the replicas of each subset are allocated statically
+	// depending on the Replicas, MinReplicas and MaxReplicas of subsets.
+	FixedUnitedDeploymentScheduleStrategyType UnitedDeploymentScheduleStrategyType = "Fixed"
+	// AdaptiveUnitedDeploymentScheduleStrategyType indicates the replicas are allocated to subsets in order, and
+	// the replicas of a subset whose pods can not be scheduled will be moved to the next subsets.
+	AdaptiveUnitedDeploymentScheduleStrategyType UnitedDeploymentScheduleStrategyType = "Adaptive"
+)
+
+// UnitedDeploymentScheduleStrategy defines the schedule performance of UnitedDeployment.
+type UnitedDeploymentScheduleStrategy struct {
+	// Type indicates the type of the UnitedDeploymentScheduleStrategy.
+	// Default is Fixed.
+	// +optional
+	Type UnitedDeploymentScheduleStrategyType `json:"type,omitempty"`
+
+	// Adaptive includes the parameters an Adaptive schedule strategy needs.
+	// +optional
+	Adaptive *AdaptiveUnitedDeploymentStrategy `json:"adaptive,omitempty"`
+}
+
+// AdaptiveUnitedDeploymentStrategy is used to communicate parameters when Type is AdaptiveUnitedDeploymentScheduleStrategyType.
+type AdaptiveUnitedDeploymentStrategy struct {
+	// RescheduleCriticalSeconds indicates how long the pods of a subset can stay unschedulable before the subset
+	// is marked unschedulable and its pending replicas are moved to the next subsets.
+	// Defaults to 30.
+	// +optional
+	RescheduleCriticalSeconds *int32 `json:"rescheduleCriticalSeconds,omitempty"`
+
+	// UnschedulableLastSeconds indicates how long a subset stays unschedulable before it is retried.
+	// Defaults to 300.
+	// +optional
+	UnschedulableLastSeconds *int32 `json:"unschedulableLastSeconds,omitempty"`
+}
+
+// IsAdaptive returns true if the schedule strategy is Adaptive.
+func (s *UnitedDeploymentScheduleStrategy) IsAdaptive() bool {
+	return s.Type == AdaptiveUnitedDeploymentScheduleStrategyType
+}
+
+// GetRescheduleCriticalDuration returns the duration the pods can stay unschedulable.
+func (s *UnitedDeploymentScheduleStrategy) GetRescheduleCriticalDuration() time.Duration {
+	if s.Adaptive == nil || s.Adaptive.RescheduleCriticalSeconds == nil {
+		return DefaultRescheduleCriticalDuration
+	}
+	return time.Duration(*s.Adaptive.RescheduleCriticalSeconds) * time.Second
 }
 
+// GetUnschedulableLastDuration returns the duration a subset stays unschedulable.
+func (s *UnitedDeploymentScheduleStrategy) GetUnschedulableLastDuration() time.Duration {
+	if s.Adaptive == nil || s.Adaptive.UnschedulableLastSeconds == nil {
+		return DefaultUnschedulableStatusLastDuration
+	}
+	return time.Duration(*s.Adaptive.UnschedulableLastSeconds) * time.Second
+}
+
+const (
+	// DefaultRescheduleCriticalDuration is the default duration the pods can stay unschedulable.
+	DefaultRescheduleCriticalDuration = 30 * time.Second
+	// DefaultUnschedulableStatusLastDuration is the default duration a subset stays unschedulable.
+	DefaultUnschedulableStatusLastDuration = 300 * time.Second
+)
+
 // Subset defines the detail of a subset.
 type Subset struct {
 	// Indicates subset name as a DNS_LABEL, which will be used to generate
@@ -216,6 +335,69 @@ type Subset struct {
 	// +kubebuilder:pruning:PreserveUnknownFields
 	// +kubebuilder:validation:Schemaless
 	Patch runtime.RawExtension `json:"patch,omitempty"`
+
+	// State indicates the state of the subset, which is one of Active, Draining and Disabled.
+	// Defaults to Active.
+	// +optional
+	State SubsetState `json:"state,omitempty"`
+
+	// Overrides indicates the typed overrides to the pod template of this subset.
+	// Overrides are applied before Patch, so Patch still takes precedence.
+	// +optional
+	Overrides *SubsetOverrides `json:"overrides,omitempty"`
+}
+
+// SubsetOverrides defines the fields of pod template which can be overridden by a subset.
+type SubsetOverrides struct {
+	// ServiceAccountName overrides the service account of the pods in this subset.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// PriorityClassName overrides the priority class of the pods in this subset.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// ImagePullSecrets overrides the image pull secrets of the pods in this subset.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// Containers overrides the resources and env of the containers in this subset.
+	// +optional
+	Containers []SubsetContainerOverride `json:"containers,omitempty"`
+}
+
+// SubsetContainerOverride defines the overrides to a container of the pod template.
+type SubsetContainerOverride struct {
+	// Name of the container in pod template, which can be either a container or an init container.
+	Name string `json:"name"`
+
+	// Resources overrides the requests and limits of the container. Only the resources specified
+	// are overridden, the others are kept as in the template.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Env overrides the env of the container by name. The env not found in the container is appended.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+}
+
+// SubsetState is a string enumeration type that enumerates all possible states of a subset.
+// +kubebuilder:validation:Enum=Active;Draining;Disabled;""
+type SubsetState string
+
+const (
+	// SubsetStateActive means the replicas are allocated to the subset normally.
+	SubsetStateActive SubsetState = "Active"
+	// SubsetStateDraining means the replicas of the subset are moved to the active subsets. The subset is
+	// scaled in only after the replicas surged in the active subsets are ready.
+	SubsetStateDraining SubsetState = "Draining"
+	// SubsetStateDisabled means the subset workload is kept with zero replicas without being deleted.
+	SubsetStateDisabled SubsetState = "Disabled"
+)
+
+// IsActive returns true if the replicas should be allocated to the subset.
+func (s *Subset) IsActive() bool {
+	return s.State == "" || s.State == SubsetStateActive
 }
 
 // UnitedDeploymentStatus defines the observed state of UnitedDeployment.
@@ -262,6 +444,66 @@ type UnitedDeploymentStatus struct {
 
 	// LabelSelector is label selectors for query over pods that should match the replica count used by HPA.
 	LabelSelector string `json:"labelSelector,omitempty"`
+
+	// Records the status of each subset, which is only set for Adaptive schedule strategy
+	// or when any subset is not Active.
+	// +optional
+	SubsetStatuses []UnitedDeploymentSubsetStatus `json:"subsetStatuses,omitempty"`
+}
+
+// UnitedDeploymentSubsetConditionType indicates valid conditions type of a subset.
+type UnitedDeploymentSubsetConditionType string
+
+const (
+	// UnitedDeploymentSubsetSchedulable means the pods of the subset can be scheduled.
+	// It turns False when the pods of the subset stay unschedulable for longer than rescheduleCriticalSeconds,
+	// and turns True again after unschedulableLastSeconds.
+	UnitedDeploymentSubsetSchedulable UnitedDeploymentSubsetConditionType = "Schedulable"
+	// UnitedDeploymentSubsetDrained means all the replicas of a Draining subset have been moved to
+	// the active subsets.
+	UnitedDeploymentSubsetDrained UnitedDeploymentSubsetConditionType = "Drained"
+)
+
+// UnitedDeploymentSubsetStatus defines the observed state of a subset.
+type UnitedDeploymentSubsetStatus struct {
+	// Subset name specified in Topology.Subsets
+	Name string `json:"name,omitempty"`
+
+	// State is the state of the subset specified in Topology.Subsets.
+	// +optional
+	State SubsetState `json:"state,omitempty"`
+
+	// Replicas is the most recently observed number of replicas of the subset.
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// ReadyReplicas is the number of ready replicas of the subset.
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// UnschedulablePods is the number of pods of the subset that have been unschedulable
+	// for longer than rescheduleCriticalSeconds.
+	UnschedulablePods int32 `json:"unschedulablePods,omitempty"`
+
+	// Conditions is an array of current observed subset conditions.
+	// +optional
+	Conditions []UnitedDeploymentSubsetCondition `json:"conditions,omitempty"`
+}
+
+// UnitedDeploymentSubsetCondition describes current state of a subset.
+type UnitedDeploymentSubsetCondition struct {
+	// Type of subset condition.
+	Type UnitedDeploymentSubsetConditionType `json:"type"`
+
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// The reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable message indicating details about the transition.
+	Message string `json:"message,omitempty"`
 }
 
 // UnitedDeploymentCondition describes current state of a UnitedDeployment.
@@ -291,6 +533,43 @@ type UpdateStatus struct {
 	// Records the current partition.
 	// +optional
 	CurrentPartitions map[string]int32 `json:"currentPartitions,omitempty"`
+
+	// Records the update progress of each subset in update order, which is only set for Auto update strategy.
+	// +optional
+	SubsetUpdateStatuses []UnitedDeploymentSubsetUpdateStatus `json:"subsetUpdateStatuses,omitempty"`
+}
+
+// UnitedDeploymentSubsetUpdatePhase indicates the update phase of a subset.
+type UnitedDeploymentSubsetUpdatePhase string
+
+const (
+	// SubsetUpdatePhasePending means the subset is waiting for the previous subsets to be updated.
+	SubsetUpdatePhasePending UnitedDeploymentSubsetUpdatePhase = "Pending"
+	// SubsetUpdatePhaseUpdating means the subset is being updated.
+	SubsetUpdatePhaseUpdating UnitedDeploymentSubsetUpdatePhase = "Updating"
+	// SubsetUpdatePhaseUpdated means all the replicas of the subset are updated and ready.
+	SubsetUpdatePhaseUpdated UnitedDeploymentSubsetUpdatePhase = "Updated"
+)
+
+// UnitedDeploymentSubsetUpdateStatus defines the update progress of a subset.
+type UnitedDeploymentSubsetUpdateStatus struct {
+	// Subset name.
+	Name string `json:"name"`
+
+	// Phase is the update phase of the subset.
+	Phase UnitedDeploymentSubsetUpdatePhase `json:"phase"`
+
+	// Replicas is the expected replicas of the subset.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// UpdatedReplicas is the number of pods of the subset at the updated revision.
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// UpdatedReadyReplicas is the number of ready pods of the subset at the updated revision.
+	// +optional
+	UpdatedReadyReplicas int32 `json:"updatedReadyReplicas,omitempty"`
 }
 
 // +genclient
@@ -17,6 +17,8 @@ const (
 	ImagePreDownloadIgnoredKey = "apps.kruise.io/image-predownload-ignored"
 	// AnnotationSubsetPatchKey indicates the patch for every subset
 	AnnotationSubsetPatchKey = "apps.kruise.io/subset-patch"
+	// AnnotationSubsetOverridesKey indicates the overrides for every subset
+	AnnotationSubsetOverridesKey = "apps.kruise.io/subset-overrides"
 )
 
 // Sidecar container environment variable definitions which are used to enable SidecarTerminator to take effect on the sidecar container.
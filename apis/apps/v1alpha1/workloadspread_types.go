@@ -36,6 +36,11 @@ type WorkloadSpreadSpec struct {
 	// ScheduleStrategy indicates the strategy the WorkloadSpread used to preform the schedule between each of subsets.
 	// +optional
 	ScheduleStrategy WorkloadSpreadScheduleStrategy `json:"scheduleStrategy,omitempty"`
+
+	// RebalanceStrategy indicates the strategy to move existing pods out of over-full subsets after subsets or
+	// maxReplicas are changed. Pods are only moved when it is specified.
+	// +optional
+	RebalanceStrategy *WorkloadSpreadRebalanceStrategy `json:"rebalanceStrategy,omitempty"`
 }
 
 // TargetReference contains enough information to let you identify an workload
@@ -89,6 +94,21 @@ type AdaptiveWorkloadSpreadStrategy struct {
 	RescheduleCriticalSeconds *int32 `json:"rescheduleCriticalSeconds,omitempty"`
 }
 
+// WorkloadSpreadRebalanceStrategy defines how the controller rebalances existing pods between subsets.
+// The controller gradually deletes pods in over-full subsets, and the workload controller recreates them
+// into under-filled subsets. The deletions are still checked by PodUnavailableBudget.
+type WorkloadSpreadRebalanceStrategy struct {
+	// MaxDeletionsPerInterval is the max number of pods deleted for rebalancing in each interval.
+	// Default is 1.
+	// +optional
+	MaxDeletionsPerInterval *int32 `json:"maxDeletionsPerInterval,omitempty"`
+
+	// IntervalSeconds is the minimum seconds between two rounds of rebalancing.
+	// Default is 60.
+	// +optional
+	IntervalSeconds *int32 `json:"intervalSeconds,omitempty"`
+}
+
 // WorkloadSpreadSubset defines the details of a subset.
 type WorkloadSpreadSubset struct {
 	// Name should be unique between all of the subsets under one WorkloadSpread.
@@ -110,6 +130,14 @@ type WorkloadSpreadSubset struct {
 	// +optional
 	MaxReplicas *intstr.IntOrString `json:"maxReplicas,omitempty"`
 
+	// Weight indicates the relative share of the workload replicas this subset should hold,
+	// e.g., weights 3:2:1 spread 6 replicas as 3, 2 and 1.
+	// For StatefulSet, the ordinals are assigned to the subsets in cycles of the total weight, so the subset of
+	// a Pod is kept while the StatefulSet scales.
+	// Weight and MaxReplicas are mutually exclusive, and if any subset has a weight, all subsets must have one.
+	// +optional
+	Weight *int32 `json:"weight,omitempty"`
+
 	// Patch indicates patching podTemplate to the Pod.
 	// +optional
 	// +kubebuilder:pruning:PreserveUnknownFields
@@ -135,6 +163,66 @@ type WorkloadSpreadStatus struct {
 	// may be earlier than deletion of old-version pod. We have to calculate the pod subset distribution for
 	// each version.
 	VersionedSubsetStatuses map[string][]WorkloadSpreadSubsetStatus `json:"versionedSubsetStatuses,omitempty"`
+
+	// RebalanceStatus is the progress of rebalancing, which is only set if RebalanceStrategy is specified.
+	// +optional
+	RebalanceStatus *WorkloadSpreadRebalanceStatus `json:"rebalanceStatus,omitempty"`
+
+	// UpdateRevision is the latest revision of the target workload observed by this WorkloadSpread,
+	// in the form of the revision label of Pods, i.e., the short hash for CloneSet.
+	// It is empty if the revision of the target workload cannot be recognized.
+	// +optional
+	UpdateRevision string `json:"updateRevision,omitempty"`
+
+	// SubsetRevisionStatuses shows the rolling update progress of each subset, which counts the
+	// active Pods of each revision in the subset.
+	// +optional
+	SubsetRevisionStatuses []WorkloadSpreadSubsetRevisionStatus `json:"subsetRevisionStatuses,omitempty"`
+}
+
+// WorkloadSpreadSubsetRevisionStatus is the rolling update progress of a subset.
+type WorkloadSpreadSubsetRevisionStatus struct {
+	// Name is the name of the subset.
+	Name string `json:"name"`
+
+	// ReadyReplicas is the number of active and ready Pods in the subset.
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// UpdatedReplicas is the number of active Pods in the subset that are at UpdateRevision.
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+
+	// UpdatedReadyReplicas is the number of active and ready Pods in the subset that are at UpdateRevision.
+	UpdatedReadyReplicas int32 `json:"updatedReadyReplicas"`
+
+	// Revisions contains the replicas of each revision in the subset, sorted by revision.
+	// +optional
+	Revisions []WorkloadSpreadRevisionReplicas `json:"revisions,omitempty"`
+}
+
+// WorkloadSpreadRevisionReplicas is the replicas of a revision in a subset.
+type WorkloadSpreadRevisionReplicas struct {
+	// Revision is the revision of Pods, i.e., the pod-template-hash or controller-revision-hash label.
+	Revision string `json:"revision"`
+
+	// Replicas is the number of active Pods of this revision.
+	Replicas int32 `json:"replicas"`
+
+	// ReadyReplicas is the number of active and ready Pods of this revision.
+	ReadyReplicas int32 `json:"readyReplicas"`
+}
+
+// WorkloadSpreadRebalanceStatus defines the observed progress of rebalancing.
+type WorkloadSpreadRebalanceStatus struct {
+	// ExcessReplicas is the number of pods in over-full subsets, or not matching any subset, that have not been moved yet.
+	ExcessReplicas int32 `json:"excessReplicas"`
+
+	// LastRebalanceTime is the last time pods were deleted for rebalancing.
+	// +optional
+	LastRebalanceTime *metav1.Time `json:"lastRebalanceTime,omitempty"`
+
+	// LastRebalancedPods are the names of pods deleted for rebalancing at LastRebalanceTime.
+	// +optional
+	LastRebalancedPods []string `json:"lastRebalancedPods,omitempty"`
 }
 
 type WorkloadSpreadSubsetConditionType string
@@ -146,6 +234,10 @@ const (
 	// even if only single Pod scheduled fails.
 	// After a period of time(e.g. 5m), the controller will recover the subset to be schedulable.
 	SubsetSchedulable WorkloadSpreadSubsetConditionType = "Schedulable"
+
+	// SubsetOverfilled means the subset has more active Pods than its maxReplicas during a rolling update,
+	// because the workload surges new-revision Pods before the old-revision Pods are deleted.
+	SubsetOverfilled WorkloadSpreadSubsetConditionType = "Overfilled"
 )
 
 type WorkloadSpreadSubsetCondition struct {
@@ -30,6 +30,31 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdaptiveUnitedDeploymentStrategy) DeepCopyInto(out *AdaptiveUnitedDeploymentStrategy) {
+	*out = *in
+	if in.RescheduleCriticalSeconds != nil {
+		in, out := &in.RescheduleCriticalSeconds, &out.RescheduleCriticalSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UnschedulableLastSeconds != nil {
+		in, out := &in.UnschedulableLastSeconds, &out.UnschedulableLastSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdaptiveUnitedDeploymentStrategy.
+func (in *AdaptiveUnitedDeploymentStrategy) DeepCopy() *AdaptiveUnitedDeploymentStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(AdaptiveUnitedDeploymentStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AdaptiveWorkloadSpreadStrategy) DeepCopyInto(out *AdaptiveWorkloadSpreadStrategy) {
 	*out = *in
@@ -191,6 +216,33 @@ func (in *AdvancedStatefulSetTemplateSpec) DeepCopy() *AdvancedStatefulSetTempla
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoUpdate) DeepCopyInto(out *AutoUpdate) {
+	*out = *in
+	if in.MaxParallelSubsets != nil {
+		in, out := &in.MaxParallelSubsets, &out.MaxParallelSubsets
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Priorities != nil {
+		in, out := &in.Priorities, &out.Priorities
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoUpdate.
+func (in *AutoUpdate) DeepCopy() *AutoUpdate {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoUpdate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BroadcastJob) DeepCopyInto(out *BroadcastJob) {
 	*out = *in
@@ -250,6 +302,21 @@ func (in *BroadcastJobList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BroadcastJobNodeResult) DeepCopyInto(out *BroadcastJobNodeResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BroadcastJobNodeResult.
+func (in *BroadcastJobNodeResult) DeepCopy() *BroadcastJobNodeResult {
+	if in == nil {
+		return nil
+	}
+	out := new(BroadcastJobNodeResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BroadcastJobSpec) DeepCopyInto(out *BroadcastJobSpec) {
 	*out = *in
@@ -260,7 +327,12 @@ func (in *BroadcastJobSpec) DeepCopyInto(out *BroadcastJobSpec) {
 	}
 	in.Template.DeepCopyInto(&out.Template)
 	in.CompletionPolicy.DeepCopyInto(&out.CompletionPolicy)
-	out.FailurePolicy = in.FailurePolicy
+	in.FailurePolicy.DeepCopyInto(&out.FailurePolicy)
+	if in.TopologyParallelism != nil {
+		in, out := &in.TopologyParallelism, &out.TopologyParallelism
+		*out = make([]BroadcastJobTopologyParallelism, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BroadcastJobSpec.
@@ -291,6 +363,11 @@ func (in *BroadcastJobStatus) DeepCopyInto(out *BroadcastJobStatus) {
 		in, out := &in.CompletionTime, &out.CompletionTime
 		*out = (*in).DeepCopy()
 	}
+	if in.NodeResults != nil {
+		in, out := &in.NodeResults, &out.NodeResults
+		*out = make([]BroadcastJobNodeResult, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BroadcastJobStatus.
@@ -320,6 +397,22 @@ func (in *BroadcastJobTemplateSpec) DeepCopy() *BroadcastJobTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BroadcastJobTopologyParallelism) DeepCopyInto(out *BroadcastJobTopologyParallelism) {
+	*out = *in
+	out.MaxParallelism = in.MaxParallelism
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BroadcastJobTopologyParallelism.
+func (in *BroadcastJobTopologyParallelism) DeepCopy() *BroadcastJobTopologyParallelism {
+	if in == nil {
+		return nil
+	}
+	out := new(BroadcastJobTopologyParallelism)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloneSet) DeepCopyInto(out *CloneSet) {
 	*out = *in
@@ -873,6 +966,23 @@ func (in *CronJobTemplate) DeepCopy() *CronJobTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomWorkloadTemplateSpec) DeepCopyInto(out *CustomWorkloadTemplateSpec) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomWorkloadTemplateSpec.
+func (in *CustomWorkloadTemplateSpec) DeepCopy() *CustomWorkloadTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomWorkloadTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DaemonSet) DeepCopyInto(out *DaemonSet) {
 	*out = *in
@@ -932,6 +1042,47 @@ func (in *DaemonSetList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonSetNodeDrainPolicy) DeepCopyInto(out *DaemonSetNodeDrainPolicy) {
+	*out = *in
+	if in.DependentPodSelector != nil {
+		in, out := &in.DependentPodSelector, &out.DependentPodSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonSetNodeDrainPolicy.
+func (in *DaemonSetNodeDrainPolicy) DeepCopy() *DaemonSetNodeDrainPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonSetNodeDrainPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonSetNodeOverride) DeepCopyInto(out *DaemonSetNodeOverride) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Patch.DeepCopyInto(&out.Patch)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonSetNodeOverride.
+func (in *DaemonSetNodeOverride) DeepCopy() *DaemonSetNodeOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonSetNodeOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DaemonSetSpec) DeepCopyInto(out *DaemonSetSpec) {
 	*out = *in
@@ -941,6 +1092,13 @@ func (in *DaemonSetSpec) DeepCopyInto(out *DaemonSetSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.Template.DeepCopyInto(&out.Template)
+	if in.NodeOverrides != nil {
+		in, out := &in.NodeOverrides, &out.NodeOverrides
+		*out = make([]DaemonSetNodeOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.UpdateStrategy.DeepCopyInto(&out.UpdateStrategy)
 	if in.BurstReplicas != nil {
 		in, out := &in.BurstReplicas, &out.BurstReplicas
@@ -984,6 +1142,11 @@ func (in *DaemonSetStatus) DeepCopyInto(out *DaemonSetStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.CurrentWave != nil {
+		in, out := &in.CurrentWave, &out.CurrentWave
+		*out = new(DaemonSetUpdateWaveStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonSetStatus.
@@ -996,6 +1159,31 @@ func (in *DaemonSetStatus) DeepCopy() *DaemonSetStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonSetUpdateFailureBudget) DeepCopyInto(out *DaemonSetUpdateFailureBudget) {
+	*out = *in
+	if in.MaxFailed != nil {
+		in, out := &in.MaxFailed, &out.MaxFailed
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.ProgressDeadlineSeconds != nil {
+		in, out := &in.ProgressDeadlineSeconds, &out.ProgressDeadlineSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonSetUpdateFailureBudget.
+func (in *DaemonSetUpdateFailureBudget) DeepCopy() *DaemonSetUpdateFailureBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonSetUpdateFailureBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DaemonSetUpdateStrategy) DeepCopyInto(out *DaemonSetUpdateStrategy) {
 	*out = *in
@@ -1016,6 +1204,50 @@ func (in *DaemonSetUpdateStrategy) DeepCopy() *DaemonSetUpdateStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonSetUpdateWave) DeepCopyInto(out *DaemonSetUpdateWave) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxUpdated != nil {
+		in, out := &in.MaxUpdated, &out.MaxUpdated
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonSetUpdateWave.
+func (in *DaemonSetUpdateWave) DeepCopy() *DaemonSetUpdateWave {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonSetUpdateWave)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonSetUpdateWaveStatus) DeepCopyInto(out *DaemonSetUpdateWaveStatus) {
+	*out = *in
+	if in.CompletedTime != nil {
+		in, out := &in.CompletedTime, &out.CompletedTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonSetUpdateWaveStatus.
+func (in *DaemonSetUpdateWaveStatus) DeepCopy() *DaemonSetUpdateWaveStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonSetUpdateWaveStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentTemplateSpec) DeepCopyInto(out *DeploymentTemplateSpec) {
 	*out = *in
@@ -1220,6 +1452,11 @@ func (in *FailedImageStatus) DeepCopy() *FailedImageStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FailurePolicy) DeepCopyInto(out *FailurePolicy) {
 	*out = *in
+	if in.BackoffSeconds != nil {
+		in, out := &in.BackoffSeconds, &out.BackoffSeconds
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailurePolicy.
@@ -2584,6 +2821,23 @@ func (in *RollingUpdateDaemonSet) DeepCopyInto(out *RollingUpdateDaemonSet) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Waves != nil {
+		in, out := &in.Waves, &out.Waves
+		*out = make([]DaemonSetUpdateWave, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeDrain != nil {
+		in, out := &in.NodeDrain, &out.NodeDrain
+		*out = new(DaemonSetNodeDrainPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FailureBudget != nil {
+		in, out := &in.FailureBudget, &out.FailureBudget
+		*out = new(DaemonSetUpdateFailureBudget)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpdateDaemonSet.
@@ -3180,6 +3434,11 @@ func (in *Subset) DeepCopyInto(out *Subset) {
 		**out = **in
 	}
 	in.Patch.DeepCopyInto(&out.Patch)
+	if in.Overrides != nil {
+		in, out := &in.Overrides, &out.Overrides
+		*out = new(SubsetOverrides)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Subset.
@@ -3192,6 +3451,56 @@ func (in *Subset) DeepCopy() *Subset {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubsetContainerOverride) DeepCopyInto(out *SubsetContainerOverride) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubsetContainerOverride.
+func (in *SubsetContainerOverride) DeepCopy() *SubsetContainerOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(SubsetContainerOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubsetOverrides) DeepCopyInto(out *SubsetOverrides) {
+	*out = *in
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]SubsetContainerOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubsetOverrides.
+func (in *SubsetOverrides) DeepCopy() *SubsetOverrides {
+	if in == nil {
+		return nil
+	}
+	out := new(SubsetOverrides)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SubsetTemplate) DeepCopyInto(out *SubsetTemplate) {
 	*out = *in
@@ -3215,6 +3524,11 @@ func (in *SubsetTemplate) DeepCopyInto(out *SubsetTemplate) {
 		*out = new(DeploymentTemplateSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CustomWorkloadTemplate != nil {
+		in, out := &in.CustomWorkloadTemplate, &out.CustomWorkloadTemplate
+		*out = new(CustomWorkloadTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubsetTemplate.
@@ -3268,6 +3582,7 @@ func (in *Topology) DeepCopyInto(out *Topology) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	in.ScheduleStrategy.DeepCopyInto(&out.ScheduleStrategy)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Topology.
@@ -3380,6 +3695,26 @@ func (in *UnitedDeploymentList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnitedDeploymentScheduleStrategy) DeepCopyInto(out *UnitedDeploymentScheduleStrategy) {
+	*out = *in
+	if in.Adaptive != nil {
+		in, out := &in.Adaptive, &out.Adaptive
+		*out = new(AdaptiveUnitedDeploymentStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnitedDeploymentScheduleStrategy.
+func (in *UnitedDeploymentScheduleStrategy) DeepCopy() *UnitedDeploymentScheduleStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(UnitedDeploymentScheduleStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UnitedDeploymentSpec) DeepCopyInto(out *UnitedDeploymentSpec) {
 	*out = *in
@@ -3440,6 +3775,13 @@ func (in *UnitedDeploymentStatus) DeepCopyInto(out *UnitedDeploymentStatus) {
 		*out = new(UpdateStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SubsetStatuses != nil {
+		in, out := &in.SubsetStatuses, &out.SubsetStatuses
+		*out = make([]UnitedDeploymentSubsetStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnitedDeploymentStatus.
@@ -3452,6 +3794,59 @@ func (in *UnitedDeploymentStatus) DeepCopy() *UnitedDeploymentStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnitedDeploymentSubsetCondition) DeepCopyInto(out *UnitedDeploymentSubsetCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnitedDeploymentSubsetCondition.
+func (in *UnitedDeploymentSubsetCondition) DeepCopy() *UnitedDeploymentSubsetCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(UnitedDeploymentSubsetCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnitedDeploymentSubsetStatus) DeepCopyInto(out *UnitedDeploymentSubsetStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]UnitedDeploymentSubsetCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnitedDeploymentSubsetStatus.
+func (in *UnitedDeploymentSubsetStatus) DeepCopy() *UnitedDeploymentSubsetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UnitedDeploymentSubsetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnitedDeploymentSubsetUpdateStatus) DeepCopyInto(out *UnitedDeploymentSubsetUpdateStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnitedDeploymentSubsetUpdateStatus.
+func (in *UnitedDeploymentSubsetUpdateStatus) DeepCopy() *UnitedDeploymentSubsetUpdateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UnitedDeploymentSubsetUpdateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UnitedDeploymentUpdateStrategy) DeepCopyInto(out *UnitedDeploymentUpdateStrategy) {
 	*out = *in
@@ -3460,6 +3855,11 @@ func (in *UnitedDeploymentUpdateStrategy) DeepCopyInto(out *UnitedDeploymentUpda
 		*out = new(ManualUpdate)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AutoUpdate != nil {
+		in, out := &in.AutoUpdate, &out.AutoUpdate
+		*out = new(AutoUpdate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnitedDeploymentUpdateStrategy.
@@ -3536,6 +3936,11 @@ func (in *UpdateStatus) DeepCopyInto(out *UpdateStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.SubsetUpdateStatuses != nil {
+		in, out := &in.SubsetUpdateStatuses, &out.SubsetUpdateStatuses
+		*out = make([]UnitedDeploymentSubsetUpdateStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateStatus.
@@ -3607,6 +4012,70 @@ func (in *WorkloadSpreadList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadSpreadRebalanceStatus) DeepCopyInto(out *WorkloadSpreadRebalanceStatus) {
+	*out = *in
+	if in.LastRebalanceTime != nil {
+		in, out := &in.LastRebalanceTime, &out.LastRebalanceTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRebalancedPods != nil {
+		in, out := &in.LastRebalancedPods, &out.LastRebalancedPods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadSpreadRebalanceStatus.
+func (in *WorkloadSpreadRebalanceStatus) DeepCopy() *WorkloadSpreadRebalanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadSpreadRebalanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadSpreadRebalanceStrategy) DeepCopyInto(out *WorkloadSpreadRebalanceStrategy) {
+	*out = *in
+	if in.MaxDeletionsPerInterval != nil {
+		in, out := &in.MaxDeletionsPerInterval, &out.MaxDeletionsPerInterval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IntervalSeconds != nil {
+		in, out := &in.IntervalSeconds, &out.IntervalSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadSpreadRebalanceStrategy.
+func (in *WorkloadSpreadRebalanceStrategy) DeepCopy() *WorkloadSpreadRebalanceStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadSpreadRebalanceStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadSpreadRevisionReplicas) DeepCopyInto(out *WorkloadSpreadRevisionReplicas) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadSpreadRevisionReplicas.
+func (in *WorkloadSpreadRevisionReplicas) DeepCopy() *WorkloadSpreadRevisionReplicas {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadSpreadRevisionReplicas)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadSpreadScheduleStrategy) DeepCopyInto(out *WorkloadSpreadScheduleStrategy) {
 	*out = *in
@@ -3643,6 +4112,11 @@ func (in *WorkloadSpreadSpec) DeepCopyInto(out *WorkloadSpreadSpec) {
 		}
 	}
 	in.ScheduleStrategy.DeepCopyInto(&out.ScheduleStrategy)
+	if in.RebalanceStrategy != nil {
+		in, out := &in.RebalanceStrategy, &out.RebalanceStrategy
+		*out = new(WorkloadSpreadRebalanceStrategy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadSpreadSpec.
@@ -3683,6 +4157,18 @@ func (in *WorkloadSpreadStatus) DeepCopyInto(out *WorkloadSpreadStatus) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.RebalanceStatus != nil {
+		in, out := &in.RebalanceStatus, &out.RebalanceStatus
+		*out = new(WorkloadSpreadRebalanceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SubsetRevisionStatuses != nil {
+		in, out := &in.SubsetRevisionStatuses, &out.SubsetRevisionStatuses
+		*out = make([]WorkloadSpreadSubsetRevisionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadSpreadStatus.
@@ -3722,6 +4208,11 @@ func (in *WorkloadSpreadSubset) DeepCopyInto(out *WorkloadSpreadSubset) {
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int32)
+		**out = **in
+	}
 	in.Patch.DeepCopyInto(&out.Patch)
 }
 
@@ -3751,6 +4242,26 @@ func (in *WorkloadSpreadSubsetCondition) DeepCopy() *WorkloadSpreadSubsetConditi
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadSpreadSubsetRevisionStatus) DeepCopyInto(out *WorkloadSpreadSubsetRevisionStatus) {
+	*out = *in
+	if in.Revisions != nil {
+		in, out := &in.Revisions, &out.Revisions
+		*out = make([]WorkloadSpreadRevisionReplicas, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadSpreadSubsetRevisionStatus.
+func (in *WorkloadSpreadSubsetRevisionStatus) DeepCopy() *WorkloadSpreadSubsetRevisionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadSpreadSubsetRevisionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadSpreadSubsetStatus) DeepCopyInto(out *WorkloadSpreadSubsetStatus) {
 	*out = *in
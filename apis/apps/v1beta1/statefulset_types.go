@@ -29,6 +29,15 @@ const (
 	MaxMinReadySeconds = 300
 )
 
+const (
+	// VolumeSnapshotAnnotation records on the PVC the name of VolumeSnapshot taken before the last update of its pod.
+	// It is recorded on the PVC rather than the pod, because the pod is deleted when it is updated by recreation.
+	VolumeSnapshotAnnotation = "apps.kruise.io/volume-snapshot"
+	// VolumeSnapshotStatefulSetLabel is the label of VolumeSnapshots that records the name of the StatefulSet created them.
+	// Names longer than 63 characters are truncated with a hash suffix.
+	VolumeSnapshotStatefulSetLabel = "apps.kruise.io/snapshot-statefulset"
+)
+
 // StatefulSetUpdateStrategy indicates the strategy that the StatefulSet
 // controller will use to perform updates. It includes any additional parameters
 // necessary to perform the update for the indicated strategy.
@@ -81,6 +90,21 @@ type RollingUpdateStatefulSetStrategy struct {
 	// Default value is 0, max is 300.
 	// +optional
 	MinReadySeconds *int32 `json:"minReadySeconds,omitempty"`
+	// SnapshotBeforeUpdate indicates that VolumeSnapshots of the pod's PVCs should be taken
+	// and ready to use before the pod is updated, either in-place or by recreation.
+	// +optional
+	SnapshotBeforeUpdate *SnapshotBeforeUpdateStrategy `json:"snapshotBeforeUpdate,omitempty"`
+}
+
+// SnapshotBeforeUpdateStrategy defines the policy of snapshotting the PVCs of a pod before updating it.
+type SnapshotBeforeUpdateStrategy struct {
+	// VolumeSnapshotClassName is the name of the VolumeSnapshotClass used to create the snapshots.
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName"`
+	// SnapshotsToKeep is the number of the latest snapshots to keep for each PVC,
+	// older ones will be deleted by the controller.
+	// Default value is 1.
+	// +optional
+	SnapshotsToKeep *int32 `json:"snapshotsToKeep,omitempty"`
 }
 
 // UnorderedUpdateStrategy defines strategies for non-ordered update.
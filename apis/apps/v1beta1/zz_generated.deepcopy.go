@@ -57,6 +57,11 @@ func (in *RollingUpdateStatefulSetStrategy) DeepCopyInto(out *RollingUpdateState
 		*out = new(int32)
 		**out = **in
 	}
+	if in.SnapshotBeforeUpdate != nil {
+		in, out := &in.SnapshotBeforeUpdate, &out.SnapshotBeforeUpdate
+		*out = new(SnapshotBeforeUpdateStrategy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpdateStatefulSetStrategy.
@@ -69,6 +74,26 @@ func (in *RollingUpdateStatefulSetStrategy) DeepCopy() *RollingUpdateStatefulSet
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotBeforeUpdateStrategy) DeepCopyInto(out *SnapshotBeforeUpdateStrategy) {
+	*out = *in
+	if in.SnapshotsToKeep != nil {
+		in, out := &in.SnapshotsToKeep, &out.SnapshotsToKeep
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotBeforeUpdateStrategy.
+func (in *SnapshotBeforeUpdateStrategy) DeepCopy() *SnapshotBeforeUpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotBeforeUpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StatefulSet) DeepCopyInto(out *StatefulSet) {
 	*out = *in
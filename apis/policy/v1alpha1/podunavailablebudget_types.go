@@ -59,6 +59,73 @@ type PodUnavailableBudgetSpec struct {
 	// Delete pod, evict pod or update pod specification is allowed if at least "minAvailable" pods selected by
 	// "selector" or "targetRef" will still be available after the above operation for pod.
 	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// TopologyBudget limits the unavailable pods in each topology domain, e.g. zone, in addition to
+	// MaxUnavailable or MinAvailable across all the pods. Pods that are not scheduled or whose node has
+	// no topologyKey label are only limited by the global budget.
+	// +optional
+	TopologyBudget *PubTopologyBudget `json:"topologyBudget,omitempty"`
+
+	// DisruptiveFields declares the pod metadata whose changes take the pod out of service, so that such
+	// updates of pod are counted against the budget. Changes of pod spec are always considered disruptive.
+	// +optional
+	DisruptiveFields *PubDisruptiveFields `json:"disruptiveFields,omitempty"`
+
+	// Schedules overrides MaxUnavailable and MinAvailable in the time windows, e.g. to be stricter in
+	// business hours. If multiple windows are active, the first one in the list takes effect.
+	// MaxUnavailable and MinAvailable of spec take effect when there is no active window.
+	// +optional
+	Schedules []PubSchedule `json:"schedules,omitempty"`
+}
+
+// PubSchedule defines the budget of a periodic time window.
+type PubSchedule struct {
+	// Name is the unique name of the schedule.
+	Name string `json:"name"`
+
+	// Schedule is the start time of the window in Cron format, see https://en.wikipedia.org/wiki/Cron.
+	Schedule string `json:"schedule"`
+
+	// Duration is how long the window lasts after each start time, e.g. "8h".
+	Duration metav1.Duration `json:"duration"`
+
+	// The time zone name for the given schedule, see https://en.wikipedia.org/wiki/List_of_tz_database_time_zones.
+	// If not specified, this will default to the time zone of the kruise-controller-manager process.
+	// +optional
+	TimeZone *string `json:"timeZone,omitempty"`
+
+	// MaxUnavailable takes the place of MaxUnavailable of spec in the window.
+	// MaxUnavailable and MinAvailable are mutually exclusive, MaxUnavailable is priority to take effect
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// MinAvailable takes the place of MinAvailable of spec in the window.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+}
+
+// PubDisruptiveFields declares the pod labels and annotations whose changes are disruptive.
+// A key ending with "*" matches all the keys with the prefix before "*".
+type PubDisruptiveFields struct {
+	// LabelKeys is the keys of pod labels whose addition, removal or value change is disruptive.
+	// +optional
+	LabelKeys []string `json:"labelKeys,omitempty"`
+
+	// AnnotationKeys is the keys of pod annotations whose addition, removal or value change is disruptive.
+	// +optional
+	AnnotationKeys []string `json:"annotationKeys,omitempty"`
+}
+
+// PubTopologyBudget defines the budget of unavailable pods in each topology domain.
+type PubTopologyBudget struct {
+	// TopologyKey is the key of node labels. Nodes that have a label with this key and identical values
+	// are considered to be in the same topology domain.
+	TopologyKey string `json:"topologyKey"`
+
+	// Delete pod, evict pod or update pod specification is allowed if at most "maxUnavailable" pods in the
+	// topology domain of the pod are unavailable after the above operation for pod.
+	// The percentage is calculated from the number of active pods in the topology domain.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable"`
 }
 
 // TargetReference contains enough information to let you identify an workload for PodUnavailableBudget
@@ -99,6 +166,15 @@ type PodUnavailableBudgetStatus struct {
 
 	// TotalReplicas total number of pods counted by this unavailable budget
 	TotalReplicas int32 `json:"totalReplicas"`
+
+	// TopologyUnavailableAllowed is the number of pod unavailable that are currently allowed in each
+	// topology domain, the key is the value of topologyKey label of nodes.
+	// +optional
+	TopologyUnavailableAllowed map[string]int32 `json:"topologyUnavailableAllowed,omitempty"`
+
+	// ActiveSchedule is the name of the schedule whose window is active, empty means no window is active.
+	// +optional
+	ActiveSchedule string `json:"activeSchedule,omitempty"`
 }
 
 // +genclient
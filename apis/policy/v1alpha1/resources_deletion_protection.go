@@ -16,8 +16,12 @@ limitations under the License.
 
 package v1alpha1
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 const (
-	// DeletionProtectionKey is a key in object labels and its value can be Always and Cascading.
+	// DeletionProtectionKey is a key in object labels and its value can be Always, AlwaysWithApproval, Cascading and CascadingStrict.
 	// Currently supports Namespace, CustomResourcesDefinition, Deployment, StatefulSet, ReplicaSet, CloneSet, Advanced StatefulSet, UnitedDeployment.
 	DeletionProtectionKey = "policy.kruise.io/delete-protection"
 
@@ -25,4 +29,25 @@ const (
 	DeletionProtectionTypeAlways = "Always"
 	// DeletionProtectionTypeCascading indicates this object will be forbidden to be deleted, if it has active resources owned.
 	DeletionProtectionTypeCascading = "Cascading"
+	// DeletionProtectionTypeCascadingStrict indicates this workload will be forbidden to be deleted as Cascading,
+	// and also forbidden to be scaled from >0 to 0 or to change its selector, including by the scale subresource.
+	// It is only supported for CloneSet, Advanced StatefulSet, UnitedDeployment, Deployment and StatefulSet,
+	// and it is the same as Cascading for ReplicaSet.
+	DeletionProtectionTypeCascadingStrict = "CascadingStrict"
+	// DeletionProtectionTypeAlwaysWithApproval indicates this object will be forbidden to be deleted, unless the deletion
+	// is approved by the DeletionProtectionApprovalKey annotation. Only the configured approvers can modify the annotation,
+	// or change the label from AlwaysWithApproval. It is the same as Always for the resources whose webhook can not check
+	// the approval annotation, including Namespace, Service, Ingress, CustomResourcesDefinition, Deployment, StatefulSet and ReplicaSet.
+	DeletionProtectionTypeAlwaysWithApproval = "AlwaysWithApproval"
+
+	// DeletionProtectionApprovalKey is a key in object annotations, and its value is a json of DeletionProtectionApproval.
+	DeletionProtectionApprovalKey = "policy.kruise.io/delete-protection-approval"
 )
+
+// DeletionProtectionApproval approves the requester to delete the object labeled with AlwaysWithApproval before it expires.
+type DeletionProtectionApproval struct {
+	// Requester is the username who is allowed to delete the object.
+	Requester string `json:"requester"`
+	// ExpireTime is the time after which the approval is invalid.
+	ExpireTime metav1.Time `json:"expireTime"`
+}
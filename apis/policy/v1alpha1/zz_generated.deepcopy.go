@@ -26,6 +26,22 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeletionProtectionApproval) DeepCopyInto(out *DeletionProtectionApproval) {
+	*out = *in
+	in.ExpireTime.DeepCopyInto(&out.ExpireTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeletionProtectionApproval.
+func (in *DeletionProtectionApproval) DeepCopy() *DeletionProtectionApproval {
+	if in == nil {
+		return nil
+	}
+	out := new(DeletionProtectionApproval)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodUnavailableBudget) DeepCopyInto(out *PodUnavailableBudget) {
 	*out = *in
@@ -108,6 +124,23 @@ func (in *PodUnavailableBudgetSpec) DeepCopyInto(out *PodUnavailableBudgetSpec)
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.TopologyBudget != nil {
+		in, out := &in.TopologyBudget, &out.TopologyBudget
+		*out = new(PubTopologyBudget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DisruptiveFields != nil {
+		in, out := &in.DisruptiveFields, &out.DisruptiveFields
+		*out = new(PubDisruptiveFields)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schedules != nil {
+		in, out := &in.Schedules, &out.Schedules
+		*out = make([]PubSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodUnavailableBudgetSpec.
@@ -137,6 +170,13 @@ func (in *PodUnavailableBudgetStatus) DeepCopyInto(out *PodUnavailableBudgetStat
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.TopologyUnavailableAllowed != nil {
+		in, out := &in.TopologyUnavailableAllowed, &out.TopologyUnavailableAllowed
+		*out = make(map[string]int32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodUnavailableBudgetStatus.
@@ -149,6 +189,82 @@ func (in *PodUnavailableBudgetStatus) DeepCopy() *PodUnavailableBudgetStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PubDisruptiveFields) DeepCopyInto(out *PubDisruptiveFields) {
+	*out = *in
+	if in.LabelKeys != nil {
+		in, out := &in.LabelKeys, &out.LabelKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AnnotationKeys != nil {
+		in, out := &in.AnnotationKeys, &out.AnnotationKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PubDisruptiveFields.
+func (in *PubDisruptiveFields) DeepCopy() *PubDisruptiveFields {
+	if in == nil {
+		return nil
+	}
+	out := new(PubDisruptiveFields)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PubSchedule) DeepCopyInto(out *PubSchedule) {
+	*out = *in
+	out.Duration = in.Duration
+	if in.TimeZone != nil {
+		in, out := &in.TimeZone, &out.TimeZone
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PubSchedule.
+func (in *PubSchedule) DeepCopy() *PubSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(PubSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PubTopologyBudget) DeepCopyInto(out *PubTopologyBudget) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PubTopologyBudget.
+func (in *PubTopologyBudget) DeepCopy() *PubTopologyBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(PubTopologyBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetReference) DeepCopyInto(out *TargetReference) {
 	*out = *in
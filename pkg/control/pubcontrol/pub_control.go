@@ -27,6 +27,7 @@ import (
 	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
 	"github.com/openkruise/kruise/pkg/util"
 	utilclient "github.com/openkruise/kruise/pkg/util/client"
+	"github.com/openkruise/kruise/pkg/util/configuration"
 	"github.com/openkruise/kruise/pkg/util/controllerfinder"
 	"github.com/openkruise/kruise/pkg/util/inplaceupdate"
 	corev1 "k8s.io/api/core/v1"
@@ -64,10 +65,69 @@ func (c *commonControl) IsPodUnavailableChanged(oldPod, newPod *corev1.Pod) bool
 	if !appspub.HasUnavailableLabel(oldPod.Labels) && appspub.HasUnavailableLabel(newPod.Labels) {
 		return true
 	}
+	// pod changes the labels or annotations declared disruptive globally or by pub
+	if c.isPodDisruptiveFieldsChanged(oldPod, newPod) {
+		klog.V(3).InfoS("Pod disruptive labels or annotations changed, and maybe cause unavailability", "pod", klog.KObj(newPod))
+		return true
+	}
 	// pod other changes will not cause unavailability situation, then return false
 	return false
 }
 
+func (c *commonControl) isPodDisruptiveFieldsChanged(oldPod, newPod *corev1.Pod) bool {
+	if reflect.DeepEqual(oldPod.Labels, newPod.Labels) && reflect.DeepEqual(oldPod.Annotations, newPod.Annotations) {
+		return false
+	}
+	// only the pods protected by pub are checked
+	if newPod.Annotations[PodRelatedPubAnnotation] == "" {
+		return false
+	}
+	var disruptiveFields []*policyv1alpha1.PubDisruptiveFields
+	if globalFields, err := configuration.GetPUBDisruptiveFields(c.Client); err != nil {
+		klog.ErrorS(err, "Failed to get pub disruptive fields from kruise configuration")
+	} else if globalFields != nil {
+		disruptiveFields = append(disruptiveFields, globalFields)
+	}
+	if pub, err := c.GetPubForPod(newPod); err != nil {
+		klog.ErrorS(err, "Failed to get pub for pod", "pod", klog.KObj(newPod))
+	} else if pub != nil && pub.Spec.DisruptiveFields != nil {
+		disruptiveFields = append(disruptiveFields, pub.Spec.DisruptiveFields)
+	}
+
+	for _, fields := range disruptiveFields {
+		if isMapKeysChanged(oldPod.Labels, newPod.Labels, fields.LabelKeys) ||
+			isMapKeysChanged(oldPod.Annotations, newPod.Annotations, fields.AnnotationKeys) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMapKeysChanged returns true if any of the keys is added, removed or changed from oldMap to newMap.
+// A key ending with "*" matches all the keys with the prefix before "*".
+func isMapKeysChanged(oldMap, newMap map[string]string, keys []string) bool {
+	for _, key := range keys {
+		if prefix := strings.TrimSuffix(key, "*"); prefix != key {
+			for _, m := range []map[string]string{oldMap, newMap} {
+				for k := range m {
+					if strings.HasPrefix(k, prefix) && !isMapValueEqual(oldMap, newMap, k) {
+						return true
+					}
+				}
+			}
+		} else if !isMapValueEqual(oldMap, newMap, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func isMapValueEqual(oldMap, newMap map[string]string, key string) bool {
+	oldValue, oldOK := oldMap[key]
+	newValue, newOK := newMap[key]
+	return oldOK == newOK && oldValue == newValue
+}
+
 // GetPodsForPub returns Pods protected by the pub object.
 // return two parameters
 // 1. podList
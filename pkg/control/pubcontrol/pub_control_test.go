@@ -21,7 +21,13 @@ import (
 	"testing"
 
 	"github.com/openkruise/kruise/apis/apps/pub"
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util"
+	"github.com/openkruise/kruise/pkg/util/configuration"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestIsPodUnavailableChanged(t *testing.T) {
@@ -29,6 +35,8 @@ func TestIsPodUnavailableChanged(t *testing.T) {
 		name      string
 		getOldPod func() *corev1.Pod
 		getNewPod func() *corev1.Pod
+		getPub    func() *policyv1alpha1.PodUnavailableBudget
+		getConfig func() *corev1.ConfigMap
 		expect    bool
 	}{
 		{
@@ -70,11 +78,103 @@ func TestIsPodUnavailableChanged(t *testing.T) {
 			},
 			expect: true,
 		},
+		{
+			name: "annotation declared disruptive by pub changed",
+			getOldPod: func() *corev1.Pod {
+				demo := podDemo.DeepCopy()
+				return demo
+			},
+			getNewPod: func() *corev1.Pod {
+				demo := podDemo.DeepCopy()
+				demo.Annotations["proxy.example.com/offline"] = "true"
+				return demo
+			},
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Spec.DisruptiveFields = &policyv1alpha1.PubDisruptiveFields{
+					AnnotationKeys: []string{"proxy.example.com/offline"},
+				}
+				return pub
+			},
+			expect: true,
+		},
+		{
+			name: "annotation not declared disruptive by pub changed",
+			getOldPod: func() *corev1.Pod {
+				demo := podDemo.DeepCopy()
+				return demo
+			},
+			getNewPod: func() *corev1.Pod {
+				demo := podDemo.DeepCopy()
+				demo.Annotations["proxy.example.com/weight"] = "10"
+				return demo
+			},
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Spec.DisruptiveFields = &policyv1alpha1.PubDisruptiveFields{
+					AnnotationKeys: []string{"proxy.example.com/offline"},
+				}
+				return pub
+			},
+			expect: false,
+		},
+		{
+			name: "label matching prefix declared disruptive globally removed",
+			getOldPod: func() *corev1.Pod {
+				demo := podDemo.DeepCopy()
+				demo.Labels["gate.example.com/traffic"] = "on"
+				return demo
+			},
+			getNewPod: func() *corev1.Pod {
+				demo := podDemo.DeepCopy()
+				return demo
+			},
+			getConfig: func() *corev1.ConfigMap {
+				return &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: util.GetKruiseNamespace(), Name: configuration.KruiseConfigurationName},
+					Data: map[string]string{
+						configuration.PUBDisruptiveFieldsKey: `{"labelKeys":["gate.example.com/*"]}`,
+					},
+				}
+			},
+			expect: true,
+		},
+		{
+			name: "label declared disruptive globally changed on pod without pub",
+			getOldPod: func() *corev1.Pod {
+				demo := podDemo.DeepCopy()
+				delete(demo.Annotations, PodRelatedPubAnnotation)
+				demo.Labels["gate.example.com/traffic"] = "on"
+				return demo
+			},
+			getNewPod: func() *corev1.Pod {
+				demo := podDemo.DeepCopy()
+				delete(demo.Annotations, PodRelatedPubAnnotation)
+				return demo
+			},
+			getConfig: func() *corev1.ConfigMap {
+				return &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: util.GetKruiseNamespace(), Name: configuration.KruiseConfigurationName},
+					Data: map[string]string{
+						configuration.PUBDisruptiveFieldsKey: `{"labelKeys":["gate.example.com/*"]}`,
+					},
+				}
+			},
+			expect: false,
+		},
 	}
 
 	for _, cs := range cases {
 		t.Run(cs.name, func(t *testing.T) {
-			control := commonControl{}
+			builder := fake.NewClientBuilder().WithScheme(scheme)
+			var objects []client.Object
+			if cs.getPub != nil {
+				objects = append(objects, cs.getPub())
+			}
+			if cs.getConfig != nil {
+				objects = append(objects, cs.getConfig())
+			}
+			control := commonControl{Client: builder.WithObjects(objects...).Build()}
 			is := control.IsPodUnavailableChanged(cs.getOldPod(), cs.getNewPod())
 			if cs.expect != is {
 				t.Fatalf("IsPodUnavailableChanged failed")
@@ -32,10 +32,13 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
 	kubeClient "github.com/openkruise/kruise/pkg/client"
+	"github.com/openkruise/kruise/pkg/features"
 	"github.com/openkruise/kruise/pkg/util"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 )
 
 const (
@@ -60,34 +63,16 @@ const (
 // 2. err(error)
 func PodUnavailableBudgetValidatePod(pod *corev1.Pod, operation policyv1alpha1.PubOperation, username string, dryRun bool) (allowed bool, reason string, err error) {
 	klog.V(3).InfoS("Validated pod operation for podUnavailableBudget", "pod", klog.KObj(pod), "operation", operation)
-	// pods that contain annotations[pod.kruise.io/pub-no-protect]="true" will be ignore
-	// and will no longer check the pub quota
-	if pod.Annotations[policyv1alpha1.PodPubNoProtectionAnnotation] == "true" {
-		klog.V(3).InfoS("Pod contained annotations=true, then didn't need check pub", "pod", klog.KObj(pod), "annotations", policyv1alpha1.PodPubNoProtectionAnnotation)
-		return true, "", nil
-		// If the pod is not ready or state is inconsistent, it doesn't count towards healthy and we should not decrement
-	} else if !PubControl.IsPodReady(pod) || !PubControl.IsPodStateConsistent(pod) {
-		klog.V(3).InfoS("Pod was not ready or state was inconsistent, then didn't need check pub", "pod", klog.KObj(pod))
-		return true, "", nil
-	}
-
-	// pub for pod
-	pub, err := PubControl.GetPubForPod(pod)
+	pub, err := getPubToCheckForPod(pod, operation)
 	if err != nil {
 		return false, "", err
-		// if there is no matching PodUnavailableBudget, just return true
 	} else if pub == nil {
 		return true, "", nil
-		// if desired available == 0, then allow all request
-	} else if pub.Status.DesiredAvailable == 0 {
-		return true, "", nil
-	} else if !isNeedPubProtection(pub, operation) {
-		klog.V(3).InfoS("Pod operation was not in pub protection", "pod", klog.KObj(pod), "operation", operation, "pubName", pub.Name)
-		return true, "", nil
-		// pod is in pub.Status.DisruptedPods or pub.Status.UnavailablePods, then don't need check it
-	} else if isPodRecordedInPub(pod.Name, pub) {
-		klog.V(3).InfoS("Pod was already recorded in pub", "pod", klog.KObj(pod), "pub", klog.KObj(pub))
-		return true, "", nil
+	}
+	// the topology domain of pod, which is empty if pub has no topology budget
+	domain, err := GetPodTopologyDomain(kclient, pod, pub)
+	if err != nil {
+		return false, "", err
 	}
 	// check and decrement pub quota
 	var conflictTimes int
@@ -136,7 +121,7 @@ func PodUnavailableBudgetValidatePod(pod *corev1.Pod, operation policyv1alpha1.P
 
 		// Try to verify-and-decrement
 		// If it was false already, or if it becomes false during the course of our retries,
-		err = checkAndDecrement(pod.Name, pubClone, operation)
+		err = checkAndDecrement(pod.Name, domain, pubClone, operation)
 		if err != nil {
 			var kind, namespace, name string
 			if ref := PubControl.GetPodControllerOf(pod); ref != nil {
@@ -199,15 +184,72 @@ func PodUnavailableBudgetValidatePod(pod *corev1.Pod, operation policyv1alpha1.P
 	return true, "", nil
 }
 
-func checkAndDecrement(podName string, pub *policyv1alpha1.PodUnavailableBudget, operation policyv1alpha1.PubOperation) error {
-	if pub.Status.UnavailableAllowed <= 0 {
+// AcquirePodDisruption asks pub for budget before kruise components disrupt the pod in place, such as updating
+// the containers or recreating the containers of pod, and records the disruption in pub status if allowed,
+// so that the disruptions from different components are counted against the same budget.
+// It always allows the disruption if PodUnavailableBudgetUpdateGate is disabled.
+func AcquirePodDisruption(pod *corev1.Pod, username string, dryRun bool) (allowed bool, reason string, err error) {
+	if !utilfeature.DefaultFeatureGate.Enabled(features.PodUnavailableBudgetUpdateGate) ||
+		pod.Annotations[PodRelatedPubAnnotation] == "" {
+		return true, "", nil
+	}
+	return PodUnavailableBudgetValidatePod(pod, policyv1alpha1.PubUpdateOperation, username, dryRun)
+}
+
+// getPubToCheckForPod returns the pub whose quota should be checked and decremented by the pod operation,
+// nil indicates that the operation is allowed without checking any pub.
+func getPubToCheckForPod(pod *corev1.Pod, operation policyv1alpha1.PubOperation) (*policyv1alpha1.PodUnavailableBudget, error) {
+	// pods that contain annotations[pod.kruise.io/pub-no-protect]="true" will be ignore
+	// and will no longer check the pub quota
+	if pod.Annotations[policyv1alpha1.PodPubNoProtectionAnnotation] == "true" {
+		klog.V(3).InfoS("Pod contained annotations=true, then didn't need check pub", "pod", klog.KObj(pod), "annotations", policyv1alpha1.PodPubNoProtectionAnnotation)
+		return nil, nil
+		// If the pod is not ready or state is inconsistent, it doesn't count towards healthy and we should not decrement
+	} else if !PubControl.IsPodReady(pod) || !PubControl.IsPodStateConsistent(pod) {
+		klog.V(3).InfoS("Pod was not ready or state was inconsistent, then didn't need check pub", "pod", klog.KObj(pod))
+		return nil, nil
+	}
+
+	// pub for pod
+	pub, err := PubControl.GetPubForPod(pod)
+	if err != nil {
+		return nil, err
+		// if there is no matching PodUnavailableBudget, then no pub needs to be checked
+	} else if pub == nil {
+		return nil, nil
+		// if desired available == 0 and there is no topology budget, then allow all requests
+	} else if pub.Status.DesiredAvailable == 0 && pub.Spec.TopologyBudget == nil {
+		return nil, nil
+	} else if !isNeedPubProtection(pub, operation) {
+		klog.V(3).InfoS("Pod operation was not in pub protection", "pod", klog.KObj(pod), "operation", operation, "pubName", pub.Name)
+		return nil, nil
+		// pod is in pub.Status.DisruptedPods or pub.Status.UnavailablePods, then don't need check it
+	} else if isPodRecordedInPub(pod.Name, pub) {
+		klog.V(3).InfoS("Pod was already recorded in pub", "pod", klog.KObj(pod), "pub", klog.KObj(pub))
+		return nil, nil
+	}
+	return pub, nil
+}
+
+// checkAndDecrement checks both the global quota and the quota of the topology domain of pod, domain is
+// empty if pub has no topology budget or pod is not in any topology domain.
+func checkAndDecrement(podName, domain string, pub *policyv1alpha1.PodUnavailableBudget, operation policyv1alpha1.PubOperation) error {
+	if pub.Status.DesiredAvailable > 0 && pub.Status.UnavailableAllowed <= 0 {
 		return errors.NewForbidden(policyv1alpha1.Resource("podunavailablebudget"), pub.Name, fmt.Errorf("pub unavailable allowed is negative"))
 	}
+	if domain != "" && pub.Status.TopologyUnavailableAllowed[domain] <= 0 {
+		return errors.NewForbidden(policyv1alpha1.Resource("podunavailablebudget"), pub.Name, fmt.Errorf("pub unavailable allowed in topology domain %s is negative", domain))
+	}
 	if len(pub.Status.DisruptedPods)+len(pub.Status.UnavailablePods) > MaxUnavailablePodSize {
 		return errors.NewForbidden(policyv1alpha1.Resource("podunavailablebudget"), pub.Name, fmt.Errorf("DisruptedPods and UnavailablePods map too big - too many unavailable not confirmed by PUB controller"))
 	}
 
-	pub.Status.UnavailableAllowed--
+	if pub.Status.UnavailableAllowed > 0 {
+		pub.Status.UnavailableAllowed--
+	}
+	if domain != "" {
+		pub.Status.TopologyUnavailableAllowed[domain]--
+	}
 
 	if pub.Status.DisruptedPods == nil {
 		pub.Status.DisruptedPods = make(map[string]metav1.Time)
@@ -226,6 +268,19 @@ func checkAndDecrement(podName string, pub *policyv1alpha1.PodUnavailableBudget,
 	return nil
 }
 
+// GetPodTopologyDomain returns the topology domain of pod, which is the topologyKey label value of its node.
+// It returns an empty string if pub has no topology budget, or pod is not scheduled.
+func GetPodTopologyDomain(reader client.Reader, pod *corev1.Pod, pub *policyv1alpha1.PodUnavailableBudget) (string, error) {
+	if pub.Spec.TopologyBudget == nil || pod.Spec.NodeName == "" {
+		return "", nil
+	}
+	node := &corev1.Node{}
+	if err := reader.Get(context.TODO(), types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	return node.Labels[pub.Spec.TopologyBudget.TopologyKey], nil
+}
+
 func isPodRecordedInPub(podName string, pub *policyv1alpha1.PodUnavailableBudget) bool {
 	if _, ok := pub.Status.UnavailablePods[podName]; ok {
 		return true
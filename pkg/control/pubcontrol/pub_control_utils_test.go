@@ -17,14 +17,19 @@ limitations under the License.
 package pubcontrol
 
 import (
+	"context"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/openkruise/kruise/apis/apps/pub"
 	appspub "github.com/openkruise/kruise/apis/apps/pub"
 	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/features"
+	"github.com/openkruise/kruise/pkg/util"
 	"github.com/openkruise/kruise/pkg/util/controllerfinder"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -35,6 +40,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 	utilpointer "k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
@@ -321,6 +327,98 @@ func TestPodUnavailableBudgetValidatePod(t *testing.T) {
 	}
 }
 
+func TestPodUnavailableBudgetValidatePodWithTopology(t *testing.T) {
+	cases := []struct {
+		name                 string
+		getPub               func() *policyv1alpha1.PodUnavailableBudget
+		nodeName             string
+		expectAllow          bool
+		expectTopologyStatus map[string]int32
+	}{
+		{
+			name: "global and topology budgets allow, decrement both",
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Status.UnavailableAllowed = 2
+				pub.Status.TopologyUnavailableAllowed = map[string]int32{"zone-a": 1, "zone-b": 1}
+				return pub
+			},
+			nodeName:             "node-a",
+			expectAllow:          true,
+			expectTopologyStatus: map[string]int32{"zone-a": 0, "zone-b": 1},
+		},
+		{
+			name: "global budget allows, but topology budget rejects",
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Status.UnavailableAllowed = 2
+				pub.Status.TopologyUnavailableAllowed = map[string]int32{"zone-a": 0, "zone-b": 1}
+				return pub
+			},
+			nodeName:             "node-a",
+			expectAllow:          false,
+			expectTopologyStatus: map[string]int32{"zone-a": 0, "zone-b": 1},
+		},
+		{
+			name: "topology budget allows, but global budget rejects",
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Status.UnavailableAllowed = 0
+				pub.Status.TopologyUnavailableAllowed = map[string]int32{"zone-a": 1, "zone-b": 1}
+				return pub
+			},
+			nodeName:             "node-a",
+			expectAllow:          false,
+			expectTopologyStatus: map[string]int32{"zone-a": 1, "zone-b": 1},
+		},
+		{
+			name: "pod is not in any topology domain, only check global budget",
+			getPub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Status.UnavailableAllowed = 1
+				pub.Status.TopologyUnavailableAllowed = map[string]int32{"zone-a": 0}
+				return pub
+			},
+			nodeName:             "node-no-zone",
+			expectAllow:          true,
+			expectTopologyStatus: map[string]int32{"zone-a": 0},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			pub := cs.getPub()
+			pub.Spec.TopologyBudget = &policyv1alpha1.PubTopologyBudget{
+				TopologyKey:    "topology.kubernetes.io/zone",
+				MaxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+			}
+			nodeA := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: map[string]string{"topology.kubernetes.io/zone": "zone-a"}}}
+			nodeNoZone := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-no-zone"}}
+			// drop the pub status cached by the former cases
+			_ = util.GlobalCache.Delete(pub)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pub, nodeA, nodeNoZone).Build()
+			finder := &controllerfinder.ControllerFinder{Client: fakeClient}
+			InitPubControl(fakeClient, finder, record.NewFakeRecorder(10))
+			pod := podDemo.DeepCopy()
+			pod.Spec.NodeName = cs.nodeName
+			allow, _, err := PodUnavailableBudgetValidatePod(pod, policyv1alpha1.PubDeleteOperation, "fake-user", false)
+			if err != nil {
+				t.Fatalf("PodUnavailableBudgetValidatePod failed: %s", err.Error())
+			}
+			if cs.expectAllow != allow {
+				t.Fatalf("expect allow %v, but got %v", cs.expectAllow, allow)
+			}
+			latestPub := &policyv1alpha1.PodUnavailableBudget{}
+			if err = fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(pub), latestPub); err != nil {
+				t.Fatalf("get pub failed: %s", err.Error())
+			}
+			if !reflect.DeepEqual(latestPub.Status.TopologyUnavailableAllowed, cs.expectTopologyStatus) {
+				t.Fatalf("expect topologyUnavailableAllowed %v, but got %v", cs.expectTopologyStatus, latestPub.Status.TopologyUnavailableAllowed)
+			}
+		})
+	}
+}
+
 func TestGetPodUnavailableBudgetForPod(t *testing.T) {
 	cases := []struct {
 		name          string
@@ -432,3 +530,51 @@ func TestGetPodUnavailableBudgetForPod(t *testing.T) {
 		})
 	}
 }
+
+func TestAcquirePodDisruption(t *testing.T) {
+	cases := []struct {
+		name              string
+		enableUpdateGate  bool
+		expectAllow       bool
+		expectUnavailable bool
+	}{
+		{
+			name:              "PodUnavailableBudgetUpdateGate is disabled",
+			enableUpdateGate:  false,
+			expectAllow:       true,
+			expectUnavailable: false,
+		},
+		{
+			name:              "PodUnavailableBudgetUpdateGate is enabled",
+			enableUpdateGate:  true,
+			expectAllow:       true,
+			expectUnavailable: true,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			defer utilfeature.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, features.PodUnavailableBudgetUpdateGate, cs.enableUpdateGate)()
+			pub := pubDemo.DeepCopy()
+			pub.Status.UnavailableAllowed = 1
+			// drop the pub status cached by the former cases
+			_ = util.GlobalCache.Delete(pub)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pub).Build()
+			finder := &controllerfinder.ControllerFinder{Client: fakeClient}
+			InitPubControl(fakeClient, finder, record.NewFakeRecorder(10))
+			pod := podDemo.DeepCopy()
+			allow, _, err := AcquirePodDisruption(pod, "kruise-manager", false)
+			if err != nil {
+				t.Fatalf("AcquirePodDisruption failed: %s", err.Error())
+			}
+			if cs.expectAllow != allow {
+				t.Fatalf("expect allow %v, but got %v", cs.expectAllow, allow)
+			}
+			latest := &policyv1alpha1.PodUnavailableBudget{}
+			_ = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: pub.Namespace, Name: pub.Name}, latest)
+			if _, ok := latest.Status.UnavailablePods[pod.Name]; ok != cs.expectUnavailable {
+				t.Fatalf("expect pod recorded in unavailablePods %v, but got %v", cs.expectUnavailable, latest.Status.UnavailablePods)
+			}
+		})
+	}
+}
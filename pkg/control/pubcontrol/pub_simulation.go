@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubcontrol
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+)
+
+// SimulatedPodOperation is an operation of pod to be simulated against PodUnavailableBudgets.
+type SimulatedPodOperation struct {
+	Pod       *corev1.Pod
+	Operation policyv1alpha1.PubOperation
+}
+
+// SimulatedPodResult is the simulated admission result of a pod operation.
+type SimulatedPodResult struct {
+	SimulatedPodOperation
+	Allowed bool
+	Reason  string
+	// Pub is the simulated PodUnavailableBudget which has been checked by the operation,
+	// it is nil if the operation is allowed without checking any pub.
+	Pub *policyv1alpha1.PodUnavailableBudget
+}
+
+// SimulatePodsOperation checks the pod operations against PodUnavailableBudgets in order, just like what
+// PodUnavailableBudgetValidatePod does, but it only decrements the quota of in-memory copies of pubs, so the
+// later operations will observe the quota consumed by the earlier ones and nothing will be persisted.
+// It returns the results in the same order of operations, and the original pubs which have been checked.
+func SimulatePodsOperation(operations []SimulatedPodOperation) ([]SimulatedPodResult, map[types.NamespacedName]*policyv1alpha1.PodUnavailableBudget, error) {
+	originalPubs := map[types.NamespacedName]*policyv1alpha1.PodUnavailableBudget{}
+	simulatedPubs := map[types.NamespacedName]*policyv1alpha1.PodUnavailableBudget{}
+	results := make([]SimulatedPodResult, 0, len(operations))
+	for _, operation := range operations {
+		result := SimulatedPodResult{SimulatedPodOperation: operation, Allowed: true}
+		pub, err := getPubToCheckForPod(operation.Pod, operation.Operation)
+		if err != nil {
+			return nil, nil, err
+		} else if pub == nil {
+			results = append(results, result)
+			continue
+		}
+
+		key := types.NamespacedName{Namespace: pub.Namespace, Name: pub.Name}
+		if _, ok := simulatedPubs[key]; !ok {
+			originalPubs[key] = pub.DeepCopy()
+			simulatedPubs[key] = pub.DeepCopy()
+		}
+		pubClone := simulatedPubs[key]
+		result.Pub = pubClone
+		// the pod may have been recorded by an earlier operation of the simulation
+		if isPodRecordedInPub(operation.Pod.Name, pubClone) {
+			results = append(results, result)
+			continue
+		}
+		domain, err := GetPodTopologyDomain(kclient, operation.Pod, pubClone)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err = checkAndDecrement(operation.Pod.Name, domain, pubClone, operation.Operation); err != nil {
+			result.Allowed = false
+			result.Reason = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, originalPubs, nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pubcontrol
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util/controllerfinder"
+)
+
+func TestSimulatePodsOperation(t *testing.T) {
+	pub := pubDemo.DeepCopy()
+	pub.Status.UnavailableAllowed = 2
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pub).Build()
+	finder := &controllerfinder.ControllerFinder{Client: fakeClient}
+	InitPubControl(fakeClient, finder, record.NewFakeRecorder(10))
+
+	newPod := func(name string) *corev1.Pod {
+		pod := podDemo.DeepCopy()
+		pod.Name = name
+		return pod
+	}
+	notReadyPod := newPod("pod-not-ready")
+	notReadyPod.Status.Conditions = nil
+	operations := []SimulatedPodOperation{
+		{Pod: newPod("pod-0"), Operation: policyv1alpha1.PubEvictOperation},
+		{Pod: notReadyPod, Operation: policyv1alpha1.PubEvictOperation},
+		{Pod: newPod("pod-1"), Operation: policyv1alpha1.PubDeleteOperation},
+		{Pod: newPod("pod-2"), Operation: policyv1alpha1.PubEvictOperation},
+		{Pod: newPod("pod-0"), Operation: policyv1alpha1.PubEvictOperation},
+	}
+	results, pubs, err := SimulatePodsOperation(operations)
+	if err != nil {
+		t.Fatalf("SimulatePodsOperation failed: %s", err.Error())
+	}
+	expectAllowed := []bool{true, true, true, false, true}
+	for i := range results {
+		if results[i].Allowed != expectAllowed[i] {
+			t.Fatalf("expect operation %d allowed %v, but got %v(%s)", i, expectAllowed[i], results[i].Allowed, results[i].Reason)
+		}
+	}
+	if results[1].Pub != nil {
+		t.Fatalf("expect not ready pod not checked by pub")
+	}
+	if results[3].Pub == nil || results[3].Pub.Status.UnavailableAllowed != 0 || len(results[3].Pub.Status.DisruptedPods) != 2 {
+		t.Fatalf("expect simulated pub consumed by pod-0 and pod-1, but got %v", results[3].Pub)
+	}
+	key := types.NamespacedName{Namespace: pub.Namespace, Name: pub.Name}
+	if original := pubs[key]; original == nil || original.Status.UnavailableAllowed != 2 {
+		t.Fatalf("expect original pub returned, but got %v", original)
+	}
+
+	// nothing should be persisted
+	latest := &policyv1alpha1.PodUnavailableBudget{}
+	if err = fakeClient.Get(context.TODO(), key, latest); err != nil {
+		t.Fatalf("get pub failed: %s", err.Error())
+	}
+	if latest.Status.UnavailableAllowed != 2 || len(latest.Status.DisruptedPods) != 0 {
+		t.Fatalf("expect pub status not changed, but got %v", latest.Status)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -37,6 +38,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/tools/record"
@@ -68,6 +70,9 @@ func init() {
 const (
 	JobNameLabelKey       = "broadcastjob-name"
 	ControllerUIDLabelKey = "broadcastjob-controller-uid"
+
+	// PodRetriesAnnotationKey is the number of failed pods which have been recreated on the node before the pod.
+	PodRetriesAnnotationKey = "apps.kruise.io/broadcastjob-retries"
 )
 
 var (
@@ -229,6 +234,9 @@ func (r *ReconcileBroadcastJob) Reconcile(_ context.Context, request reconcile.R
 		}
 	}
 
+	// the failed pods which have been recreated on their nodes are to be deleted
+	pods, supersededPods := splitSupersededPods(pods)
+
 	// Get the map (nodeName -> Pod) for pods with node assigned
 	existingNodeToPodMap := r.getNodeToPodMap(pods, job)
 	// list all nodes in cluster
@@ -241,18 +249,28 @@ func (r *ReconcileBroadcastJob) Reconcile(_ context.Context, request reconcile.R
 
 	// Get active, failed, succeeded pods
 	activePods, failedPods, succeededPods := filterPods(job.Spec.FailurePolicy.RestartLimit, pods)
+
+	desiredNodes, restNodesToRunPod, podsToDelete, skippedNodes := getNodesToRunPod(nodes, job, existingNodeToPodMap)
+	desired := int32(len(desiredNodes))
+	// the pods on skipped nodes may never finish, they should not occupy the parallelism
+	activePods = excludePodsOnNodes(activePods, skippedNodes)
+
+	// the failed pods to be recreated on the same nodes are neither active nor failed
+	podsToRecreate, retryingNodes, failedPods, retryAfter := getFailedPodsToRetry(job, failedPods, desiredNodes, time.Now())
+	if retryAfter > 0 && (requeueAfter == 0 || retryAfter < requeueAfter) {
+		requeueAfter = retryAfter
+	}
+
 	active := int32(len(activePods))
 	failed := int32(len(failedPods))
 	succeeded := int32(len(succeededPods))
-
-	desiredNodes, restNodesToRunPod, podsToDelete := getNodesToRunPod(nodes, job, existingNodeToPodMap)
-	desired := int32(len(desiredNodes))
 	klog.Infof("%s/%s has %d/%d nodes remaining to schedule pods", job.Namespace, job.Name, len(restNodesToRunPod), desired)
 	klog.Infof("Before broadcastjob reconcile %s/%s, desired=%d, active=%d, failed=%d", job.Namespace, job.Name, desired, active, failed)
 	job.Status.Active = active
 	job.Status.Failed = failed
 	job.Status.Succeeded = succeeded
 	job.Status.Desired = desired
+	job.Status.NodeResults = calculateNodeResults(job, desiredNodes, skippedNodes, retryingNodes)
 
 	if job.Status.Phase == appsv1alpha1.PhaseFailed {
 		return reconcile.Result{RequeueAfter: requeueAfter}, r.updateJobStatus(request, job)
@@ -308,15 +326,45 @@ func (r *ReconcileBroadcastJob) Reconcile(_ context.Context, request reconcile.R
 			}
 		}
 
+		if len(supersededPods) > 0 {
+			if err = r.deleteSupersededPods(job, supersededPods); err != nil {
+				klog.Errorf("failed to delete superseded pods for job %s, %v", job.Name, err)
+			}
+		}
+
+		// the failed pods are recreated on their nodes before creating pods on the other nodes,
+		// and the new pods record the retries of their nodes
+		nodeRetries := make(map[string]int32, len(podsToRecreate))
+		if len(podsToRecreate) > 0 {
+			nodeMap := getNodeMap(nodes)
+			var retryNodes []*corev1.Node
+			for _, pod := range podsToRecreate {
+				nodeName := getAssignedNode(pod)
+				if node, ok := nodeMap[nodeName]; ok {
+					retryNodes = append(retryNodes, node)
+					nodeRetries[nodeName] = getPodRetries(pod) + 1
+				}
+			}
+			restNodesToRunPod = append(retryNodes, restNodesToRunPod...)
+		}
+
+		if job.DeletionTimestamp == nil && len(restNodesToRunPod) > 0 {
+			// limit the nodes to run pod by the parallelism of each topology domain
+			restNodesToRunPod, err = getNodesAllowedByTopologyParallelism(job, restNodesToRunPod, activePods, getNodeMap(nodes), desiredNodes)
+			if err != nil {
+				klog.Errorf("failed to get nodes allowed by topology parallelism for job %s, %v", job.Name, err)
+			}
+		}
+
 		// DeletionTimestamp is not set and more nodes to run pod
 		if job.DeletionTimestamp == nil && len(restNodesToRunPod) > 0 {
-			active, err = r.reconcilePods(job, restNodesToRunPod, active, desired)
+			active, err = r.reconcilePods(job, restNodesToRunPod, nodeRetries, active, desired)
 			if err != nil {
 				klog.Errorf("failed to reconcilePods for job %s,", job.Name)
 			}
 		}
 
-		if isJobComplete(job, desiredNodes) {
+		if retryingNodes.Len() == 0 && isJobComplete(job, desiredNodes) {
 			message := fmt.Sprintf("Job completed, %d pods succeeded, %d pods failed", succeeded, failed)
 			job.Status.Phase = appsv1alpha1.PhaseCompleted
 			requeueAfter = finishJob(job, appsv1alpha1.JobComplete, message)
@@ -387,7 +435,7 @@ func addLabelToPodTemplate(job *appsv1alpha1.BroadcastJob) {
 }
 
 func (r *ReconcileBroadcastJob) reconcilePods(job *appsv1alpha1.BroadcastJob,
-	restNodesToRunPod []*corev1.Node, active, desired int32) (int32, error) {
+	restNodesToRunPod []*corev1.Node, nodeRetries map[string]int32, active, desired int32) (int32, error) {
 
 	// max concurrent running pods
 	parallelismInt, err := intstr.GetValueFromIntOrPercent(intstr.ValueOrDefault(job.Spec.Parallelism, intstr.FromInt(1<<31-1)), int(desired), true)
@@ -432,7 +480,7 @@ func (r *ReconcileBroadcastJob) reconcilePods(job *appsv1alpha1.BroadcastJob,
 					defer wait.Done()
 					// parallelize pod creation
 					klog.Infof("creating pod on node %s", nodeName)
-					err := r.createPodOnNode(nodeName, job.Namespace, &job.Spec.Template, job, asOwner(job))
+					err := r.createPodOnNode(nodeName, job.Namespace, &job.Spec.Template, job, asOwner(job), nodeRetries[nodeName])
 					if err != nil && errors.IsTimeout(err) {
 						// Pod is created but its initialization has timed out.
 						// If the initialization is successful eventually, the
@@ -513,13 +561,19 @@ func isJobFailed(job *appsv1alpha1.BroadcastJob, pods []*corev1.Pod) (bool, stri
 // * desiredNodes : the nodes desired to run pods including node with or without running pods
 // * restNodesToRunPod:  the nodes do not have pods running yet, excluding the nodes not satisfying constraints such as affinity, taints
 // * podsToDelete: the pods that do not satisfy the node constraint any more
+// * skippedNodes: the nodes skipped because they are not ready, with their existing pods, only if SkipNotReadyNodes is true
 func getNodesToRunPod(nodes *corev1.NodeList, job *appsv1alpha1.BroadcastJob,
-	existingNodeToPodMap map[string]*corev1.Pod) (map[string]*corev1.Pod, []*corev1.Node, []*corev1.Pod) {
+	existingNodeToPodMap map[string]*corev1.Pod) (map[string]*corev1.Pod, []*corev1.Node, []*corev1.Pod, map[string]*corev1.Pod) {
 
 	var podsToDelete []*corev1.Pod
 	var restNodesToRunPod []*corev1.Node
 	desiredNodes := make(map[string]*corev1.Pod)
+	skippedNodes := make(map[string]*corev1.Pod)
 	for i, node := range nodes.Items {
+		if job.Spec.SkipNotReadyNodes && !isNodeReady(&nodes.Items[i]) {
+			skippedNodes[node.Name] = existingNodeToPodMap[node.Name]
+			continue
+		}
 
 		var canFit bool
 		var err error
@@ -545,7 +599,15 @@ func getNodesToRunPod(nodes *corev1.NodeList, job *appsv1alpha1.BroadcastJob,
 			desiredNodes[node.Name] = nil
 		}
 	}
-	return desiredNodes, restNodesToRunPod, podsToDelete
+	return desiredNodes, restNodesToRunPod, podsToDelete, skippedNodes
+}
+
+func getNodeMap(nodes *corev1.NodeList) map[string]*corev1.Node {
+	nodeMap := make(map[string]*corev1.Node, len(nodes.Items))
+	for i := range nodes.Items {
+		nodeMap[nodes.Items[i].Name] = &nodes.Items[i]
+	}
+	return nodeMap
 }
 
 // getNodeToPodMap scans the pods and construct a map : nodeName -> pod.
@@ -677,19 +739,45 @@ func (r *ReconcileBroadcastJob) deleteJobPods(job *appsv1alpha1.BroadcastJob, po
 	return failed, active, manageJobErr
 }
 
-func (r *ReconcileBroadcastJob) createPodOnNode(nodeName, namespace string, template *corev1.PodTemplateSpec, object runtime.Object, controllerRef *metav1.OwnerReference) error {
+// deleteSupersededPods deletes the failed pods which have been recreated on their nodes.
+func (r *ReconcileBroadcastJob) deleteSupersededPods(job *appsv1alpha1.BroadcastJob, pods []*corev1.Pod) error {
+	key := types.NamespacedName{Namespace: job.Namespace, Name: job.Name}.String()
+	var errs []error
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		nodeName := getAssignedNode(pod)
+		scaleExpectations.ExpectScale(key, expectations.Delete, nodeName)
+		if err := r.Delete(context.TODO(), pod); err != nil {
+			scaleExpectations.ObserveScale(key, expectations.Delete, nodeName)
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (r *ReconcileBroadcastJob) createPodOnNode(nodeName, namespace string, template *corev1.PodTemplateSpec, object runtime.Object,
+	controllerRef *metav1.OwnerReference, retries int32) error {
 	if err := validateControllerRef(controllerRef); err != nil {
 		return err
 	}
-	return r.createPod(nodeName, namespace, template, object, controllerRef)
+	return r.createPod(nodeName, namespace, template, object, controllerRef, retries)
 }
 
-func (r *ReconcileBroadcastJob) createPod(nodeName, namespace string, template *corev1.PodTemplateSpec, object runtime.Object, controllerRef *metav1.OwnerReference) error {
+func (r *ReconcileBroadcastJob) createPod(nodeName, namespace string, template *corev1.PodTemplateSpec, object runtime.Object,
+	controllerRef *metav1.OwnerReference, retries int32) error {
 	pod, err := kubecontroller.GetPodFromTemplate(template, object, controllerRef)
 	if err != nil {
 		return err
 	}
 	pod.Namespace = namespace
+	if retries > 0 {
+		if pod.Annotations == nil {
+			pod.Annotations = make(map[string]string)
+		}
+		pod.Annotations[PodRetriesAnnotationKey] = strconv.Itoa(int(retries))
+	}
 	if scheduleBroadcastJobPods {
 		// The pod's NodeAffinity will be updated to make sure the Pod is bound
 		// to the target node by default scheduler. It is safe to do so because there
@@ -725,7 +813,12 @@ func (r *ReconcileBroadcastJob) createPod(nodeName, namespace string, template *
 		return nil
 	}
 	klog.Infof("Controller %v created pod %v", accessor.GetName(), pod.Name)
-	r.recorder.Eventf(object, corev1.EventTypeNormal, kubecontroller.SuccessfulCreatePodReason, "Created pod: %v", pod.Name)
+	if retries > 0 {
+		r.recorder.Eventf(object, corev1.EventTypeNormal, "RecreateFailedPod",
+			"Recreated failed pod as %s on node %s, retries %d", pod.Name, nodeName, retries)
+	} else {
+		r.recorder.Eventf(object, corev1.EventTypeNormal, kubecontroller.SuccessfulCreatePodReason, "Created pod: %v", pod.Name)
+	}
 
 	return nil
 }
@@ -20,9 +20,12 @@ import (
 	"flag"
 	"fmt"
 	"reflect"
+	"strconv"
 	"testing"
+	"time"
 
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	"github.com/stretchr/testify/assert"
@@ -37,6 +40,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
+	utilpointer "k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -600,6 +604,196 @@ func TestJobFailedAfterActiveDeadline(t *testing.T) {
 	assert.Equal(t, 0, len(podList.Items))
 }
 
+// Test scenario:
+// 3 nodes in rack-a, 1 node in rack-b
+// 1 pod running on node1 in rack-a
+// topology parallelism of rack is 1
+// 1 new pod created on node4 in rack-b
+func TestReconcileJobTopologyParallelism(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(appsv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(v1.AddToScheme(scheme))
+
+	job := createJob("job-topology", intstr.FromInt(10))
+	job.Spec.TopologyParallelism = []appsv1alpha1.BroadcastJobTopologyParallelism{
+		{TopologyKey: "rack", MaxParallelism: intstr.FromInt(1)},
+	}
+
+	var nodes []client.Object
+	for i, rack := range []string{"rack-a", "rack-a", "rack-a", "rack-b"} {
+		node := createNode(fmt.Sprintf("node%d", i+1))
+		node.Labels = map[string]string{"rack": rack}
+		nodes = append(nodes, node)
+	}
+	pod1onNode1 := createPod(job, "pod1node1", "node1", v1.PodRunning)
+
+	reconcileJob := createReconcileJob(scheme, append(nodes, job, pod1onNode1)...)
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: job.Name, Namespace: "default"}}
+	_, err := reconcileJob.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	retrievedJob := &appsv1alpha1.BroadcastJob{}
+	err = reconcileJob.Get(context.TODO(), request.NamespacedName, retrievedJob)
+	assert.NoError(t, err)
+
+	podList := &v1.PodList{}
+	err = reconcileJob.List(context.TODO(), podList, client.InNamespace(request.Namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(podList.Items))
+	var nodeNames []string
+	for i := range podList.Items {
+		nodeNames = append(nodeNames, getAssignedNode(&podList.Items[i]))
+	}
+	assert.ElementsMatch(t, []string{"node1", "node4"}, nodeNames)
+	assert.Equal(t, int32(2), retrievedJob.Status.Active)
+	assert.Equal(t, int32(4), retrievedJob.Status.Desired)
+	assert.Equal(t, 4, len(retrievedJob.Status.NodeResults))
+	assert.Equal(t, appsv1alpha1.NodePhaseRunning, retrievedJob.Status.NodeResults[0].Phase)
+}
+
+// Test scenario:
+// failed pod on node1 has passed the backoff, it is deleted to be recreated
+// failed pod on node2 is waiting for the backoff
+// failed pod on node3 has used up the retries, it is counted as failed
+func TestJobRecreateFailedPods(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(appsv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(v1.AddToScheme(scheme))
+
+	job := createJob("job-retry", intstr.FromInt(10))
+	job.Spec.Template.Spec.RestartPolicy = v1.RestartPolicyNever
+	job.Spec.FailurePolicy = appsv1alpha1.FailurePolicy{
+		Type:               appsv1alpha1.FailurePolicyTypeContinue,
+		RestartLimit:       2,
+		RecreateFailedPods: true,
+		BackoffSeconds:     utilpointer.Int32(60),
+	}
+	now := metav1.Now()
+	job.Status = appsv1alpha1.BroadcastJobStatus{StartTime: &now}
+
+	node1 := createNode("node1")
+	node2 := createNode("node2")
+	node3 := createNode("node3")
+	var pods []client.Object
+	for i, finishedAt := range []time.Time{now.Add(-10 * time.Minute), now.Time, now.Time} {
+		pod := createPod(job, fmt.Sprintf("pod%dnode%d", i+1, i+1), fmt.Sprintf("node%d", i+1), v1.PodFailed)
+		pod.Spec.RestartPolicy = v1.RestartPolicyNever
+		if i > 0 {
+			// the retries are recorded by the pods, job status is not trusted
+			pod.Annotations = map[string]string{PodRetriesAnnotationKey: strconv.Itoa(i)}
+		}
+		pod.Status.ContainerStatuses = []v1.ContainerStatus{{
+			Name:  "main",
+			State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 1, FinishedAt: metav1.NewTime(finishedAt)}},
+		}}
+		pods = append(pods, pod)
+	}
+
+	reconcileJob := createReconcileJob(scheme, append(pods, job, node1, node2, node3)...)
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: job.Name, Namespace: "default"}}
+	result, err := reconcileJob.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	assert.True(t, result.RequeueAfter > 0 && result.RequeueAfter <= 2*time.Minute)
+	retrievedJob := &appsv1alpha1.BroadcastJob{}
+	err = reconcileJob.Get(context.TODO(), request.NamespacedName, retrievedJob)
+	assert.NoError(t, err)
+
+	// the failed pod on node1 is recreated and the new pod records the retries
+	podList := &v1.PodList{}
+	err = reconcileJob.List(context.TODO(), podList, client.InNamespace(request.Namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(podList.Items))
+	var recreatedPod *v1.Pod
+	for i := range podList.Items {
+		if getAssignedNode(&podList.Items[i]) == "node1" && podList.Items[i].Name != "pod1node1" {
+			recreatedPod = &podList.Items[i]
+		}
+	}
+	assert.NotNil(t, recreatedPod)
+	assert.Equal(t, int32(1), getPodRetries(recreatedPod))
+	assert.Equal(t, int32(1), retrievedJob.Status.Failed)
+	assert.Equal(t, appsv1alpha1.PhaseRunning, retrievedJob.Status.Phase)
+	assert.Equal(t, []appsv1alpha1.BroadcastJobNodeResult{
+		{NodeName: "node1", PodName: "pod1node1", Phase: appsv1alpha1.NodePhaseRetrying, Retries: 0, Message: "pod failed and will be recreated, 0/2 retries used"},
+		{NodeName: "node2", PodName: "pod2node2", Phase: appsv1alpha1.NodePhaseRetrying, Retries: 1, Message: "pod failed and will be recreated, 1/2 retries used"},
+		{NodeName: "node3", PodName: "pod3node3", Phase: appsv1alpha1.NodePhaseFailed, Retries: 2},
+	}, retrievedJob.Status.NodeResults)
+
+	// the superseded failed pod is deleted, and the retries are still derived from the pods even if job status is stale
+	scaleExpectations.DeleteExpectations(request.String())
+	retrievedJob.Status.NodeResults = nil
+	err = reconcileJob.Status().Update(context.TODO(), retrievedJob)
+	assert.NoError(t, err)
+	_, err = reconcileJob.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	err = reconcileJob.Get(context.TODO(), request.NamespacedName, retrievedJob)
+	assert.NoError(t, err)
+	err = reconcileJob.List(context.TODO(), podList, client.InNamespace(request.Namespace))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(podList.Items))
+	assert.Equal(t, appsv1alpha1.BroadcastJobNodeResult{
+		NodeName: "node1", PodName: recreatedPod.Name, Phase: appsv1alpha1.NodePhaseRunning, Retries: 1,
+	}, retrievedJob.Status.NodeResults[0])
+}
+
+// Test scenario:
+// pod on the ready node1 succeeded
+// pod on the NotReady node2 is still running
+// job is completed since node2 is skipped
+func TestJobSkipNotReadyNodes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(appsv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(v1.AddToScheme(scheme))
+
+	job := createJob("job-skip", intstr.FromInt(10))
+	job.Spec.SkipNotReadyNodes = true
+
+	node1 := createNode("node1")
+	node1.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}
+	node2 := createNode("node2")
+	node2.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionUnknown}}
+	pod1onNode1 := createPod(job, "pod1node1", "node1", v1.PodSucceeded)
+	pod2onNode2 := createPod(job, "pod2node2", "node2", v1.PodRunning)
+
+	reconcileJob := createReconcileJob(scheme, job, pod1onNode1, pod2onNode2, node1, node2)
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: job.Name, Namespace: "default"}}
+	_, err := reconcileJob.Reconcile(context.TODO(), request)
+	assert.NoError(t, err)
+	retrievedJob := &appsv1alpha1.BroadcastJob{}
+	err = reconcileJob.Get(context.TODO(), request.NamespacedName, retrievedJob)
+	assert.NoError(t, err)
+
+	assert.Equal(t, appsv1alpha1.PhaseCompleted, retrievedJob.Status.Phase)
+	assert.Equal(t, int32(1), retrievedJob.Status.Desired)
+	assert.Equal(t, int32(0), retrievedJob.Status.Active)
+	assert.Equal(t, []appsv1alpha1.BroadcastJobNodeResult{
+		{NodeName: "node2", PodName: "pod2node2", Phase: appsv1alpha1.NodePhaseSkipped, Message: "node is not ready"},
+	}, retrievedJob.Status.NodeResults)
+}
+
+func TestCalculateNodeResultsLimit(t *testing.T) {
+	job := createJob("job-large", intstr.FromInt(10))
+	desiredNodes := make(map[string]*v1.Pod)
+	for i := 0; i < maxNodeResults; i++ {
+		nodeName := fmt.Sprintf("node-pending-%04d", i)
+		desiredNodes[nodeName] = nil
+	}
+	for i := 0; i < 10; i++ {
+		nodeName := fmt.Sprintf("node-succeeded-%d", i)
+		desiredNodes[nodeName] = createPod(job, "pod-"+nodeName, nodeName, v1.PodSucceeded)
+	}
+	desiredNodes["node-failed"] = createPod(job, "pod-node-failed", "node-failed", v1.PodFailed)
+
+	results := calculateNodeResults(job, desiredNodes, nil, sets.NewString())
+	assert.Equal(t, maxNodeResults, len(results))
+	// the failed node is kept and the succeeded nodes are omitted
+	assert.Equal(t, "node-failed", results[0].NodeName)
+	assert.Equal(t, appsv1alpha1.NodePhaseFailed, results[0].Phase)
+	for _, result := range results[1:] {
+		assert.Equal(t, appsv1alpha1.NodePhasePending, result.Phase)
+	}
+	assert.Equal(t, fmt.Sprintf("node-pending-%04d", maxNodeResults-2), results[len(results)-1].NodeName)
+}
+
 func createReconcileJob(scheme *runtime.Scheme, initObjs ...client.Object) ReconcileBroadcastJob {
 	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjs...).Build()
 	eventBroadcaster := record.NewBroadcaster()
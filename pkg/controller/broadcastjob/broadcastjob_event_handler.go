@@ -137,7 +137,7 @@ func (p *enqueueBroadcastJobForNode) updateNode(q workqueue.RateLimitingInterfac
 		canOldNodeFit, _ := checkNodeFitness(mockPod, oldNode)
 		canCurNodeFit, _ := checkNodeFitness(mockPod, curNode)
 
-		if canOldNodeFit != canCurNodeFit {
+		if canOldNodeFit != canCurNodeFit || (bcj.Spec.SkipNotReadyNodes && isNodeReady(oldNode) != isNodeReady(curNode)) {
 			// enqueue the broadcast job for matching node
 			q.Add(reconcile.Request{
 				NamespacedName: types.NamespacedName{
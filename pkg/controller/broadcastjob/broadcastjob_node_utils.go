@@ -0,0 +1,273 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package broadcastjob
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	nodeutil "k8s.io/kubernetes/pkg/controller/util/node"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+const (
+	defaultRetryBackoff = 10 * time.Second
+	maxRetryBackoff     = 10 * time.Minute
+
+	// maxNodeResults bounds the size of job status for the jobs running on a large number of nodes.
+	maxNodeResults = 1000
+)
+
+// nodePhasePriorities is the order in which the node results are kept when there are more than maxNodeResults.
+var nodePhasePriorities = map[appsv1alpha1.BroadcastJobNodePhase]int{
+	appsv1alpha1.NodePhaseFailed:   0,
+	appsv1alpha1.NodePhaseRetrying: 1,
+	appsv1alpha1.NodePhaseSkipped:  2,
+	appsv1alpha1.NodePhaseRunning:  3,
+	appsv1alpha1.NodePhasePending:  4,
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	_, condition := nodeutil.GetNodeCondition(&node.Status, corev1.NodeReady)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
+
+// excludePodsOnNodes returns the pods which are not assigned to the given nodes.
+func excludePodsOnNodes(pods []*corev1.Pod, nodes map[string]*corev1.Pod) []*corev1.Pod {
+	var remaining []*corev1.Pod
+	for _, pod := range pods {
+		if _, ok := nodes[getAssignedNode(pod)]; !ok {
+			remaining = append(remaining, pod)
+		}
+	}
+	return remaining
+}
+
+// getNodesAllowedByTopologyParallelism returns the nodes in restNodesToRunPod on which pods can be created
+// in order without exceeding the parallelism of any topology domain.
+func getNodesAllowedByTopologyParallelism(job *appsv1alpha1.BroadcastJob, restNodesToRunPod []*corev1.Node, activePods []*corev1.Pod,
+	nodeMap map[string]*corev1.Node, desiredNodes map[string]*corev1.Pod) ([]*corev1.Node, error) {
+	if len(job.Spec.TopologyParallelism) == 0 {
+		return restNodesToRunPod, nil
+	}
+
+	// the max parallelism and the number of active pods of each domain for each topology key
+	limits := make([]map[string]int, len(job.Spec.TopologyParallelism))
+	actives := make([]map[string]int, len(job.Spec.TopologyParallelism))
+	for i := range job.Spec.TopologyParallelism {
+		tp := &job.Spec.TopologyParallelism[i]
+		desired := make(map[string]int)
+		for nodeName := range desiredNodes {
+			if node, ok := nodeMap[nodeName]; ok {
+				desired[node.Labels[tp.TopologyKey]]++
+			}
+		}
+		limits[i] = make(map[string]int, len(desired))
+		for domain, count := range desired {
+			limit, err := intstr.GetScaledValueFromIntOrPercent(&tp.MaxParallelism, count, true)
+			if err != nil {
+				return nil, err
+			}
+			limits[i][domain] = limit
+		}
+		actives[i] = make(map[string]int)
+		for _, pod := range activePods {
+			if node, ok := nodeMap[getAssignedNode(pod)]; ok {
+				actives[i][node.Labels[tp.TopologyKey]]++
+			}
+		}
+	}
+
+	var allowedNodes []*corev1.Node
+	for _, node := range restNodesToRunPod {
+		allowed := true
+		for i := range job.Spec.TopologyParallelism {
+			domain := node.Labels[job.Spec.TopologyParallelism[i].TopologyKey]
+			if actives[i][domain] >= limits[i][domain] {
+				allowed = false
+				break
+			}
+		}
+		if !allowed {
+			continue
+		}
+		for i := range job.Spec.TopologyParallelism {
+			actives[i][node.Labels[job.Spec.TopologyParallelism[i].TopologyKey]]++
+		}
+		allowedNodes = append(allowedNodes, node)
+	}
+	return allowedNodes, nil
+}
+
+// getPodRetries returns the number of failed pods which have been recreated on the node before the pod.
+func getPodRetries(pod *corev1.Pod) int32 {
+	retries, err := strconv.ParseInt(pod.Annotations[PodRetriesAnnotationKey], 10, 32)
+	if err != nil || retries < 0 {
+		return 0
+	}
+	return int32(retries)
+}
+
+// splitSupersededPods splits the failed pods which have been recreated on the same nodes, i.e., there are pods
+// with more retries on their nodes, from the others.
+func splitSupersededPods(pods []*corev1.Pod) ([]*corev1.Pod, []*corev1.Pod) {
+	maxRetries := make(map[string]int32)
+	for _, pod := range pods {
+		nodeName := getAssignedNode(pod)
+		if retries, ok := maxRetries[nodeName]; !ok || getPodRetries(pod) > retries {
+			maxRetries[nodeName] = getPodRetries(pod)
+		}
+	}
+	var currentPods, supersededPods []*corev1.Pod
+	for _, pod := range pods {
+		if getPodRetries(pod) < maxRetries[getAssignedNode(pod)] {
+			supersededPods = append(supersededPods, pod)
+		} else {
+			currentPods = append(currentPods, pod)
+		}
+	}
+	return currentPods, supersededPods
+}
+
+// getRetryBackoff returns the delay before recreating the failed pod on a node which has been retried for the given times.
+func getRetryBackoff(job *appsv1alpha1.BroadcastJob, retries int32) time.Duration {
+	backoff := defaultRetryBackoff
+	if job.Spec.FailurePolicy.BackoffSeconds != nil {
+		backoff = time.Duration(*job.Spec.FailurePolicy.BackoffSeconds) * time.Second
+	}
+	for i := int32(0); i < retries && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	return backoff
+}
+
+// getPodFinishedTime returns the latest time at which the containers of pod terminated,
+// or the creation time of pod if no terminated container is found.
+func getPodFinishedTime(pod *corev1.Pod) time.Time {
+	finishedTime := pod.CreationTimestamp.Time
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil && status.State.Terminated.FinishedAt.After(finishedTime) {
+			finishedTime = status.State.Terminated.FinishedAt.Time
+		}
+	}
+	return finishedTime
+}
+
+// getFailedPodsToRetry splits the failed pods which can be recreated on their nodes from the others, and returns
+// * podsToRecreate: the pods that have passed the retry backoff and should be recreated on their nodes
+// * retryingNodes: the nodes whose failed pods will be recreated, including the nodes of podsToRecreate
+// * failedPods: the pods that failed and will not be recreated
+// * retryAfter: the duration after which the next pod will pass its retry backoff, zero means no waiting pod
+func getFailedPodsToRetry(job *appsv1alpha1.BroadcastJob, failedPods []*corev1.Pod, desiredNodes map[string]*corev1.Pod,
+	now time.Time) ([]*corev1.Pod, sets.String, []*corev1.Pod, time.Duration) {
+	retryingNodes := sets.NewString()
+	if !job.Spec.FailurePolicy.RecreateFailedPods {
+		return nil, retryingNodes, failedPods, 0
+	}
+
+	var podsToRecreate, remainingFailedPods []*corev1.Pod
+	var retryAfter time.Duration
+	for _, pod := range failedPods {
+		nodeName := getAssignedNode(pod)
+		if pod.Spec.RestartPolicy != corev1.RestartPolicyNever || pod.Status.Phase != corev1.PodFailed ||
+			desiredNodes[nodeName] != pod || getPodRetries(pod) >= job.Spec.FailurePolicy.RestartLimit {
+			remainingFailedPods = append(remainingFailedPods, pod)
+			continue
+		}
+		retryingNodes.Insert(nodeName)
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		left := getPodFinishedTime(pod).Add(getRetryBackoff(job, getPodRetries(pod))).Sub(now)
+		if left <= 0 {
+			podsToRecreate = append(podsToRecreate, pod)
+		} else if retryAfter == 0 || left < retryAfter {
+			retryAfter = left
+		}
+	}
+	return podsToRecreate, retryingNodes, remainingFailedPods, retryAfter
+}
+
+// calculateNodeResults returns the results of the job on the desired nodes and skipped nodes, sorted by node name.
+// The succeeded nodes are omitted, and at most maxNodeResults results are returned with the failed ones first.
+func calculateNodeResults(job *appsv1alpha1.BroadcastJob, desiredNodes, skippedNodes map[string]*corev1.Pod,
+	retryingNodes sets.String) []appsv1alpha1.BroadcastJobNodeResult {
+	if len(desiredNodes) == 0 && len(skippedNodes) == 0 {
+		return nil
+	}
+	results := make([]appsv1alpha1.BroadcastJobNodeResult, 0, len(desiredNodes)+len(skippedNodes))
+	addResult := func(nodeName string, pod *corev1.Pod, skipped bool) {
+		result := appsv1alpha1.BroadcastJobNodeResult{NodeName: nodeName}
+		if pod != nil {
+			result.PodName = pod.Name
+			result.Retries = getPodRetries(pod)
+		}
+		switch {
+		case skipped:
+			result.Phase = appsv1alpha1.NodePhaseSkipped
+			result.Message = "node is not ready"
+		case pod == nil:
+			result.Phase = appsv1alpha1.NodePhasePending
+		case retryingNodes.Has(nodeName):
+			result.Phase = appsv1alpha1.NodePhaseRetrying
+			result.Message = fmt.Sprintf("pod failed and will be recreated, %d/%d retries used",
+				result.Retries, job.Spec.FailurePolicy.RestartLimit)
+		case pod.Status.Phase == corev1.PodSucceeded:
+			return
+		case pod.Status.Phase == corev1.PodFailed:
+			result.Phase = appsv1alpha1.NodePhaseFailed
+			result.Message = pod.Status.Message
+		case isPodFailed(job.Spec.FailurePolicy.RestartLimit, pod):
+			result.Phase = appsv1alpha1.NodePhaseFailed
+			result.Message = fmt.Sprintf("restart count of containers exceeds the limit %d", job.Spec.FailurePolicy.RestartLimit)
+		default:
+			result.Phase = appsv1alpha1.NodePhaseRunning
+		}
+		results = append(results, result)
+	}
+	for nodeName, pod := range desiredNodes {
+		addResult(nodeName, pod, false)
+	}
+	for nodeName, pod := range skippedNodes {
+		addResult(nodeName, pod, true)
+	}
+	if len(results) > maxNodeResults {
+		sort.Slice(results, func(i, j int) bool {
+			if nodePhasePriorities[results[i].Phase] != nodePhasePriorities[results[j].Phase] {
+				return nodePhasePriorities[results[i].Phase] < nodePhasePriorities[results[j].Phase]
+			}
+			return results[i].NodeName < results[j].NodeName
+		})
+		results = results[:maxNodeResults]
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].NodeName < results[j].NodeName
+	})
+	if len(results) == 0 {
+		return nil
+	}
+	return results
+}
@@ -28,6 +28,7 @@ import (
 
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -296,7 +297,7 @@ type ReconcileDaemonSet struct {
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=pods/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=daemonsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.kruise.io,resources=daemonsets/status,verbs=get;update;patch
@@ -378,7 +379,8 @@ func (dsc *ReconcileDaemonSet) syncDaemonSet(ctx context.Context, request reconc
 	// For example if daemon set foo asked for 3 new daemon pods in the previous call to manage,
 	// then we do not want to call manage on foo until the daemon pods have been created.
 	if ds.DeletionTimestamp != nil {
-		return nil
+		// Advanced: untaint the nodes drained by the deleting daemon set
+		return dsc.cleanupNodeDrainTaints(ctx, ds)
 	}
 
 	everything := metav1.LabelSelector{}
@@ -443,6 +445,13 @@ func (dsc *ReconcileDaemonSet) syncDaemonSet(ctx context.Context, request reconc
 		return err
 	}
 
+	// Advanced: untaint the nodes drained by the daemon set, if it has been paused or does not drain nodes any more
+	if isNodeDrainStopped(ds) {
+		if err = dsc.cleanupNodeDrainTaints(ctx, ds); err != nil {
+			return fmt.Errorf("failed to clean up node drain taints of DaemonSet: %v", err)
+		}
+	}
+
 	// Process rolling updates if we're ready. For all kinds of update should not be executed if the update
 	// expectation is not satisfied.
 	if !isDaemonSetPaused(ds) {
@@ -454,6 +463,8 @@ func (dsc *ReconcileDaemonSet) syncDaemonSet(ctx context.Context, request reconc
 				return err
 			}
 		}
+	} else if err = dsc.rollbackUpdateFailedNodes(ctx, ds, hash, old); err != nil {
+		return fmt.Errorf("failed to roll back failed nodes of DaemonSet: %v", err)
 	}
 
 	err = dsc.cleanupHistory(ctx, ds, old)
@@ -507,6 +518,8 @@ func NewPod(ds *appsv1alpha1.DaemonSet, nodeName string) *corev1.Pod {
 
 	// Added default tolerations for DaemonSet pods.
 	util.AddOrUpdateDaemonPodTolerations(&newPod.Spec)
+	// Advanced: tolerate the taint added by node drain.
+	addNodeDrainToleration(ds, &newPod.Spec)
 
 	newPodForDSCache.Store(ds.UID, &newPodForDS{generation: ds.Generation, pod: newPod})
 	return newPod
@@ -556,11 +569,29 @@ func (dsc *ReconcileDaemonSet) updateDaemonSetStatus(ctx context.Context, ds *ap
 	}
 	numberUnavailable := desiredNumberScheduled - numberAvailable
 
-	err = dsc.storeDaemonSetStatus(ctx, ds, desiredNumberScheduled, currentNumberScheduled, numberMisscheduled, numberReady, updatedNumberScheduled, numberAvailable, numberUnavailable, updateObservedGen, hash)
+	desiredNodeToDaemonPods := getDesiredNodeToDaemonPods(ds, nodeList, nodeToDaemonPods)
+	currentWave, err := dsc.calculateUpdateWaveStatus(ds, hash, desiredNodeToDaemonPods)
+	if err != nil {
+		return fmt.Errorf("couldn't calculate update wave for DaemonSet %q: %v", ds.Name, err)
+	}
+
+	conditions, failureBudgetExceeded, err := dsc.calculateUpdateFailedCondition(ds, hash, desiredNodeToDaemonPods)
+	if err != nil {
+		return fmt.Errorf("couldn't calculate update failed condition for DaemonSet %q: %v", ds.Name, err)
+	}
+
+	err = dsc.storeDaemonSetStatus(ctx, ds, desiredNumberScheduled, currentNumberScheduled, numberMisscheduled, numberReady, updatedNumberScheduled, numberAvailable, numberUnavailable, updateObservedGen, hash, currentWave, conditions)
 	if err != nil {
 		return fmt.Errorf("error storing status for DaemonSet %v: %v", ds.Name, err)
 	}
 
+	// Advanced: pause the rolling update if too many updated pods failed
+	if failureBudgetExceeded && !isDaemonSetPaused(ds) {
+		if err = dsc.pauseDaemonSetForFailureBudget(ctx, ds); err != nil {
+			return fmt.Errorf("error pausing DaemonSet %v: %v", ds.Name, err)
+		}
+	}
+
 	// Resync the DaemonSet after MinReadySeconds as a last line of defense to guard against clock-skew.
 	if ds.Spec.MinReadySeconds >= 0 && numberReady != numberAvailable {
 		durationStore.Push(keyFunc(ds), time.Duration(ds.Spec.MinReadySeconds)*time.Second)
@@ -579,7 +610,9 @@ func (dsc *ReconcileDaemonSet) storeDaemonSetStatus(
 	numberAvailable,
 	numberUnavailable int,
 	updateObservedGen bool,
-	hash string) error {
+	hash string,
+	currentWave *appsv1alpha1.DaemonSetUpdateWaveStatus,
+	conditions []apps.DaemonSetCondition) error {
 	if int(ds.Status.DesiredNumberScheduled) == desiredNumberScheduled &&
 		int(ds.Status.CurrentNumberScheduled) == currentNumberScheduled &&
 		int(ds.Status.NumberMisscheduled) == numberMisscheduled &&
@@ -588,7 +621,9 @@ func (dsc *ReconcileDaemonSet) storeDaemonSetStatus(
 		int(ds.Status.NumberAvailable) == numberAvailable &&
 		int(ds.Status.NumberUnavailable) == numberUnavailable &&
 		ds.Status.ObservedGeneration >= ds.Generation &&
-		ds.Status.DaemonSetHash == hash {
+		ds.Status.DaemonSetHash == hash &&
+		apiequality.Semantic.DeepEqual(ds.Status.CurrentWave, currentWave) &&
+		apiequality.Semantic.DeepEqual(ds.Status.Conditions, conditions) {
 		return nil
 	}
 
@@ -607,6 +642,8 @@ func (dsc *ReconcileDaemonSet) storeDaemonSetStatus(
 		toUpdate.Status.NumberAvailable = int32(numberAvailable)
 		toUpdate.Status.NumberUnavailable = int32(numberUnavailable)
 		toUpdate.Status.DaemonSetHash = hash
+		toUpdate.Status.CurrentWave = currentWave
+		toUpdate.Status.Conditions = conditions
 
 		if _, updateErr = dsClient.UpdateStatus(ctx, toUpdate, metav1.UpdateOptions{}); updateErr == nil {
 			klog.Infof("Updated DaemonSet %s/%s status to %v", ds.Namespace, ds.Name, kruiseutil.DumpJSON(toUpdate.Status))
@@ -677,6 +714,36 @@ func (dsc *ReconcileDaemonSet) manage(ctx context.Context, ds *appsv1alpha1.Daem
 // syncNodes deletes given pods and creates new daemon set pods on the given nodes
 // returns slice with errors if any
 func (dsc *ReconcileDaemonSet) syncNodes(ctx context.Context, ds *appsv1alpha1.DaemonSet, podsToDelete, nodesNeedingDaemonPods []string, hash string) error {
+	// If the returned error is not nil we have a parse error.
+	// The controller handles this via the hash.
+	generation, err := GetTemplateGeneration(ds)
+	if err != nil {
+		generation = nil
+	}
+	template := newDaemonPodTemplate(ds, ds.Spec.Template, generation, hash)
+	return dsc.syncNodesWithTemplate(ctx, ds, podsToDelete, nodesNeedingDaemonPods, template, ds.Spec.NodeOverrides)
+}
+
+// newDaemonPodTemplate returns the template to create daemon pods of the given revision hash.
+func newDaemonPodTemplate(ds *appsv1alpha1.DaemonSet, podTemplate corev1.PodTemplateSpec, generation *int64, hash string) corev1.PodTemplateSpec {
+	template := util.CreatePodTemplate(podTemplate, generation, hash)
+	addNodeDrainToleration(ds, &template.Spec)
+
+	if ds.Spec.UpdateStrategy.Type == appsv1alpha1.RollingUpdateDaemonSetStrategyType &&
+		ds.Spec.UpdateStrategy.RollingUpdate != nil &&
+		ds.Spec.UpdateStrategy.RollingUpdate.Type == appsv1alpha1.InplaceRollingUpdateType {
+		readinessGate := corev1.PodReadinessGate{
+			ConditionType: appspub.InPlaceUpdateReady,
+		}
+		template.Spec.ReadinessGates = append(template.Spec.ReadinessGates, readinessGate)
+	}
+	return template
+}
+
+// syncNodesWithTemplate deletes given pods and creates daemon set pods from the template on the given nodes,
+// with the node overrides applied for each node.
+func (dsc *ReconcileDaemonSet) syncNodesWithTemplate(ctx context.Context, ds *appsv1alpha1.DaemonSet, podsToDelete, nodesNeedingDaemonPods []string,
+	template corev1.PodTemplateSpec, nodeOverrides []appsv1alpha1.DaemonSetNodeOverride) error {
 	if ds.Spec.Lifecycle != nil && ds.Spec.Lifecycle.PreDelete != nil {
 		var err error
 		podsToDelete, err = dsc.syncWithPreparingDelete(ds, podsToDelete)
@@ -705,22 +772,6 @@ func (dsc *ReconcileDaemonSet) syncNodes(ctx context.Context, ds *appsv1alpha1.D
 
 	klog.V(4).Infof("Nodes needing daemon pods for DaemonSet %s: %+v, creating %d", ds.Name, nodesNeedingDaemonPods, createDiff)
 	createWait := sync.WaitGroup{}
-	// If the returned error is not nil we have a parse error.
-	// The controller handles this via the hash.
-	generation, err := GetTemplateGeneration(ds)
-	if err != nil {
-		generation = nil
-	}
-	template := util.CreatePodTemplate(ds.Spec.Template, generation, hash)
-
-	if ds.Spec.UpdateStrategy.Type == appsv1alpha1.RollingUpdateDaemonSetStrategyType &&
-		ds.Spec.UpdateStrategy.RollingUpdate != nil &&
-		ds.Spec.UpdateStrategy.RollingUpdate.Type == appsv1alpha1.InplaceRollingUpdateType {
-		readinessGate := corev1.PodReadinessGate{
-			ConditionType: appspub.InPlaceUpdateReady,
-		}
-		template.Spec.ReadinessGates = append(template.Spec.ReadinessGates, readinessGate)
-	}
 
 	// Batch the pod creates. Batch sizes start at SlowStartInitialBatchSize
 	// and double with each successful iteration in a kind of "slow start".
@@ -740,6 +791,14 @@ func (dsc *ReconcileDaemonSet) syncNodes(ctx context.Context, ds *appsv1alpha1.D
 				var err error
 
 				podTemplate := template.DeepCopy()
+				// Advanced: patch the template with the node overrides that match the node
+				if err = dsc.applyNodeOverrides(podTemplate, nodeOverrides, nodesNeedingDaemonPods[ix]); err != nil {
+					klog.V(2).Infof("Failed to apply node overrides, decrementing expectations for set %q/%q", ds.Namespace, ds.Name)
+					dsc.expectations.CreationObserved(dsKey)
+					errCh <- err
+					utilruntime.HandleError(err)
+					return
+				}
 				if scheduleDaemonSetPods {
 					// The pod's NodeAffinity will be updated to make sure the Pod is bound
 					// to the target node by default scheduler. It is safe to do so because there
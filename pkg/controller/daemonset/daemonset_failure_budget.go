@@ -0,0 +1,287 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package daemonset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	appspub "github.com/openkruise/kruise/apis/apps/pub"
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+const (
+	// defaultUpdateProgressDeadlineSeconds is the default deadline for an updated daemon pod to become available.
+	defaultUpdateProgressDeadlineSeconds = 600
+
+	// UpdateFailureBudgetExceededReason is added in the UpdateFailed condition and the event when the rolling update
+	// is paused, because too many updated daemon pods failed to become available.
+	UpdateFailureBudgetExceededReason = "FailureBudgetExceeded"
+)
+
+func getUpdateFailureBudget(ds *appsv1alpha1.DaemonSet) *appsv1alpha1.DaemonSetUpdateFailureBudget {
+	if ds.Spec.UpdateStrategy.Type != appsv1alpha1.RollingUpdateDaemonSetStrategyType || ds.Spec.UpdateStrategy.RollingUpdate == nil {
+		return nil
+	}
+	return ds.Spec.UpdateStrategy.RollingUpdate.FailureBudget
+}
+
+// getPodUpdateTime returns the time when the pod has been created or in-place updated.
+func getPodUpdateTime(pod *corev1.Pod) time.Time {
+	updateTime := pod.CreationTimestamp.Time
+	if stateStr, ok := appspub.GetInPlaceUpdateState(pod); ok {
+		state := appspub.InPlaceUpdateState{}
+		if err := json.Unmarshal([]byte(stateStr), &state); err == nil && state.UpdateTimestamp.After(updateTime) {
+			updateTime = state.UpdateTimestamp.Time
+		}
+	}
+	return updateTime
+}
+
+// getUpdateFailedNodes returns the sorted nodes, on which the updated daemon pods failed to become available
+// within the progress deadline.
+func (dsc *ReconcileDaemonSet) getUpdateFailedNodes(ds *appsv1alpha1.DaemonSet, hash string, nodeToDaemonPods map[string][]*corev1.Pod) []string {
+	budget := getUpdateFailureBudget(ds)
+	deadline := time.Duration(defaultUpdateProgressDeadlineSeconds) * time.Second
+	if budget.ProgressDeadlineSeconds != nil {
+		deadline = time.Duration(*budget.ProgressDeadlineSeconds) * time.Second
+	}
+
+	now := dsc.failedPodsBackoff.Clock.Now()
+	var failedNodes []string
+	var nextCheck time.Duration
+	for nodeName, pods := range nodeToDaemonPods {
+		newPod, _, ok := findUpdatedPodsOnNode(ds, pods, hash)
+		if !ok || isPodNilOrPreDeleting(newPod) || isDaemonPodAvailable(newPod, ds.Spec.MinReadySeconds, metav1.Time{Time: now}) {
+			continue
+		}
+		updateTime := getPodUpdateTime(newPod)
+		if updateTime.IsZero() {
+			continue
+		}
+		if remaining := updateTime.Add(deadline).Sub(now); remaining > 0 {
+			if nextCheck == 0 || remaining < nextCheck {
+				nextCheck = remaining
+			}
+			continue
+		}
+		failedNodes = append(failedNodes, nodeName)
+	}
+	if nextCheck > 0 {
+		durationStore.Push(keyFunc(ds), nextCheck)
+	}
+	sort.Strings(failedNodes)
+	return failedNodes
+}
+
+// calculateUpdateFailedCondition calculates the conditions of DaemonSet with the UpdateFailed condition,
+// and returns true if the failure budget has been exceeded.
+func (dsc *ReconcileDaemonSet) calculateUpdateFailedCondition(ds *appsv1alpha1.DaemonSet, hash string, nodeToDaemonPods map[string][]*corev1.Pod) ([]apps.DaemonSetCondition, bool, error) {
+	budget := getUpdateFailureBudget(ds)
+	if budget == nil {
+		return removeDaemonSetCondition(ds.Status.Conditions, appsv1alpha1.DaemonSetConditionTypeUpdateFailed), false, nil
+	}
+
+	maxFailed := 0
+	if budget.MaxFailed != nil {
+		var err error
+		maxFailed, err = intstrutil.GetScaledValueFromIntOrPercent(budget.MaxFailed, len(nodeToDaemonPods), false)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	failedNodes := dsc.getUpdateFailedNodes(ds, hash, nodeToDaemonPods)
+	if len(failedNodes) <= maxFailed {
+		// keep the condition until the rolling update is resumed
+		if isDaemonSetPaused(ds) {
+			return ds.Status.Conditions, false, nil
+		}
+		return removeDaemonSetCondition(ds.Status.Conditions, appsv1alpha1.DaemonSetConditionTypeUpdateFailed), false, nil
+	}
+
+	condition := apps.DaemonSetCondition{
+		Type:               appsv1alpha1.DaemonSetConditionTypeUpdateFailed,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Time{Time: dsc.failedPodsBackoff.Clock.Now()},
+		Reason:             UpdateFailureBudgetExceededReason,
+		Message: fmt.Sprintf("%d updated daemon pods of revision %s failed to become available on nodes: %s",
+			len(failedNodes), hash, strings.Join(failedNodes, ", ")),
+	}
+	return setDaemonSetCondition(ds.Status.Conditions, condition), true, nil
+}
+
+// pauseDaemonSetForFailureBudget sets Paused of the rolling update to true.
+func (dsc *ReconcileDaemonSet) pauseDaemonSetForFailureBudget(ctx context.Context, ds *appsv1alpha1.DaemonSet) error {
+	dsClient := dsc.kruiseClient.AppsV1alpha1().DaemonSets(ds.Namespace)
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		toUpdate, err := dsClient.Get(ctx, ds.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if toUpdate.UID != ds.UID || toUpdate.Spec.UpdateStrategy.RollingUpdate == nil || isDaemonSetPaused(toUpdate) {
+			return nil
+		}
+		paused := true
+		toUpdate.Spec.UpdateStrategy.RollingUpdate.Paused = &paused
+		_, err = dsClient.Update(ctx, toUpdate, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	klog.Warningf("DaemonSet %s/%s has paused the rolling update, for the failure budget exceeded", ds.Namespace, ds.Name)
+	dsc.eventRecorder.Eventf(ds, corev1.EventTypeWarning, UpdateFailureBudgetExceededReason,
+		"paused the rolling update, for too many updated daemon pods failed to become available")
+	return nil
+}
+
+// rollbackUpdateFailedNodes replaces the failed daemon pods with the pods of the previous revision,
+// after the rolling update has been paused for the failure budget exceeded.
+func (dsc *ReconcileDaemonSet) rollbackUpdateFailedNodes(ctx context.Context, ds *appsv1alpha1.DaemonSet, hash string, old []*apps.ControllerRevision) error {
+	budget := getUpdateFailureBudget(ds)
+	if budget == nil || !budget.RollbackFailedNodes || len(old) == 0 {
+		return nil
+	}
+	if condition := getDaemonSetCondition(ds.Status.Conditions, appsv1alpha1.DaemonSetConditionTypeUpdateFailed); condition == nil ||
+		condition.Status != corev1.ConditionTrue || !isDaemonSetPaused(ds) {
+		return nil
+	}
+
+	nodeToDaemonPods, err := dsc.getNodesToDaemonPods(ctx, ds)
+	if err != nil {
+		return fmt.Errorf("couldn't get node to daemon pod mapping for DaemonSet %q: %v", ds.Name, err)
+	}
+	failedNodes := dsc.getUpdateFailedNodes(ds, hash, nodeToDaemonPods)
+	if len(failedNodes) == 0 {
+		return nil
+	}
+
+	var podsToDelete []string
+	for _, nodeName := range failedNodes {
+		newPod, _, _ := findUpdatedPodsOnNode(ds, nodeToDaemonPods[nodeName], hash)
+		podsToDelete = append(podsToDelete, newPod.Name)
+	}
+	if ds.Spec.Lifecycle != nil && ds.Spec.Lifecycle.PreDelete != nil {
+		if podsToDelete, err = dsc.syncWithPreparingDelete(ds, podsToDelete); err != nil {
+			return err
+		}
+	}
+
+	// only create pods on the nodes that have no old pods and the failed pods are deleted now
+	var nodesToRollback []string
+	podsCanDelete := make(map[string]struct{}, len(podsToDelete))
+	for _, podName := range podsToDelete {
+		podsCanDelete[podName] = struct{}{}
+	}
+	podsToDelete = podsToDelete[:0]
+	for _, nodeName := range failedNodes {
+		newPod, oldPod, _ := findUpdatedPodsOnNode(ds, nodeToDaemonPods[nodeName], hash)
+		if _, ok := podsCanDelete[newPod.Name]; !ok {
+			continue
+		}
+		podsToDelete = append(podsToDelete, newPod.Name)
+		if oldPod == nil {
+			nodesToRollback = append(nodesToRollback, nodeName)
+		}
+	}
+	if len(podsToDelete) == 0 {
+		return nil
+	}
+
+	// roll back to the latest one of the old revisions
+	revision := old[0]
+	for _, history := range old {
+		if history.Revision > revision.Revision {
+			revision = history
+		}
+	}
+	rollbackDS, err := applyDaemonSetHistory(ds, revision)
+	if err != nil {
+		return err
+	}
+	template := newDaemonPodTemplate(ds, rollbackDS.Spec.Template, nil, revision.Labels[apps.DefaultDaemonSetUniqueLabelKey])
+
+	klog.V(3).Infof("DaemonSet %s/%s is rolling back failed pods %v to revision %s on nodes %v",
+		ds.Namespace, ds.Name, podsToDelete, revision.Name, nodesToRollback)
+	dsc.eventRecorder.Eventf(ds, corev1.EventTypeNormal, "RollbackFailedNodes",
+		"rolling back %d failed pods to revision %s", len(podsToDelete), revision.Name)
+	return dsc.syncNodesWithTemplate(ctx, ds, podsToDelete, nodesToRollback, template, rollbackDS.Spec.NodeOverrides)
+}
+
+// applyDaemonSetHistory returns the DaemonSet with the template restored from the history.
+func applyDaemonSetHistory(ds *appsv1alpha1.DaemonSet, history *apps.ControllerRevision) (*appsv1alpha1.DaemonSet, error) {
+	clone := ds.DeepCopy()
+	// node overrides only exist in the history if not empty
+	clone.Spec.NodeOverrides = nil
+	dsBytes, err := json.Marshal(clone)
+	if err != nil {
+		return nil, err
+	}
+	patched, err := strategicpatch.StrategicMergePatch(dsBytes, history.Data.Raw, clone)
+	if err != nil {
+		return nil, err
+	}
+	restoredDS := &appsv1alpha1.DaemonSet{}
+	if err = json.Unmarshal(patched, restoredDS); err != nil {
+		return nil, err
+	}
+	return restoredDS, nil
+}
+
+func getDaemonSetCondition(conditions []apps.DaemonSetCondition, condType apps.DaemonSetConditionType) *apps.DaemonSetCondition {
+	for i := range conditions {
+		if conditions[i].Type == condType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// setDaemonSetCondition returns the conditions with the given condition set, the last transition time is kept
+// if the status is not changed.
+func setDaemonSetCondition(conditions []apps.DaemonSetCondition, condition apps.DaemonSetCondition) []apps.DaemonSetCondition {
+	if current := getDaemonSetCondition(conditions, condition.Type); current != nil && current.Status == condition.Status {
+		condition.LastTransitionTime = current.LastTransitionTime
+	}
+	return append(removeDaemonSetCondition(conditions, condition.Type), condition)
+}
+
+func removeDaemonSetCondition(conditions []apps.DaemonSetCondition, condType apps.DaemonSetConditionType) []apps.DaemonSetCondition {
+	if getDaemonSetCondition(conditions, condType) == nil {
+		return conditions
+	}
+	newConditions := make([]apps.DaemonSetCondition, 0, len(conditions))
+	for _, c := range conditions {
+		if c.Type != condType {
+			newConditions = append(newConditions, c)
+		}
+	}
+	return newConditions
+}
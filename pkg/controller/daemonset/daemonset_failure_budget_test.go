@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package daemonset
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	testingclock "k8s.io/utils/clock/testing"
+	utilpointer "k8s.io/utils/pointer"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func TestDaemonSetUpdatePausedByFailureBudget(t *testing.T) {
+	ds := newDaemonSet("foo")
+	manager, podControl, client, err := newTestController(ds)
+	if err != nil {
+		t.Fatalf("error creating DaemonSets controller: %v", err)
+	}
+	addNodes(manager.nodeStore, 0, 5, nil)
+	manager.dsStore.Add(ds)
+	expectSyncDaemonSets(t, manager, ds, podControl, 5, 0, 0)
+	markPodsReady(podControl.podStore)
+
+	revisions, err := client.AppsV1().ControllerRevisions(ds.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil || len(revisions.Items) != 1 {
+		t.Fatalf("expected one revision, got %v, %v", revisions, err)
+	}
+	oldHash := revisions.Items[0].Labels[apps.DefaultDaemonSetUniqueLabelKey]
+	manager.historyStore.Add(&revisions.Items[0])
+
+	ds.Spec.Template.Spec.Containers[0].Image = "foo2/bar2"
+	ds.Spec.UpdateStrategy = newUpdateUnavailable(intstr.FromInt(2))
+	maxFailed := intstr.FromInt(1)
+	ds.Spec.UpdateStrategy.RollingUpdate.FailureBudget = &appsv1alpha1.DaemonSetUpdateFailureBudget{
+		MaxFailed:               &maxFailed,
+		ProgressDeadlineSeconds: utilpointer.Int32Ptr(60),
+		RollbackFailedNodes:     true,
+	}
+	manager.dsStore.Update(ds)
+
+	clearExpectations(t, manager, ds, podControl)
+	expectSyncDaemonSets(t, manager, ds, podControl, 0, 2, 0)
+	clearExpectations(t, manager, ds, podControl)
+	expectSyncDaemonSets(t, manager, ds, podControl, 2, 0, 0)
+
+	// the new pods are not ready before the deadline
+	clearExpectations(t, manager, ds, podControl)
+	expectSyncDaemonSets(t, manager, ds, podControl, 0, 0, 0)
+
+	manager.failedPodsBackoff.Clock.(*testingclock.FakeClock).Step(2 * time.Minute)
+	clearExpectations(t, manager, ds, podControl)
+	expectSyncDaemonSets(t, manager, ds, podControl, 0, 0, 1)
+
+	got, err := manager.kruiseClient.AppsV1alpha1().DaemonSets(ds.Namespace).Get(context.TODO(), ds.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get DaemonSet: %v", err)
+	}
+	if !isDaemonSetPaused(got) {
+		t.Fatalf("expected DaemonSet paused")
+	}
+	condition := getDaemonSetCondition(got.Status.Conditions, appsv1alpha1.DaemonSetConditionTypeUpdateFailed)
+	if condition == nil || condition.Status != corev1.ConditionTrue || !strings.HasPrefix(condition.Message, "2 updated daemon pods") {
+		t.Fatalf("unexpected UpdateFailed condition: %+v", condition)
+	}
+
+	// roll the failed nodes back to the previous revision
+	manager.dsStore.Update(got)
+	clearExpectations(t, manager, got, podControl)
+	expectSyncDaemonSets(t, manager, got, podControl, 2, 2, 2)
+	for _, template := range podControl.Templates {
+		if hash := template.Labels[apps.DefaultDaemonSetUniqueLabelKey]; hash != oldHash {
+			t.Fatalf("expected pods rolled back to %s, got %s", oldHash, hash)
+		}
+	}
+
+	// keep the condition while paused
+	clearExpectations(t, manager, got, podControl)
+	expectSyncDaemonSets(t, manager, got, podControl, 0, 0, 2)
+	got, err = manager.kruiseClient.AppsV1alpha1().DaemonSets(ds.Namespace).Get(context.TODO(), ds.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get DaemonSet: %v", err)
+	}
+	if getDaemonSetCondition(got.Status.Conditions, appsv1alpha1.DaemonSetConditionTypeUpdateFailed) == nil {
+		t.Fatalf("expected UpdateFailed condition kept while paused")
+	}
+}
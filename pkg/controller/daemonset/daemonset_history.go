@@ -157,6 +157,10 @@ func getPatch(ds *appsv1alpha1.DaemonSet) ([]byte, error) {
 	template := spec["template"].(map[string]interface{})
 	specCopy["template"] = template
 	template["$patch"] = "replace"
+	// Advanced: node overrides take part in the revision only if exist, to keep the existing revisions matched
+	if nodeOverrides, ok := spec["nodeOverrides"]; ok {
+		specCopy["nodeOverrides"] = nodeOverrides
+	}
 	objCopy["spec"] = specCopy
 	patch, err := json.Marshal(objCopy)
 	return patch, err
@@ -178,7 +182,7 @@ func (dsc *ReconcileDaemonSet) snapshot(ctx context.Context, ds *appsv1alpha1.Da
 	if err != nil {
 		return nil, err
 	}
-	hash := kubecontroller.ComputeHash(&ds.Spec.Template, ds.Status.CollisionCount)
+	hash := computeRevisionHash(ds)
 	name := ds.Name + "-" + hash
 	history := &apps.ControllerRevision{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,345 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package daemonset
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	kruiseutil "github.com/openkruise/kruise/pkg/util"
+	utilclient "github.com/openkruise/kruise/pkg/util/client"
+	"github.com/openkruise/kruise/pkg/util/fieldindex"
+)
+
+// NodeDrainFinalizer is added to the DaemonSet before it taints any node for drain, and removed after the drain
+// taints of the DaemonSet have been removed from all nodes, so that the nodes are untainted even if it is deleted.
+const NodeDrainFinalizer = "apps.kruise.io/daemonset-node-drain"
+
+// nodeDrainCheckInterval is the interval to requeue the DaemonSet while waiting for dependent pods to be drained.
+var nodeDrainCheckInterval = 5 * time.Second
+
+func getNodeDrainPolicy(ds *appsv1alpha1.DaemonSet) *appsv1alpha1.DaemonSetNodeDrainPolicy {
+	if ds.Spec.UpdateStrategy.Type != appsv1alpha1.RollingUpdateDaemonSetStrategyType || ds.Spec.UpdateStrategy.RollingUpdate == nil {
+		return nil
+	}
+	return ds.Spec.UpdateStrategy.RollingUpdate.NodeDrain
+}
+
+func getNodeDrainTaintKey(policy *appsv1alpha1.DaemonSetNodeDrainPolicy) string {
+	if policy.TaintKey == "" {
+		return appsv1alpha1.DefaultDaemonSetNodeDrainTaintKey
+	}
+	return policy.TaintKey
+}
+
+// addNodeDrainToleration makes daemon pods tolerate the taint added by node drain,
+// so that the new daemon pod can still be scheduled onto the draining node.
+func addNodeDrainToleration(ds *appsv1alpha1.DaemonSet, spec *corev1.PodSpec) {
+	policy := getNodeDrainPolicy(ds)
+	if policy == nil {
+		return
+	}
+	toleration := corev1.Toleration{
+		Key:      getNodeDrainTaintKey(policy),
+		Operator: corev1.TolerationOpExists,
+		Effect:   corev1.TaintEffectNoSchedule,
+	}
+	for i := range spec.Tolerations {
+		if spec.Tolerations[i].MatchToleration(&toleration) {
+			return
+		}
+	}
+	// copy the tolerations to avoid modifying the DaemonSet template
+	spec.Tolerations = append(spec.Tolerations[:len(spec.Tolerations):len(spec.Tolerations)], toleration)
+}
+
+// isNodeDraining returns true if the node has been tainted for drain by the DaemonSet.
+func (dsc *ReconcileDaemonSet) isNodeDraining(ds *appsv1alpha1.DaemonSet, nodeName string) bool {
+	policy := getNodeDrainPolicy(ds)
+	if policy == nil {
+		return false
+	}
+	node, err := dsc.nodeLister.Get(nodeName)
+	if err != nil {
+		return false
+	}
+	return getNodeDrainTaint(ds, policy, node) != nil
+}
+
+// getNodeDrainTaint returns the taint on the node added by the DaemonSet, or nil if there is none.
+func getNodeDrainTaint(ds *appsv1alpha1.DaemonSet, policy *appsv1alpha1.DaemonSetNodeDrainPolicy, node *corev1.Node) *corev1.Taint {
+	key := getNodeDrainTaintKey(policy)
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Key == key && taint.Effect == corev1.TaintEffectNoSchedule && taint.Value == string(ds.UID) {
+			return taint
+		}
+	}
+	return nil
+}
+
+// filterNodeDrainedPods taints the nodes of the old daemon pods to delete, and returns only the pods whose
+// dependent pods on the node have been evicted or are ready for the daemon pod to be replaced.
+func (dsc *ReconcileDaemonSet) filterNodeDrainedPods(ctx context.Context, ds *appsv1alpha1.DaemonSet, podNames []string) ([]string, error) {
+	policy := getNodeDrainPolicy(ds)
+	if policy == nil || len(podNames) == 0 {
+		return podNames, nil
+	}
+
+	if err := dsc.updateNodeDrainFinalizer(ctx, ds, true); err != nil {
+		return nil, err
+	}
+
+	now := dsc.failedPodsBackoff.Clock.Now()
+	var drainedPods []string
+	var waiting bool
+	for _, podName := range podNames {
+		pod, err := dsc.podLister.Pods(ds.Namespace).Get(podName)
+		if err != nil || pod.Spec.NodeName == "" {
+			// let the pod be deleted as usual
+			drainedPods = append(drainedPods, podName)
+			continue
+		}
+
+		taint, err := dsc.taintNodeForDrain(ctx, ds, policy, pod.Spec.NodeName)
+		if err != nil {
+			return nil, err
+		} else if taint == nil {
+			// the node has been tainted by another DaemonSet, wait for it to be untainted
+			waiting = true
+			continue
+		}
+
+		drained, err := dsc.isNodeDrained(ctx, ds, policy, pod.Spec.NodeName)
+		if err != nil {
+			return nil, err
+		}
+		if !drained && policy.TimeoutSeconds > 0 && taint.TimeAdded != nil &&
+			!now.Before(taint.TimeAdded.Add(time.Duration(policy.TimeoutSeconds)*time.Second)) {
+			klog.Warningf("DaemonSet %s/%s timed out waiting for dependent pods drained on node %s, deleting pod %s anyway",
+				ds.Namespace, ds.Name, pod.Spec.NodeName, podName)
+			dsc.eventRecorder.Eventf(ds, corev1.EventTypeWarning, "NodeDrainTimeout",
+				"timed out waiting for dependent pods drained on node %s", pod.Spec.NodeName)
+			drained = true
+		}
+		if !drained {
+			klog.V(4).Infof("DaemonSet %s/%s is waiting for dependent pods drained on node %s before deleting pod %s",
+				ds.Namespace, ds.Name, pod.Spec.NodeName, podName)
+			waiting = true
+			continue
+		}
+		drainedPods = append(drainedPods, podName)
+	}
+
+	if waiting {
+		durationStore.Push(keyFunc(ds), nodeDrainCheckInterval)
+	}
+	return drainedPods, nil
+}
+
+// taintNodeForDrain adds the drain taint to the node if not exists. It returns nil if the taint key has been
+// used by another DaemonSet on the node.
+func (dsc *ReconcileDaemonSet) taintNodeForDrain(ctx context.Context, ds *appsv1alpha1.DaemonSet, policy *appsv1alpha1.DaemonSetNodeDrainPolicy, nodeName string) (*corev1.Taint, error) {
+	key := getNodeDrainTaintKey(policy)
+	var taint *corev1.Taint
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		taint = nil
+		node, err := dsc.kubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		for i := range node.Spec.Taints {
+			if node.Spec.Taints[i].Key == key && node.Spec.Taints[i].Effect == corev1.TaintEffectNoSchedule {
+				if node.Spec.Taints[i].Value == string(ds.UID) {
+					taint = &node.Spec.Taints[i]
+				}
+				return nil
+			}
+		}
+
+		newTaint := corev1.Taint{
+			Key:       key,
+			Value:     string(ds.UID),
+			Effect:    corev1.TaintEffectNoSchedule,
+			TimeAdded: &metav1.Time{Time: dsc.failedPodsBackoff.Clock.Now()},
+		}
+		node.Spec.Taints = append(node.Spec.Taints, newTaint)
+		if _, err = dsc.kubeClient.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+		klog.V(3).Infof("DaemonSet %s/%s tainted node %s for drain", ds.Namespace, ds.Name, nodeName)
+		taint = &newTaint
+		return nil
+	})
+	return taint, err
+}
+
+// isNodeDrained returns true if all the dependent pods on the node have been evicted, or annotated as ready.
+func (dsc *ReconcileDaemonSet) isNodeDrained(ctx context.Context, ds *appsv1alpha1.DaemonSet, policy *appsv1alpha1.DaemonSetNodeDrainPolicy, nodeName string) (bool, error) {
+	if policy.DependentPodSelector == nil {
+		return true, nil
+	}
+	selector, err := kruiseutil.ValidatedLabelSelectorAsSelector(policy.DependentPodSelector)
+	if err != nil {
+		return false, err
+	}
+
+	podList := &corev1.PodList{}
+	if err := dsc.List(ctx, podList, client.MatchingFields{fieldindex.IndexNameForPodNodeName: nodeName},
+		client.MatchingLabelsSelector{Selector: selector}, utilclient.DisableDeepCopy); err != nil {
+		return false, err
+	}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed ||
+			metav1.IsControlledBy(pod, ds) {
+			continue
+		}
+		if pod.Annotations[appsv1alpha1.DaemonSetNodeDrainReadyAnnotation] == "true" {
+			continue
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// syncNodeDrainTaints removes the drain taints from the nodes, on which the new daemon pods are available
+// or there are no daemon pods any more.
+func (dsc *ReconcileDaemonSet) syncNodeDrainTaints(ctx context.Context, ds *appsv1alpha1.DaemonSet, nodeList []*corev1.Node, hash string, nodeToDaemonPods map[string][]*corev1.Pod) error {
+	policy := getNodeDrainPolicy(ds)
+	if policy == nil {
+		return nil
+	}
+
+	now := metav1.Time{Time: dsc.failedPodsBackoff.Clock.Now()}
+	for _, node := range nodeList {
+		if getNodeDrainTaint(ds, policy, node) == nil {
+			continue
+		}
+		if pods := nodeToDaemonPods[node.Name]; len(pods) > 0 {
+			newPod, oldPod, ok := findUpdatedPodsOnNode(ds, pods, hash)
+			if !ok || newPod == nil || oldPod != nil || !isDaemonPodAvailable(newPod, ds.Spec.MinReadySeconds, now) {
+				continue
+			}
+		}
+		if err := dsc.untaintNodeForDrain(ctx, ds, node.Name); err != nil {
+			return err
+		}
+		klog.V(3).Infof("DaemonSet %s/%s untainted node %s after drain", ds.Namespace, ds.Name, node.Name)
+	}
+	return nil
+}
+
+// isNodeDrainStopped returns true if the DaemonSet should not keep any node drained, that is
+// it has been deleted, paused, or does not drain nodes for update any more.
+func isNodeDrainStopped(ds *appsv1alpha1.DaemonSet) bool {
+	return ds.DeletionTimestamp != nil || getNodeDrainPolicy(ds) == nil || isDaemonSetPaused(ds)
+}
+
+// cleanupNodeDrainTaints removes all the drain taints of the DaemonSet from the nodes and then its finalizer.
+func (dsc *ReconcileDaemonSet) cleanupNodeDrainTaints(ctx context.Context, ds *appsv1alpha1.DaemonSet) error {
+	if !controllerutil.ContainsFinalizer(ds, NodeDrainFinalizer) {
+		return nil
+	}
+	nodeList, err := dsc.nodeLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, node := range nodeList {
+		if !hasNodeDrainTaintOf(ds, node) {
+			continue
+		}
+		if err = dsc.untaintNodeForDrain(ctx, ds, node.Name); err != nil {
+			return err
+		}
+		klog.V(3).Infof("DaemonSet %s/%s untainted node %s for drain cleanup", ds.Namespace, ds.Name, node.Name)
+	}
+	return dsc.updateNodeDrainFinalizer(ctx, ds, false)
+}
+
+// hasNodeDrainTaintOf returns true if the node has any drain taint added by the DaemonSet, whatever its key is,
+// since the taint key may have been changed or removed with the node drain policy.
+func hasNodeDrainTaintOf(ds *appsv1alpha1.DaemonSet, node *corev1.Node) bool {
+	for i := range node.Spec.Taints {
+		if isNodeDrainTaintOf(ds, &node.Spec.Taints[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func isNodeDrainTaintOf(ds *appsv1alpha1.DaemonSet, taint *corev1.Taint) bool {
+	return taint.Effect == corev1.TaintEffectNoSchedule && taint.Value == string(ds.UID)
+}
+
+func (dsc *ReconcileDaemonSet) untaintNodeForDrain(ctx context.Context, ds *appsv1alpha1.DaemonSet, nodeName string) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		node, err := dsc.kubeClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if !hasNodeDrainTaintOf(ds, node) {
+			return nil
+		}
+		taints := make([]corev1.Taint, 0, len(node.Spec.Taints))
+		for i := range node.Spec.Taints {
+			if !isNodeDrainTaintOf(ds, &node.Spec.Taints[i]) {
+				taints = append(taints, node.Spec.Taints[i])
+			}
+		}
+		node.Spec.Taints = taints
+		_, err = dsc.kubeClient.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// updateNodeDrainFinalizer adds or removes the NodeDrainFinalizer of the DaemonSet.
+func (dsc *ReconcileDaemonSet) updateNodeDrainFinalizer(ctx context.Context, ds *appsv1alpha1.DaemonSet, add bool) error {
+	if controllerutil.ContainsFinalizer(ds, NodeDrainFinalizer) == add {
+		return nil
+	}
+	dsClient := dsc.kruiseClient.AppsV1alpha1().DaemonSets(ds.Namespace)
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		toUpdate, err := dsClient.Get(ctx, ds.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if toUpdate.UID != ds.UID || controllerutil.ContainsFinalizer(toUpdate, NodeDrainFinalizer) == add {
+			return nil
+		}
+		if add {
+			controllerutil.AddFinalizer(toUpdate, NodeDrainFinalizer)
+		} else {
+			controllerutil.RemoveFinalizer(toUpdate, NodeDrainFinalizer)
+		}
+		_, err = dsClient.Update(ctx, toUpdate, metav1.UpdateOptions{})
+		return err
+	})
+}
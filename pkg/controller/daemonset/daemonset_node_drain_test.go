@@ -0,0 +1,290 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package daemonset
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
+	testingclock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	kruisefake "github.com/openkruise/kruise/pkg/client/clientset/versioned/fake"
+	"github.com/openkruise/kruise/pkg/util/fieldindex"
+)
+
+func newNodeDrainTestReconciler(now time.Time, ds *appsv1alpha1.DaemonSet, nodes []*corev1.Node, pods []*corev1.Pod) *ReconcileDaemonSet {
+	kubeClient := fake.NewSimpleClientset()
+	kruiseClient := kruisefake.NewSimpleClientset(ds)
+	nodeIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, node := range nodes {
+		_, _ = kubeClient.CoreV1().Nodes().Create(context.TODO(), node, metav1.CreateOptions{})
+		_ = nodeIndexer.Add(node)
+	}
+	podIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	builder := fakeclient.NewClientBuilder().WithIndex(&corev1.Pod{}, fieldindex.IndexNameForPodNodeName, func(obj client.Object) []string {
+		return []string{obj.(*corev1.Pod).Spec.NodeName}
+	})
+	for _, pod := range pods {
+		_ = podIndexer.Add(pod)
+		builder = builder.WithObjects(pod)
+	}
+	return &ReconcileDaemonSet{
+		Client:            builder.Build(),
+		kubeClient:        kubeClient,
+		kruiseClient:      kruiseClient,
+		eventRecorder:     record.NewFakeRecorder(10),
+		podLister:         corelisters.NewPodLister(podIndexer),
+		nodeLister:        corelisters.NewNodeLister(nodeIndexer),
+		failedPodsBackoff: flowcontrol.NewFakeBackOff(time.Second, time.Minute, testingclock.NewFakeClock(now)),
+	}
+}
+
+func newNodeDrainTestPod(name, nodeName string, labels map[string]string, annotations map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: name, Labels: labels, Annotations: annotations},
+		Spec:       corev1.PodSpec{NodeName: nodeName},
+	}
+}
+
+func TestFilterNodeDrainedPods(t *testing.T) {
+	now := time.Unix(1000, 0)
+	dependentLabels := map[string]string{"depends-on": "csi"}
+	readyAnnotations := map[string]string{appsv1alpha1.DaemonSetNodeDrainReadyAnnotation: "true"}
+	ds := &appsv1alpha1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "ds", UID: "ds-uid"},
+		Spec: appsv1alpha1.DaemonSetSpec{UpdateStrategy: appsv1alpha1.DaemonSetUpdateStrategy{
+			Type: appsv1alpha1.RollingUpdateDaemonSetStrategyType,
+			RollingUpdate: &appsv1alpha1.RollingUpdateDaemonSet{NodeDrain: &appsv1alpha1.DaemonSetNodeDrainPolicy{
+				DependentPodSelector: &metav1.LabelSelector{MatchLabels: dependentLabels},
+				TimeoutSeconds:       60,
+			}},
+		}},
+	}
+	drainTaint := func(timeAdded time.Time) []corev1.Taint {
+		return []corev1.Taint{{Key: appsv1alpha1.DefaultDaemonSetNodeDrainTaintKey, Value: "ds-uid",
+			Effect: corev1.TaintEffectNoSchedule, TimeAdded: &metav1.Time{Time: timeAdded}}}
+	}
+
+	nodes := []*corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "n1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "n2"}, Spec: corev1.NodeSpec{Taints: drainTaint(now)}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "n3"}, Spec: corev1.NodeSpec{Taints: drainTaint(now)}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "n4"}, Spec: corev1.NodeSpec{Taints: drainTaint(now.Add(-time.Minute))}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "n5"}, Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+			{Key: appsv1alpha1.DefaultDaemonSetNodeDrainTaintKey, Value: "other-uid", Effect: corev1.TaintEffectNoSchedule}}}},
+	}
+	pods := []*corev1.Pod{
+		newNodeDrainTestPod("ds-n1", "n1", nil, nil),
+		newNodeDrainTestPod("ds-n2", "n2", nil, nil),
+		newNodeDrainTestPod("ds-n3", "n3", nil, nil),
+		newNodeDrainTestPod("ds-n4", "n4", nil, nil),
+		newNodeDrainTestPod("ds-n5", "n5", nil, nil),
+		newNodeDrainTestPod("dependent-n1", "n1", dependentLabels, nil),
+		newNodeDrainTestPod("dependent-n2", "n2", dependentLabels, readyAnnotations),
+		newNodeDrainTestPod("dependent-n3", "n3", dependentLabels, nil),
+		newNodeDrainTestPod("dependent-n4", "n4", dependentLabels, nil),
+		newNodeDrainTestPod("other-n3", "n3", nil, nil),
+	}
+
+	dsc := newNodeDrainTestReconciler(now, ds, nodes, pods)
+	got, err := dsc.filterNodeDrainedPods(context.TODO(), ds, []string{"ds-n1", "ds-n2", "ds-n3", "ds-n4", "ds-n5"})
+	if err != nil {
+		t.Fatalf("failed to filterNodeDrainedPods: %v", err)
+	}
+	// n1 has just been tainted, n2 has dependent pods ready, n3 is waiting, n4 has timed out, n5 is draining by another
+	if expected := []string{"ds-n2", "ds-n4"}; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected drained pods %v, got %v", expected, got)
+	}
+
+	node, err := dsc.kubeClient.CoreV1().Nodes().Get(context.TODO(), "n1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get node: %v", err)
+	}
+	if getNodeDrainTaint(ds, ds.Spec.UpdateStrategy.RollingUpdate.NodeDrain, node) == nil {
+		t.Fatalf("expected node n1 tainted, got %v", node.Spec.Taints)
+	}
+	fetched, err := dsc.kruiseClient.AppsV1alpha1().DaemonSets(ds.Namespace).Get(context.TODO(), ds.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get DaemonSet: %v", err)
+	}
+	if !controllerutil.ContainsFinalizer(fetched, NodeDrainFinalizer) {
+		t.Fatalf("expected DaemonSet has finalizer %s, got %v", NodeDrainFinalizer, fetched.Finalizers)
+	}
+}
+
+func TestCleanupNodeDrainTaints(t *testing.T) {
+	now := time.Unix(1000, 0)
+	paused := true
+	cases := []struct {
+		name         string
+		updateDS     func(ds *appsv1alpha1.DaemonSet)
+		expectTaints bool
+	}{
+		{
+			name:         "node drain policy is kept",
+			updateDS:     func(ds *appsv1alpha1.DaemonSet) {},
+			expectTaints: true,
+		},
+		{
+			name: "node drain policy is removed",
+			updateDS: func(ds *appsv1alpha1.DaemonSet) {
+				ds.Spec.UpdateStrategy.RollingUpdate.NodeDrain = nil
+			},
+		},
+		{
+			name: "update strategy is changed to OnDelete",
+			updateDS: func(ds *appsv1alpha1.DaemonSet) {
+				ds.Spec.UpdateStrategy = appsv1alpha1.DaemonSetUpdateStrategy{Type: appsv1alpha1.OnDeleteDaemonSetStrategyType}
+			},
+		},
+		{
+			name: "rolling update is paused",
+			updateDS: func(ds *appsv1alpha1.DaemonSet) {
+				ds.Spec.UpdateStrategy.RollingUpdate.Paused = &paused
+			},
+		},
+		{
+			name: "daemon set is deleted",
+			updateDS: func(ds *appsv1alpha1.DaemonSet) {
+				ds.DeletionTimestamp = &metav1.Time{Time: now}
+			},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			ds := &appsv1alpha1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "ds", UID: "ds-uid",
+					Finalizers: []string{NodeDrainFinalizer}},
+				Spec: appsv1alpha1.DaemonSetSpec{UpdateStrategy: appsv1alpha1.DaemonSetUpdateStrategy{
+					Type: appsv1alpha1.RollingUpdateDaemonSetStrategyType,
+					RollingUpdate: &appsv1alpha1.RollingUpdateDaemonSet{NodeDrain: &appsv1alpha1.DaemonSetNodeDrainPolicy{
+						TaintKey: "example.com/updating",
+					}},
+				}},
+			}
+			cs.updateDS(ds)
+			otherTaint := corev1.Taint{Key: "example.com/updating", Value: "other-uid", Effect: corev1.TaintEffectNoSchedule}
+			nodes := []*corev1.Node{
+				{ObjectMeta: metav1.ObjectMeta{Name: "n1"}, Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+					{Key: "example.com/updating", Value: "ds-uid", Effect: corev1.TaintEffectNoSchedule}}}},
+				// the taint added with the previous taint key
+				{ObjectMeta: metav1.ObjectMeta{Name: "n2"}, Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+					{Key: appsv1alpha1.DefaultDaemonSetNodeDrainTaintKey, Value: "ds-uid", Effect: corev1.TaintEffectNoSchedule}}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "n3"}, Spec: corev1.NodeSpec{Taints: []corev1.Taint{otherTaint}}},
+			}
+
+			dsc := newNodeDrainTestReconciler(now, ds, nodes, nil)
+			var err error
+			if isNodeDrainStopped(ds) {
+				err = dsc.cleanupNodeDrainTaints(context.TODO(), ds)
+			}
+			if err != nil {
+				t.Fatalf("failed to cleanupNodeDrainTaints: %v", err)
+			}
+			for _, name := range []string{"n1", "n2"} {
+				node, err := dsc.kubeClient.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("failed to get node: %v", err)
+				}
+				if tainted := len(node.Spec.Taints) > 0; tainted != cs.expectTaints {
+					t.Fatalf("expected node %s tainted %v, got %v", name, cs.expectTaints, node.Spec.Taints)
+				}
+			}
+			node, err := dsc.kubeClient.CoreV1().Nodes().Get(context.TODO(), "n3", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get node: %v", err)
+			}
+			if !reflect.DeepEqual(node.Spec.Taints, []corev1.Taint{otherTaint}) {
+				t.Fatalf("expected taint of other DaemonSet kept, got %v", node.Spec.Taints)
+			}
+			fetched, err := dsc.kruiseClient.AppsV1alpha1().DaemonSets(ds.Namespace).Get(context.TODO(), ds.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get DaemonSet: %v", err)
+			}
+			if controllerutil.ContainsFinalizer(fetched, NodeDrainFinalizer) != cs.expectTaints {
+				t.Fatalf("expected DaemonSet has finalizer %v, got %v", cs.expectTaints, fetched.Finalizers)
+			}
+		})
+	}
+}
+
+func TestSyncNodeDrainTaints(t *testing.T) {
+	now := time.Unix(1000, 0)
+	ds := &appsv1alpha1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "ds", UID: types.UID("ds-uid")},
+		Spec: appsv1alpha1.DaemonSetSpec{UpdateStrategy: appsv1alpha1.DaemonSetUpdateStrategy{
+			Type: appsv1alpha1.RollingUpdateDaemonSetStrategyType,
+			RollingUpdate: &appsv1alpha1.RollingUpdateDaemonSet{NodeDrain: &appsv1alpha1.DaemonSetNodeDrainPolicy{
+				TaintKey: "example.com/updating",
+			}},
+		}},
+	}
+	taints := []corev1.Taint{{Key: "example.com/updating", Value: "ds-uid", Effect: corev1.TaintEffectNoSchedule}}
+	nodes := []*corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "n1"}, Spec: corev1.NodeSpec{Taints: taints}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "n2"}, Spec: corev1.NodeSpec{Taints: taints}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "n3"}, Spec: corev1.NodeSpec{Taints: taints}},
+	}
+	newPod := func(hash string, ready bool) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{apps.DefaultDaemonSetUniqueLabelKey: hash}}}
+		if ready {
+			pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		}
+		return pod
+	}
+	nodeToDaemonPods := map[string][]*corev1.Pod{
+		"n1": {newPod("v2", true)},
+		"n2": {newPod("v2", false)},
+	}
+
+	dsc := newNodeDrainTestReconciler(now, ds, nodes, nil)
+	if err := dsc.syncNodeDrainTaints(context.TODO(), ds, nodes, "v2", nodeToDaemonPods); err != nil {
+		t.Fatalf("failed to syncNodeDrainTaints: %v", err)
+	}
+	for name, expectTainted := range map[string]bool{"n1": false, "n2": true, "n3": false} {
+		node, err := dsc.kubeClient.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get node: %v", err)
+		}
+		if tainted := len(node.Spec.Taints) > 0; tainted != expectTainted {
+			t.Fatalf("expected node %s tainted %v, got %v", name, expectTainted, node.Spec.Taints)
+		}
+	}
+
+	spec := &corev1.PodSpec{}
+	addNodeDrainToleration(ds, spec)
+	addNodeDrainToleration(ds, spec)
+	if len(spec.Tolerations) != 1 || !spec.Tolerations[0].ToleratesTaint(&taints[0]) {
+		t.Fatalf("expected the drain taint tolerated, got %v", spec.Tolerations)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package daemonset
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	kubecontroller "k8s.io/kubernetes/pkg/controller"
+	"k8s.io/kubernetes/pkg/controller/daemon/util"
+	hashutil "k8s.io/kubernetes/pkg/util/hash"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	kruiseutil "github.com/openkruise/kruise/pkg/util"
+)
+
+// computeRevisionHash returns the hash of the DaemonSet revision. The node overrides take part in it only if exist,
+// so that the hash of existing DaemonSets will not be changed.
+func computeRevisionHash(ds *appsv1alpha1.DaemonSet) string {
+	if len(ds.Spec.NodeOverrides) == 0 {
+		return kubecontroller.ComputeHash(&ds.Spec.Template, ds.Status.CollisionCount)
+	}
+
+	hasher := fnv.New32a()
+	hashutil.DeepHashObject(hasher, struct {
+		Template      corev1.PodTemplateSpec
+		NodeOverrides []appsv1alpha1.DaemonSetNodeOverride
+	}{ds.Spec.Template, ds.Spec.NodeOverrides})
+	// Add collisionCount in the hash if it exists.
+	if ds.Status.CollisionCount != nil {
+		collisionCountBytes := make([]byte, 8)
+		binary.LittleEndian.PutUint32(collisionCountBytes, uint32(*ds.Status.CollisionCount))
+		hasher.Write(collisionCountBytes)
+	}
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
+
+// getRevisionNodeOverrides returns the node overrides recorded in the revision.
+func getRevisionNodeOverrides(revision *apps.ControllerRevision) ([]appsv1alpha1.DaemonSetNodeOverride, error) {
+	ds := &appsv1alpha1.DaemonSet{}
+	if err := json.Unmarshal(revision.Data.Raw, ds); err != nil {
+		return nil, err
+	}
+	return ds.Spec.NodeOverrides, nil
+}
+
+// getMatchedNodeOverrides returns the node overrides that match the node.
+func getMatchedNodeOverrides(nodeOverrides []appsv1alpha1.DaemonSetNodeOverride, node *corev1.Node) ([]appsv1alpha1.DaemonSetNodeOverride, error) {
+	var matched []appsv1alpha1.DaemonSetNodeOverride
+	for i := range nodeOverrides {
+		selector, err := kruiseutil.ValidatedLabelSelectorAsSelector(nodeOverrides[i].NodeSelector)
+		if err != nil {
+			return nil, err
+		}
+		if selector.Matches(labels.Set(node.Labels)) {
+			matched = append(matched, nodeOverrides[i])
+		}
+	}
+	return matched, nil
+}
+
+// applyNodeOverrides patches the pod template with the node overrides that match the node.
+func (dsc *ReconcileDaemonSet) applyNodeOverrides(template *corev1.PodTemplateSpec, nodeOverrides []appsv1alpha1.DaemonSetNodeOverride, nodeName string) error {
+	if len(nodeOverrides) == 0 {
+		return nil
+	}
+	node, err := dsc.nodeLister.Get(nodeName)
+	if err != nil {
+		return err
+	}
+	matched, err := getMatchedNodeOverrides(nodeOverrides, node)
+	if err != nil || len(matched) == 0 {
+		return err
+	}
+
+	templateBytes, err := json.Marshal(template)
+	if err != nil {
+		return err
+	}
+	for _, override := range matched {
+		if templateBytes, err = strategicpatch.StrategicMergePatch(templateBytes, override.Patch.Raw, &corev1.PodTemplateSpec{}); err != nil {
+			return fmt.Errorf("failed to apply node override patch %s: %v", override.Patch.Raw, err)
+		}
+	}
+	patched := corev1.PodTemplateSpec{}
+	if err = json.Unmarshal(templateBytes, &patched); err != nil {
+		return err
+	}
+	*template = patched
+	return nil
+}
+
+// canNodeOverridesInPlaceUpdate returns false if the pod can not be updated in-place for the node overrides,
+// which happens when the node overrides have been changed, or the pod has been patched by them.
+func (dsc *ReconcileDaemonSet) canNodeOverridesInPlaceUpdate(pod *corev1.Pod, oldRevision, curRevision *apps.ControllerRevision) bool {
+	oldOverrides, err := getRevisionNodeOverrides(oldRevision)
+	if err != nil {
+		return false
+	}
+	curOverrides, err := getRevisionNodeOverrides(curRevision)
+	if err != nil || !apiequality.Semantic.DeepEqual(oldOverrides, curOverrides) {
+		return false
+	}
+	if len(curOverrides) == 0 {
+		return true
+	}
+
+	nodeName, err := util.GetTargetNodeName(pod)
+	if err != nil {
+		return false
+	}
+	node, err := dsc.nodeLister.Get(nodeName)
+	if err != nil {
+		return false
+	}
+	matched, err := getMatchedNodeOverrides(curOverrides, node)
+	return err == nil && len(matched) == 0
+}
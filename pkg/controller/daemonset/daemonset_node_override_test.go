@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package daemonset
+
+import (
+	"testing"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubecontroller "k8s.io/kubernetes/pkg/controller"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func TestComputeRevisionHash(t *testing.T) {
+	ds := newDaemonSet("foo")
+	if hash := computeRevisionHash(ds); hash != kubecontroller.ComputeHash(&ds.Spec.Template, ds.Status.CollisionCount) {
+		t.Fatalf("expected hash not changed without node overrides, got %s", hash)
+	}
+
+	withOverrides := ds.DeepCopy()
+	withOverrides.Spec.NodeOverrides = []appsv1alpha1.DaemonSetNodeOverride{{
+		NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"node-type": "gpu"}},
+		Patch:        runtime.RawExtension{Raw: []byte(`{"spec":{"containers":[{"name":"foo","image":"foo/gpu"}]}}`)},
+	}}
+	hash := computeRevisionHash(withOverrides)
+	if hash == computeRevisionHash(ds) {
+		t.Fatalf("expected hash changed with node overrides")
+	}
+
+	withOverrides.Spec.NodeOverrides[0].Patch.Raw = []byte(`{"spec":{"containers":[{"name":"foo","image":"foo/gpu2"}]}}`)
+	if computeRevisionHash(withOverrides) == hash {
+		t.Fatalf("expected hash changed with node override patch")
+	}
+}
+
+func TestDaemonSetNodeOverrides(t *testing.T) {
+	ds := newDaemonSet("foo")
+	ds.Spec.Template.Spec.Containers[0].Name = "foo"
+	ds.Spec.NodeOverrides = []appsv1alpha1.DaemonSetNodeOverride{{
+		NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"node-type": "gpu"}},
+		Patch:        runtime.RawExtension{Raw: []byte(`{"metadata":{"labels":{"node-type":"gpu"}},"spec":{"containers":[{"name":"foo","image":"foo/gpu"}]}}`)},
+	}}
+	manager, podControl, _, err := newTestController(ds)
+	if err != nil {
+		t.Fatalf("error creating DaemonSets controller: %v", err)
+	}
+	addNodes(manager.nodeStore, 0, 2, nil)
+	addNodes(manager.nodeStore, 2, 3, map[string]string{"node-type": "gpu"})
+	manager.dsStore.Add(ds)
+	expectSyncDaemonSets(t, manager, ds, podControl, 5, 0, 0)
+
+	var patched int
+	for _, template := range podControl.Templates {
+		image := template.Spec.Containers[0].Image
+		switch template.Labels["node-type"] {
+		case "gpu":
+			patched++
+			if image != "foo/gpu" {
+				t.Fatalf("expected patched image on gpu node, got %s", image)
+			}
+		default:
+			if image != ds.Spec.Template.Spec.Containers[0].Image {
+				t.Fatalf("expected template image on other node, got %s", image)
+			}
+		}
+		if len(template.Spec.Containers) != 1 || template.Spec.Containers[0].Name != "foo" {
+			t.Fatalf("unexpected containers %v", template.Spec.Containers)
+		}
+	}
+	if patched != 3 {
+		t.Fatalf("expected 3 pods patched, got %d", patched)
+	}
+}
+
+func newNodeOverrideTestRevision(t *testing.T, ds *appsv1alpha1.DaemonSet) *apps.ControllerRevision {
+	patch, err := getPatch(ds)
+	if err != nil {
+		t.Fatalf("failed to get patch: %v", err)
+	}
+	return &apps.ControllerRevision{Data: runtime.RawExtension{Raw: patch}}
+}
+
+func TestCanNodeOverridesInPlaceUpdate(t *testing.T) {
+	ds := newDaemonSet("foo")
+	manager, _, _, err := newTestController(ds)
+	if err != nil {
+		t.Fatalf("error creating DaemonSets controller: %v", err)
+	}
+	addNodes(manager.nodeStore, 0, 1, nil)
+	addNodes(manager.nodeStore, 1, 1, map[string]string{"node-type": "gpu"})
+
+	oldRevision := newNodeOverrideTestRevision(t, ds)
+	ds.Spec.Template.Spec.Containers[0].Image = "foo2/bar2"
+	curRevision := newNodeOverrideTestRevision(t, ds)
+	ds.Spec.NodeOverrides = []appsv1alpha1.DaemonSetNodeOverride{{
+		NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"node-type": "gpu"}},
+		Patch:        runtime.RawExtension{Raw: []byte(`{"metadata":{"labels":{"node-type":"gpu"}}}`)},
+	}}
+	overriddenRevision := newNodeOverrideTestRevision(t, ds)
+
+	pod := func(nodeName string) *corev1.Pod {
+		return &corev1.Pod{Spec: corev1.PodSpec{NodeName: nodeName}}
+	}
+	if !manager.canNodeOverridesInPlaceUpdate(pod("node-0"), oldRevision, curRevision) {
+		t.Fatalf("expected in-place update allowed without node overrides")
+	}
+	if manager.canNodeOverridesInPlaceUpdate(pod("node-0"), curRevision, overriddenRevision) {
+		t.Fatalf("expected in-place update not allowed when node overrides changed")
+	}
+	if !manager.canNodeOverridesInPlaceUpdate(pod("node-0"), overriddenRevision, overriddenRevision) {
+		t.Fatalf("expected in-place update allowed on node not overridden")
+	}
+	if manager.canNodeOverridesInPlaceUpdate(pod("node-1"), overriddenRevision, overriddenRevision) {
+		t.Fatalf("expected in-place update not allowed on overridden node")
+	}
+}
@@ -51,6 +51,11 @@ func (dsc *ReconcileDaemonSet) rollingUpdate(ctx context.Context, ds *appsv1alph
 		return fmt.Errorf("couldn't get unavailable numbers: %v", err)
 	}
 
+	// Advanced: untaint the nodes that have finished node drain
+	if err = dsc.syncNodeDrainTaints(ctx, ds, nodeList, hash, nodeToDaemonPods); err != nil {
+		return fmt.Errorf("failed to sync node drain taints: %v", err)
+	}
+
 	// Advanced: filter the pods updated, updating and can update, according to partition and selector
 	nodeToDaemonPods, err = dsc.filterDaemonPodsToUpdate(ds, nodeList, hash, nodeToDaemonPods)
 	if err != nil {
@@ -75,6 +80,7 @@ func (dsc *ReconcileDaemonSet) rollingUpdate(ctx context.Context, ds *appsv1alph
 		var numUnavailable int
 		var allowedReplacementPods []string
 		var candidatePodsToDelete []string
+		var drainingPodsToDelete []string
 		for nodeName, pods := range nodeToDaemonPods {
 			newPod, oldPod, ok := findUpdatedPodsOnNode(ds, pods, hash)
 			if !ok {
@@ -116,6 +122,11 @@ func (dsc *ReconcileDaemonSet) rollingUpdate(ctx context.Context, ds *appsv1alph
 					continue
 				default:
 					klog.V(5).Infof("DaemonSet %s/%s pod %s on node %s is out of date, this is a candidate to replace", ds.Namespace, ds.Name, oldPod.Name, nodeName)
+					// Advanced: prefer the candidates on nodes that are already draining
+					if dsc.isNodeDraining(ds, nodeName) {
+						drainingPodsToDelete = append(drainingPodsToDelete, oldPod.Name)
+						continue
+					}
 					// record the candidate
 					if candidatePodsToDelete == nil {
 						candidatePodsToDelete = make([]string, 0, maxUnavailable)
@@ -125,6 +136,8 @@ func (dsc *ReconcileDaemonSet) rollingUpdate(ctx context.Context, ds *appsv1alph
 			}
 		}
 
+		candidatePodsToDelete = append(drainingPodsToDelete, candidatePodsToDelete...)
+
 		// use any of the candidates we can, including the allowedReplacemnntPods
 		klog.V(5).Infof("DaemonSet %s/%s allowing %d replacements, up to %d unavailable, %d new are unavailable, %d candidates", ds.Namespace, ds.Name, len(allowedReplacementPods), maxUnavailable, numUnavailable, len(candidatePodsToDelete))
 		remainingUnavailable := maxUnavailable - numUnavailable
@@ -136,6 +149,12 @@ func (dsc *ReconcileDaemonSet) rollingUpdate(ctx context.Context, ds *appsv1alph
 		}
 		oldPodsToDelete := append(allowedReplacementPods, candidatePodsToDelete[:remainingUnavailable]...)
 
+		// Advanced: wait for the nodes drained before the old pods are updated
+		oldPodsToDelete, err = dsc.filterNodeDrainedPods(ctx, ds, oldPodsToDelete)
+		if err != nil {
+			return err
+		}
+
 		// Advanced: update pods in-place first and still delete the others
 		if ds.Spec.UpdateStrategy.RollingUpdate.Type == appsv1alpha1.InplaceRollingUpdateType {
 			oldPodsToDelete, err = dsc.inPlaceUpdatePods(ds, oldPodsToDelete, curRevision, oldRevisions)
@@ -226,6 +245,12 @@ func (dsc *ReconcileDaemonSet) rollingUpdate(ctx context.Context, ds *appsv1alph
 	}
 	newNodesToCreate := append(allowedNewNodes, candidateNewNodes[:remainingSurge]...)
 
+	// Advanced: wait for the nodes drained before the old pods are deleted
+	oldPodsToDelete, err = dsc.filterNodeDrainedPods(ctx, ds, oldPodsToDelete)
+	if err != nil {
+		return err
+	}
+
 	return dsc.syncNodes(ctx, ds, oldPodsToDelete, newNodesToCreate, hash)
 }
 
@@ -288,6 +313,10 @@ func (dsc *ReconcileDaemonSet) filterDaemonPodsToUpdate(ds *appsv1alpha1.DaemonS
 			delete(nodeToDaemonPods, nodeName)
 		}
 	}
+	if ds.Spec.UpdateStrategy.RollingUpdate != nil && len(ds.Spec.UpdateStrategy.RollingUpdate.Waves) > 0 {
+		// the same nodes as the wave status is calculated on in updateDaemonSetStatus
+		nodeToDaemonPods = getDesiredNodeToDaemonPods(ds, nodeList, nodeToDaemonPods)
+	}
 
 	nodeNames, err := dsc.filterDaemonPodsNodeToUpdate(ds, hash, nodeToDaemonPods)
 	if err != nil {
@@ -302,18 +331,29 @@ func (dsc *ReconcileDaemonSet) filterDaemonPodsToUpdate(ds *appsv1alpha1.DaemonS
 }
 
 func (dsc *ReconcileDaemonSet) filterDaemonPodsNodeToUpdate(ds *appsv1alpha1.DaemonSet, hash string, nodeToDaemonPods map[string][]*corev1.Pod) ([]string, error) {
-	var err error
+	if ds.Spec.UpdateStrategy.RollingUpdate != nil && len(ds.Spec.UpdateStrategy.RollingUpdate.Waves) > 0 {
+		return dsc.filterDaemonPodsNodeToUpdateInWave(ds, hash, nodeToDaemonPods, getCurrentUpdateWaveIndex(ds, hash))
+	}
+
 	var partition int32
-	var selector labels.Selector
+	var selectors []labels.Selector
 	if ds.Spec.UpdateStrategy.RollingUpdate != nil && ds.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
 		partition = *ds.Spec.UpdateStrategy.RollingUpdate.Partition
 	}
 	if ds.Spec.UpdateStrategy.RollingUpdate != nil && ds.Spec.UpdateStrategy.RollingUpdate.Selector != nil {
-		if selector, err = util.ValidatedLabelSelectorAsSelector(ds.Spec.UpdateStrategy.RollingUpdate.Selector); err != nil {
+		selector, err := util.ValidatedLabelSelectorAsSelector(ds.Spec.UpdateStrategy.RollingUpdate.Selector)
+		if err != nil {
 			return nil, err
 		}
+		selectors = append(selectors, selector)
 	}
+	return dsc.sortDaemonPodsNodeToUpdate(ds, hash, nodeToDaemonPods, selectors, len(nodeToDaemonPods)-int(partition))
+}
 
+// sortDaemonPodsNodeToUpdate returns at most maxUpdate nodes that can be updated, in the order of updated, updating
+// and the rest. If selectors are not empty, only the nodes matching any of them can be chosen from the rest.
+func (dsc *ReconcileDaemonSet) sortDaemonPodsNodeToUpdate(ds *appsv1alpha1.DaemonSet, hash string, nodeToDaemonPods map[string][]*corev1.Pod,
+	selectors []labels.Selector, maxUpdate int) ([]string, error) {
 	var allNodeNames []string
 	for nodeName := range nodeToDaemonPods {
 		allNodeNames = append(allNodeNames, nodeName)
@@ -337,27 +377,30 @@ func (dsc *ReconcileDaemonSet) filterDaemonPodsNodeToUpdate(ds *appsv1alpha1.Dae
 			continue
 		}
 
-		if selector != nil {
+		if len(selectors) > 0 {
 			node, err := dsc.nodeLister.Get(nodeName)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get node %v: %v", nodeName, err)
 			}
-			if selector.Matches(labels.Set(node.Labels)) {
-				selected = append(selected, nodeName)
-				continue
+			for _, selector := range selectors {
+				if selector.Matches(labels.Set(node.Labels)) {
+					selected = append(selected, nodeName)
+					break
+				}
 			}
+			continue
 		}
 
 		rest = append(rest, nodeName)
 	}
 
 	sorted := append(updated, updating...)
-	if selector != nil {
+	if len(selectors) > 0 {
 		sorted = append(sorted, selected...)
 	} else {
 		sorted = append(sorted, rest...)
 	}
-	if maxUpdate := len(allNodeNames) - int(partition); maxUpdate <= 0 {
+	if maxUpdate <= 0 {
 		return nil, nil
 	} else if maxUpdate < len(sorted) {
 		sorted = sorted[:maxUpdate]
@@ -385,6 +428,10 @@ func (dsc *ReconcileDaemonSet) canPodInPlaceUpdate(pod *corev1.Pod, curRevision
 	if oldRevision == nil {
 		return false
 	}
+	// Advanced: recreate the pods patched by node overrides, in case the patches conflict with the in-place update
+	if !dsc.canNodeOverridesInPlaceUpdate(pod, oldRevision, curRevision) {
+		return false
+	}
 	return dsc.inplaceControl.CanUpdateInPlace(oldRevision, curRevision, getInPlaceUpdateOptions())
 }
 
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package daemonset
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util"
+)
+
+// getCurrentUpdateWaveIndex returns the index of the wave that the rolling update to the revision hash is in.
+func getCurrentUpdateWaveIndex(ds *appsv1alpha1.DaemonSet, hash string) int {
+	waves := ds.Spec.UpdateStrategy.RollingUpdate.Waves
+	if ds.Status.CurrentWave == nil || ds.Status.CurrentWave.Revision != hash || ds.Status.CurrentWave.Index < 0 {
+		return 0
+	}
+	if index := int(ds.Status.CurrentWave.Index); index < len(waves) {
+		return index
+	}
+	return len(waves) - 1
+}
+
+// getDesiredNodeToDaemonPods returns the daemon pods of the nodes that should run the daemon pod. The update waves
+// are rolled and reported on these nodes, so that the status shows the wave that is actually being rolled.
+func getDesiredNodeToDaemonPods(ds *appsv1alpha1.DaemonSet, nodeList []*corev1.Node, nodeToDaemonPods map[string][]*corev1.Pod) map[string][]*corev1.Pod {
+	desiredNodeToDaemonPods := make(map[string][]*corev1.Pod, len(nodeList))
+	for _, node := range nodeList {
+		if shouldRun, _ := nodeShouldRunDaemonPod(node, ds); shouldRun {
+			desiredNodeToDaemonPods[node.Name] = nodeToDaemonPods[node.Name]
+		}
+	}
+	return desiredNodeToDaemonPods
+}
+
+// filterDaemonPodsNodeToUpdateInWave returns the nodes that can be updated when the rolling update reaches the wave,
+// the nodes of a wave are accumulated with all the waves before it.
+func (dsc *ReconcileDaemonSet) filterDaemonPodsNodeToUpdateInWave(ds *appsv1alpha1.DaemonSet, hash string, nodeToDaemonPods map[string][]*corev1.Pod, index int) ([]string, error) {
+	waves := ds.Spec.UpdateStrategy.RollingUpdate.Waves
+
+	var selectors []labels.Selector
+	for i := 0; i <= index; i++ {
+		if waves[i].NodeSelector == nil {
+			// all nodes can be updated since this wave
+			selectors = nil
+			break
+		}
+		selector, err := util.ValidatedLabelSelectorAsSelector(waves[i].NodeSelector)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+
+	maxUpdate := len(nodeToDaemonPods)
+	if waves[index].MaxUpdated != nil {
+		var err error
+		maxUpdate, err = intstrutil.GetScaledValueFromIntOrPercent(waves[index].MaxUpdated, len(nodeToDaemonPods), true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dsc.sortDaemonPodsNodeToUpdate(ds, hash, nodeToDaemonPods, selectors, maxUpdate)
+}
+
+// calculateUpdateWaveStatus calculates the current wave of the rolling update. It moves to the next wave once all
+// daemon pods in the current wave are updated and available, and the soak duration of the wave has passed.
+func (dsc *ReconcileDaemonSet) calculateUpdateWaveStatus(ds *appsv1alpha1.DaemonSet, hash string, nodeToDaemonPods map[string][]*corev1.Pod) (*appsv1alpha1.DaemonSetUpdateWaveStatus, error) {
+	if ds.Spec.UpdateStrategy.Type != appsv1alpha1.RollingUpdateDaemonSetStrategyType ||
+		ds.Spec.UpdateStrategy.RollingUpdate == nil || len(ds.Spec.UpdateStrategy.RollingUpdate.Waves) == 0 {
+		return nil, nil
+	}
+	waves := ds.Spec.UpdateStrategy.RollingUpdate.Waves
+
+	index := getCurrentUpdateWaveIndex(ds, hash)
+	status := &appsv1alpha1.DaemonSetUpdateWaveStatus{Revision: hash, Index: int32(index), Name: waves[index].Name}
+	if ds.Status.CurrentWave != nil && ds.Status.CurrentWave.Revision == hash && int(ds.Status.CurrentWave.Index) == index {
+		status.CompletedTime = ds.Status.CurrentWave.CompletedTime
+	}
+
+	nodeNames, err := dsc.filterDaemonPodsNodeToUpdateInWave(ds, hash, nodeToDaemonPods, index)
+	if err != nil {
+		return nil, err
+	}
+	now := dsc.failedPodsBackoff.Clock.Now()
+	for _, nodeName := range nodeNames {
+		newPod, oldPod, ok := findUpdatedPodsOnNode(ds, nodeToDaemonPods[nodeName], hash)
+		if !ok || newPod == nil || oldPod != nil || !isDaemonPodAvailable(newPod, ds.Spec.MinReadySeconds, metav1.Time{Time: now}) {
+			status.CompletedTime = nil
+			return status, nil
+		}
+	}
+
+	if status.CompletedTime == nil {
+		status.CompletedTime = &metav1.Time{Time: now}
+	}
+	if index >= len(waves)-1 {
+		return status, nil
+	}
+
+	soakDuration := time.Duration(waves[index].SoakSeconds) * time.Second
+	if remaining := status.CompletedTime.Add(soakDuration).Sub(now); remaining > 0 {
+		durationStore.Push(keyFunc(ds), remaining)
+		return status, nil
+	}
+
+	klog.V(3).Infof("DaemonSet %s/%s has completed update wave %s, moving to the next wave %s",
+		ds.Namespace, ds.Name, waves[index].Name, waves[index+1].Name)
+	return &appsv1alpha1.DaemonSetUpdateWaveStatus{Revision: hash, Index: int32(index + 1), Name: waves[index+1].Name}, nil
+}
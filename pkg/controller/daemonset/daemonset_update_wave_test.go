@@ -0,0 +1,219 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package daemonset
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/flowcontrol"
+	testingclock "k8s.io/utils/clock/testing"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func newWaveTestPod(hash string, ready bool) *corev1.Pod {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{apps.DefaultDaemonSetUniqueLabelKey: hash}}}
+	if ready {
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	}
+	return pod
+}
+
+func TestCalculateUpdateWaveStatus(t *testing.T) {
+	now := time.Unix(1000, 0)
+	half := intstr.FromString("50%")
+	waves := []appsv1alpha1.DaemonSetUpdateWave{
+		{Name: "canary", NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"node-type": "canary"}}, SoakSeconds: 60},
+		{Name: "half", MaxUpdated: &half},
+		{Name: "all"},
+	}
+	nodes := []*corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "n1", Labels: map[string]string{"node-type": "canary"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "n2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "n3"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "n4"}},
+	}
+
+	tests := []struct {
+		name             string
+		currentWave      *appsv1alpha1.DaemonSetUpdateWaveStatus
+		nodeToDaemonPods map[string][]*corev1.Pod
+		expectNodes      []string
+		expectWave       *appsv1alpha1.DaemonSetUpdateWaveStatus
+	}{
+		{
+			name: "start the first wave",
+			nodeToDaemonPods: map[string][]*corev1.Pod{
+				"n1": {newWaveTestPod("v1", true)},
+				"n2": {newWaveTestPod("v1", true)},
+				"n3": {newWaveTestPod("v1", true)},
+				"n4": {newWaveTestPod("v1", true)},
+			},
+			expectNodes: []string{"n1"},
+			expectWave:  &appsv1alpha1.DaemonSetUpdateWaveStatus{Revision: "v2", Index: 0, Name: "canary"},
+		},
+		{
+			name:        "restart waves for a new revision",
+			currentWave: &appsv1alpha1.DaemonSetUpdateWaveStatus{Revision: "v0", Index: 2, Name: "all"},
+			nodeToDaemonPods: map[string][]*corev1.Pod{
+				"n1": {newWaveTestPod("v1", true)},
+				"n2": {newWaveTestPod("v1", true)},
+				"n3": {newWaveTestPod("v1", true)},
+				"n4": {newWaveTestPod("v1", true)},
+			},
+			expectNodes: []string{"n1"},
+			expectWave:  &appsv1alpha1.DaemonSetUpdateWaveStatus{Revision: "v2", Index: 0, Name: "canary"},
+		},
+		{
+			name:        "first wave completed and soaking",
+			currentWave: &appsv1alpha1.DaemonSetUpdateWaveStatus{Revision: "v2", Index: 0, Name: "canary"},
+			nodeToDaemonPods: map[string][]*corev1.Pod{
+				"n1": {newWaveTestPod("v2", true)},
+				"n2": {newWaveTestPod("v1", true)},
+				"n3": {newWaveTestPod("v1", true)},
+				"n4": {newWaveTestPod("v1", true)},
+			},
+			expectNodes: []string{"n1"},
+			expectWave:  &appsv1alpha1.DaemonSetUpdateWaveStatus{Revision: "v2", Index: 0, Name: "canary", CompletedTime: &metav1.Time{Time: now}},
+		},
+		{
+			name: "first wave soaked",
+			currentWave: &appsv1alpha1.DaemonSetUpdateWaveStatus{Revision: "v2", Index: 0, Name: "canary",
+				CompletedTime: &metav1.Time{Time: now.Add(-time.Minute)}},
+			nodeToDaemonPods: map[string][]*corev1.Pod{
+				"n1": {newWaveTestPod("v2", true)},
+				"n2": {newWaveTestPod("v1", true)},
+				"n3": {newWaveTestPod("v1", true)},
+				"n4": {newWaveTestPod("v1", true)},
+			},
+			expectNodes: []string{"n1"},
+			expectWave:  &appsv1alpha1.DaemonSetUpdateWaveStatus{Revision: "v2", Index: 1, Name: "half"},
+		},
+		{
+			name:        "second wave in progress",
+			currentWave: &appsv1alpha1.DaemonSetUpdateWaveStatus{Revision: "v2", Index: 1, Name: "half"},
+			nodeToDaemonPods: map[string][]*corev1.Pod{
+				"n1": {newWaveTestPod("v2", true)},
+				"n2": {newWaveTestPod("v1", true)},
+				"n3": {newWaveTestPod("v1", true)},
+				"n4": {newWaveTestPod("v2", false)},
+			},
+			expectNodes: []string{"n4", "n1"},
+			expectWave:  &appsv1alpha1.DaemonSetUpdateWaveStatus{Revision: "v2", Index: 1, Name: "half"},
+		},
+		{
+			name:        "second wave completed without soak",
+			currentWave: &appsv1alpha1.DaemonSetUpdateWaveStatus{Revision: "v2", Index: 1, Name: "half"},
+			nodeToDaemonPods: map[string][]*corev1.Pod{
+				"n1": {newWaveTestPod("v2", true)},
+				"n2": {newWaveTestPod("v1", true)},
+				"n3": {newWaveTestPod("v1", true)},
+				"n4": {newWaveTestPod("v2", true)},
+			},
+			expectNodes: []string{"n4", "n1"},
+			expectWave:  &appsv1alpha1.DaemonSetUpdateWaveStatus{Revision: "v2", Index: 2, Name: "all"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			for _, node := range nodes {
+				if err := indexer.Add(node); err != nil {
+					t.Fatalf("failed to add node into indexer: %v", err)
+				}
+			}
+			dsc := &ReconcileDaemonSet{
+				nodeLister:        corelisters.NewNodeLister(indexer),
+				failedPodsBackoff: flowcontrol.NewFakeBackOff(time.Second, time.Minute, testingclock.NewFakeClock(now)),
+			}
+			ds := &appsv1alpha1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "ds"},
+				Spec: appsv1alpha1.DaemonSetSpec{UpdateStrategy: appsv1alpha1.DaemonSetUpdateStrategy{
+					Type:          appsv1alpha1.RollingUpdateDaemonSetStrategyType,
+					RollingUpdate: &appsv1alpha1.RollingUpdateDaemonSet{Waves: waves},
+				}},
+				Status: appsv1alpha1.DaemonSetStatus{CurrentWave: test.currentWave},
+			}
+
+			gotNodes, err := dsc.filterDaemonPodsNodeToUpdate(ds, "v2", test.nodeToDaemonPods)
+			if err != nil {
+				t.Fatalf("failed to call filterDaemonPodsNodeToUpdate: %v", err)
+			}
+			if !reflect.DeepEqual(gotNodes, test.expectNodes) {
+				t.Fatalf("expected nodes %v, got %v", test.expectNodes, gotNodes)
+			}
+
+			gotWave, err := dsc.calculateUpdateWaveStatus(ds, "v2", test.nodeToDaemonPods)
+			if err != nil {
+				t.Fatalf("failed to call calculateUpdateWaveStatus: %v", err)
+			}
+			if !reflect.DeepEqual(gotWave, test.expectWave) {
+				t.Fatalf("expected wave %+v, got %+v", test.expectWave, gotWave)
+			}
+		})
+	}
+}
+
+func TestFilterDaemonPodsToUpdateInWaveOnDesiredNodes(t *testing.T) {
+	half := intstr.FromString("50%")
+	ds := &appsv1alpha1.DaemonSet{
+		// a unique UID keeps the template pod out of the NewPod cache shared with other tests.
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "ds", UID: uuid.NewUUID()},
+		Spec: appsv1alpha1.DaemonSetSpec{
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{NodeSelector: map[string]string{"run": "true"}}},
+			UpdateStrategy: appsv1alpha1.DaemonSetUpdateStrategy{
+				Type:          appsv1alpha1.RollingUpdateDaemonSetStrategyType,
+				RollingUpdate: &appsv1alpha1.RollingUpdateDaemonSet{Waves: []appsv1alpha1.DaemonSetUpdateWave{{Name: "half", MaxUpdated: &half}}},
+			},
+		},
+	}
+	var nodes []*corev1.Node
+	nodeToDaemonPods := map[string][]*corev1.Pod{}
+	for i := 1; i <= 5; i++ {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("n%d", i), Labels: map[string]string{"run": "true"}}}
+		// the pod on n5 is misscheduled, so n5 is not counted in the waves.
+		if i == 5 {
+			node.Labels = nil
+		}
+		nodes = append(nodes, node)
+		nodeToDaemonPods[node.Name] = []*corev1.Pod{newWaveTestPod("v1", true)}
+	}
+
+	dsc := &ReconcileDaemonSet{}
+	desiredNodes := getDesiredNodeToDaemonPods(ds, nodes, nodeToDaemonPods)
+	if len(desiredNodes) != 4 {
+		t.Fatalf("expected 4 desired nodes, got %v", desiredNodes)
+	}
+	got, err := dsc.filterDaemonPodsToUpdate(ds, nodes, "v2", nodeToDaemonPods)
+	if err != nil {
+		t.Fatalf("failed to call filterDaemonPodsToUpdate: %v", err)
+	}
+	if _, ok := got["n5"]; ok || len(got) != 2 {
+		t.Fatalf("expected 50%% of the 4 desired nodes to update, got %v", got)
+	}
+}
@@ -20,9 +20,11 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"sync/atomic"
 	"time"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
 	clonesetutils "github.com/openkruise/kruise/pkg/controller/cloneset/utils"
 	"github.com/openkruise/kruise/pkg/controller/ephemeraljob/econtainer"
 	"github.com/openkruise/kruise/pkg/util"
@@ -223,8 +225,12 @@ func (r *ReconcileEphemeralJob) Reconcile(context context.Context, request recon
 		return reconcile.Result{RequeueAfter: requeueAfter}, r.updateJobStatus(job)
 	}
 
-	if err := r.syncTargetPods(job, targetPods); err != nil {
+	deniedByPub, err := r.syncTargetPods(job, targetPods)
+	if err != nil {
 		return reconcile.Result{RequeueAfter: requeueAfter}, err
+	} else if deniedByPub && (requeueAfter == 0 || requeueAfter > time.Second) {
+		// pub check does not pass, try again in seconds
+		requeueAfter = time.Second
 	}
 
 	return reconcile.Result{RequeueAfter: requeueAfter}, r.updateJobStatus(job)
@@ -309,11 +315,13 @@ func (r *ReconcileEphemeralJob) filterInjectedPods(job *appsv1alpha1.EphemeralJo
 	return targetPods, nil
 }
 
-func (r *ReconcileEphemeralJob) syncTargetPods(job *appsv1alpha1.EphemeralJob, targetPods []*v1.Pod) error {
+// syncTargetPods creates ephemeral containers in the target pods, deniedByPub indicates that
+// some of the pods are skipped because the budget of PodUnavailableBudget is exhausted.
+func (r *ReconcileEphemeralJob) syncTargetPods(job *appsv1alpha1.EphemeralJob, targetPods []*v1.Pod) (deniedByPub bool, err error) {
 	toCreatePods, _, _ := getSyncPods(job, targetPods)
 	if len(toCreatePods) == 0 {
 		klog.Infoln("there is no target pod to attach")
-		return nil
+		return false, nil
 	}
 
 	klog.Infof("Ready to create ephemeral containers in %d pods.", len(toCreatePods))
@@ -329,7 +337,7 @@ func (r *ReconcileEphemeralJob) syncTargetPods(job *appsv1alpha1.EphemeralJob, t
 	diff := parallelism - int(job.Status.Running)
 	if diff < 0 {
 		klog.Infof("error sync phemeraljob %s/%s for parallisem %d less than running pod %d", job.Namespace, job.Name, parallelism, job.Status.Running)
-		return nil
+		return false, nil
 	}
 
 	toCreatePods = toCreatePods[:diff]
@@ -341,13 +349,24 @@ func (r *ReconcileEphemeralJob) syncTargetPods(job *appsv1alpha1.EphemeralJob, t
 
 	control := econtainer.New(job)
 	key := types.NamespacedName{Namespace: job.Namespace, Name: job.Name}.String()
-	_, err := clonesetutils.DoItSlowly(len(toCreatePods), kubecontroller.SlowStartInitialBatchSize, func() error {
+	var denied int32
+	_, err = clonesetutils.DoItSlowly(len(toCreatePods), kubecontroller.SlowStartInitialBatchSize, func() error {
 		pod := <-podsCreationChan
 
 		if exists, _ := control.ContainsEphemeralContainer(pod); exists {
 			return nil
 		}
 
+		// Determine the pub before creating ephemeral containers in the pod
+		allowed, reason, err := pubcontrol.AcquirePodDisruption(pod, "kruise-manager", false)
+		if err != nil {
+			return err
+		} else if !allowed {
+			klog.V(3).Infof("Skip creating ephemeral container in pod %s/%s by pub: %s", pod.Namespace, pod.Name, reason)
+			atomic.StoreInt32(&denied, 1)
+			return nil
+		}
+
 		klog.Infof("Creating ephemeral container in pod %s/%s", pod.Namespace, pod.Name)
 
 		for _, podEphemeralContainerName := range getPodEphemeralContainers(pod, job) {
@@ -366,7 +385,7 @@ func (r *ReconcileEphemeralJob) syncTargetPods(job *appsv1alpha1.EphemeralJob, t
 		r.recorder.Eventf(job, v1.EventTypeWarning, "CreateFailed", err.Error())
 	}
 
-	return err
+	return atomic.LoadInt32(&denied) > 0, err
 }
 
 func (r *ReconcileEphemeralJob) calculateStatus(job *appsv1alpha1.EphemeralJob, targetPods []*v1.Pod) error {
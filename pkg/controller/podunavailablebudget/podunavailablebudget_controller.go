@@ -264,7 +264,9 @@ func (r *ReconcilePodUnavailableBudget) syncPodUnavailableBudget(pub *policyv1al
 	}
 
 	klog.V(3).Infof("pub(%s/%s) controller pods(%d) expectedCount(%d)", pub.Namespace, pub.Name, len(pods), expectedCount)
-	desiredAvailable, err := r.getDesiredAvailableForPub(pub, expectedCount)
+	// the budget of active schedule takes the place of spec
+	activeSchedule, scheduleRecheckTime := getActivePubSchedule(pub, currentTime)
+	desiredAvailable, err := r.getDesiredAvailableForPub(pub, activeSchedule, expectedCount)
 	if err != nil {
 		r.recorder.Eventf(pub, corev1.EventTypeWarning, "CalculateExpectedPodCountFailed", "Failed to calculate the number of expected pods: %v", err)
 		return nil, err
@@ -318,9 +320,18 @@ func (r *ReconcilePodUnavailableBudget) syncPodUnavailableBudget(pub *policyv1al
 		var disruptedPods, unavailablePods map[string]metav1.Time
 		disruptedPods, unavailablePods, recheckTime = r.buildDisruptedAndUnavailablePods(pods, pubClone, currentTime)
 		currentAvailable := countAvailablePods(pods, disruptedPods, unavailablePods)
+		topologyUnavailableAllowed, err := r.getTopologyUnavailableAllowed(pubClone, pods, disruptedPods, unavailablePods)
+		if err != nil {
+			return err
+		}
 
 		start = time.Now()
-		updateErr := r.updatePubStatus(pubClone, currentAvailable, desiredAvailable, expectedCount, disruptedPods, unavailablePods)
+		var activeScheduleName string
+		if activeSchedule != nil {
+			activeScheduleName = activeSchedule.Name
+		}
+		updateErr := r.updatePubStatus(pubClone, currentAvailable, desiredAvailable, expectedCount, disruptedPods, unavailablePods,
+			topologyUnavailableAllowed, activeScheduleName)
 		costOfUpdate += time.Since(start)
 		if updateErr == nil {
 			return nil
@@ -335,6 +346,9 @@ func (r *ReconcilePodUnavailableBudget) syncPodUnavailableBudget(pub *policyv1al
 	if err != nil {
 		klog.Errorf("update pub(%s/%s) status failed: %s", pub.Namespace, pub.Name, err.Error())
 	}
+	if scheduleRecheckTime != nil && (recheckTime == nil || scheduleRecheckTime.Before(*recheckTime)) {
+		recheckTime = scheduleRecheckTime
+	}
 	return recheckTime, err
 }
 
@@ -385,10 +399,59 @@ func countAvailablePods(pods []*corev1.Pod, disruptedPods, unavailablePods map[s
 	return
 }
 
-func (r *ReconcilePodUnavailableBudget) getDesiredAvailableForPub(pub *policyv1alpha1.PodUnavailableBudget, expectedCount int32) (desiredAvailable int32, err error) {
-	if pub.Spec.MaxUnavailable != nil {
+// getTopologyUnavailableAllowed returns the number of pod unavailable that are allowed in each topology domain,
+// which is the maxUnavailable of the domain minus the active pods that are unavailable in the domain.
+func (r *ReconcilePodUnavailableBudget) getTopologyUnavailableAllowed(pub *policyv1alpha1.PodUnavailableBudget, pods []*corev1.Pod,
+	disruptedPods, unavailablePods map[string]metav1.Time) (map[string]int32, error) {
+	if pub.Spec.TopologyBudget == nil {
+		return nil, nil
+	}
+
+	totalPods := map[string]int32{}
+	availablePods := map[string]int32{}
+	for _, pod := range pods {
+		if !kubecontroller.IsPodActive(pod) {
+			continue
+		}
+		domain, err := pubcontrol.GetPodTopologyDomain(r, pod, pub)
+		if err != nil {
+			return nil, err
+		}
+		if domain == "" {
+			continue
+		}
+		totalPods[domain]++
+		_, disrupted := disruptedPods[pod.Name]
+		_, unavailable := unavailablePods[pod.Name]
+		if !disrupted && !unavailable && pubcontrol.PubControl.IsPodStateConsistent(pod) && pubcontrol.PubControl.IsPodReady(pod) {
+			availablePods[domain]++
+		}
+	}
+
+	topologyUnavailableAllowed := make(map[string]int32, len(totalPods))
+	for domain, total := range totalPods {
+		maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(pub.Spec.TopologyBudget.MaxUnavailable, int(total), true)
+		if err != nil {
+			return nil, err
+		}
+		allowed := int32(maxUnavailable) - (total - availablePods[domain])
+		if allowed < 0 {
+			allowed = 0
+		}
+		topologyUnavailableAllowed[domain] = allowed
+	}
+	return topologyUnavailableAllowed, nil
+}
+
+func (r *ReconcilePodUnavailableBudget) getDesiredAvailableForPub(pub *policyv1alpha1.PodUnavailableBudget, activeSchedule *policyv1alpha1.PubSchedule,
+	expectedCount int32) (desiredAvailable int32, err error) {
+	maxUnavailableBudget, minAvailableBudget := pub.Spec.MaxUnavailable, pub.Spec.MinAvailable
+	if activeSchedule != nil {
+		maxUnavailableBudget, minAvailableBudget = activeSchedule.MaxUnavailable, activeSchedule.MinAvailable
+	}
+	if maxUnavailableBudget != nil {
 		var maxUnavailable int
-		maxUnavailable, err = intstr.GetScaledValueFromIntOrPercent(pub.Spec.MaxUnavailable, int(expectedCount), true)
+		maxUnavailable, err = intstr.GetScaledValueFromIntOrPercent(maxUnavailableBudget, int(expectedCount), true)
 		if err != nil {
 			return
 		}
@@ -397,12 +460,12 @@ func (r *ReconcilePodUnavailableBudget) getDesiredAvailableForPub(pub *policyv1a
 		if desiredAvailable < 0 {
 			desiredAvailable = 0
 		}
-	} else if pub.Spec.MinAvailable != nil {
-		if pub.Spec.MinAvailable.Type == intstr.Int {
-			desiredAvailable = pub.Spec.MinAvailable.IntVal
-		} else if pub.Spec.MinAvailable.Type == intstr.String {
+	} else if minAvailableBudget != nil {
+		if minAvailableBudget.Type == intstr.Int {
+			desiredAvailable = minAvailableBudget.IntVal
+		} else if minAvailableBudget.Type == intstr.String {
 			var minAvailable int
-			minAvailable, err = intstr.GetScaledValueFromIntOrPercent(pub.Spec.MinAvailable, int(expectedCount), true)
+			minAvailable, err = intstr.GetScaledValueFromIntOrPercent(minAvailableBudget, int(expectedCount), true)
 			if err != nil {
 				return
 			}
@@ -466,7 +529,7 @@ func (r *ReconcilePodUnavailableBudget) buildDisruptedAndUnavailablePods(pods []
 }
 
 func (r *ReconcilePodUnavailableBudget) updatePubStatus(pub *policyv1alpha1.PodUnavailableBudget, currentAvailable, desiredAvailable, expectedCount int32,
-	disruptedPods, unavailablePods map[string]metav1.Time) error {
+	disruptedPods, unavailablePods map[string]metav1.Time, topologyUnavailableAllowed map[string]int32, activeSchedule string) error {
 
 	unavailableAllowed := currentAvailable - desiredAvailable
 	if unavailableAllowed <= 0 {
@@ -479,18 +542,22 @@ func (r *ReconcilePodUnavailableBudget) updatePubStatus(pub *policyv1alpha1.PodU
 		pub.Status.UnavailableAllowed == unavailableAllowed &&
 		pub.Status.ObservedGeneration == pub.Generation &&
 		apiequality.Semantic.DeepEqual(pub.Status.DisruptedPods, disruptedPods) &&
-		apiequality.Semantic.DeepEqual(pub.Status.UnavailablePods, unavailablePods) {
+		apiequality.Semantic.DeepEqual(pub.Status.UnavailablePods, unavailablePods) &&
+		apiequality.Semantic.DeepEqual(pub.Status.TopologyUnavailableAllowed, topologyUnavailableAllowed) &&
+		pub.Status.ActiveSchedule == activeSchedule {
 		return nil
 	}
 
 	pub.Status = policyv1alpha1.PodUnavailableBudgetStatus{
-		CurrentAvailable:   currentAvailable,
-		DesiredAvailable:   desiredAvailable,
-		TotalReplicas:      expectedCount,
-		UnavailableAllowed: unavailableAllowed,
-		DisruptedPods:      disruptedPods,
-		UnavailablePods:    unavailablePods,
-		ObservedGeneration: pub.Generation,
+		CurrentAvailable:           currentAvailable,
+		DesiredAvailable:           desiredAvailable,
+		TotalReplicas:              expectedCount,
+		UnavailableAllowed:         unavailableAllowed,
+		DisruptedPods:              disruptedPods,
+		UnavailablePods:            unavailablePods,
+		ObservedGeneration:         pub.Generation,
+		TopologyUnavailableAllowed: topologyUnavailableAllowed,
+		ActiveSchedule:             activeSchedule,
 	}
 	err := r.Client.Status().Update(context.TODO(), pub)
 	if err != nil {
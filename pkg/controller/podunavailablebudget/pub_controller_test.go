@@ -1207,7 +1207,7 @@ func TestDesiredAvailableForPub(t *testing.T) {
 	rec := ReconcilePodUnavailableBudget{}
 	for _, cs := range cases {
 		t.Run(cs.name, func(t *testing.T) {
-			expect, _ := rec.getDesiredAvailableForPub(cs.getPub(), cs.totalReplicas)
+			expect, _ := rec.getDesiredAvailableForPub(cs.getPub(), nil, cs.totalReplicas)
 			if expect != cs.desiredAvailable {
 				t.Fatalf("expect %d, but get %d", cs.desiredAvailable, expect)
 			}
@@ -1215,6 +1215,60 @@ func TestDesiredAvailableForPub(t *testing.T) {
 	}
 }
 
+func TestTopologyUnavailableAllowedForPub(t *testing.T) {
+	// zone-a has 3 pods, one of them is not ready; zone-b has 2 pods, one of them is disrupted;
+	// zone-c has 1 pod; and the pod on the node without zone label is ignored.
+	nodes := []client.Object{
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a", Labels: map[string]string{"zone": "zone-a"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b", Labels: map[string]string{"zone": "zone-b"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-c", Labels: map[string]string{"zone": "zone-c"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-no-zone"}},
+	}
+	var pods []*corev1.Pod
+	for i, nodeName := range []string{"node-a", "node-a", "node-a", "node-b", "node-b", "node-c", "node-no-zone"} {
+		pod := podDemo.DeepCopy()
+		pod.Name = fmt.Sprintf("test-pod-%d", i)
+		pod.Spec.NodeName = nodeName
+		pods = append(pods, pod)
+	}
+	podutil.GetPodReadyCondition(pods[2].Status).Status = corev1.ConditionFalse
+	disruptedPods := map[string]metav1.Time{pods[4].Name: metav1.Now()}
+
+	cases := []struct {
+		name           string
+		maxUnavailable intstr.IntOrString
+		expectAllowed  map[string]int32
+	}{
+		{
+			name:           "maxUnavailable 1 in each zone",
+			maxUnavailable: intstr.FromInt(1),
+			expectAllowed:  map[string]int32{"zone-a": 0, "zone-b": 0, "zone-c": 1},
+		},
+		{
+			name:           "maxUnavailable 50% in each zone",
+			maxUnavailable: intstr.FromString("50%"),
+			expectAllowed:  map[string]int32{"zone-a": 1, "zone-b": 0, "zone-c": 1},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			pub := pubDemo.DeepCopy()
+			pub.Spec.TopologyBudget = &policyv1alpha1.PubTopologyBudget{TopologyKey: "zone", MaxUnavailable: &cs.maxUnavailable}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pub).WithObjects(nodes...).Build()
+			pubcontrol.InitPubControl(fakeClient, &controllerfinder.ControllerFinder{Client: fakeClient}, record.NewFakeRecorder(10))
+			rec := ReconcilePodUnavailableBudget{Client: fakeClient}
+			allowed, err := rec.getTopologyUnavailableAllowed(pub, pods, disruptedPods, nil)
+			if err != nil {
+				t.Fatalf("getTopologyUnavailableAllowed failed: %s", err.Error())
+			}
+			if !reflect.DeepEqual(allowed, cs.expectAllowed) {
+				t.Fatalf("expect %v, but get %v", cs.expectAllowed, allowed)
+			}
+		})
+	}
+}
+
 func getLatestPub(client client.Client, pub *policyv1alpha1.PodUnavailableBudget) (*policyv1alpha1.PodUnavailableBudget, error) {
 	newPub := &policyv1alpha1.PodUnavailableBudget{}
 	key := types.NamespacedName{
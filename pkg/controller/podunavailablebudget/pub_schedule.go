@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podunavailablebudget
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/klog/v2"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+)
+
+// ParsePubSchedule parses the cron schedule of PubSchedule with its time zone.
+func ParsePubSchedule(schedule *policyv1alpha1.PubSchedule) (cron.Schedule, error) {
+	if schedule.TimeZone != nil {
+		return cron.ParseStandard(fmt.Sprintf("TZ=%s %s", *schedule.TimeZone, schedule.Schedule))
+	}
+	return cron.ParseStandard(schedule.Schedule)
+}
+
+// getActivePubSchedule returns the first schedule of pub whose window contains the current time,
+// and the earliest time at which any window of pub starts or ends, when the pub should be synced again.
+func getActivePubSchedule(pub *policyv1alpha1.PodUnavailableBudget, currentTime time.Time) (*policyv1alpha1.PubSchedule, *time.Time) {
+	var active *policyv1alpha1.PubSchedule
+	var recheckTime *time.Time
+	record := func(t time.Time) {
+		if !t.IsZero() && (recheckTime == nil || t.Before(*recheckTime)) {
+			recheckTime = &t
+		}
+	}
+	for i := range pub.Spec.Schedules {
+		schedule := &pub.Spec.Schedules[i]
+		sched, err := ParsePubSchedule(schedule)
+		if err != nil || schedule.Duration.Duration <= 0 {
+			klog.Warningf("pub(%s/%s) ignored invalid schedule %s: %v", pub.Namespace, pub.Name, schedule.Name, err)
+			continue
+		}
+		if windowEnd := getScheduleWindowEnd(sched, schedule.Duration.Duration, currentTime); windowEnd != nil {
+			if active == nil {
+				active = schedule
+			}
+			record(*windowEnd)
+		}
+		record(sched.Next(currentTime))
+	}
+	return active, recheckTime
+}
+
+// getScheduleWindowEnd returns the end time of the window which contains the current time,
+// nil means the current time is not in any window of the schedule.
+func getScheduleWindowEnd(sched cron.Schedule, duration time.Duration, currentTime time.Time) *time.Time {
+	// the latest start time of the window must be in (currentTime - duration, currentTime], it is searched
+	// backwards from the current time with doubled steps, so that a frequent schedule with a long duration
+	// is not stepped through tick by tick.
+	var start time.Time
+	for step := time.Second; start.IsZero(); step *= 2 {
+		if step > duration {
+			step = duration
+		}
+		if next := sched.Next(currentTime.Add(-step)); !next.IsZero() && !next.After(currentTime) {
+			start = next
+		} else if step == duration {
+			return nil
+		}
+	}
+	// there is no tick in the latter half of the last step, so only a few ticks are left before the current time.
+	for next := sched.Next(start); !next.IsZero() && !next.After(currentTime); next = sched.Next(next) {
+		start = next
+	}
+	end := start.Add(duration)
+	return &end
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podunavailablebudget
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	utilpointer "k8s.io/utils/pointer"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+)
+
+func TestGetActivePubSchedule(t *testing.T) {
+	pub := pubDemo.DeepCopy()
+	pub.Spec.MaxUnavailable = &intstr.IntOrString{Type: intstr.String, StrVal: "30%"}
+	pub.Spec.Schedules = []policyv1alpha1.PubSchedule{
+		{
+			Name:           "business-hours",
+			Schedule:       "0 9 * * *",
+			Duration:       metav1.Duration{Duration: 8 * time.Hour},
+			TimeZone:       utilpointer.String("UTC"),
+			MaxUnavailable: &intstr.IntOrString{Type: intstr.String, StrVal: "10%"},
+		},
+		{
+			Name:           "night",
+			Schedule:       "0 22 * * *",
+			Duration:       metav1.Duration{Duration: 6 * time.Hour},
+			TimeZone:       utilpointer.String("UTC"),
+			MaxUnavailable: &intstr.IntOrString{Type: intstr.String, StrVal: "50%"},
+		},
+	}
+	date := func(day, hour int) time.Time {
+		return time.Date(2024, 1, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		name                   string
+		currentTime            time.Time
+		expectActive           string
+		expectRecheckTime      time.Time
+		expectDesiredAvailable int32
+	}{
+		{
+			name:                   "in business hours",
+			currentTime:            date(2, 10),
+			expectActive:           "business-hours",
+			expectRecheckTime:      date(2, 17),
+			expectDesiredAvailable: 90,
+		},
+		{
+			name:                   "start of business hours",
+			currentTime:            date(2, 9),
+			expectActive:           "business-hours",
+			expectRecheckTime:      date(2, 17),
+			expectDesiredAvailable: 90,
+		},
+		{
+			name:                   "end of business hours",
+			currentTime:            date(2, 17),
+			expectRecheckTime:      date(2, 22),
+			expectDesiredAvailable: 70,
+		},
+		{
+			name:                   "in night window of the previous day",
+			currentTime:            date(2, 2),
+			expectActive:           "night",
+			expectRecheckTime:      date(2, 4),
+			expectDesiredAvailable: 50,
+		},
+		{
+			name:                   "no window is active",
+			currentTime:            date(2, 5),
+			expectRecheckTime:      date(2, 9),
+			expectDesiredAvailable: 70,
+		},
+	}
+
+	r := ReconcilePodUnavailableBudget{recorder: record.NewFakeRecorder(10)}
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			active, recheckTime := getActivePubSchedule(pub, cs.currentTime)
+			var activeName string
+			if active != nil {
+				activeName = active.Name
+			}
+			if activeName != cs.expectActive {
+				t.Fatalf("expect active schedule %q, but got %q", cs.expectActive, activeName)
+			}
+			if recheckTime == nil || !recheckTime.Equal(cs.expectRecheckTime) {
+				t.Fatalf("expect recheck time %v, but got %v", cs.expectRecheckTime, recheckTime)
+			}
+			desiredAvailable, err := r.getDesiredAvailableForPub(pub, active, 100)
+			if err != nil {
+				t.Fatalf("getDesiredAvailableForPub failed: %s", err.Error())
+			}
+			if desiredAvailable != cs.expectDesiredAvailable {
+				t.Fatalf("expect desiredAvailable %d, but got %d", cs.expectDesiredAvailable, desiredAvailable)
+			}
+		})
+	}
+}
+
+func TestGetScheduleWindowEnd(t *testing.T) {
+	currentTime := time.Date(2024, 1, 10, 12, 30, 30, 0, time.UTC)
+	cases := []struct {
+		name      string
+		schedule  string
+		duration  time.Duration
+		expectEnd time.Time
+	}{
+		{
+			name:      "every minute with a multi-day duration",
+			schedule:  "* * * * *",
+			duration:  72 * time.Hour,
+			expectEnd: time.Date(2024, 1, 13, 12, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "daily window contains the current time",
+			schedule:  "0 9 * * *",
+			duration:  8 * time.Hour,
+			expectEnd: time.Date(2024, 1, 10, 17, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "daily window has ended",
+			schedule: "0 9 * * *",
+			duration: 3 * time.Hour,
+		},
+		{
+			name:     "window has not started",
+			schedule: "0 13 * * *",
+			duration: 30 * time.Minute,
+		},
+	}
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			sched, err := ParsePubSchedule(&policyv1alpha1.PubSchedule{Schedule: cs.schedule, TimeZone: utilpointer.String("UTC")})
+			if err != nil {
+				t.Fatalf("parse schedule failed: %s", err.Error())
+			}
+			end := getScheduleWindowEnd(sched, cs.duration, currentTime)
+			if (end == nil) != cs.expectEnd.IsZero() || (end != nil && !end.Equal(cs.expectEnd)) {
+				t.Fatalf("expect window end %v, but got %v", cs.expectEnd, end)
+			}
+		})
+	}
+}
@@ -26,6 +26,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
 	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
 	controlutil "github.com/openkruise/kruise/pkg/controller/util"
 	"github.com/openkruise/kruise/pkg/util"
@@ -149,20 +150,26 @@ func (p *Processor) UpdateSidecarSet(sidecarSet *appsv1alpha1.SidecarSet) (recon
 	}
 
 	// 7. upgrade pod sidecar
-	if err := p.updatePods(control, pods); err != nil {
+	deniedByPub, err := p.updatePods(control, pods)
+	if err != nil {
 		return reconcile.Result{}, err
+	} else if deniedByPub {
+		// pub check does not pass, try again in seconds
+		return reconcile.Result{RequeueAfter: time.Second}, nil
 	}
 	return reconcile.Result{}, nil
 }
 
-func (p *Processor) updatePods(control sidecarcontrol.SidecarControl, pods []*corev1.Pod) error {
+// updatePods upgrades the sidecar containers of the next batch of pods, deniedByPub indicates that
+// some of the pods are not updated because the budget of PodUnavailableBudget is exhausted.
+func (p *Processor) updatePods(control sidecarcontrol.SidecarControl, pods []*corev1.Pod) (deniedByPub bool, err error) {
 	sidecarset := control.GetSidecarset()
 	// compute next updated pods based on the sidecarset upgrade strategy
 	upgradePods, notUpgradablePods := NewStrategy().GetNextUpgradePods(control, pods)
 	for _, pod := range notUpgradablePods {
 		if err := p.updatePodSidecarSetUpgradableCondition(sidecarset, pod, false); err != nil {
 			klog.Errorf("update NotUpgradable PodCondition error, s:%s, pod:%s, err:%v", sidecarset.Name, pod.Name, err)
-			return err
+			return false, err
 		}
 		// Since the pod sidecarSet hash is not updated here, it cannot be called ExpectUpdated
 		// TODO: add ResourceVersionExpectation instead of UpdateExpectations
@@ -173,22 +180,30 @@ func (p *Processor) updatePods(control sidecarcontrol.SidecarControl, pods []*co
 
 	if len(upgradePods) == 0 {
 		klog.V(3).Infof("sidecarSet next update is nil, skip this round, name: %s", sidecarset.Name)
-		return nil
+		return false, nil
 	}
 	// mark upgrade pods list
 	podNames := make([]string, 0, len(upgradePods))
 	// upgrade pod sidecar
 	for _, pod := range upgradePods {
+		// Determine the pub before updating the pod
+		allowed, _, err := pubcontrol.AcquirePodDisruption(pod, "kruise-manager", false)
+		if err != nil {
+			return false, err
+		} else if !allowed {
+			deniedByPub = true
+			continue
+		}
 		podNames = append(podNames, pod.Name)
 		if err := p.updatePodSidecarAndHash(control, pod); err != nil {
 			klog.Errorf("updatePodSidecarAndHash error, s:%s, pod:%s, err:%v", sidecarset.Name, pod.Name, err)
-			return err
+			return false, err
 		}
 		sidecarcontrol.UpdateExpectations.ExpectUpdated(sidecarset.Name, sidecarcontrol.GetSidecarSetRevision(sidecarset), pod)
 	}
 
 	klog.V(3).Infof("sidecarSet(%s) updated pods(%s)", sidecarset.Name, strings.Join(podNames, ","))
-	return nil
+	return deniedByPub, nil
 }
 
 func (p *Processor) updatePodSidecarAndHash(control sidecarcontrol.SidecarControl, pod *corev1.Pod) error {
@@ -24,13 +24,20 @@ import (
 	"testing"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
 	"github.com/openkruise/kruise/pkg/control/sidecarcontrol"
+	"github.com/openkruise/kruise/pkg/features"
 	"github.com/openkruise/kruise/pkg/util"
+	"github.com/openkruise/kruise/pkg/util/controllerfinder"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	webhookutil "github.com/openkruise/kruise/pkg/webhook/util"
 
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/kubernetes/pkg/controller/history"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -515,3 +522,62 @@ func TestTruncateHistory(t *testing.T) {
 		t.Fatalf("expected name %s, actual : %s", getName(15), rvs[9].Name)
 	}
 }
+
+func TestUpdatePodsDeniedByPub(t *testing.T) {
+	defer utilfeature.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, features.PodUnavailableBudgetUpdateGate, true)()
+	utilruntime.Must(policyv1alpha1.AddToScheme(scheme))
+	sidecarSet := factorySidecarSet()
+	// use a different name from other tests to avoid the update expectations left by them
+	sidecarSet.Name = "pub-sidecarset"
+	sidecarSet.Annotations[sidecarcontrol.SidecarSetHashWithoutImageAnnotation] = "without-aaa"
+	sidecarSet.Spec.UpdateStrategy.MaxUnavailable = &intstr.IntOrString{
+		Type:   intstr.String,
+		StrVal: "50%",
+	}
+	pub := &policyv1alpha1.PodUnavailableBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pub"},
+		Spec: policyv1alpha1.PodUnavailableBudgetSpec{
+			Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "sidecar"}},
+			MaxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 2},
+		},
+		Status: policyv1alpha1.PodUnavailableBudgetStatus{
+			UnavailableAllowed: 2,
+			DesiredAvailable:   8,
+		},
+	}
+	// drop the pub status cached by other tests
+	_ = util.GlobalCache.Delete(pub)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(sidecarSet, pub).Build()
+	pubcontrol.InitPubControl(fakeClient, &controllerfinder.ControllerFinder{Client: fakeClient}, record.NewFakeRecorder(10))
+	pods := factoryPodsCommon(10, 0, sidecarSet)
+	for i := range pods {
+		pods[i].Annotations[sidecarcontrol.SidecarSetHashAnnotation] = `{"pub-sidecarset":{"hash":"aaa","sidecarList":["test-sidecar"]}}`
+		pods[i].Annotations[sidecarcontrol.SidecarSetHashWithoutImageAnnotation] = `{"pub-sidecarset":{"hash":"without-aaa","sidecarList":["test-sidecar"]}}`
+		pods[i].Annotations[sidecarcontrol.SidecarSetListAnnotation] = `pub-sidecarset`
+		pods[i].Annotations[pubcontrol.PodRelatedPubAnnotation] = pub.Name
+		fakeClient.Create(context.TODO(), pods[i])
+	}
+
+	processor := NewSidecarSetProcessor(fakeClient, record.NewFakeRecorder(10))
+	result, err := processor.UpdateSidecarSet(sidecarSet)
+	if err != nil {
+		t.Fatalf("processor update sidecarset failed: %s", err.Error())
+	}
+	if result.RequeueAfter == 0 {
+		t.Fatalf("expect requeue after pods denied by pub, but got %v", result)
+	}
+
+	var updated int
+	for i := range pods {
+		podOutput, err := getLatestPod(fakeClient, pods[i])
+		if err != nil {
+			t.Fatalf("get latest pod(%s) failed: %s", pods[i].Name, err.Error())
+		}
+		if podOutput.Spec.Containers[1].Image == "test-image:v2" {
+			updated++
+		}
+	}
+	if updated != 2 {
+		t.Fatalf("expect 2 pods updated within the budget of pub, but got %d", updated)
+	}
+}
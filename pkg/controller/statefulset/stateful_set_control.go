@@ -821,6 +821,11 @@ func (ssc *defaultStatefulSetControl) rollingUpdateStatefulsetPods(
 
 		// delete the Pod if it is not already terminating and does not match the update revision.
 		if !isTerminating(replicas[target]) {
+			// wait for the snapshots of pod PVCs to be ready before updating it
+			if ready, err := ssc.ensureSnapshotsBeforeUpdate(set, replicas[target], updateRevision); err != nil || !ready {
+				return status, err
+			}
+
 			// todo validate in-place for pub
 			inplacing, inplaceUpdateErr := ssc.inPlaceUpdatePod(set, replicas[target], updateRevision, revisions)
 			if inplaceUpdateErr != nil {
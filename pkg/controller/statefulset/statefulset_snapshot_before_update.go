@@ -0,0 +1,221 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statefulset
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/controller/history"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+)
+
+var (
+	// volumeSnapshotGVK is the GroupVersionKind of VolumeSnapshot from external-snapshotter.
+	volumeSnapshotGVK = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshot"}
+
+	// snapshotReadyCheckInterval is the interval to requeue the StatefulSet while waiting for snapshots to be ready.
+	snapshotReadyCheckInterval = 5 * time.Second
+)
+
+// ensureSnapshotsBeforeUpdate makes sure that VolumeSnapshots of all PVCs of the pod have been taken for the update revision.
+// It returns true only if all of them are ready to use, which means the pod can be updated.
+func (ssc *defaultStatefulSetControl) ensureSnapshotsBeforeUpdate(set *appsv1beta1.StatefulSet, pod *v1.Pod, updateRevision *apps.ControllerRevision) (bool, error) {
+	if set.Spec.UpdateStrategy.RollingUpdate == nil || set.Spec.UpdateStrategy.RollingUpdate.SnapshotBeforeUpdate == nil {
+		return true, nil
+	}
+	if sigsruntimeClient == nil {
+		return false, fmt.Errorf("no client to take snapshots for pod %s", pod.Name)
+	}
+	ready, err := ensurePodSnapshots(sigsruntimeClient, set, pod, updateRevision)
+	if err != nil {
+		ssc.recorder.Eventf(set, v1.EventTypeWarning, "FailedSnapshotBeforeUpdate", "failed to snapshot PVCs of pod %s: %v", pod.Name, err)
+		return false, err
+	}
+	if !ready {
+		klog.V(4).Infof("StatefulSet %s is waiting for snapshots of Pod %s to be ready before update", getStatefulSetKey(set), pod.Name)
+		durationStore.Push(getStatefulSetKey(set), snapshotReadyCheckInterval)
+	}
+	return ready, nil
+}
+
+func ensurePodSnapshots(c client.Client, set *appsv1beta1.StatefulSet, pod *v1.Pod, updateRevision *apps.ControllerRevision) (bool, error) {
+	policy := set.Spec.UpdateStrategy.RollingUpdate.SnapshotBeforeUpdate
+	claims := getPersistentVolumeClaims(set, pod)
+	if len(claims) == 0 {
+		return true, nil
+	}
+
+	allReady := true
+	snapshots := make(map[string]string, len(claims))
+	for _, claim := range claims {
+		snapshotName := getVolumeSnapshotName(claim.Name, updateRevision)
+		snapshots[claim.Name] = snapshotName
+
+		snapshot := &unstructured.Unstructured{}
+		snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+		err := c.Get(context.TODO(), types.NamespacedName{Namespace: set.Namespace, Name: snapshotName}, snapshot)
+		if errors.IsNotFound(err) {
+			snapshot = newVolumeSnapshot(set, claim.Name, snapshotName, policy.VolumeSnapshotClassName)
+			if err = c.Create(context.TODO(), snapshot); err != nil && !errors.IsAlreadyExists(err) {
+				return false, err
+			}
+			klog.V(3).Infof("StatefulSet %s created VolumeSnapshot %s for PVC %s of Pod %s",
+				getStatefulSetKey(set), snapshotName, claim.Name, pod.Name)
+			allReady = false
+			continue
+		} else if err != nil {
+			return false, err
+		}
+
+		if msg, found, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); found && msg != "" {
+			return false, fmt.Errorf("VolumeSnapshot %s failed: %s", snapshotName, msg)
+		}
+		if readyToUse, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse"); !readyToUse {
+			allReady = false
+		}
+	}
+	if !allReady {
+		return false, nil
+	}
+
+	if err := recordClaimSnapshots(c, set.Namespace, snapshots); err != nil {
+		return false, err
+	}
+
+	snapshotsToKeep := 1
+	if policy.SnapshotsToKeep != nil {
+		snapshotsToKeep = int(*policy.SnapshotsToKeep)
+	}
+	for claimName, snapshotName := range snapshots {
+		if err := truncateSnapshots(c, set, claimName, snapshotName, snapshotsToKeep); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// getVolumeSnapshotName returns the name of snapshot for the PVC before it is updated to the revision.
+// The revision number is included besides the hash, because it is increased when rolling back to a previous
+// revision, so that the snapshot taken in the previous rollout of the same template will not be reused.
+func getVolumeSnapshotName(claimName string, revision *apps.ControllerRevision) string {
+	hash := revision.Labels[history.ControllerRevisionHashLabel]
+	if hash == "" {
+		hash = revision.Name
+	}
+	return fmt.Sprintf("%s-%s-%d", claimName, hash, revision.Revision)
+}
+
+func newVolumeSnapshot(set *appsv1beta1.StatefulSet, claimName, snapshotName, className string) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"volumeSnapshotClassName": className,
+			"source": map[string]interface{}{
+				"persistentVolumeClaimName": claimName,
+			},
+		},
+	}}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	snapshot.SetNamespace(set.Namespace)
+	snapshot.SetName(snapshotName)
+	// no owner reference here, so that snapshots can still be used to restore after the StatefulSet deleted
+	snapshot.SetLabels(map[string]string{appsv1beta1.VolumeSnapshotStatefulSetLabel: getSnapshotStatefulSetLabelValue(set)})
+	return snapshot
+}
+
+// getSnapshotStatefulSetLabelValue returns the StatefulSet name as label value,
+// which is truncated with a hash suffix if it is longer than the limit of label values.
+func getSnapshotStatefulSetLabelValue(set *appsv1beta1.StatefulSet) string {
+	if len(set.Name) <= validation.LabelValueMaxLength {
+		return set.Name
+	}
+	hasher := fnv.New32a()
+	hasher.Write([]byte(set.Name))
+	hash := rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+	return set.Name[:validation.LabelValueMaxLength-len(hash)-1] + "-" + hash
+}
+
+// recordClaimSnapshots records the snapshot names into the annotations of PVCs for restore.
+func recordClaimSnapshots(c client.Client, namespace string, snapshots map[string]string) error {
+	for claimName, snapshotName := range snapshots {
+		claim := &v1.PersistentVolumeClaim{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: claimName}, claim); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if claim.Annotations[appsv1beta1.VolumeSnapshotAnnotation] == snapshotName {
+			continue
+		}
+		body := fmt.Sprintf(`{"metadata":{"annotations":{"%s":%q}}}`, appsv1beta1.VolumeSnapshotAnnotation, snapshotName)
+		if err := c.Patch(context.TODO(), claim, client.RawPatch(types.MergePatchType, []byte(body))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// truncateSnapshots deletes the oldest snapshots of the PVC beyond snapshotsToKeep, the latest one is always kept.
+func truncateSnapshots(c client.Client, set *appsv1beta1.StatefulSet, claimName, latestName string, snapshotsToKeep int) error {
+	snapshotList := &unstructured.UnstructuredList{}
+	snapshotList.SetGroupVersionKind(volumeSnapshotGVK.GroupVersion().WithKind(volumeSnapshotGVK.Kind + "List"))
+	if err := c.List(context.TODO(), snapshotList, client.InNamespace(set.Namespace),
+		client.MatchingLabels{appsv1beta1.VolumeSnapshotStatefulSetLabel: getSnapshotStatefulSetLabelValue(set)}); err != nil {
+		return err
+	}
+
+	var snapshots []*unstructured.Unstructured
+	for i := range snapshotList.Items {
+		snapshot := &snapshotList.Items[i]
+		source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+		if source != claimName || snapshot.GetName() == latestName || snapshot.GetDeletionTimestamp() != nil {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if len(snapshots) < snapshotsToKeep {
+		return nil
+	}
+
+	// newest first
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		ti, tj := snapshots[i].GetCreationTimestamp(), snapshots[j].GetCreationTimestamp()
+		return tj.Before(&ti)
+	})
+	for _, snapshot := range snapshots[snapshotsToKeep-1:] {
+		if err := c.Delete(context.TODO(), snapshot); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		klog.V(3).Infof("StatefulSet %s deleted old VolumeSnapshot %s for PVC %s", getStatefulSetKey(set), snapshot.GetName(), claimName)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statefulset
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/kubernetes/pkg/controller/history"
+	utilpointer "k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+)
+
+func newSnapshotBeforeUpdateSet(snapshotsToKeep int32) *appsv1beta1.StatefulSet {
+	set := newStatefulSet(3)
+	set.Spec.UpdateStrategy.RollingUpdate = &appsv1beta1.RollingUpdateStatefulSetStrategy{
+		SnapshotBeforeUpdate: &appsv1beta1.SnapshotBeforeUpdateStrategy{
+			VolumeSnapshotClassName: "csi-snapclass",
+			SnapshotsToKeep:         utilpointer.Int32Ptr(snapshotsToKeep),
+		},
+	}
+	return set
+}
+
+func newTestVolumeSnapshot(set *appsv1beta1.StatefulSet, claimName, name string, ready bool, created time.Time) *unstructured.Unstructured {
+	snapshot := newVolumeSnapshot(set, claimName, name, "csi-snapclass")
+	snapshot.SetCreationTimestamp(metav1.NewTime(created))
+	_ = unstructured.SetNestedField(snapshot.Object, ready, "status", "readyToUse")
+	return snapshot
+}
+
+func TestEnsurePodSnapshots(t *testing.T) {
+	set := newSnapshotBeforeUpdateSet(2)
+	pod := newStatefulSetPod(set, 1)
+	claimName := getPersistentVolumeClaimName(set, &set.Spec.VolumeClaimTemplates[0], 1)
+	revision := &apps.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: set.Name + "-abc", Labels: map[string]string{history.ControllerRevisionHashLabel: "abc"}},
+		Revision:   3,
+	}
+	now := time.Now()
+
+	cases := []struct {
+		name              string
+		snapshots         []*unstructured.Unstructured
+		expectReady       bool
+		expectSnapshots   []string
+		expectAnnotations bool
+	}{
+		{
+			name:            "create snapshot for the first time",
+			expectReady:     false,
+			expectSnapshots: []string{claimName + "-abc-3"},
+		},
+		{
+			name: "snapshot taken before the previous rollout of the same revision is not reused",
+			snapshots: []*unstructured.Unstructured{
+				newTestVolumeSnapshot(set, claimName, claimName+"-abc-1", true, now.Add(-time.Hour)),
+			},
+			expectReady:     false,
+			expectSnapshots: []string{claimName + "-abc-1", claimName + "-abc-3"},
+		},
+		{
+			name: "snapshot is not ready",
+			snapshots: []*unstructured.Unstructured{
+				newTestVolumeSnapshot(set, claimName, claimName+"-abc-3", false, now),
+			},
+			expectReady:     false,
+			expectSnapshots: []string{claimName + "-abc-3"},
+		},
+		{
+			name: "snapshot is ready and old ones truncated",
+			snapshots: []*unstructured.Unstructured{
+				newTestVolumeSnapshot(set, claimName, claimName+"-v1", true, now.Add(-3*time.Hour)),
+				newTestVolumeSnapshot(set, claimName, claimName+"-v2", true, now.Add(-2*time.Hour)),
+				newTestVolumeSnapshot(set, claimName, claimName+"-v3", true, now.Add(-time.Hour)),
+				newTestVolumeSnapshot(set, claimName, claimName+"-abc-3", true, now),
+			},
+			expectReady:       true,
+			expectSnapshots:   []string{claimName + "-abc-3", claimName + "-v3"},
+			expectAnnotations: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			claim := &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: set.Namespace, Name: claimName}}
+			builder := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(pod.DeepCopy(), claim)
+			for _, snapshot := range tc.snapshots {
+				builder = builder.WithObjects(snapshot.DeepCopy())
+			}
+			c := builder.Build()
+
+			ready, err := ensurePodSnapshots(c, set, pod.DeepCopy(), revision)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ready != tc.expectReady {
+				t.Fatalf("expected ready %v, got %v", tc.expectReady, ready)
+			}
+
+			snapshotList := &unstructured.UnstructuredList{}
+			snapshotList.SetGroupVersionKind(volumeSnapshotGVK.GroupVersion().WithKind("VolumeSnapshotList"))
+			if err := c.List(context.TODO(), snapshotList, client.InNamespace(set.Namespace)); err != nil {
+				t.Fatalf("failed to list snapshots: %v", err)
+			}
+			names := map[string]bool{}
+			for _, snapshot := range snapshotList.Items {
+				names[snapshot.GetName()] = true
+			}
+			if len(names) != len(tc.expectSnapshots) {
+				t.Fatalf("expected snapshots %v, got %v", tc.expectSnapshots, names)
+			}
+			for _, name := range tc.expectSnapshots {
+				if !names[name] {
+					t.Fatalf("expected snapshots %v, got %v", tc.expectSnapshots, names)
+				}
+			}
+
+			newClaim := &v1.PersistentVolumeClaim{}
+			if err := c.Get(context.TODO(), types.NamespacedName{Namespace: set.Namespace, Name: claimName}, newClaim); err != nil {
+				t.Fatalf("failed to get pvc: %v", err)
+			}
+			value, ok := newClaim.Annotations[appsv1beta1.VolumeSnapshotAnnotation]
+			if ok != tc.expectAnnotations {
+				t.Fatalf("expected annotation %v, got %v", tc.expectAnnotations, newClaim.Annotations)
+			}
+			if ok && value != claimName+"-abc-3" {
+				t.Fatalf("unexpected annotation %s", value)
+			}
+		})
+	}
+}
+
+func TestGetSnapshotStatefulSetLabelValue(t *testing.T) {
+	set := newStatefulSet(1)
+	if value := getSnapshotStatefulSetLabelValue(set); value != set.Name {
+		t.Fatalf("expected %s, got %s", set.Name, value)
+	}
+
+	set.Name = strings.Repeat("a", 70)
+	value := getSnapshotStatefulSetLabelValue(set)
+	if len(value) != 63 || !strings.HasPrefix(value, strings.Repeat("a", 50)) {
+		t.Fatalf("unexpected label value %s", value)
+	}
+	other := set.DeepCopy()
+	other.Name = strings.Repeat("a", 69) + "b"
+	if getSnapshotStatefulSetLabelValue(other) == value {
+		t.Fatalf("expected different label values for different names")
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package adapter
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -40,6 +41,15 @@ type Adapter interface {
 	// IsExpected checks the subset is the expected revision or not.
 	// If not, UnitedDeployment will call ApplySubsetTemplate to update it.
 	IsExpected(subset metav1.Object, revision string) bool
+	// GetSubsetPods returns all the pods owned by the subset.
+	GetSubsetPods(subset metav1.Object) ([]*corev1.Pod, error)
 	// PostUpdate does some works after subset updated
 	PostUpdate(ud *alpha1.UnitedDeployment, subset runtime.Object, revision string, partition int32) error
 }
+
+// ScalableAdapter is implemented by the adapters whose existing subsets are scaled through the scale subresource,
+// instead of updating the replicas together with the template in ApplySubsetTemplate.
+type ScalableAdapter interface {
+	// ScaleSubset updates the replicas of the subset.
+	ScaleSubset(subset runtime.Object, replicas int32) error
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package adapter
 
 import (
+	"encoding/json"
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
@@ -79,6 +80,87 @@ func attachTolerations(podSpec *corev1.PodSpec, subsetConfig *appsv1alpha1.Subse
 	podSpec.Tolerations = append(podSpec.Tolerations, subsetConfig.Tolerations...)
 }
 
+// attachSubsetOverrides applies the typed overrides of the subset to the pod template spec.
+func attachSubsetOverrides(podSpec *corev1.PodSpec, subsetConfig *appsv1alpha1.Subset) {
+	overrides := subsetConfig.Overrides
+	if overrides == nil {
+		return
+	}
+
+	if overrides.ServiceAccountName != "" {
+		podSpec.ServiceAccountName = overrides.ServiceAccountName
+		podSpec.DeprecatedServiceAccount = ""
+	}
+	if overrides.PriorityClassName != "" {
+		podSpec.PriorityClassName = overrides.PriorityClassName
+		// priority will be resolved from the priority class by apiserver
+		podSpec.Priority = nil
+	}
+	if len(overrides.ImagePullSecrets) > 0 {
+		podSpec.ImagePullSecrets = append([]corev1.LocalObjectReference{}, overrides.ImagePullSecrets...)
+	}
+
+	for i := range overrides.Containers {
+		containerOverride := &overrides.Containers[i]
+		if container := getPodSpecContainer(podSpec, containerOverride.Name); container != nil {
+			overrideContainer(container, containerOverride)
+		}
+	}
+}
+
+func getPodSpecContainer(podSpec *corev1.PodSpec, name string) *corev1.Container {
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == name {
+			return &podSpec.Containers[i]
+		}
+	}
+	for i := range podSpec.InitContainers {
+		if podSpec.InitContainers[i].Name == name {
+			return &podSpec.InitContainers[i]
+		}
+	}
+	return nil
+}
+
+func overrideContainer(container *corev1.Container, containerOverride *appsv1alpha1.SubsetContainerOverride) {
+	for name, quantity := range containerOverride.Resources.Requests {
+		if container.Resources.Requests == nil {
+			container.Resources.Requests = corev1.ResourceList{}
+		}
+		container.Resources.Requests[name] = quantity
+	}
+	for name, quantity := range containerOverride.Resources.Limits {
+		if container.Resources.Limits == nil {
+			container.Resources.Limits = corev1.ResourceList{}
+		}
+		container.Resources.Limits[name] = quantity
+	}
+
+	for _, env := range containerOverride.Env {
+		found := false
+		for i := range container.Env {
+			if container.Env[i].Name == env.Name {
+				container.Env[i] = env
+				found = true
+				break
+			}
+		}
+		if !found {
+			container.Env = append(container.Env, env)
+		}
+	}
+}
+
+// GetSubsetOverridesAnnotation returns the value of the overrides annotation recorded in the subset workload,
+// which is used to tell if the overrides of subset have been changed.
+func GetSubsetOverridesAnnotation(subsetConfig *appsv1alpha1.Subset) string {
+	if subsetConfig.Overrides == nil {
+		return ""
+	}
+	overrides, _ := json.Marshal(subsetConfig.Overrides)
+	return string(overrides)
+}
+
 func getRevision(objMeta metav1.Object) string {
 	if objMeta.GetLabels() == nil {
 		return ""
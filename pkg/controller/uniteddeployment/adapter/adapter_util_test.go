@@ -21,7 +21,10 @@ import (
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 )
@@ -63,6 +66,65 @@ func TestGetCurrentPartitionForStrategyOnDelete(t *testing.T) {
 	}
 }
 
+func TestAttachSubsetOverrides(t *testing.T) {
+	podSpec := &corev1.PodSpec{
+		ServiceAccountName: "default",
+		Priority:           pointer.Int32(100),
+		ImagePullSecrets:   []corev1.LocalObjectReference{{Name: "secret-a"}},
+		InitContainers:     []corev1.Container{{Name: "init"}},
+		Containers: []corev1.Container{{
+			Name: "main",
+			Env:  []corev1.EnvVar{{Name: "ZONE", Value: "default"}, {Name: "KEEP", Value: "true"}},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+			},
+		}},
+	}
+	subset := &appsv1alpha1.Subset{
+		Name: "subset-a",
+		Overrides: &appsv1alpha1.SubsetOverrides{
+			ServiceAccountName: "sa-a",
+			PriorityClassName:  "high",
+			ImagePullSecrets:   []corev1.LocalObjectReference{{Name: "secret-b"}},
+			Containers: []appsv1alpha1.SubsetContainerOverride{
+				{
+					Name: "main",
+					Env:  []corev1.EnvVar{{Name: "ZONE", Value: "a"}, {Name: "NEW", Value: "1"}},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+						Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+					},
+				},
+				{Name: "init", Env: []corev1.EnvVar{{Name: "ZONE", Value: "a"}}},
+				{Name: "not-found", Env: []corev1.EnvVar{{Name: "ZONE", Value: "a"}}},
+			},
+		},
+	}
+
+	attachSubsetOverrides(podSpec, subset)
+	expected := &corev1.PodSpec{
+		ServiceAccountName: "sa-a",
+		PriorityClassName:  "high",
+		ImagePullSecrets:   []corev1.LocalObjectReference{{Name: "secret-b"}},
+		InitContainers:     []corev1.Container{{Name: "init", Env: []corev1.EnvVar{{Name: "ZONE", Value: "a"}}}},
+		Containers: []corev1.Container{{
+			Name: "main",
+			Env:  []corev1.EnvVar{{Name: "ZONE", Value: "a"}, {Name: "KEEP", Value: "true"}, {Name: "NEW", Value: "1"}},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+			},
+		}},
+	}
+	if !apiequality.Semantic.DeepEqual(podSpec, expected) {
+		t.Fatalf("expected %v, got %v", expected, podSpec)
+	}
+
+	if GetSubsetOverridesAnnotation(&appsv1alpha1.Subset{}) != "" || GetSubsetOverridesAnnotation(subset) == "" {
+		t.Fatalf("unexpected overrides annotation")
+	}
+}
+
 func buildPodList(ordinals []int, revisions []string, t *testing.T) []*corev1.Pod {
 	if len(ordinals) != len(revisions) {
 		t.Fatalf("ordinals count should equals to revision count")
@@ -177,6 +177,7 @@ func (a *AdvancedStatefulSetAdapter) ApplySubsetTemplate(ud *alpha1.UnitedDeploy
 
 	attachNodeAffinity(&set.Spec.Template.Spec, subSetConfig)
 	attachTolerations(&set.Spec.Template.Spec, subSetConfig)
+	attachSubsetOverrides(&set.Spec.Template.Spec, subSetConfig)
 	if subSetConfig.Patch.Raw != nil {
 		TemplateSpecBytes, _ := json.Marshal(set.Spec.Template)
 		modified, err := strategicpatch.StrategicMergePatch(TemplateSpecBytes, subSetConfig.Patch.Raw, &corev1.PodTemplateSpec{})
@@ -197,6 +198,7 @@ func (a *AdvancedStatefulSetAdapter) ApplySubsetTemplate(ud *alpha1.UnitedDeploy
 		set.Annotations = make(map[string]string)
 	}
 	set.Annotations[alpha1.AnnotationSubsetPatchKey] = string(subSetConfig.Patch.Raw)
+	set.Annotations[alpha1.AnnotationSubsetOverridesKey] = GetSubsetOverridesAnnotation(subSetConfig)
 
 	return nil
 }
@@ -212,6 +214,11 @@ func (a *AdvancedStatefulSetAdapter) IsExpected(obj metav1.Object, revision stri
 	return obj.GetLabels()[appsv1.ControllerRevisionHashLabelKey] != revision
 }
 
+// GetSubsetPods returns all the pods owned by the subset.
+func (a *AdvancedStatefulSetAdapter) GetSubsetPods(obj metav1.Object) ([]*corev1.Pod, error) {
+	return a.getStatefulSetPods(obj.(*v1beta1.StatefulSet))
+}
+
 func (a *AdvancedStatefulSetAdapter) getStatefulSetPods(set *v1beta1.StatefulSet) ([]*corev1.Pod, error) {
 	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
 	if err != nil {
@@ -138,6 +138,7 @@ func (a *CloneSetAdapter) ApplySubsetTemplate(ud *alpha1.UnitedDeployment, subse
 
 	attachNodeAffinity(&set.Spec.Template.Spec, subSetConfig)
 	attachTolerations(&set.Spec.Template.Spec, subSetConfig)
+	attachSubsetOverrides(&set.Spec.Template.Spec, subSetConfig)
 	if subSetConfig.Patch.Raw != nil {
 		TemplateSpecBytes, _ := json.Marshal(set.Spec.Template)
 		modified, err := strategicpatch.StrategicMergePatch(TemplateSpecBytes, subSetConfig.Patch.Raw, &corev1.PodTemplateSpec{})
@@ -158,6 +159,7 @@ func (a *CloneSetAdapter) ApplySubsetTemplate(ud *alpha1.UnitedDeployment, subse
 		set.Annotations = make(map[string]string)
 	}
 	set.Annotations[alpha1.AnnotationSubsetPatchKey] = string(subSetConfig.Patch.Raw)
+	set.Annotations[alpha1.AnnotationSubsetOverridesKey] = GetSubsetOverridesAnnotation(subSetConfig)
 	return nil
 }
 
@@ -169,6 +171,11 @@ func (a *CloneSetAdapter) IsExpected(obj metav1.Object, revision string) bool {
 	return obj.GetLabels()[alpha1.ControllerRevisionHashLabelKey] != revision
 }
 
+// GetSubsetPods returns all the pods owned by the subset.
+func (a *CloneSetAdapter) GetSubsetPods(obj metav1.Object) ([]*corev1.Pod, error) {
+	return a.getCloneSetPods(obj.(*alpha1.CloneSet))
+}
+
 func (a *CloneSetAdapter) getCloneSetPods(set *alpha1.CloneSet) ([]*corev1.Pod, error) {
 
 	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
@@ -0,0 +1,389 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	scaleclient "k8s.io/client-go/scale"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util/configuration"
+)
+
+// CustomWorkloadAdapter implements the Adapter interface for the custom workloads registered in the
+// UnitedDeployment custom workload white list. The workloads are managed as unstructured objects,
+// whose fields are located by the paths in the white list. The replicas of existing workloads are
+// read and updated through the scale subresource, so that they go through the scale admission.
+type CustomWorkloadAdapter struct {
+	client.Client
+	Scheme      *runtime.Scheme
+	Workload    configuration.UDCustomWorkload
+	Mapper      meta.RESTMapper
+	ScaleClient scaleclient.ScalesGetter
+}
+
+func (a *CustomWorkloadAdapter) NewResourceObject() client.Object {
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(a.Workload.GroupVersionKind)
+	return object
+}
+
+func (a *CustomWorkloadAdapter) NewResourceListObject() client.ObjectList {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(a.Workload.GroupVersionKind.GroupVersion().WithKind(a.Workload.Kind + "List"))
+	return list
+}
+
+func (a *CustomWorkloadAdapter) GetStatusObservedGeneration(obj metav1.Object) int64 {
+	generation, _ := getNestedInt64(obj.(*unstructured.Unstructured), a.Workload.ObservedGenerationPath, "status.observedGeneration")
+	return generation
+}
+
+func (a *CustomWorkloadAdapter) GetReplicaDetails(obj metav1.Object, updatedRevision string) (specReplicas, specPartition *int32, statusReplicas, statusReadyReplicas, statusUpdatedReplicas, statusUpdatedReadyReplicas int32, err error) {
+	set := obj.(*unstructured.Unstructured)
+
+	var pods []*corev1.Pod
+	pods, err = a.GetSubsetPods(set)
+	if err != nil {
+		return
+	}
+
+	replicas, found := getNestedInt64(set, a.Workload.ReplicasPath, "spec.replicas")
+	if found {
+		specReplicas = int32Ptr(int32(replicas))
+	}
+	if scale, scaleErr := a.getScale(set); scaleErr != nil {
+		err = scaleErr
+		return
+	} else if scale != nil {
+		replicas = int64(scale.Spec.Replicas)
+		specReplicas = int32Ptr(scale.Spec.Replicas)
+	}
+	if a.Workload.PartitionPath != "" {
+		partition, found, err := unstructured.NestedFieldNoCopy(set.Object, splitPath(a.Workload.PartitionPath, "")...)
+		if err == nil && found {
+			if partitionValue, ok := parsePartition(partition, int(replicas)); ok {
+				specPartition = int32Ptr(partitionValue)
+			}
+		}
+	}
+
+	value, _ := getNestedInt64(set, a.Workload.StatusReplicasPath, "status.replicas")
+	statusReplicas = int32(value)
+	value, _ = getNestedInt64(set, a.Workload.StatusReadyReplicasPath, "status.readyReplicas")
+	statusReadyReplicas = int32(value)
+	statusUpdatedReplicas, statusUpdatedReadyReplicas = calculateUpdatedReplicas(pods, updatedRevision)
+	return
+}
+
+func (a *CustomWorkloadAdapter) GetSubsetFailure() *string {
+	return nil
+}
+
+func (a *CustomWorkloadAdapter) ApplySubsetTemplate(ud *alpha1.UnitedDeployment, subsetName, revision string, replicas, partition int32, obj runtime.Object) error {
+	set := obj.(*unstructured.Unstructured)
+	template := ud.Spec.Template.CustomWorkloadTemplate
+	if template == nil {
+		return fmt.Errorf("no customWorkloadTemplate found in UnitedDeployment %s/%s", ud.Namespace, ud.Name)
+	}
+
+	var subSetConfig *alpha1.Subset
+	for _, subset := range ud.Spec.Topology.Subsets {
+		if subset.Name == subsetName {
+			subSetConfig = &subset
+			break
+		}
+	}
+	if subSetConfig == nil {
+		return fmt.Errorf("fail to find subset config %s", subsetName)
+	}
+
+	set.SetGroupVersionKind(a.Workload.GroupVersionKind)
+	set.SetNamespace(ud.Namespace)
+
+	labels := set.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for k, v := range template.Labels {
+		labels[k] = v
+	}
+	for k, v := range ud.Spec.Selector.MatchLabels {
+		labels[k] = v
+	}
+	labels[alpha1.ControllerRevisionHashLabelKey] = revision
+	// record the subset name as a label
+	labels[alpha1.SubSetNameLabelKey] = subsetName
+	set.SetLabels(labels)
+
+	annotations := set.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for k, v := range template.Annotations {
+		annotations[k] = v
+	}
+	annotations[alpha1.AnnotationSubsetPatchKey] = string(subSetConfig.Patch.Raw)
+	annotations[alpha1.AnnotationSubsetOverridesKey] = GetSubsetOverridesAnnotation(subSetConfig)
+	set.SetAnnotations(annotations)
+
+	set.SetGenerateName(getSubsetPrefix(ud.Name, subsetName))
+	if err := controllerutil.SetControllerReference(ud, set, a.Scheme); err != nil {
+		return err
+	}
+
+	// the template spec is merged into the existing spec, so that the fields defaulted by the controller
+	// or webhooks of the workload are kept, and the replicas of existing workloads are left to ScaleSubset.
+	replicasPath := splitPath(a.Workload.ReplicasPath, "spec.replicas")
+	existingReplicas, replicasFound, _ := unstructured.NestedFieldCopy(set.Object, replicasPath...)
+	if len(template.Spec.Raw) > 0 {
+		existingSpec, found, _ := unstructured.NestedMap(set.Object, "spec")
+		if !found {
+			existingSpec = map[string]interface{}{}
+		}
+		existingSpecBytes, err := json.Marshal(existingSpec)
+		if err != nil {
+			return err
+		}
+		mergedSpecBytes, err := jsonpatch.MergePatch(existingSpecBytes, template.Spec.Raw)
+		if err != nil {
+			return err
+		}
+		spec := map[string]interface{}{}
+		if err = json.Unmarshal(mergedSpecBytes, &spec); err != nil {
+			return err
+		}
+		set.Object["spec"] = spec
+	}
+
+	selector := ud.Spec.Selector.DeepCopy()
+	if selector.MatchLabels == nil {
+		selector.MatchLabels = map[string]string{}
+	}
+	selector.MatchLabels[alpha1.SubSetNameLabelKey] = subsetName
+	selectorObject, err := runtime.DefaultUnstructuredConverter.ToUnstructured(selector)
+	if err != nil {
+		return err
+	}
+	if err = unstructured.SetNestedField(set.Object, selectorObject, splitPath(a.Workload.SelectorPath, "spec.selector")...); err != nil {
+		return err
+	}
+	if set.GetResourceVersion() == "" {
+		if err = unstructured.SetNestedField(set.Object, int64(replicas), replicasPath...); err != nil {
+			return err
+		}
+	} else if replicasFound {
+		if err = unstructured.SetNestedField(set.Object, existingReplicas, replicasPath...); err != nil {
+			return err
+		}
+	} else {
+		unstructured.RemoveNestedField(set.Object, replicasPath...)
+	}
+	if a.Workload.PartitionPath != "" {
+		if err = unstructured.SetNestedField(set.Object, int64(partition), splitPath(a.Workload.PartitionPath, "")...); err != nil {
+			return err
+		}
+	}
+
+	podTemplate, err := a.getPodTemplate(set)
+	if err != nil {
+		return err
+	}
+	if podTemplate.Labels == nil {
+		podTemplate.Labels = map[string]string{}
+	}
+	podTemplate.Labels[alpha1.SubSetNameLabelKey] = subsetName
+	podTemplate.Labels[alpha1.ControllerRevisionHashLabelKey] = revision
+
+	attachNodeAffinity(&podTemplate.Spec, subSetConfig)
+	attachTolerations(&podTemplate.Spec, subSetConfig)
+	attachSubsetOverrides(&podTemplate.Spec, subSetConfig)
+	if subSetConfig.Patch.Raw != nil {
+		TemplateSpecBytes, _ := json.Marshal(podTemplate)
+		modified, err := strategicpatch.StrategicMergePatch(TemplateSpecBytes, subSetConfig.Patch.Raw, &corev1.PodTemplateSpec{})
+		if err != nil {
+			klog.Errorf("failed to merge patch raw %s", subSetConfig.Patch.Raw)
+			return err
+		}
+		patchedTemplateSpec := &corev1.PodTemplateSpec{}
+		if err = json.Unmarshal(modified, patchedTemplateSpec); err != nil {
+			klog.Errorf("failed to unmarshal %s to podTemplateSpec", modified)
+			return err
+		}
+
+		podTemplate = patchedTemplateSpec
+		klog.V(2).Infof("%s [%s/%s] was patched successfully: %s", a.Workload.Kind, set.GetNamespace(), set.GetGenerateName(), subSetConfig.Patch.Raw)
+	}
+
+	podTemplateObject, err := runtime.DefaultUnstructuredConverter.ToUnstructured(podTemplate)
+	if err != nil {
+		return err
+	}
+	return unstructured.SetNestedField(set.Object, podTemplateObject, splitPath(a.Workload.TemplatePath, "spec.template")...)
+}
+
+// ScaleSubset updates the replicas of the existing workload through the scale subresource.
+func (a *CustomWorkloadAdapter) ScaleSubset(obj runtime.Object, replicas int32) error {
+	set := obj.(*unstructured.Unstructured)
+	scale, err := a.getScale(set)
+	if err != nil || scale == nil || scale.Spec.Replicas == replicas {
+		return err
+	}
+	gr, err := a.getGroupResource()
+	if err != nil {
+		return err
+	}
+	scale.Spec.Replicas = replicas
+	_, err = a.ScaleClient.Scales(set.GetNamespace()).Update(context.TODO(), gr, scale, metav1.UpdateOptions{})
+	return err
+}
+
+// getScale returns the scale subresource of the workload, which is nil if the workload has not been created
+// or does not have the scale subresource.
+func (a *CustomWorkloadAdapter) getScale(set *unstructured.Unstructured) (*autoscalingv1.Scale, error) {
+	if a.ScaleClient == nil || set.GetName() == "" || set.GetResourceVersion() == "" {
+		return nil, nil
+	}
+	gr, err := a.getGroupResource()
+	if err != nil {
+		return nil, err
+	}
+	scale, err := a.ScaleClient.Scales(set.GetNamespace()).Get(context.TODO(), gr, set.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	return scale, err
+}
+
+func (a *CustomWorkloadAdapter) getGroupResource() (schema.GroupResource, error) {
+	mapping, err := a.Mapper.RESTMapping(a.Workload.GroupVersionKind.GroupKind(), a.Workload.Version)
+	if err != nil {
+		return schema.GroupResource{}, err
+	}
+	return mapping.Resource.GroupResource(), nil
+}
+
+func (a *CustomWorkloadAdapter) PostUpdate(ud *alpha1.UnitedDeployment, obj runtime.Object, revision string, partition int32) error {
+	return nil
+}
+
+func (a *CustomWorkloadAdapter) IsExpected(obj metav1.Object, revision string) bool {
+	return obj.GetLabels()[alpha1.ControllerRevisionHashLabelKey] != revision
+}
+
+// GetSubsetPods returns all the pods matching the selector of the subset. The pods are not required to be
+// owned by the workload directly, since custom workloads may manage pods through other workloads.
+func (a *CustomWorkloadAdapter) GetSubsetPods(obj metav1.Object) ([]*corev1.Pod, error) {
+	set := obj.(*unstructured.Unstructured)
+	selectorObject, found, err := unstructured.NestedMap(set.Object, splitPath(a.Workload.SelectorPath, "spec.selector")...)
+	if err != nil || !found {
+		return nil, fmt.Errorf("failed to get selector of %s %s/%s: %v", a.Workload.Kind, set.GetNamespace(), set.GetName(), err)
+	}
+	labelSelector := &metav1.LabelSelector{}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(selectorObject, labelSelector); err != nil {
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	podList := &corev1.PodList{}
+	if err = a.Client.List(context.TODO(), podList, &client.ListOptions{Namespace: set.GetNamespace(), LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+	pods := make([]*corev1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		pods = append(pods, &podList.Items[i])
+	}
+	return pods, nil
+}
+
+func (a *CustomWorkloadAdapter) getPodTemplate(set *unstructured.Unstructured) (*corev1.PodTemplateSpec, error) {
+	podTemplate := &corev1.PodTemplateSpec{}
+	templateObject, found, err := unstructured.NestedMap(set.Object, splitPath(a.Workload.TemplatePath, "spec.template")...)
+	if err != nil {
+		return nil, err
+	} else if !found {
+		return podTemplate, nil
+	}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(templateObject, podTemplate); err != nil {
+		return nil, err
+	}
+	return podTemplate, nil
+}
+
+func splitPath(path, defaultPath string) []string {
+	if path == "" {
+		path = defaultPath
+	}
+	return strings.Split(path, ".")
+}
+
+func getNestedInt64(set *unstructured.Unstructured, path, defaultPath string) (int64, bool) {
+	value, found, err := unstructured.NestedFieldNoCopy(set.Object, splitPath(path, defaultPath)...)
+	if err != nil || !found {
+		return 0, false
+	}
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// parsePartition parses the partition in integer or percentage.
+func parsePartition(partition interface{}, replicas int) (int32, bool) {
+	switch v := partition.(type) {
+	case int64:
+		return int32(v), true
+	case float64:
+		return int32(v), true
+	case string:
+		intOrStr := intstr.FromString(v)
+		value, err := intstr.GetScaledValueFromIntOrPercent(&intOrStr, replicas, true)
+		if err != nil {
+			return 0, false
+		}
+		return int32(value), true
+	}
+	return 0, false
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"testing"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	scalefake "k8s.io/client-go/scale/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util/configuration"
+)
+
+func newCustomWorkloadTestUnitedDeployment() *appsv1alpha1.UnitedDeployment {
+	return &appsv1alpha1.UnitedDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", UID: "ud-uid"},
+		Spec: appsv1alpha1.UnitedDeploymentSpec{
+			Replicas: pointer.Int32(4),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "demo"}},
+			Template: appsv1alpha1.SubsetTemplate{
+				CustomWorkloadTemplate: &appsv1alpha1.CustomWorkloadTemplateSpec{
+					APIVersion: "example.io/v1",
+					Kind:       "Rollout",
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "demo"}},
+					Spec: runtime.RawExtension{Raw: []byte(`{"strategy":{"canary":{}},"template":{"metadata":{"labels":{"app":"demo"}},` +
+						`"spec":{"containers":[{"name":"main","image":"nginx"}]}}}`)},
+				},
+			},
+			Topology: appsv1alpha1.Topology{
+				Subsets: []appsv1alpha1.Subset{{
+					Name: "subset-a",
+					NodeSelectorTerm: corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}},
+					}},
+				}},
+			},
+		},
+	}
+}
+
+func TestCustomWorkloadAdapter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	pods := []runtime.Object{
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "default", Labels: map[string]string{
+			"app": "demo", appsv1alpha1.SubSetNameLabelKey: "subset-a", appsv1alpha1.ControllerRevisionHashLabelKey: "v2"}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-2", Namespace: "default", Labels: map[string]string{
+			"app": "demo", appsv1alpha1.SubSetNameLabelKey: "subset-a", appsv1alpha1.ControllerRevisionHashLabelKey: "v1"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-3", Namespace: "default", Labels: map[string]string{
+			"app": "demo", appsv1alpha1.SubSetNameLabelKey: "subset-b", appsv1alpha1.ControllerRevisionHashLabelKey: "v2"}}},
+	}
+	a := &CustomWorkloadAdapter{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(pods...).Build(),
+		Scheme: scheme,
+		Workload: configuration.UDCustomWorkload{
+			GroupVersionKind: schema.GroupVersionKind{Group: "example.io", Version: "v1", Kind: "Rollout"},
+			PartitionPath:    "spec.strategy.canary.partition",
+		},
+	}
+
+	ud := newCustomWorkloadTestUnitedDeployment()
+	set := a.NewResourceObject().(*unstructured.Unstructured)
+	if err := a.ApplySubsetTemplate(ud, "subset-a", "v2", 3, 1, set); err != nil {
+		t.Fatalf("failed to apply subset template: %v", err)
+	}
+
+	if set.GetAPIVersion() != "example.io/v1" || set.GetKind() != "Rollout" {
+		t.Fatalf("unexpected gvk %v", set.GroupVersionKind())
+	}
+	if set.GetLabels()[appsv1alpha1.SubSetNameLabelKey] != "subset-a" || set.GetLabels()[appsv1alpha1.ControllerRevisionHashLabelKey] != "v2" {
+		t.Fatalf("unexpected labels %v", set.GetLabels())
+	}
+	if len(set.GetOwnerReferences()) != 1 || set.GetOwnerReferences()[0].UID != ud.UID {
+		t.Fatalf("unexpected owner references %v", set.GetOwnerReferences())
+	}
+	if replicas, _, _ := unstructured.NestedInt64(set.Object, "spec", "replicas"); replicas != 3 {
+		t.Fatalf("expected replicas 3, got %d", replicas)
+	}
+	if partition, _, _ := unstructured.NestedInt64(set.Object, "spec", "strategy", "canary", "partition"); partition != 1 {
+		t.Fatalf("expected partition 1, got %d", partition)
+	}
+	selector, _, _ := unstructured.NestedStringMap(set.Object, "spec", "selector", "matchLabels")
+	if selector["app"] != "demo" || selector[appsv1alpha1.SubSetNameLabelKey] != "subset-a" {
+		t.Fatalf("unexpected selector %v", selector)
+	}
+	podTemplate, err := a.getPodTemplate(set)
+	if err != nil {
+		t.Fatalf("failed to get pod template: %v", err)
+	}
+	if podTemplate.Labels[appsv1alpha1.SubSetNameLabelKey] != "subset-a" {
+		t.Fatalf("unexpected pod template labels %v", podTemplate.Labels)
+	}
+	if podTemplate.Spec.Affinity == nil || podTemplate.Spec.Affinity.NodeAffinity == nil {
+		t.Fatalf("expected node affinity attached to pod template")
+	}
+	if len(podTemplate.Spec.Containers) != 1 || podTemplate.Spec.Containers[0].Image != "nginx" {
+		t.Fatalf("unexpected containers %v", podTemplate.Spec.Containers)
+	}
+
+	set.SetName("demo-subset-a")
+	_ = unstructured.SetNestedField(set.Object, int64(2), "status", "replicas")
+	_ = unstructured.SetNestedField(set.Object, int64(1), "status", "readyReplicas")
+	_ = unstructured.SetNestedField(set.Object, int64(5), "status", "observedGeneration")
+	if generation := a.GetStatusObservedGeneration(set); generation != 5 {
+		t.Fatalf("expected observed generation 5, got %d", generation)
+	}
+
+	specReplicas, specPartition, statusReplicas, statusReadyReplicas, statusUpdatedReplicas, statusUpdatedReadyReplicas, err := a.GetReplicaDetails(set, "v2")
+	if err != nil {
+		t.Fatalf("failed to get replica details: %v", err)
+	}
+	if *specReplicas != 3 || *specPartition != 1 || statusReplicas != 2 || statusReadyReplicas != 1 ||
+		statusUpdatedReplicas != 1 || statusUpdatedReadyReplicas != 1 {
+		t.Fatalf("unexpected replica details: %d %d %d %d %d %d", *specReplicas, *specPartition, statusReplicas,
+			statusReadyReplicas, statusUpdatedReplicas, statusUpdatedReadyReplicas)
+	}
+
+	if !a.IsExpected(set, "v3") || a.IsExpected(set, "v2") {
+		t.Fatalf("unexpected IsExpected result")
+	}
+}
+
+func TestCustomWorkloadAdapterScale(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+
+	gvk := schema.GroupVersionKind{Group: "example.io", Version: "v1", Kind: "Rollout"}
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{gvk.GroupVersion()})
+	mapper.Add(gvk, meta.RESTScopeNamespace)
+	scaleReplicas := int32(5)
+	scaleClient := &scalefake.FakeScaleClient{}
+	scaleClient.AddReactor("get", "rollouts", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &autoscalingv1.Scale{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "demo-subset-a"},
+			Spec:       autoscalingv1.ScaleSpec{Replicas: scaleReplicas},
+		}, nil
+	})
+	scaleClient.AddReactor("update", "rollouts", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		scale := action.(clienttesting.UpdateAction).GetObject().(*autoscalingv1.Scale)
+		scaleReplicas = scale.Spec.Replicas
+		return true, scale, nil
+	})
+	a := &CustomWorkloadAdapter{
+		Client:      fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme:      scheme,
+		Workload:    configuration.UDCustomWorkload{GroupVersionKind: gvk},
+		Mapper:      mapper,
+		ScaleClient: scaleClient,
+	}
+
+	set := a.NewResourceObject().(*unstructured.Unstructured)
+	set.SetNamespace("default")
+	set.SetName("demo-subset-a")
+	set.SetResourceVersion("1")
+	_ = unstructured.SetNestedField(set.Object, int64(5), "spec", "replicas")
+	_ = unstructured.SetNestedField(set.Object, int64(600), "spec", "progressDeadlineSeconds")
+	ud := newCustomWorkloadTestUnitedDeployment()
+	if err := a.ApplySubsetTemplate(ud, "subset-a", "v2", 3, 0, set); err != nil {
+		t.Fatalf("failed to apply subset template: %v", err)
+	}
+	if deadline, _, _ := unstructured.NestedInt64(set.Object, "spec", "progressDeadlineSeconds"); deadline != 600 {
+		t.Fatalf("expected defaulted field kept, got spec %v", set.Object["spec"])
+	}
+	if _, found, _ := unstructured.NestedMap(set.Object, "spec", "strategy", "canary"); !found {
+		t.Fatalf("expected template spec merged, got spec %v", set.Object["spec"])
+	}
+	if replicas, _, _ := unstructured.NestedInt64(set.Object, "spec", "replicas"); replicas != 5 {
+		t.Fatalf("expected replicas left to the scale subresource, got %d", replicas)
+	}
+
+	if err := a.ScaleSubset(set, 3); err != nil {
+		t.Fatalf("failed to scale subset: %v", err)
+	}
+	if scaleReplicas != 3 {
+		t.Fatalf("expected scaled to 3, got %d", scaleReplicas)
+	}
+	specReplicas, _, _, _, _, _, err := a.GetReplicaDetails(set, "v2")
+	if err != nil {
+		t.Fatalf("failed to get replica details: %v", err)
+	}
+	if *specReplicas != 3 {
+		t.Fatalf("expected spec replicas 3 from the scale subresource, got %d", *specReplicas)
+	}
+}
+
+func TestParsePartition(t *testing.T) {
+	cases := []struct {
+		partition interface{}
+		replicas  int
+		expected  int32
+		ok        bool
+	}{
+		{partition: int64(2), replicas: 5, expected: 2, ok: true},
+		{partition: "40%", replicas: 5, expected: 2, ok: true},
+		{partition: "abc", replicas: 5, ok: false},
+		{partition: true, replicas: 5, ok: false},
+	}
+	for _, cs := range cases {
+		got, ok := parsePartition(cs.partition, cs.replicas)
+		if ok != cs.ok || got != cs.expected {
+			t.Errorf("parsePartition(%v, %d) = %d, %v; expected %d, %v", cs.partition, cs.replicas, got, ok, cs.expected, cs.ok)
+		}
+	}
+}
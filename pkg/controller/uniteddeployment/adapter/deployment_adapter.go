@@ -149,6 +149,7 @@ func (a *DeploymentAdapter) ApplySubsetTemplate(ud *alpha1.UnitedDeployment, sub
 
 	attachNodeAffinity(&set.Spec.Template.Spec, subSetConfig)
 	attachTolerations(&set.Spec.Template.Spec, subSetConfig)
+	attachSubsetOverrides(&set.Spec.Template.Spec, subSetConfig)
 
 	if subSetConfig.Patch.Raw != nil {
 		TemplateSpecBytes, _ := json.Marshal(set.Spec.Template)
@@ -170,6 +171,7 @@ func (a *DeploymentAdapter) ApplySubsetTemplate(ud *alpha1.UnitedDeployment, sub
 		set.Annotations = make(map[string]string)
 	}
 	set.Annotations[alpha1.AnnotationSubsetPatchKey] = string(subSetConfig.Patch.Raw)
+	set.Annotations[alpha1.AnnotationSubsetOverridesKey] = GetSubsetOverridesAnnotation(subSetConfig)
 
 	return nil
 }
@@ -185,6 +187,11 @@ func (a *DeploymentAdapter) IsExpected(obj metav1.Object, revision string) bool
 	return obj.GetLabels()[appsv1.ControllerRevisionHashLabelKey] != revision
 }
 
+// GetSubsetPods returns all the pods owned by the subset.
+func (a *DeploymentAdapter) GetSubsetPods(obj metav1.Object) ([]*corev1.Pod, error) {
+	return a.getDeploymentPods(obj.(*appsv1.Deployment))
+}
+
 // getDeploymentPods gets all Pods under a Deployment object
 func (a *DeploymentAdapter) getDeploymentPods(set *appsv1.Deployment) ([]*corev1.Pod, error) {
 	deploymentReplicaSets, err := a.getDeploymentReplicaSets(set)
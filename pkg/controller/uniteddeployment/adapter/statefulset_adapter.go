@@ -160,6 +160,7 @@ func (a *StatefulSetAdapter) ApplySubsetTemplate(ud *alpha1.UnitedDeployment, su
 
 	attachNodeAffinity(&set.Spec.Template.Spec, subSetConfig)
 	attachTolerations(&set.Spec.Template.Spec, subSetConfig)
+	attachSubsetOverrides(&set.Spec.Template.Spec, subSetConfig)
 	if subSetConfig.Patch.Raw != nil {
 		TemplateSpecBytes, _ := json.Marshal(set.Spec.Template)
 		modified, err := strategicpatch.StrategicMergePatch(TemplateSpecBytes, subSetConfig.Patch.Raw, &corev1.PodTemplateSpec{})
@@ -180,6 +181,7 @@ func (a *StatefulSetAdapter) ApplySubsetTemplate(ud *alpha1.UnitedDeployment, su
 		set.Annotations = make(map[string]string)
 	}
 	set.Annotations[alpha1.AnnotationSubsetPatchKey] = string(subSetConfig.Patch.Raw)
+	set.Annotations[alpha1.AnnotationSubsetOverridesKey] = GetSubsetOverridesAnnotation(subSetConfig)
 
 	return nil
 }
@@ -201,6 +203,11 @@ func (a *StatefulSetAdapter) IsExpected(obj metav1.Object, revision string) bool
 	return obj.GetLabels()[alpha1.ControllerRevisionHashLabelKey] != revision
 }
 
+// GetSubsetPods returns all the pods owned by the subset.
+func (a *StatefulSetAdapter) GetSubsetPods(obj metav1.Object) ([]*corev1.Pod, error) {
+	return a.getStatefulSetPods(obj.(*appsv1.StatefulSet))
+}
+
 func (a *StatefulSetAdapter) getStatefulSetPods(set *appsv1.StatefulSet) ([]*corev1.Pod, error) {
 	selector, err := metav1.LabelSelectorAsSelector(set.Spec.Selector)
 	if err != nil {
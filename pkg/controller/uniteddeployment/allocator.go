@@ -60,6 +60,16 @@ type ReplicaAllocator interface {
 }
 
 func NewReplicaAllocator(ud *appsv1alpha1.UnitedDeployment) ReplicaAllocator {
+	if hasInactiveSubsets(ud) {
+		return &subsetStateAllocator{UnitedDeployment: ud}
+	}
+	return newReplicaAllocator(ud)
+}
+
+func newReplicaAllocator(ud *appsv1alpha1.UnitedDeployment) ReplicaAllocator {
+	if ud.Spec.Topology.ScheduleStrategy.IsAdaptive() {
+		return &elasticAllocator{ud}
+	}
 	for _, subset := range ud.Spec.Topology.Subsets {
 		if subset.MinReplicas != nil || subset.MaxReplicas != nil {
 			return &elasticAllocator{ud}
@@ -250,20 +260,23 @@ type elasticAllocator struct {
 //     maxReplicas: nil  # will be satisfied with 4th priority
 //
 // the results of map will be: {"subset-a": 3, "subset-b": 2}
-func (ac *elasticAllocator) Alloc(_ *map[string]*Subset) (*map[string]int32, error) {
+//
+// For Adaptive schedule strategy, the maxReplicas of an unschedulable subset is limited to its replicas
+// that have been scheduled, so that its pending replicas are moved to the next subsets.
+func (ac *elasticAllocator) Alloc(nameToSubset *map[string]*Subset) (*map[string]int32, error) {
 	replicas := int32(1)
 	if ac.Spec.Replicas != nil {
 		replicas = *ac.Spec.Replicas
 	}
 
-	minReplicasMap, maxReplicasMap, err := ac.validateAndCalculateMinMaxMap(replicas)
+	minReplicasMap, maxReplicasMap, err := ac.validateAndCalculateMinMaxMap(replicas, nameToSubset)
 	if err != nil {
 		return nil, err
 	}
 	return ac.alloc(replicas, minReplicasMap, maxReplicasMap), nil
 }
 
-func (ac *elasticAllocator) validateAndCalculateMinMaxMap(replicas int32) (map[string]int32, map[string]int32, error) {
+func (ac *elasticAllocator) validateAndCalculateMinMaxMap(replicas int32, nameToSubset *map[string]*Subset) (map[string]int32, map[string]int32, error) {
 	totalMin, totalMax := int64(0), int64(0)
 	numSubset := len(ac.Spec.Topology.Subsets)
 	minReplicasMap := make(map[string]int32, numSubset)
@@ -280,12 +293,21 @@ func (ac *elasticAllocator) validateAndCalculateMinMaxMap(replicas int32) (map[s
 		if subset.MaxReplicas != nil {
 			maxReplicas, _ = ParseSubsetReplicas(replicas, *subset.MaxReplicas)
 		}
-		totalMax += int64(maxReplicas)
-		maxReplicasMap[subset.Name] = maxReplicas
 
 		if minReplicas > maxReplicas {
 			return nil, nil, fmt.Errorf("subset[%d].maxReplicas must be more than or equal to minReplicas", index)
 		}
+
+		if ac.Spec.Topology.ScheduleStrategy.IsAdaptive() && nameToSubset != nil {
+			if s, ok := (*nameToSubset)[subset.Name]; ok && s.Status.UnschedulableStatus.Unschedulable {
+				scheduledReplicas := integer.Int32Max(s.Spec.Replicas-s.Status.UnschedulableStatus.PendingPods, 0)
+				maxReplicas = integer.Int32Min(maxReplicas, scheduledReplicas)
+				minReplicas = integer.Int32Min(minReplicas, maxReplicas)
+				minReplicasMap[subset.Name] = minReplicas
+			}
+		}
+		totalMax += int64(maxReplicas)
+		maxReplicasMap[subset.Name] = maxReplicas
 	}
 	return minReplicasMap, maxReplicasMap, nil
 }
@@ -18,6 +18,7 @@ package uniteddeployment
 
 import (
 	"fmt"
+	"reflect"
 	"sort"
 	"testing"
 
@@ -307,6 +308,66 @@ func TestCapacityAllocator(t *testing.T) {
 	}
 }
 
+func TestAdaptiveAllocator(t *testing.T) {
+	ud := &appsv1alpha1.UnitedDeployment{}
+	ud.Spec.Replicas = pointer.Int32(6)
+	maxReplicas := intstr.FromInt(4)
+	ud.Spec.Topology.Subsets = []appsv1alpha1.Subset{
+		{Name: "subset-a", MaxReplicas: &maxReplicas},
+		{Name: "subset-b"},
+	}
+	ud.Spec.Topology.ScheduleStrategy.Type = appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType
+
+	newSubset := func(replicas int32, status SubsetUnschedulableStatus) *Subset {
+		subset := &Subset{}
+		subset.Spec.Replicas = replicas
+		subset.Status.UnschedulableStatus = status
+		return subset
+	}
+	cases := []struct {
+		name            string
+		nameToSubset    map[string]*Subset
+		desiredReplicas map[string]int32
+	}{
+		{
+			name: "all subsets schedulable",
+			nameToSubset: map[string]*Subset{
+				"subset-a": newSubset(4, SubsetUnschedulableStatus{}),
+				"subset-b": newSubset(2, SubsetUnschedulableStatus{}),
+			},
+			desiredReplicas: map[string]int32{"subset-a": 4, "subset-b": 2},
+		},
+		{
+			name: "pending pods moved to next subset",
+			nameToSubset: map[string]*Subset{
+				"subset-a": newSubset(4, SubsetUnschedulableStatus{Unschedulable: true, PendingPods: 3}),
+				"subset-b": newSubset(2, SubsetUnschedulableStatus{}),
+			},
+			desiredReplicas: map[string]int32{"subset-a": 1, "subset-b": 5},
+		},
+		{
+			name: "keep replicas while unschedulable",
+			nameToSubset: map[string]*Subset{
+				"subset-a": newSubset(1, SubsetUnschedulableStatus{Unschedulable: true}),
+				"subset-b": newSubset(5, SubsetUnschedulableStatus{}),
+			},
+			desiredReplicas: map[string]int32{"subset-a": 1, "subset-b": 5},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			result, err := NewReplicaAllocator(ud).Alloc(&cs.nameToSubset)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(*result, cs.desiredReplicas) {
+				t.Fatalf("expected %v, got %v", cs.desiredReplicas, *result)
+			}
+		})
+	}
+}
+
 func createSubset(name string, replicas int32) *nameToReplicas {
 	return &nameToReplicas{
 		Replicas:   replicas,
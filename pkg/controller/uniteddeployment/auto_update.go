@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"sort"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func isAutoUpdate(ud *appsv1alpha1.UnitedDeployment) bool {
+	return ud.Spec.UpdateStrategy.Type == appsv1alpha1.AutoUpdateStrategyType
+}
+
+// getAutoUpdateSubsetOrder returns the subset names in update order. Subsets with higher priority come first,
+// and the ones with the same priority keep the order in topology.
+func getAutoUpdateSubsetOrder(ud *appsv1alpha1.UnitedDeployment) []string {
+	var priorities map[string]int32
+	if ud.Spec.UpdateStrategy.AutoUpdate != nil {
+		priorities = ud.Spec.UpdateStrategy.AutoUpdate.Priorities
+	}
+
+	names := make([]string, 0, len(ud.Spec.Topology.Subsets))
+	for _, subset := range ud.Spec.Topology.Subsets {
+		names = append(names, subset.Name)
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return priorities[names[i]] > priorities[names[j]]
+	})
+	return names
+}
+
+func getAutoUpdateMaxParallelSubsets(ud *appsv1alpha1.UnitedDeployment) int {
+	if ud.Spec.UpdateStrategy.AutoUpdate == nil || ud.Spec.UpdateStrategy.AutoUpdate.MaxParallelSubsets == nil {
+		return 1
+	}
+	if maxParallel := int(*ud.Spec.UpdateStrategy.AutoUpdate.MaxParallelSubsets); maxParallel > 1 {
+		return maxParallel
+	}
+	return 1
+}
+
+// isSubsetUpdated returns true if all the expected replicas of the subset are updated and ready.
+// A subset not created yet is regarded as updated, because it will be created with the updated revision.
+func isSubsetUpdated(subset *Subset, replicas int32) bool {
+	if subset == nil {
+		return true
+	}
+	if subset.Status.ObservedGeneration < subset.Generation {
+		return false
+	}
+	return subset.Status.UpdatedReplicas >= replicas && subset.Status.UpdatedReadyReplicas >= replicas
+}
+
+// calcAutoUpdatePartitions calculates the partitions of subsets for Auto update strategy. The subsets are updated
+// in order, and at most maxParallelSubsets subsets are updating at the same time. The subsets waiting for update
+// keep all their replicas at the current revision by setting the partition to the replicas.
+func calcAutoUpdatePartitions(ud *appsv1alpha1.UnitedDeployment, nextReplicas *map[string]int32, nameToSubset *map[string]*Subset) map[string]int32 {
+	partitions := map[string]int32{}
+	maxParallel := getAutoUpdateMaxParallelSubsets(ud)
+	updating := 0
+	for _, name := range getAutoUpdateSubsetOrder(ud) {
+		replicas := (*nextReplicas)[name]
+		if isSubsetUpdated((*nameToSubset)[name], replicas) {
+			partitions[name] = 0
+			continue
+		}
+		if updating < maxParallel {
+			updating++
+			partitions[name] = 0
+			continue
+		}
+		partitions[name] = replicas
+	}
+	return partitions
+}
+
+// calcSubsetUpdateStatuses reports the update progress of each subset in update order for Auto update strategy.
+func calcSubsetUpdateStatuses(ud *appsv1alpha1.UnitedDeployment, nameToSubset *map[string]*Subset, nextReplicas, nextPartitions *map[string]int32) []appsv1alpha1.UnitedDeploymentSubsetUpdateStatus {
+	var statuses []appsv1alpha1.UnitedDeploymentSubsetUpdateStatus
+	for _, name := range getAutoUpdateSubsetOrder(ud) {
+		replicas := (*nextReplicas)[name]
+		status := appsv1alpha1.UnitedDeploymentSubsetUpdateStatus{Name: name, Replicas: replicas}
+		subset := (*nameToSubset)[name]
+		if subset != nil {
+			status.UpdatedReplicas = subset.Status.UpdatedReplicas
+			status.UpdatedReadyReplicas = subset.Status.UpdatedReadyReplicas
+		}
+
+		switch {
+		case isSubsetUpdated(subset, replicas):
+			status.Phase = appsv1alpha1.SubsetUpdatePhaseUpdated
+		case (*nextPartitions)[name] >= replicas:
+			status.Phase = appsv1alpha1.SubsetUpdatePhasePending
+		default:
+			status.Phase = appsv1alpha1.SubsetUpdatePhaseUpdating
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/utils/pointer"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func newAutoUpdateUnitedDeployment(maxParallel int32, priorities map[string]int32) *appsv1alpha1.UnitedDeployment {
+	return &appsv1alpha1.UnitedDeployment{
+		Spec: appsv1alpha1.UnitedDeploymentSpec{
+			Topology: appsv1alpha1.Topology{
+				Subsets: []appsv1alpha1.Subset{{Name: "subset-a"}, {Name: "subset-b"}, {Name: "subset-c"}},
+			},
+			UpdateStrategy: appsv1alpha1.UnitedDeploymentUpdateStrategy{
+				Type: appsv1alpha1.AutoUpdateStrategyType,
+				AutoUpdate: &appsv1alpha1.AutoUpdate{
+					MaxParallelSubsets: pointer.Int32(maxParallel),
+					Priorities:         priorities,
+				},
+			},
+		},
+	}
+}
+
+func newAutoUpdateSubset(updatedReplicas, updatedReadyReplicas int32) *Subset {
+	return &Subset{Status: SubsetStatus{UpdatedReplicas: updatedReplicas, UpdatedReadyReplicas: updatedReadyReplicas}}
+}
+
+func TestGetAutoUpdateSubsetOrder(t *testing.T) {
+	ud := newAutoUpdateUnitedDeployment(1, nil)
+	if order := getAutoUpdateSubsetOrder(ud); !reflect.DeepEqual(order, []string{"subset-a", "subset-b", "subset-c"}) {
+		t.Fatalf("unexpected order %v", order)
+	}
+
+	ud = newAutoUpdateUnitedDeployment(1, map[string]int32{"subset-c": 10, "subset-b": -1})
+	if order := getAutoUpdateSubsetOrder(ud); !reflect.DeepEqual(order, []string{"subset-c", "subset-a", "subset-b"}) {
+		t.Fatalf("unexpected order %v", order)
+	}
+}
+
+func TestCalcAutoUpdatePartitions(t *testing.T) {
+	nextReplicas := map[string]int32{"subset-a": 2, "subset-b": 3, "subset-c": 4}
+	cases := []struct {
+		name             string
+		ud               *appsv1alpha1.UnitedDeployment
+		nameToSubset     map[string]*Subset
+		expectPartitions map[string]int32
+		expectPhases     []appsv1alpha1.UnitedDeploymentSubsetUpdatePhase
+	}{
+		{
+			name: "update the first subset",
+			ud:   newAutoUpdateUnitedDeployment(1, nil),
+			nameToSubset: map[string]*Subset{
+				"subset-a": newAutoUpdateSubset(0, 0),
+				"subset-b": newAutoUpdateSubset(0, 0),
+				"subset-c": newAutoUpdateSubset(0, 0),
+			},
+			expectPartitions: map[string]int32{"subset-a": 0, "subset-b": 3, "subset-c": 4},
+			expectPhases: []appsv1alpha1.UnitedDeploymentSubsetUpdatePhase{
+				appsv1alpha1.SubsetUpdatePhaseUpdating, appsv1alpha1.SubsetUpdatePhasePending, appsv1alpha1.SubsetUpdatePhasePending,
+			},
+		},
+		{
+			name: "wait for the first subset to be ready",
+			ud:   newAutoUpdateUnitedDeployment(1, nil),
+			nameToSubset: map[string]*Subset{
+				"subset-a": newAutoUpdateSubset(2, 1),
+				"subset-b": newAutoUpdateSubset(0, 0),
+				"subset-c": newAutoUpdateSubset(0, 0),
+			},
+			expectPartitions: map[string]int32{"subset-a": 0, "subset-b": 3, "subset-c": 4},
+			expectPhases: []appsv1alpha1.UnitedDeploymentSubsetUpdatePhase{
+				appsv1alpha1.SubsetUpdatePhaseUpdating, appsv1alpha1.SubsetUpdatePhasePending, appsv1alpha1.SubsetUpdatePhasePending,
+			},
+		},
+		{
+			name: "move on to the next subset",
+			ud:   newAutoUpdateUnitedDeployment(1, nil),
+			nameToSubset: map[string]*Subset{
+				"subset-a": newAutoUpdateSubset(2, 2),
+				"subset-b": newAutoUpdateSubset(0, 0),
+				"subset-c": newAutoUpdateSubset(0, 0),
+			},
+			expectPartitions: map[string]int32{"subset-a": 0, "subset-b": 0, "subset-c": 4},
+			expectPhases: []appsv1alpha1.UnitedDeploymentSubsetUpdatePhase{
+				appsv1alpha1.SubsetUpdatePhaseUpdated, appsv1alpha1.SubsetUpdatePhaseUpdating, appsv1alpha1.SubsetUpdatePhasePending,
+			},
+		},
+		{
+			name: "update in parallel by priorities",
+			ud:   newAutoUpdateUnitedDeployment(2, map[string]int32{"subset-c": 1}),
+			nameToSubset: map[string]*Subset{
+				"subset-a": newAutoUpdateSubset(0, 0),
+				"subset-b": newAutoUpdateSubset(0, 0),
+				"subset-c": newAutoUpdateSubset(0, 0),
+			},
+			expectPartitions: map[string]int32{"subset-a": 0, "subset-b": 3, "subset-c": 0},
+			expectPhases: []appsv1alpha1.UnitedDeploymentSubsetUpdatePhase{
+				appsv1alpha1.SubsetUpdatePhaseUpdating, appsv1alpha1.SubsetUpdatePhaseUpdating, appsv1alpha1.SubsetUpdatePhasePending,
+			},
+		},
+		{
+			name: "subset not created yet",
+			ud:   newAutoUpdateUnitedDeployment(1, nil),
+			nameToSubset: map[string]*Subset{
+				"subset-b": newAutoUpdateSubset(0, 0),
+				"subset-c": newAutoUpdateSubset(0, 0),
+			},
+			expectPartitions: map[string]int32{"subset-a": 0, "subset-b": 0, "subset-c": 4},
+			expectPhases: []appsv1alpha1.UnitedDeploymentSubsetUpdatePhase{
+				appsv1alpha1.SubsetUpdatePhaseUpdated, appsv1alpha1.SubsetUpdatePhaseUpdating, appsv1alpha1.SubsetUpdatePhasePending,
+			},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			partitions := calcNextPartitions(cs.ud, &nextReplicas, &cs.nameToSubset)
+			if !reflect.DeepEqual(*partitions, cs.expectPartitions) {
+				t.Fatalf("expected partitions %v, got %v", cs.expectPartitions, *partitions)
+			}
+
+			statuses := calcSubsetUpdateStatuses(cs.ud, &cs.nameToSubset, &nextReplicas, partitions)
+			if len(statuses) != len(cs.expectPhases) {
+				t.Fatalf("expected %d subset update statuses, got %v", len(cs.expectPhases), statuses)
+			}
+			for i := range statuses {
+				if statuses[i].Phase != cs.expectPhases[i] {
+					t.Fatalf("expected phase %s of subset %s, got %s", cs.expectPhases[i], statuses[i].Name, statuses[i].Phase)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util/requeueduration"
+)
+
+var durationStore = requeueduration.DurationStore{}
+
+func getUnitedDeploymentKey(ud *appsv1alpha1.UnitedDeployment) string {
+	return client.ObjectKeyFromObject(ud).String()
+}
+
+// getSubsetUnschedulableStatus counts the pods of a subset that have been unschedulable for longer than
+// rescheduleCriticalSeconds. The subset containing such pods is unschedulable.
+func getSubsetUnschedulableStatus(ud *appsv1alpha1.UnitedDeployment, pods []*corev1.Pod) SubsetUnschedulableStatus {
+	status := SubsetUnschedulableStatus{}
+	for _, pod := range pods {
+		if podUnscheduledTimeout(ud, pod) {
+			status.PendingPods++
+		}
+	}
+	status.Unschedulable = status.PendingPods > 0
+	return status
+}
+
+// podUnscheduledTimeout returns true when the pod was scheduled failed and timeout.
+func podUnscheduledTimeout(ud *appsv1alpha1.UnitedDeployment, pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodPending || pod.Spec.NodeName != "" {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == corev1.PodReasonUnschedulable {
+			currentTime := time.Now()
+			expectSchedule := pod.CreationTimestamp.Add(ud.Spec.Topology.ScheduleStrategy.GetRescheduleCriticalDuration())
+			// schedule timeout
+			if expectSchedule.Before(currentTime) {
+				return true
+			}
+
+			// no timeout, requeue key when expectSchedule is equal to time.Now()
+			durationStore.Push(getUnitedDeploymentKey(ud), expectSchedule.Sub(currentTime))
+			return false
+		}
+	}
+	return false
+}
+
+// manageUnschedulableStatus calculates the subset statuses for the Adaptive schedule strategy.
+// A subset is marked unschedulable once its pods stay unschedulable for longer than rescheduleCriticalSeconds,
+// so that the allocator moves its pending replicas to the next subsets. The unschedulable status is kept for
+// unschedulableLastSeconds, and then the subset is recovered to be schedulable to try scheduling pods again.
+func (r *ReconcileUnitedDeployment) manageUnschedulableStatus(ud *appsv1alpha1.UnitedDeployment, nameToSubset *map[string]*Subset) []appsv1alpha1.UnitedDeploymentSubsetStatus {
+	if !ud.Spec.Topology.ScheduleStrategy.IsAdaptive() {
+		return nil
+	}
+
+	subsetStatuses := make([]appsv1alpha1.UnitedDeploymentSubsetStatus, 0, len(ud.Spec.Topology.Subsets))
+	for _, subsetDef := range ud.Spec.Topology.Subsets {
+		subsetStatus := appsv1alpha1.UnitedDeploymentSubsetStatus{Name: subsetDef.Name}
+		subset := (*nameToSubset)[subsetDef.Name]
+		if subset != nil {
+			subsetStatus.Replicas = subset.Status.Replicas
+			subsetStatus.ReadyReplicas = subset.Status.ReadyReplicas
+			subsetStatus.UnschedulablePods = subset.Status.UnschedulableStatus.PendingPods
+		}
+
+		oldCondition := GetUnitedDeploymentSubsetCondition(getUnitedDeploymentSubsetStatus(&ud.Status, subsetDef.Name), appsv1alpha1.UnitedDeploymentSubsetSchedulable)
+		if oldCondition != nil {
+			// copy old condition to avoid unnecessary update.
+			SetUnitedDeploymentSubsetCondition(&subsetStatus, oldCondition.DeepCopy())
+		}
+
+		switch {
+		case subset != nil && subset.Status.UnschedulableStatus.Unschedulable:
+			if oldCondition == nil || oldCondition.Status != corev1.ConditionFalse {
+				klog.V(3).Infof("Subset %s of UnitedDeployment %s/%s is unschedulable", subsetDef.Name, ud.Namespace, ud.Name)
+				r.recorder.Eventf(ud.DeepCopy(), corev1.EventTypeWarning, "SubsetUnschedulable",
+					"Subset %s has %d pods unschedulable, move its replicas to other subsets", subsetDef.Name, subset.Status.UnschedulableStatus.PendingPods)
+			}
+			SetUnitedDeploymentSubsetCondition(&subsetStatus, NewUnitedDeploymentSubsetCondition(appsv1alpha1.UnitedDeploymentSubsetSchedulable, corev1.ConditionFalse,
+				"PodsUnschedulable", fmt.Sprintf("%d pods have been unschedulable", subset.Status.UnschedulableStatus.PendingPods)))
+
+		case oldCondition != nil && oldCondition.Status == corev1.ConditionFalse:
+			// consider to recover
+			expectRecover := oldCondition.LastTransitionTime.Add(ud.Spec.Topology.ScheduleStrategy.GetUnschedulableLastDuration())
+			currentTime := time.Now()
+			if expectRecover.Before(currentTime) {
+				r.recorder.Eventf(ud.DeepCopy(), corev1.EventTypeNormal, "RecoverSchedulable",
+					"Subset %s is recovered from unschedulable to schedulable", subsetDef.Name)
+				SetUnitedDeploymentSubsetCondition(&subsetStatus, NewUnitedDeploymentSubsetCondition(appsv1alpha1.UnitedDeploymentSubsetSchedulable, corev1.ConditionTrue, "", ""))
+			} else {
+				// keep unschedulable until expectRecover
+				if subset != nil {
+					subset.Status.UnschedulableStatus.Unschedulable = true
+				}
+				durationStore.Push(getUnitedDeploymentKey(ud), expectRecover.Sub(currentTime))
+			}
+
+		default:
+			SetUnitedDeploymentSubsetCondition(&subsetStatus, NewUnitedDeploymentSubsetCondition(appsv1alpha1.UnitedDeploymentSubsetSchedulable, corev1.ConditionTrue, "", ""))
+		}
+		subsetStatuses = append(subsetStatuses, subsetStatus)
+	}
+	return subsetStatuses
+}
+
+func getUnitedDeploymentSubsetStatus(status *appsv1alpha1.UnitedDeploymentStatus, name string) *appsv1alpha1.UnitedDeploymentSubsetStatus {
+	for i := range status.SubsetStatuses {
+		if status.SubsetStatuses[i].Name == name {
+			return &status.SubsetStatuses[i]
+		}
+	}
+	return nil
+}
+
+// NewUnitedDeploymentSubsetCondition creates a new subset condition.
+func NewUnitedDeploymentSubsetCondition(condType appsv1alpha1.UnitedDeploymentSubsetConditionType, status corev1.ConditionStatus, reason, message string) *appsv1alpha1.UnitedDeploymentSubsetCondition {
+	return &appsv1alpha1.UnitedDeploymentSubsetCondition{
+		Type:               condType,
+		Status:             status,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// GetUnitedDeploymentSubsetCondition returns the subset condition with the provided type.
+func GetUnitedDeploymentSubsetCondition(status *appsv1alpha1.UnitedDeploymentSubsetStatus, condType appsv1alpha1.UnitedDeploymentSubsetConditionType) *appsv1alpha1.UnitedDeploymentSubsetCondition {
+	if status == nil {
+		return nil
+	}
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == condType {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetUnitedDeploymentSubsetCondition updates the subset status to include the provided condition. If the condition that
+// we are about to add already exists and has the same status and reason then we are not going to update.
+func SetUnitedDeploymentSubsetCondition(status *appsv1alpha1.UnitedDeploymentSubsetStatus, condition *appsv1alpha1.UnitedDeploymentSubsetCondition) {
+	currentCond := GetUnitedDeploymentSubsetCondition(status, condition.Type)
+	if currentCond != nil && currentCond.Status == condition.Status && currentCond.Reason == condition.Reason {
+		return
+	}
+
+	if currentCond != nil && currentCond.Status == condition.Status {
+		condition.LastTransitionTime = currentCond.LastTransitionTime
+	}
+	var newConditions []appsv1alpha1.UnitedDeploymentSubsetCondition
+	for _, c := range status.Conditions {
+		if c.Type != condition.Type {
+			newConditions = append(newConditions, c)
+		}
+	}
+	status.Conditions = append(newConditions, *condition)
+}
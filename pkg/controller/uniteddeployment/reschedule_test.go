@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func newUnschedulablePod(createdBefore time.Duration) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(time.Now().Add(-createdBefore))},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type:   corev1.PodScheduled,
+				Status: corev1.ConditionFalse,
+				Reason: corev1.PodReasonUnschedulable,
+			}},
+		},
+	}
+}
+
+func TestGetSubsetUnschedulableStatus(t *testing.T) {
+	ud := &appsv1alpha1.UnitedDeployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ud"}}
+	ud.Spec.Topology.ScheduleStrategy = appsv1alpha1.UnitedDeploymentScheduleStrategy{
+		Type:     appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType,
+		Adaptive: &appsv1alpha1.AdaptiveUnitedDeploymentStrategy{RescheduleCriticalSeconds: pointer.Int32(60)},
+	}
+	scheduled := &corev1.Pod{Spec: corev1.PodSpec{NodeName: "node"}, Status: corev1.PodStatus{Phase: corev1.PodRunning}}
+
+	status := getSubsetUnschedulableStatus(ud, []*corev1.Pod{scheduled, newUnschedulablePod(time.Second)})
+	if status.Unschedulable || status.PendingPods != 0 {
+		t.Fatalf("expected subset schedulable, got %+v", status)
+	}
+	if d := durationStore.Pop(getUnitedDeploymentKey(ud)); d <= 0 || d > time.Minute {
+		t.Fatalf("expected requeue before timeout, got %v", d)
+	}
+
+	status = getSubsetUnschedulableStatus(ud, []*corev1.Pod{scheduled, newUnschedulablePod(2 * time.Minute), newUnschedulablePod(3 * time.Minute)})
+	if !status.Unschedulable || status.PendingPods != 2 {
+		t.Fatalf("expected subset unschedulable with 2 pending pods, got %+v", status)
+	}
+}
+
+func TestManageUnschedulableStatus(t *testing.T) {
+	ud := &appsv1alpha1.UnitedDeployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ud"}}
+	ud.Spec.Topology.Subsets = []appsv1alpha1.Subset{{Name: "subset-a"}, {Name: "subset-b"}, {Name: "subset-c"}}
+	ud.Spec.Topology.ScheduleStrategy = appsv1alpha1.UnitedDeploymentScheduleStrategy{
+		Type:     appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType,
+		Adaptive: &appsv1alpha1.AdaptiveUnitedDeploymentStrategy{UnschedulableLastSeconds: pointer.Int32(300)},
+	}
+	unschedulableCondition := func(before time.Duration) []appsv1alpha1.UnitedDeploymentSubsetCondition {
+		return []appsv1alpha1.UnitedDeploymentSubsetCondition{{
+			Type:               appsv1alpha1.UnitedDeploymentSubsetSchedulable,
+			Status:             corev1.ConditionFalse,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-before)),
+		}}
+	}
+	ud.Status.SubsetStatuses = []appsv1alpha1.UnitedDeploymentSubsetStatus{
+		{Name: "subset-b", Conditions: unschedulableCondition(time.Minute)},
+		{Name: "subset-c", Conditions: unschedulableCondition(10 * time.Minute)},
+	}
+	nameToSubset := map[string]*Subset{"subset-a": {}, "subset-b": {}, "subset-c": {}}
+	nameToSubset["subset-a"].Status.UnschedulableStatus = SubsetUnschedulableStatus{Unschedulable: true, PendingPods: 1}
+
+	r := &ReconcileUnitedDeployment{recorder: record.NewFakeRecorder(10)}
+	subsetStatuses := r.manageUnschedulableStatus(ud, &nameToSubset)
+	if len(subsetStatuses) != 3 {
+		t.Fatalf("expected 3 subset statuses, got %v", subsetStatuses)
+	}
+	expected := map[string]corev1.ConditionStatus{"subset-a": corev1.ConditionFalse, "subset-b": corev1.ConditionFalse, "subset-c": corev1.ConditionTrue}
+	for i := range subsetStatuses {
+		condition := GetUnitedDeploymentSubsetCondition(&subsetStatuses[i], appsv1alpha1.UnitedDeploymentSubsetSchedulable)
+		if condition == nil || condition.Status != expected[subsetStatuses[i].Name] {
+			t.Fatalf("unexpected condition of %s: %v", subsetStatuses[i].Name, condition)
+		}
+	}
+	if !nameToSubset["subset-b"].Status.UnschedulableStatus.Unschedulable || nameToSubset["subset-c"].Status.UnschedulableStatus.Unschedulable {
+		t.Fatalf("expected only subset-b kept unschedulable")
+	}
+	if d := durationStore.Pop(getUnitedDeploymentKey(ud)); d <= 0 || d > 4*time.Minute {
+		t.Fatalf("expected requeue for recovery, got %v", d)
+	}
+}
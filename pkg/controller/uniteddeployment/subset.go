@@ -45,6 +45,15 @@ type SubsetStatus struct {
 	ReadyReplicas        int32
 	UpdatedReplicas      int32
 	UpdatedReadyReplicas int32
+	UnschedulableStatus  SubsetUnschedulableStatus
+}
+
+// SubsetUnschedulableStatus stores the unschedulable state of the Subset, which is only used for Adaptive schedule strategy.
+type SubsetUnschedulableStatus struct {
+	// Unschedulable is true if the Subset should not be allocated more replicas.
+	Unschedulable bool
+	// PendingPods is the number of pods that have been unschedulable for longer than rescheduleCriticalSeconds.
+	PendingPods int32
 }
 
 // SubsetUpdateStrategy stores the strategy detail of the Subset.
@@ -57,6 +66,7 @@ type SubsetUpdate struct {
 	Replicas  int32
 	Partition int32
 	Patch     string
+	Overrides string
 }
 
 // ResourceRef stores the Subset resource it represents.
@@ -68,7 +68,7 @@ func (m *SubsetControl) GetAllSubsets(ud *alpha1.UnitedDeployment, updatedRevisi
 	}
 
 	for _, claimedSet := range claimedSets {
-		subSet, err := m.convertToSubset(claimedSet, updatedRevision)
+		subSet, err := m.convertToSubset(ud, claimedSet, updatedRevision)
 		if err != nil {
 			return nil, err
 		}
@@ -112,6 +112,11 @@ func (m *SubsetControl) UpdateSubset(subset *Subset, ud *alpha1.UnitedDeployment
 		return updateError
 	}
 
+	if scalable, ok := m.adapter.(adapter.ScalableAdapter); ok {
+		if err := scalable.ScaleSubset(set, replicas); err != nil {
+			return err
+		}
+	}
 	return m.adapter.PostUpdate(ud, set, revision, partition)
 }
 
@@ -131,7 +136,7 @@ func (m *SubsetControl) IsExpected(subSet *Subset, revision string) bool {
 	return m.adapter.IsExpected(subSet.Spec.SubsetRef.Resources[0], revision)
 }
 
-func (m *SubsetControl) convertToSubset(set metav1.Object, updatedRevision string) (*Subset, error) {
+func (m *SubsetControl) convertToSubset(ud *alpha1.UnitedDeployment, set metav1.Object, updatedRevision string) (*Subset, error) {
 	subSetName, err := getSubsetNameFrom(set)
 	if err != nil {
 		return nil, err
@@ -175,6 +180,14 @@ func (m *SubsetControl) convertToSubset(set metav1.Object, updatedRevision strin
 	subset.Status.UpdatedReplicas = statusUpdatedReplicas
 	subset.Status.UpdatedReadyReplicas = statusUpdatedReadyReplicas
 
+	if ud.Spec.Topology.ScheduleStrategy.IsAdaptive() {
+		pods, err := m.adapter.GetSubsetPods(set)
+		if err != nil {
+			return subset, err
+		}
+		subset.Status.UnschedulableStatus = getSubsetUnschedulableStatus(ud, pods)
+	}
+
 	subset.Spec.SubsetRef.Resources = append(subset.Spec.SubsetRef.Resources, set)
 
 	return subset, nil
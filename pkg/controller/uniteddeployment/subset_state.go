@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/integer"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func hasInactiveSubsets(ud *appsv1alpha1.UnitedDeployment) bool {
+	for i := range ud.Spec.Topology.Subsets {
+		if !ud.Spec.Topology.Subsets[i].IsActive() {
+			return true
+		}
+	}
+	return false
+}
+
+// subsetStateAllocator allocates the replicas to the Active subsets only, by the allocator the UnitedDeployment
+// would use if there were only the Active subsets. If the replicas or maxReplicas of the Active subsets can not hold
+// all the replicas, the rest replicas are spread over the Active subsets beyond their limits.
+// Disabled subsets are allocated zero replicas. Draining subsets keep the replicas not yet ready in the Active subsets,
+// so that the ready replicas never drop below the target while the replicas are moved.
+type subsetStateAllocator struct {
+	*appsv1alpha1.UnitedDeployment
+
+	// exceededReplicas is the number of replicas allocated to the Active subsets beyond their limits.
+	exceededReplicas int32
+}
+
+func (s *subsetStateAllocator) Alloc(nameToSubset *map[string]*Subset) (*map[string]int32, error) {
+	activeUD := s.UnitedDeployment.DeepCopy()
+	activeUD.Spec.Topology.Subsets = nil
+	activeNameToSubset := map[string]*Subset{}
+	for _, subsetDef := range s.Spec.Topology.Subsets {
+		if !subsetDef.IsActive() {
+			continue
+		}
+		activeUD.Spec.Topology.Subsets = append(activeUD.Spec.Topology.Subsets, subsetDef)
+		if subset, ok := (*nameToSubset)[subsetDef.Name]; ok {
+			activeNameToSubset[subsetDef.Name] = subset
+		}
+	}
+
+	allocated := map[string]int32{}
+	s.exceededReplicas = 0
+	if len(activeUD.Spec.Topology.Subsets) > 0 {
+		if capacity, ok := getSpecifiedCapacity(activeUD); ok && s.Spec.Replicas != nil && capacity < *s.Spec.Replicas {
+			// all the Active subsets have specified replicas, allocate them as specified and spread the rest later
+			activeUD.Spec.Replicas = &capacity
+		}
+		next, err := newReplicaAllocator(activeUD).Alloc(&activeNameToSubset)
+		if err != nil {
+			return nil, err
+		}
+		allocated = *next
+		if s.Spec.Replicas != nil {
+			s.exceededReplicas = spreadReplicas(allocated, activeUD.Spec.Topology.Subsets, *s.Spec.Replicas)
+		}
+	}
+
+	// the replicas that should be kept by the draining subsets
+	var notReady int32
+	for name, replicas := range allocated {
+		var ready int32
+		if subset, ok := activeNameToSubset[name]; ok {
+			ready = integer.Int32Min(subset.Status.ReadyReplicas, replicas)
+		}
+		notReady += replicas - ready
+	}
+
+	for _, subsetDef := range s.Spec.Topology.Subsets {
+		switch subsetDef.State {
+		case appsv1alpha1.SubsetStateDisabled:
+			allocated[subsetDef.Name] = 0
+		case appsv1alpha1.SubsetStateDraining:
+			var current int32
+			if subset, ok := (*nameToSubset)[subsetDef.Name]; ok {
+				current = subset.Spec.Replicas
+			}
+			keep := integer.Int32Min(current, notReady)
+			notReady -= keep
+			allocated[subsetDef.Name] = keep
+		}
+	}
+
+	klog.V(4).Infof("UnitedDeployment %s/%s allocated replicas with subset states: %v", s.Namespace, s.Name, allocated)
+	return &allocated, nil
+}
+
+// getSpecifiedCapacity returns the sum of the specified replicas of subsets, if the replicas of all the subsets
+// are specified and the UnitedDeployment is allocated by the specified replicas.
+func getSpecifiedCapacity(ud *appsv1alpha1.UnitedDeployment) (int32, bool) {
+	if _, ok := newReplicaAllocator(ud).(*specificAllocator); !ok || ud.Spec.Replicas == nil {
+		return 0, false
+	}
+	specified := getSpecifiedSubsetReplicas(*ud.Spec.Replicas, ud)
+	if len(*specified) != len(ud.Spec.Topology.Subsets) {
+		return 0, false
+	}
+	var capacity int32
+	for _, replicas := range *specified {
+		capacity += replicas
+	}
+	return capacity, true
+}
+
+// spreadReplicas spreads the replicas not yet allocated evenly over the subsets, ignoring their limits,
+// and returns the number of the spread replicas.
+func spreadReplicas(allocated map[string]int32, subsets []appsv1alpha1.Subset, replicas int32) int32 {
+	left := replicas
+	for _, subset := range subsets {
+		left -= allocated[subset.Name]
+	}
+	if left <= 0 {
+		return 0
+	}
+	average, remainder := left/int32(len(subsets)), left%int32(len(subsets))
+	for i, subset := range subsets {
+		allocated[subset.Name] += average
+		if int32(i) < remainder {
+			allocated[subset.Name]++
+		}
+	}
+	return left
+}
+
+// manageSubsetStates records the state of each subset in subset statuses, and reports the drain progress
+// of the Draining subsets by the Drained condition.
+func (r *ReconcileUnitedDeployment) manageSubsetStates(ud *appsv1alpha1.UnitedDeployment, nameToSubset *map[string]*Subset, subsetStatuses []appsv1alpha1.UnitedDeploymentSubsetStatus) []appsv1alpha1.UnitedDeploymentSubsetStatus {
+	if !hasInactiveSubsets(ud) {
+		return subsetStatuses
+	}
+
+	if len(subsetStatuses) != len(ud.Spec.Topology.Subsets) {
+		subsetStatuses = make([]appsv1alpha1.UnitedDeploymentSubsetStatus, 0, len(ud.Spec.Topology.Subsets))
+		for _, subsetDef := range ud.Spec.Topology.Subsets {
+			subsetStatus := appsv1alpha1.UnitedDeploymentSubsetStatus{Name: subsetDef.Name}
+			if subset := (*nameToSubset)[subsetDef.Name]; subset != nil {
+				subsetStatus.Replicas = subset.Status.Replicas
+				subsetStatus.ReadyReplicas = subset.Status.ReadyReplicas
+			}
+			subsetStatuses = append(subsetStatuses, subsetStatus)
+		}
+	}
+
+	for i, subsetDef := range ud.Spec.Topology.Subsets {
+		subsetStatus := &subsetStatuses[i]
+		subsetStatus.State = subsetDef.State
+		if subsetStatus.State == "" {
+			subsetStatus.State = appsv1alpha1.SubsetStateActive
+		}
+		if subsetDef.State != appsv1alpha1.SubsetStateDraining {
+			continue
+		}
+
+		oldCondition := GetUnitedDeploymentSubsetCondition(getUnitedDeploymentSubsetStatus(&ud.Status, subsetDef.Name), appsv1alpha1.UnitedDeploymentSubsetDrained)
+		if oldCondition != nil {
+			// copy old condition to avoid unnecessary update.
+			SetUnitedDeploymentSubsetCondition(subsetStatus, oldCondition.DeepCopy())
+		}
+
+		var leftReplicas int32
+		if subset := (*nameToSubset)[subsetDef.Name]; subset != nil {
+			leftReplicas = integer.Int32Max(subset.Spec.Replicas, subset.Status.Replicas)
+		}
+		if leftReplicas > 0 {
+			SetUnitedDeploymentSubsetCondition(subsetStatus, NewUnitedDeploymentSubsetCondition(appsv1alpha1.UnitedDeploymentSubsetDrained, corev1.ConditionFalse,
+				"Draining", fmt.Sprintf("%d replicas left", leftReplicas)))
+			continue
+		}
+
+		if oldCondition == nil || oldCondition.Status != corev1.ConditionTrue {
+			r.recorder.Eventf(ud.DeepCopy(), corev1.EventTypeNormal, "SubsetDrained", "Subset %s has been drained", subsetDef.Name)
+		}
+		SetUnitedDeploymentSubsetCondition(subsetStatus, NewUnitedDeploymentSubsetCondition(appsv1alpha1.UnitedDeploymentSubsetDrained, corev1.ConditionTrue, "", ""))
+	}
+	return subsetStatuses
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uniteddeployment
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func newSubsetStateUnitedDeployment(states ...appsv1alpha1.SubsetState) *appsv1alpha1.UnitedDeployment {
+	ud := &appsv1alpha1.UnitedDeployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ud"}}
+	ud.Spec.Replicas = pointer.Int32(6)
+	names := []string{"subset-a", "subset-b", "subset-c"}
+	for i, state := range states {
+		ud.Spec.Topology.Subsets = append(ud.Spec.Topology.Subsets, appsv1alpha1.Subset{Name: names[i], State: state})
+	}
+	return ud
+}
+
+func newSubsetWithReplicas(specReplicas, readyReplicas int32) *Subset {
+	subset := &Subset{}
+	subset.Spec.Replicas = specReplicas
+	subset.Status.Replicas = specReplicas
+	subset.Status.ReadyReplicas = readyReplicas
+	return subset
+}
+
+func TestSubsetStateAllocator(t *testing.T) {
+	cases := []struct {
+		name         string
+		ud           *appsv1alpha1.UnitedDeployment
+		nameToSubset map[string]*Subset
+		expected     map[string]int32
+	}{
+		{
+			name: "disabled subset",
+			ud:   newSubsetStateUnitedDeployment("", appsv1alpha1.SubsetStateActive, appsv1alpha1.SubsetStateDisabled),
+			nameToSubset: map[string]*Subset{
+				"subset-a": newSubsetWithReplicas(2, 2),
+				"subset-b": newSubsetWithReplicas(2, 2),
+				"subset-c": newSubsetWithReplicas(2, 2),
+			},
+			expected: map[string]int32{"subset-a": 3, "subset-b": 3, "subset-c": 0},
+		},
+		{
+			name: "draining subset surges first",
+			ud:   newSubsetStateUnitedDeployment("", "", appsv1alpha1.SubsetStateDraining),
+			nameToSubset: map[string]*Subset{
+				"subset-a": newSubsetWithReplicas(2, 2),
+				"subset-b": newSubsetWithReplicas(2, 2),
+				"subset-c": newSubsetWithReplicas(2, 2),
+			},
+			expected: map[string]int32{"subset-a": 3, "subset-b": 3, "subset-c": 2},
+		},
+		{
+			name: "draining subset scales in as replicas get ready",
+			ud:   newSubsetStateUnitedDeployment("", "", appsv1alpha1.SubsetStateDraining),
+			nameToSubset: map[string]*Subset{
+				"subset-a": newSubsetWithReplicas(3, 3),
+				"subset-b": newSubsetWithReplicas(3, 2),
+				"subset-c": newSubsetWithReplicas(2, 2),
+			},
+			expected: map[string]int32{"subset-a": 3, "subset-b": 3, "subset-c": 1},
+		},
+		{
+			name: "draining subset drained",
+			ud:   newSubsetStateUnitedDeployment("", "", appsv1alpha1.SubsetStateDraining),
+			nameToSubset: map[string]*Subset{
+				"subset-a": newSubsetWithReplicas(3, 3),
+				"subset-b": newSubsetWithReplicas(3, 3),
+				"subset-c": newSubsetWithReplicas(1, 1),
+			},
+			expected: map[string]int32{"subset-a": 3, "subset-b": 3, "subset-c": 0},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			allocator := NewReplicaAllocator(cs.ud)
+			if _, ok := allocator.(*subsetStateAllocator); !ok {
+				t.Fatalf("expected subsetStateAllocator, got %T", allocator)
+			}
+			result, err := allocator.Alloc(&cs.nameToSubset)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(*result, cs.expected) {
+				t.Fatalf("expected %v, got %v", cs.expected, *result)
+			}
+		})
+	}
+}
+
+func TestSubsetStateAllocatorBeyondLimits(t *testing.T) {
+	specifiedUD := newSubsetStateUnitedDeployment("", appsv1alpha1.SubsetStateDraining)
+	specifiedUD.Spec.Replicas = pointer.Int32(5)
+	specifiedUD.Spec.Topology.Subsets[0].Replicas = &intstr.IntOrString{Type: intstr.Int, IntVal: 3}
+	specifiedUD.Spec.Topology.Subsets[1].Replicas = &intstr.IntOrString{Type: intstr.Int, IntVal: 2}
+
+	cappedUD := newSubsetStateUnitedDeployment("", "", appsv1alpha1.SubsetStateDraining)
+	cappedUD.Spec.Replicas = pointer.Int32(7)
+	cappedUD.Spec.Topology.Subsets[0].MaxReplicas = &intstr.IntOrString{Type: intstr.Int, IntVal: 2}
+	cappedUD.Spec.Topology.Subsets[1].MaxReplicas = &intstr.IntOrString{Type: intstr.Int, IntVal: 1}
+
+	cases := []struct {
+		name             string
+		ud               *appsv1alpha1.UnitedDeployment
+		nameToSubset     map[string]*Subset
+		expected         map[string]int32
+		expectedExceeded int32
+	}{
+		{
+			name: "all active subsets have specified replicas",
+			ud:   specifiedUD,
+			nameToSubset: map[string]*Subset{
+				"subset-a": newSubsetWithReplicas(3, 3),
+				"subset-b": newSubsetWithReplicas(2, 2),
+			},
+			expected:         map[string]int32{"subset-a": 5, "subset-b": 2},
+			expectedExceeded: 2,
+		},
+		{
+			name: "active subsets are capped by maxReplicas",
+			ud:   cappedUD,
+			nameToSubset: map[string]*Subset{
+				"subset-a": newSubsetWithReplicas(2, 2),
+				"subset-b": newSubsetWithReplicas(1, 1),
+				"subset-c": newSubsetWithReplicas(4, 4),
+			},
+			expected:         map[string]int32{"subset-a": 4, "subset-b": 3, "subset-c": 4},
+			expectedExceeded: 4,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			allocator := NewReplicaAllocator(cs.ud)
+			if _, ok := allocator.(*subsetStateAllocator); !ok {
+				t.Fatalf("expected subsetStateAllocator, got %T", allocator)
+			}
+			result, err := allocator.Alloc(&cs.nameToSubset)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(*result, cs.expected) {
+				t.Fatalf("expected %v, got %v", cs.expected, *result)
+			}
+			if exceeded := allocator.(*subsetStateAllocator).exceededReplicas; exceeded != cs.expectedExceeded {
+				t.Fatalf("expected %d exceeded replicas, got %d", cs.expectedExceeded, exceeded)
+			}
+		})
+	}
+}
+
+func TestManageSubsetStates(t *testing.T) {
+	ud := newSubsetStateUnitedDeployment("", appsv1alpha1.SubsetStateDraining, appsv1alpha1.SubsetStateDraining)
+	nameToSubset := map[string]*Subset{
+		"subset-a": newSubsetWithReplicas(6, 6),
+		"subset-b": newSubsetWithReplicas(0, 0),
+		"subset-c": newSubsetWithReplicas(2, 2),
+	}
+
+	r := &ReconcileUnitedDeployment{recorder: record.NewFakeRecorder(10)}
+	subsetStatuses := r.manageSubsetStates(ud, &nameToSubset, nil)
+	if len(subsetStatuses) != 3 {
+		t.Fatalf("expected 3 subset statuses, got %v", subsetStatuses)
+	}
+	if subsetStatuses[0].State != appsv1alpha1.SubsetStateActive || len(subsetStatuses[0].Conditions) != 0 {
+		t.Fatalf("unexpected status of subset-a: %v", subsetStatuses[0])
+	}
+	expected := map[string]corev1.ConditionStatus{"subset-b": corev1.ConditionTrue, "subset-c": corev1.ConditionFalse}
+	for _, subsetStatus := range subsetStatuses[1:] {
+		condition := GetUnitedDeploymentSubsetCondition(&subsetStatus, appsv1alpha1.UnitedDeploymentSubsetDrained)
+		if subsetStatus.State != appsv1alpha1.SubsetStateDraining || condition == nil || condition.Status != expected[subsetStatus.Name] {
+			t.Fatalf("unexpected status of %s: %v", subsetStatus.Name, subsetStatus)
+		}
+	}
+
+	ud.Spec.Topology.Subsets[1].State = appsv1alpha1.SubsetStateActive
+	ud.Spec.Topology.Subsets[2].State = appsv1alpha1.SubsetStateActive
+	if subsetStatuses = r.manageSubsetStates(ud, &nameToSubset, nil); subsetStatuses != nil {
+		t.Fatalf("expected no subset statuses when all subsets are active, got %v", subsetStatuses)
+	}
+}
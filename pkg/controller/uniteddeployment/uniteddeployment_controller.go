@@ -26,6 +26,12 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	scaleclient "k8s.io/client-go/scale"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -38,8 +44,10 @@ import (
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
 	"github.com/openkruise/kruise/pkg/controller/uniteddeployment/adapter"
+	ctrlUtil "github.com/openkruise/kruise/pkg/controller/util"
 	"github.com/openkruise/kruise/pkg/util"
 	utilclient "github.com/openkruise/kruise/pkg/util/client"
+	"github.com/openkruise/kruise/pkg/util/configuration"
 	utildiscovery "github.com/openkruise/kruise/pkg/util/discovery"
 	"github.com/openkruise/kruise/pkg/util/ratelimiter"
 )
@@ -86,18 +94,54 @@ func Add(mgr manager.Manager) error {
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 	cli := utilclient.NewClientFromManager(mgr, "uniteddeployment-controller")
+	subSetControls := map[subSetType]ControlInterface{
+		statefulSetSubSetType:         &SubsetControl{Client: cli, scheme: mgr.GetScheme(), adapter: &adapter.StatefulSetAdapter{Client: cli, Scheme: mgr.GetScheme()}},
+		advancedStatefulSetSubSetType: &SubsetControl{Client: cli, scheme: mgr.GetScheme(), adapter: &adapter.AdvancedStatefulSetAdapter{Client: cli, Scheme: mgr.GetScheme()}},
+		cloneSetSubSetType:            &SubsetControl{Client: cli, scheme: mgr.GetScheme(), adapter: &adapter.CloneSetAdapter{Client: cli, Scheme: mgr.GetScheme()}},
+		deploymentSubSetType:          &SubsetControl{Client: cli, scheme: mgr.GetScheme(), adapter: &adapter.DeploymentAdapter{Client: cli, Scheme: mgr.GetScheme()}},
+	}
+	// The custom workloads are registered only once when kruise-manager starts, the same as their watches,
+	// so that the subsets of them can be cleaned when the subset type of UnitedDeployment is changed.
+	whiteList, err := configuration.GetUDCustomWorkloadWhiteList(mgr.GetClient())
+	if err != nil {
+		klog.Errorf("Failed to get custom workload white list of UnitedDeployment: %v", err)
+	} else if len(whiteList.Workloads) > 0 {
+		scaleClient, err := newScaleClient(mgr)
+		if err != nil {
+			klog.Errorf("Failed to create scale client for custom workloads of UnitedDeployment: %v", err)
+		}
+		for _, workload := range whiteList.Workloads {
+			subSetControls[subSetType(workload.GroupVersionKind.String())] = &SubsetControl{Client: cli, scheme: mgr.GetScheme(),
+				adapter: &adapter.CustomWorkloadAdapter{Client: cli, Scheme: mgr.GetScheme(), Workload: workload,
+					Mapper: mgr.GetRESTMapper(), ScaleClient: scaleClient}}
+		}
+	}
 	return &ReconcileUnitedDeployment{
 		Client: cli,
 		scheme: mgr.GetScheme(),
 
-		recorder: mgr.GetEventRecorderFor(controllerName),
-		subSetControls: map[subSetType]ControlInterface{
-			statefulSetSubSetType:         &SubsetControl{Client: cli, scheme: mgr.GetScheme(), adapter: &adapter.StatefulSetAdapter{Client: cli, Scheme: mgr.GetScheme()}},
-			advancedStatefulSetSubSetType: &SubsetControl{Client: cli, scheme: mgr.GetScheme(), adapter: &adapter.AdvancedStatefulSetAdapter{Client: cli, Scheme: mgr.GetScheme()}},
-			cloneSetSubSetType:            &SubsetControl{Client: cli, scheme: mgr.GetScheme(), adapter: &adapter.CloneSetAdapter{Client: cli, Scheme: mgr.GetScheme()}},
-			deploymentSubSetType:          &SubsetControl{Client: cli, scheme: mgr.GetScheme(), adapter: &adapter.DeploymentAdapter{Client: cli, Scheme: mgr.GetScheme()}},
-		},
+		recorder:       mgr.GetEventRecorderFor(controllerName),
+		subSetControls: subSetControls,
+	}
+}
+
+// newScaleClient returns the client of scale subresources, which is used to scale the custom workloads.
+func newScaleClient(mgr manager.Manager) (scaleclient.ScalesGetter, error) {
+	cfg := rest.CopyConfig(mgr.GetConfig())
+	if cfg.GroupVersion == nil {
+		cfg.GroupVersion = &schema.GroupVersion{}
 	}
+	cfg.NegotiatedSerializer = serializer.NewCodecFactory(mgr.GetScheme()).WithoutConversion()
+	restClient, err := rest.RESTClientFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	scaleKindResolver := scaleclient.NewDiscoveryScaleKindResolver(discoveryClient)
+	return scaleclient.New(restClient, mgr.GetRESTMapper(), dynamic.LegacyAPIPathResolverFunc, scaleKindResolver), nil
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -148,6 +192,21 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for changes to the custom workloads in white list
+	whiteList, err := configuration.GetUDCustomWorkloadWhiteList(mgr.GetClient())
+	if err != nil {
+		return err
+	}
+	for _, workload := range whiteList.Workloads {
+		workloadHandler := &handler.EnqueueRequestForOwner{
+			IsController: true,
+			OwnerType:    &appsv1alpha1.UnitedDeployment{},
+		}
+		if _, err := ctrlUtil.AddWatcherDynamically(c, workloadHandler, workload.GroupVersionKind, "UnitedDeployment"); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -198,7 +257,12 @@ func (r *ReconcileUnitedDeployment) Reconcile(_ context.Context, request reconci
 		return reconcile.Result{}, err
 	}
 
-	control, subsetType := r.getSubsetControls(instance)
+	control, subsetType, err := r.getSubsetControls(instance)
+	if err != nil {
+		klog.Errorf("Fail to get subset control of UnitedDeployment %s/%s: %s", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypeFindSubsets), err.Error())
+		return reconcile.Result{}, err
+	}
 
 	klog.V(4).Infof("Get UnitedDeployment %s/%s all subsets", request.Namespace, request.Name)
 	expectedRevision := currentRevision.Name
@@ -213,7 +277,10 @@ func (r *ReconcileUnitedDeployment) Reconcile(_ context.Context, request reconci
 		return reconcile.Result{}, err
 	}
 
-	nextReplicas, err := NewReplicaAllocator(instance).Alloc(nameToSubset)
+	subsetStatuses := r.manageUnschedulableStatus(instance, nameToSubset)
+	subsetStatuses = r.manageSubsetStates(instance, nameToSubset, subsetStatuses)
+	allocator := NewReplicaAllocator(instance)
+	nextReplicas, err := allocator.Alloc(nameToSubset)
 	klog.V(4).Infof("Get UnitedDeployment %s/%s next replicas %v", instance.Namespace, instance.Name, nextReplicas)
 	if err != nil {
 		klog.Errorf("UnitedDeployment %s/%s Specified subset replicas is ineffective: %s",
@@ -222,12 +289,16 @@ func (r *ReconcileUnitedDeployment) Reconcile(_ context.Context, request reconci
 			eventTypeSpecifySubbsetReplicas), "Specified subset replicas is ineffective: %s", err.Error())
 		return reconcile.Result{}, err
 	}
+	if stateAllocator, ok := allocator.(*subsetStateAllocator); ok && stateAllocator.exceededReplicas > 0 {
+		r.recorder.Eventf(instance.DeepCopy(), corev1.EventTypeWarning, "SubsetLimitsExceeded",
+			"%d replicas are allocated to Active subsets beyond their replicas limits", stateAllocator.exceededReplicas)
+	}
 
-	nextPartitions := calcNextPartitions(instance, nextReplicas)
+	nextPartitions := calcNextPartitions(instance, nextReplicas, nameToSubset)
 	nextUpdate := getNextUpdate(instance, nextReplicas, nextPartitions)
 	klog.V(4).Infof("Get UnitedDeployment %s/%s next update %v", instance.Namespace, instance.Name, nextUpdate)
 
-	newStatus, err := r.manageSubsets(instance, nameToSubset, nextUpdate, currentRevision, updatedRevision, subsetType)
+	newStatus, err := r.manageSubsets(instance, nameToSubset, nextUpdate, currentRevision, updatedRevision, control, subsetType)
 	if err != nil {
 		klog.Errorf("Fail to update UnitedDeployment %s/%s: %s", instance.Namespace, instance.Name, err)
 		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypeSubsetsUpdate), err.Error())
@@ -241,8 +312,10 @@ func (r *ReconcileUnitedDeployment) Reconcile(_ context.Context, request reconci
 		return reconcile.Result{}, nil
 	}
 	newStatus.LabelSelector = selector.String()
+	newStatus.SubsetStatuses = subsetStatuses
 
-	return r.updateStatus(instance, newStatus, oldStatus, nameToSubset, nextReplicas, nextPartitions, currentRevision, updatedRevision, collisionCount, control)
+	_, err = r.updateStatus(instance, newStatus, oldStatus, nameToSubset, nextReplicas, nextPartitions, currentRevision, updatedRevision, collisionCount, control)
+	return reconcile.Result{RequeueAfter: durationStore.Pop(getUnitedDeploymentKey(instance))}, err
 }
 
 func (r *ReconcileUnitedDeployment) getNameToSubset(instance *appsv1alpha1.UnitedDeployment, control ControlInterface, expectedRevision string) (*map[string]*Subset, error) {
@@ -264,7 +337,12 @@ func (r *ReconcileUnitedDeployment) getNameToSubset(instance *appsv1alpha1.Unite
 	return nameToSubset, nil
 }
 
-func calcNextPartitions(ud *appsv1alpha1.UnitedDeployment, nextReplicas *map[string]int32) *map[string]int32 {
+func calcNextPartitions(ud *appsv1alpha1.UnitedDeployment, nextReplicas *map[string]int32, nameToSubset *map[string]*Subset) *map[string]int32 {
+	if isAutoUpdate(ud) {
+		partitions := calcAutoUpdatePartitions(ud, nextReplicas, nameToSubset)
+		return &partitions
+	}
+
 	partitions := map[string]int32{}
 	for _, subset := range ud.Spec.Topology.Subsets {
 		var subsetPartition int32
@@ -291,6 +369,7 @@ func getNextUpdate(ud *appsv1alpha1.UnitedDeployment, nextReplicas *map[string]i
 		t.Replicas = (*nextReplicas)[subset.Name]
 		t.Partition = (*nextPartitions)[subset.Name]
 		t.Patch = string(subset.Patch.Raw)
+		t.Overrides = adapter.GetSubsetOverridesAnnotation(&subset)
 
 		next[subset.Name] = t
 	}
@@ -321,25 +400,34 @@ func (r *ReconcileUnitedDeployment) deleteDupSubset(ud *appsv1alpha1.UnitedDeplo
 	return &nameToSubset, nil
 }
 
-func (r *ReconcileUnitedDeployment) getSubsetControls(instance *appsv1alpha1.UnitedDeployment) (ControlInterface, subSetType) {
+func (r *ReconcileUnitedDeployment) getSubsetControls(instance *appsv1alpha1.UnitedDeployment) (ControlInterface, subSetType, error) {
 	if instance.Spec.Template.StatefulSetTemplate != nil {
-		return r.subSetControls[statefulSetSubSetType], statefulSetSubSetType
+		return r.subSetControls[statefulSetSubSetType], statefulSetSubSetType, nil
 	}
 
 	if instance.Spec.Template.AdvancedStatefulSetTemplate != nil {
-		return r.subSetControls[advancedStatefulSetSubSetType], advancedStatefulSetSubSetType
+		return r.subSetControls[advancedStatefulSetSubSetType], advancedStatefulSetSubSetType, nil
 	}
 
 	if instance.Spec.Template.CloneSetTemplate != nil {
-		return r.subSetControls[cloneSetSubSetType], cloneSetSubSetType
+		return r.subSetControls[cloneSetSubSetType], cloneSetSubSetType, nil
 	}
 
 	if instance.Spec.Template.DeploymentTemplate != nil {
-		return r.subSetControls[deploymentSubSetType], deploymentSubSetType
+		return r.subSetControls[deploymentSubSetType], deploymentSubSetType, nil
+	}
+
+	if template := instance.Spec.Template.CustomWorkloadTemplate; template != nil {
+		t := subSetType(schema.FromAPIVersionAndKind(template.APIVersion, template.Kind).String())
+		control, ok := r.subSetControls[t]
+		if !ok {
+			return nil, "", fmt.Errorf("custom workload %s was not in the white list of UnitedDeployment when kruise-manager started", t)
+		}
+		return control, t, nil
 	}
 
 	// unexpected
-	return nil, statefulSetSubSetType
+	return nil, "", fmt.Errorf("no subset template found")
 }
 
 func (r *ReconcileUnitedDeployment) classifySubsetBySubsetName(ud *appsv1alpha1.UnitedDeployment, subsets []*Subset) map[string][]*Subset {
@@ -358,12 +446,12 @@ func (r *ReconcileUnitedDeployment) classifySubsetBySubsetName(ud *appsv1alpha1.
 }
 
 func (r *ReconcileUnitedDeployment) updateStatus(instance *appsv1alpha1.UnitedDeployment, newStatus, oldStatus *appsv1alpha1.UnitedDeploymentStatus, nameToSubset *map[string]*Subset, nextReplicas, nextPartition *map[string]int32, currentRevision, updatedRevision *appsv1.ControllerRevision, collisionCount int32, control ControlInterface) (reconcile.Result, error) {
-	newStatus = r.calculateStatus(newStatus, nameToSubset, nextReplicas, nextPartition, currentRevision, updatedRevision, collisionCount, control)
+	newStatus = r.calculateStatus(instance, newStatus, nameToSubset, nextReplicas, nextPartition, currentRevision, updatedRevision, collisionCount, control)
 	_, err := r.updateUnitedDeployment(instance, oldStatus, newStatus)
 	return reconcile.Result{}, err
 }
 
-func (r *ReconcileUnitedDeployment) calculateStatus(newStatus *appsv1alpha1.UnitedDeploymentStatus, nameToSubset *map[string]*Subset, nextReplicas, nextPartition *map[string]int32, currentRevision, updatedRevision *appsv1.ControllerRevision, collisionCount int32, control ControlInterface) *appsv1alpha1.UnitedDeploymentStatus {
+func (r *ReconcileUnitedDeployment) calculateStatus(instance *appsv1alpha1.UnitedDeployment, newStatus *appsv1alpha1.UnitedDeploymentStatus, nameToSubset *map[string]*Subset, nextReplicas, nextPartition *map[string]int32, currentRevision, updatedRevision *appsv1.ControllerRevision, collisionCount int32, control ControlInterface) *appsv1alpha1.UnitedDeploymentStatus {
 	expectedRevision := currentRevision.Name
 	if updatedRevision != nil {
 		expectedRevision = updatedRevision.Name
@@ -396,6 +484,11 @@ func (r *ReconcileUnitedDeployment) calculateStatus(newStatus *appsv1alpha1.Unit
 
 	newStatus.UpdateStatus.UpdatedRevision = expectedRevision
 	newStatus.UpdateStatus.CurrentPartitions = *nextPartition
+	if isAutoUpdate(instance) {
+		newStatus.UpdateStatus.SubsetUpdateStatuses = calcSubsetUpdateStatuses(instance, nameToSubset, nextReplicas, nextPartition)
+	} else {
+		newStatus.UpdateStatus.SubsetUpdateStatuses = nil
+	}
 
 	if newStatus.UpdateStatus.UpdatedRevision != newStatus.CurrentRevision && newStatus.UpdatedReadyReplicas >= newStatus.Replicas {
 		newStatus.CurrentRevision = newStatus.UpdateStatus.UpdatedRevision
@@ -440,7 +533,8 @@ func (r *ReconcileUnitedDeployment) updateUnitedDeployment(ud *appsv1alpha1.Unit
 		ud.Generation == newStatus.ObservedGeneration &&
 		reflect.DeepEqual(oldStatus.SubsetReplicas, newStatus.SubsetReplicas) &&
 		reflect.DeepEqual(oldStatus.UpdateStatus, newStatus.UpdateStatus) &&
-		reflect.DeepEqual(oldStatus.Conditions, newStatus.Conditions) {
+		reflect.DeepEqual(oldStatus.Conditions, newStatus.Conditions) &&
+		reflect.DeepEqual(oldStatus.SubsetStatuses, newStatus.SubsetStatuses) {
 		return ud, nil
 	}
 
@@ -0,0 +1,233 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadspread
+
+import (
+	"context"
+	"math"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	kubecontroller "k8s.io/kubernetes/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	wsutil "github.com/openkruise/kruise/pkg/util/workloadspread"
+)
+
+const (
+	defaultRebalanceMaxDeletionsPerInterval = 1
+	defaultRebalanceIntervalSeconds         = 60
+)
+
+// calculateRebalance sets the rebalance status and returns the Pods to delete in this round, which are
+// the least healthy Pods that do not match any subset or live in the most over-full subsets.
+// Pods are only deleted when:
+//  1. the interval since last rebalance has passed;
+//  2. no Pod creation or deletion recorded by webhook is in progress, i.e., the last round has settled;
+//  3. the under-filled subsets have room for the recreated Pods.
+func (r *ReconcileWorkloadSpread) calculateRebalance(ws *appsv1alpha1.WorkloadSpread, status *appsv1alpha1.WorkloadSpreadStatus,
+	subsetPodMap map[string][]*corev1.Pod, workloadReplicas int32) []*corev1.Pod {
+	strategy := ws.Spec.RebalanceStrategy
+	if strategy == nil {
+		status.RebalanceStatus = nil
+		return nil
+	}
+	if len(status.SubsetStatuses) != len(ws.Spec.Subsets) {
+		status.RebalanceStatus = ws.Status.RebalanceStatus
+		return nil
+	}
+	if ws.Status.RebalanceStatus != nil {
+		status.RebalanceStatus = ws.Status.RebalanceStatus.DeepCopy()
+	} else {
+		status.RebalanceStatus = &appsv1alpha1.WorkloadSpreadRebalanceStatus{}
+	}
+
+	// count the excess Pods of each subset, and the room of all subsets.
+	excessPods := make(map[string]int, len(ws.Spec.Subsets)+1)
+	excessPods[FakeSubsetName] = countActivePods(subsetPodMap[FakeSubsetName])
+	totalExcess := excessPods[FakeSubsetName]
+	room := 0
+	settled := true
+	for i := range ws.Spec.Subsets {
+		subset := &ws.Spec.Subsets[i]
+		subsetStatus := &status.SubsetStatuses[i]
+		if len(subsetStatus.CreatingPods) > 0 || len(subsetStatus.DeletingPods) > 0 {
+			settled = false
+		}
+		maxReplicas, err := wsutil.GetSubsetMaxReplicas(ws, subset, workloadReplicas)
+		if err != nil {
+			return nil
+		}
+		if maxReplicas >= 0 && int(subsetStatus.Replicas) > maxReplicas {
+			excessPods[subset.Name] = int(subsetStatus.Replicas) - maxReplicas
+			totalExcess += excessPods[subset.Name]
+		}
+		if condition := GetWorkloadSpreadSubsetCondition(subsetStatus, appsv1alpha1.SubsetSchedulable); condition != nil &&
+			condition.Status == corev1.ConditionFalse {
+			continue
+		}
+		if subsetStatus.MissingReplicas == -1 {
+			room = math.MaxInt32
+		} else if room < math.MaxInt32 {
+			room += int(subsetStatus.MissingReplicas)
+		}
+	}
+	status.RebalanceStatus.ExcessReplicas = int32(totalExcess)
+	if totalExcess == 0 || room == 0 || !settled {
+		return nil
+	}
+
+	interval := time.Duration(defaultRebalanceIntervalSeconds) * time.Second
+	if strategy.IntervalSeconds != nil {
+		interval = time.Duration(*strategy.IntervalSeconds) * time.Second
+	}
+	if lastTime := status.RebalanceStatus.LastRebalanceTime; lastTime != nil {
+		if next := lastTime.Add(interval); time.Now().Before(next) {
+			durationStore.Push(getWorkloadSpreadKey(ws), time.Until(next))
+			return nil
+		}
+	}
+
+	maxDeletions := defaultRebalanceMaxDeletionsPerInterval
+	if strategy.MaxDeletionsPerInterval != nil {
+		maxDeletions = int(*strategy.MaxDeletionsPerInterval)
+	}
+	count := integerMin(totalExcess, room, maxDeletions)
+	if count <= 0 {
+		return nil
+	}
+
+	// sort the active Pods of each subset to be deleted, the least healthy Pods first.
+	candidates := make(map[string][]*corev1.Pod, len(excessPods))
+	for subsetName, excess := range excessPods {
+		if excess == 0 {
+			continue
+		}
+		activePods := make([]*corev1.Pod, 0, len(subsetPodMap[subsetName]))
+		for _, pod := range subsetPodMap[subsetName] {
+			if kubecontroller.IsPodActive(pod) {
+				activePods = append(activePods, pod)
+			}
+		}
+		for _, index := range sortDeleteIndexes(activePods) {
+			candidates[subsetName] = append(candidates[subsetName], activePods[index])
+		}
+	}
+
+	podsToDelete := make([]*corev1.Pod, 0, count)
+	for len(podsToDelete) < count {
+		// the Pods not matching any subset go first, then the subset with the most excess Pods.
+		target := FakeSubsetName
+		if excessPods[FakeSubsetName] == 0 {
+			target = ""
+			for i := range ws.Spec.Subsets {
+				name := ws.Spec.Subsets[i].Name
+				if excessPods[name] > 0 && (target == "" || excessPods[name] > excessPods[target]) {
+					target = name
+				}
+			}
+		}
+		if target == "" || len(candidates[target]) == 0 {
+			break
+		}
+		podsToDelete = append(podsToDelete, candidates[target][0])
+		candidates[target] = candidates[target][1:]
+		excessPods[target]--
+	}
+	if len(podsToDelete) == 0 {
+		return nil
+	}
+
+	if totalExcess > len(podsToDelete) {
+		durationStore.Push(getWorkloadSpreadKey(ws), interval)
+	}
+	return podsToDelete
+}
+
+// rebalancePods deletes the Pods chosen by calculateRebalance. The deletion may be rejected by
+// PodUnavailableBudget, then the rest Pods are left to the next round. The rebalance round is
+// only recorded once some Pods are deleted, so a rejected round does not wait for the interval.
+func (r *ReconcileWorkloadSpread) rebalancePods(ws *appsv1alpha1.WorkloadSpread, pods []*corev1.Pod) error {
+	var deletedPods []string
+	var deleteErr error
+	for _, pod := range pods {
+		if err := r.Client.Delete(context.TODO(), pod); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			r.recorder.Eventf(ws, corev1.EventTypeWarning,
+				"RebalancePodFailed", "Failed to delete Pod %s/%s for rebalancing: %v", pod.Namespace, pod.Name, err)
+			if !errors.IsForbidden(err) && !errors.IsTooManyRequests(err) {
+				deleteErr = err
+			}
+			break
+		}
+		deletedPods = append(deletedPods, pod.Name)
+		r.recorder.Eventf(ws, corev1.EventTypeNormal,
+			"RebalancePod", "Deleted Pod %s/%s to rebalance subsets", pod.Namespace, pod.Name)
+		klog.V(3).Infof("WorkloadSpread (%s/%s) deleted Pod (%s/%s) for rebalancing", ws.Namespace, ws.Name, pod.Namespace, pod.Name)
+	}
+	if len(deletedPods) > 0 {
+		if err := r.recordRebalance(ws, deletedPods); err != nil {
+			return err
+		}
+	}
+	return deleteErr
+}
+
+// recordRebalance sets the time and the deleted Pods of the last rebalance round on the latest WorkloadSpread,
+// whose status has been changed by webhook for the deletions.
+func (r *ReconcileWorkloadSpread) recordRebalance(ws *appsv1alpha1.WorkloadSpread, deletedPods []string) error {
+	now := metav1.Now()
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest := &appsv1alpha1.WorkloadSpread{}
+		if err := r.Client.Get(context.TODO(), client.ObjectKeyFromObject(ws), latest); err != nil {
+			return err
+		}
+		if latest.Status.RebalanceStatus == nil {
+			latest.Status.RebalanceStatus = &appsv1alpha1.WorkloadSpreadRebalanceStatus{}
+		}
+		latest.Status.RebalanceStatus.LastRebalanceTime = &now
+		latest.Status.RebalanceStatus.LastRebalancedPods = deletedPods
+		return r.writeWorkloadSpreadStatus(latest)
+	})
+}
+
+func countActivePods(pods []*corev1.Pod) int {
+	count := 0
+	for _, pod := range pods {
+		if kubecontroller.IsPodActive(pod) {
+			count++
+		}
+	}
+	return count
+}
+
+func integerMin(values ...int) int {
+	result := values[0]
+	for _, v := range values[1:] {
+		if v < result {
+			result = v
+		}
+	}
+	return result
+}
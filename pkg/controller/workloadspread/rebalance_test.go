@@ -0,0 +1,230 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadspread
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util/controllerfinder"
+	"github.com/openkruise/kruise/pkg/util/fieldindex"
+	wsutil "github.com/openkruise/kruise/pkg/util/workloadspread"
+)
+
+func TestRebalanceSubsets(t *testing.T) {
+	// subset-a has 4 Pods (subset-a-3 is pending) but maxReplicas is 2, subset-b has 2 Pods.
+	getPods := func() []*corev1.Pod {
+		var pods []*corev1.Pod
+		for subset, count := range map[string]int{"subset-a": 4, "subset-b": 2} {
+			for i := 0; i < count; i++ {
+				pod := podDemo.DeepCopy()
+				pod.Name = fmt.Sprintf("%s-%d", subset, i)
+				pod.Annotations = map[string]string{
+					wsutil.MatchedWorkloadSpreadSubsetAnnotations: fmt.Sprintf(`{"name":"test-workloadSpread","subset":"%s"}`, subset),
+				}
+				pod.Status.Phase = corev1.PodRunning
+				pods = append(pods, pod)
+			}
+		}
+		for _, pod := range pods {
+			if pod.Name == "subset-a-3" {
+				pod.Status.Phase = corev1.PodPending
+			}
+		}
+		return pods
+	}
+	getWorkloadSpread := func() *appsv1alpha1.WorkloadSpread {
+		ws := workloadSpreadDemo.DeepCopy()
+		ws.Spec.Subsets = []appsv1alpha1.WorkloadSpreadSubset{
+			{Name: "subset-a", MaxReplicas: &intstr.IntOrString{Type: intstr.Int, IntVal: 2}},
+			{Name: "subset-b"},
+		}
+		ws.Spec.RebalanceStrategy = &appsv1alpha1.WorkloadSpreadRebalanceStrategy{}
+		ws.Status.SubsetStatuses = nil
+		return ws
+	}
+
+	cases := []struct {
+		name                  string
+		getWorkloadSpread     func() *appsv1alpha1.WorkloadSpread
+		expectDeletedCount    int
+		expectDeletedPods     []string
+		expectExcessReplicas  int32
+		expectNilRebalanceSts bool
+		denyDeletion          bool
+	}{
+		{
+			name: "no rebalance strategy",
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				ws := getWorkloadSpread()
+				ws.Spec.RebalanceStrategy = nil
+				return ws
+			},
+			expectNilRebalanceSts: true,
+		},
+		{
+			name:                 "default strategy deletes the least healthy Pod",
+			getWorkloadSpread:    getWorkloadSpread,
+			expectDeletedCount:   1,
+			expectDeletedPods:    []string{"subset-a-3"},
+			expectExcessReplicas: 2,
+		},
+		{
+			name: "maxDeletionsPerInterval = 5",
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				ws := getWorkloadSpread()
+				ws.Spec.RebalanceStrategy.MaxDeletionsPerInterval = pointer.Int32(5)
+				return ws
+			},
+			expectDeletedCount:   2,
+			expectDeletedPods:    []string{"subset-a-3"},
+			expectExcessReplicas: 2,
+		},
+		{
+			name: "maxDeletionsPerInterval = 5, but subset-b only has room for 1 Pod",
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				ws := getWorkloadSpread()
+				ws.Spec.Subsets[1].MaxReplicas = &intstr.IntOrString{Type: intstr.Int, IntVal: 3}
+				ws.Spec.RebalanceStrategy.MaxDeletionsPerInterval = pointer.Int32(5)
+				return ws
+			},
+			expectDeletedCount:   1,
+			expectDeletedPods:    []string{"subset-a-3"},
+			expectExcessReplicas: 2,
+		},
+		{
+			name: "interval since last rebalance has not passed",
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				ws := getWorkloadSpread()
+				ws.Status.RebalanceStatus = &appsv1alpha1.WorkloadSpreadRebalanceStatus{
+					LastRebalanceTime: &metav1.Time{Time: currentTime},
+				}
+				return ws
+			},
+			expectExcessReplicas: 2,
+		},
+		{
+			name: "Pod creation is in progress",
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				ws := getWorkloadSpread()
+				ws.Status.SubsetStatuses = []appsv1alpha1.WorkloadSpreadSubsetStatus{
+					{Name: "subset-a"},
+					{Name: "subset-b", CreatingPods: map[string]metav1.Time{"subset-b-2": {Time: currentTime}}},
+				}
+				return ws
+			},
+			expectExcessReplicas: 2,
+		},
+		{
+			name:                 "deletion is denied by PodUnavailableBudget",
+			getWorkloadSpread:    getWorkloadSpread,
+			denyDeletion:         true,
+			expectExcessReplicas: 2,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			currentTime = metav1.Now().Time
+			workloadSpread := cs.getWorkloadSpread()
+			cloneSet := cloneSetDemo.DeepCopy()
+			cloneSet.Spec.Replicas = pointer.Int32(6)
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(workloadSpread, cloneSet).
+				WithIndex(&corev1.Pod{}, fieldindex.IndexNameForOwnerRefUID, func(obj client.Object) []string {
+					var owners []string
+					for _, ref := range obj.GetOwnerReferences() {
+						owners = append(owners, string(ref.UID))
+					}
+					return owners
+				}).Build()
+			for _, pod := range getPods() {
+				if err := fakeClient.Create(context.TODO(), pod); err != nil {
+					t.Fatalf("create pod failed: %s", err.Error())
+				}
+			}
+
+			var reconcilerClient client.Client = fakeClient
+			if cs.denyDeletion {
+				reconcilerClient = &denyDeletionClient{Client: fakeClient}
+			}
+			reconciler := ReconcileWorkloadSpread{
+				Client:           reconcilerClient,
+				recorder:         record.NewFakeRecorder(10),
+				controllerFinder: &controllerfinder.ControllerFinder{Client: fakeClient},
+			}
+			if err := reconciler.syncWorkloadSpread(workloadSpread); err != nil {
+				t.Fatalf("sync WorkloadSpread failed: %s", err.Error())
+			}
+
+			latestPods, _ := getLatestPods(fakeClient, workloadSpread)
+			remaining := sets.NewString()
+			for _, pod := range latestPods {
+				remaining.Insert(pod.Name)
+			}
+			if len(latestPods) != 6-cs.expectDeletedCount {
+				t.Fatalf("expect %d Pods deleted, but got remaining %v", cs.expectDeletedCount, remaining.List())
+			}
+			for _, name := range cs.expectDeletedPods {
+				if remaining.Has(name) {
+					t.Fatalf("expect Pod %s deleted, but got remaining %v", name, remaining.List())
+				}
+			}
+			if !remaining.HasAll("subset-b-0", "subset-b-1") {
+				t.Fatalf("expect no Pod deleted from subset-b, but got remaining %v", remaining.List())
+			}
+
+			latestWorkloadSpread, _ := getLatestWorkloadSpread(fakeClient, workloadSpread)
+			rebalanceStatus := latestWorkloadSpread.Status.RebalanceStatus
+			if cs.expectNilRebalanceSts {
+				if rebalanceStatus != nil {
+					t.Fatalf("expect nil rebalanceStatus, but got %v", rebalanceStatus)
+				}
+				return
+			}
+			if rebalanceStatus == nil || rebalanceStatus.ExcessReplicas != cs.expectExcessReplicas {
+				t.Fatalf("expect excessReplicas %d, but got %v", cs.expectExcessReplicas, rebalanceStatus)
+			}
+			if cs.expectDeletedCount > 0 && len(rebalanceStatus.LastRebalancedPods) != cs.expectDeletedCount {
+				t.Fatalf("expect %d lastRebalancedPods, but got %v", cs.expectDeletedCount, rebalanceStatus.LastRebalancedPods)
+			}
+			if cs.denyDeletion && rebalanceStatus.LastRebalanceTime != nil {
+				t.Fatalf("expect no lastRebalanceTime when the deletion is denied, but got %v", rebalanceStatus.LastRebalanceTime)
+			}
+		})
+	}
+}
+
+// denyDeletionClient rejects all deletions as PodUnavailableBudget does.
+type denyDeletionClient struct {
+	client.Client
+}
+
+func (c *denyDeletionClient) Delete(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+	return errors.NewForbidden(corev1.Resource("pods"), obj.GetName(), fmt.Errorf("denied by PodUnavailableBudget"))
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadspread
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+	kubecontroller "k8s.io/kubernetes/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	clonesetutils "github.com/openkruise/kruise/pkg/controller/cloneset/utils"
+	"github.com/openkruise/kruise/pkg/util"
+	utilclient "github.com/openkruise/kruise/pkg/util/client"
+	wsutil "github.com/openkruise/kruise/pkg/util/workloadspread"
+)
+
+// getWorkloadUpdateRevision returns the latest revision of the target workload, and the key of Pod label
+// which is compared with it. It returns an empty revision if the revision cannot be recognized.
+func (r *ReconcileWorkloadSpread) getWorkloadUpdateRevision(ws *appsv1alpha1.WorkloadSpread) (string, string, error) {
+	targetRef := ws.Spec.TargetReference
+	if targetRef == nil {
+		return "", "", nil
+	}
+	gv, err := schema.ParseGroupVersion(targetRef.APIVersion)
+	if err != nil {
+		return "", "", err
+	}
+	object := wsutil.GenerateEmptyWorkloadObject(gv.WithKind(targetRef.Kind), types.NamespacedName{Namespace: ws.Namespace, Name: targetRef.Name})
+	if err = r.Get(context.TODO(), client.ObjectKeyFromObject(object), object); err != nil {
+		return "", "", client.IgnoreNotFound(err)
+	}
+
+	switch o := object.(type) {
+	case *appsv1alpha1.CloneSet:
+		// the controller-revision-hash label of CloneSet Pods may be either the revision name or the short hash,
+		// but the pod-template-hash label is always the short hash.
+		return clonesetutils.GetShortHash(o.Status.UpdateRevision), appsv1.DefaultDeploymentUniqueLabelKey, nil
+	case *appsv1.StatefulSet:
+		return o.Status.UpdateRevision, appsv1.ControllerRevisionHashLabelKey, nil
+	case *appsv1beta1.StatefulSet:
+		return o.Status.UpdateRevision, appsv1.ControllerRevisionHashLabelKey, nil
+	case *appsv1.ReplicaSet:
+		return o.Labels[appsv1.DefaultDeploymentUniqueLabelKey], appsv1.DefaultDeploymentUniqueLabelKey, nil
+	case *appsv1.Deployment:
+		rsList := &appsv1.ReplicaSetList{}
+		selector, err := metav1.LabelSelectorAsSelector(o.Spec.Selector)
+		if err != nil {
+			return "", "", err
+		}
+		if err = r.List(context.TODO(), rsList, &client.ListOptions{Namespace: o.Namespace, LabelSelector: selector}, utilclient.DisableDeepCopy); err != nil {
+			return "", "", err
+		}
+		for i := range rsList.Items {
+			rs := &rsList.Items[i]
+			if owner := metav1.GetControllerOfNoCopy(rs); owner == nil || owner.UID != o.UID || !rs.DeletionTimestamp.IsZero() {
+				continue
+			}
+			if util.EqualIgnoreHash(&o.Spec.Template, &rs.Spec.Template) {
+				return rs.Labels[appsv1.DefaultDeploymentUniqueLabelKey], appsv1.DefaultDeploymentUniqueLabelKey, nil
+			}
+		}
+		return "", appsv1.DefaultDeploymentUniqueLabelKey, nil
+	case *unstructured.Unstructured:
+		// custom workloads usually follow the convention of native workloads.
+		revision, _, _ := unstructured.NestedString(o.Object, "status", "updateRevision")
+		return revision, appsv1.ControllerRevisionHashLabelKey, nil
+	}
+	return "", "", nil
+}
+
+// getPodRevision returns the revision label of the Pod, which is set by the workload.
+// If the label key is unknown, the pod-template-hash label is preferred.
+func getPodRevision(pod *corev1.Pod, revisionLabelKey string) string {
+	if revisionLabelKey != "" {
+		return pod.Labels[revisionLabelKey]
+	}
+	if revision, ok := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; ok {
+		return revision
+	}
+	return pod.Labels[appsv1.ControllerRevisionHashLabelKey]
+}
+
+// calculateSubsetRevisionStatuses counts the active Pods of each revision in each subset, and sets the
+// Overfilled condition for the subsets that exceed their maxReplicas because of surge during rolling update.
+func (r *ReconcileWorkloadSpread) calculateSubsetRevisionStatuses(ws *appsv1alpha1.WorkloadSpread, status *appsv1alpha1.WorkloadSpreadStatus,
+	subsetPodMap map[string][]*corev1.Pod, workloadReplicas int32) {
+	updateRevision, revisionLabelKey, err := r.getWorkloadUpdateRevision(ws)
+	if err != nil {
+		klog.Warningf("WorkloadSpread (%s/%s) failed to get update revision of workload: %v", ws.Namespace, ws.Name, err)
+		updateRevision = ws.Status.UpdateRevision
+	}
+	status.UpdateRevision = updateRevision
+
+	oldSubsetStatusMap := make(map[string]*appsv1alpha1.WorkloadSpreadSubsetStatus, len(ws.Status.SubsetStatuses))
+	for i := range ws.Status.SubsetStatuses {
+		oldSubsetStatusMap[ws.Status.SubsetStatuses[i].Name] = &ws.Status.SubsetStatuses[i]
+	}
+
+	status.SubsetRevisionStatuses = make([]appsv1alpha1.WorkloadSpreadSubsetRevisionStatus, 0, len(ws.Spec.Subsets))
+	for i := range ws.Spec.Subsets {
+		subset := &ws.Spec.Subsets[i]
+		revisionStatus := appsv1alpha1.WorkloadSpreadSubsetRevisionStatus{Name: subset.Name}
+		revisions := map[string]*appsv1alpha1.WorkloadSpreadRevisionReplicas{}
+		for _, pod := range subsetPodMap[subset.Name] {
+			if !kubecontroller.IsPodActive(pod) {
+				continue
+			}
+			ready := podutil.IsPodReady(pod)
+			if ready {
+				revisionStatus.ReadyReplicas++
+			}
+			revision := getPodRevision(pod, revisionLabelKey)
+			if revision == "" {
+				continue
+			}
+			if revisions[revision] == nil {
+				revisions[revision] = &appsv1alpha1.WorkloadSpreadRevisionReplicas{Revision: revision}
+			}
+			revisions[revision].Replicas++
+			if ready {
+				revisions[revision].ReadyReplicas++
+			}
+			if revision == updateRevision {
+				revisionStatus.UpdatedReplicas++
+				if ready {
+					revisionStatus.UpdatedReadyReplicas++
+				}
+			}
+		}
+		for _, replicas := range revisions {
+			revisionStatus.Revisions = append(revisionStatus.Revisions, *replicas)
+		}
+		sort.Slice(revisionStatus.Revisions, func(i, j int) bool {
+			return revisionStatus.Revisions[i].Revision < revisionStatus.Revisions[j].Revision
+		})
+		status.SubsetRevisionStatuses = append(status.SubsetRevisionStatuses, revisionStatus)
+
+		if i < len(status.SubsetStatuses) {
+			setSubsetOverfilledCondition(ws, subset, &status.SubsetStatuses[i], oldSubsetStatusMap[subset.Name],
+				len(revisions) > 1, workloadReplicas)
+		}
+	}
+}
+
+// setSubsetOverfilledCondition sets the Overfilled condition if the subset has more active Pods than its
+// maxReplicas while Pods of multiple revisions coexist in it, otherwise removes the condition.
+func setSubsetOverfilledCondition(ws *appsv1alpha1.WorkloadSpread, subset *appsv1alpha1.WorkloadSpreadSubset,
+	subsetStatus, oldSubsetStatus *appsv1alpha1.WorkloadSpreadSubsetStatus, updating bool, workloadReplicas int32) {
+	maxReplicas, err := wsutil.GetSubsetMaxReplicas(ws, subset, workloadReplicas)
+	if err != nil || maxReplicas < 0 || !updating || int(subsetStatus.Replicas) <= maxReplicas {
+		removeWorkloadSpreadSubsetCondition(subsetStatus, appsv1alpha1.SubsetOverfilled)
+		return
+	}
+	if oldCondition := GetWorkloadSpreadSubsetCondition(oldSubsetStatus, appsv1alpha1.SubsetOverfilled); oldCondition != nil {
+		setWorkloadSpreadSubsetCondition(subsetStatus, oldCondition.DeepCopy())
+	}
+	setWorkloadSpreadSubsetCondition(subsetStatus, NewWorkloadSpreadSubsetCondition(appsv1alpha1.SubsetOverfilled, corev1.ConditionTrue,
+		"RollingUpdateSurge", fmt.Sprintf("subset has more replicas than maxReplicas %d during rolling update", maxReplicas)))
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadspread
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util/controllerfinder"
+	"github.com/openkruise/kruise/pkg/util/fieldindex"
+	wsutil "github.com/openkruise/kruise/pkg/util/workloadspread"
+)
+
+func TestCalculateSubsetRevisionStatuses(t *testing.T) {
+	// subset-a: 2 old Pods and 2 new Pods (one is not ready), maxReplicas is 3.
+	// subset-b: 1 old Pod and 1 new Pod.
+	newPod := func(name, subset, revision string, ready bool) *corev1.Pod {
+		pod := podDemo.DeepCopy()
+		pod.Name = name
+		// labels set by CloneSet without CloneSetShortHash
+		pod.Labels[apps.DefaultDeploymentUniqueLabelKey] = revision
+		pod.Labels[apps.ControllerRevisionHashLabelKey] = fmt.Sprintf("%s-%s", cloneSetDemo.Name, revision)
+		pod.Annotations = map[string]string{
+			wsutil.MatchedWorkloadSpreadSubsetAnnotations: fmt.Sprintf(`{"name":"test-workloadSpread","subset":"%s"}`, subset),
+		}
+		pod.Status.Phase = corev1.PodRunning
+		if ready {
+			pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		}
+		return pod
+	}
+	pods := []*corev1.Pod{
+		newPod("pod-a-0", "subset-a", "5f8b9c6d7", true),
+		newPod("pod-a-1", "subset-a", "5f8b9c6d7", true),
+		newPod("pod-a-2", "subset-a", "7c9d8f6b5", true),
+		newPod("pod-a-3", "subset-a", "7c9d8f6b5", false),
+		newPod("pod-b-0", "subset-b", "5f8b9c6d7", true),
+		newPod("pod-b-1", "subset-b", "7c9d8f6b5", true),
+	}
+
+	workloadSpread := workloadSpreadDemo.DeepCopy()
+	workloadSpread.Spec.Subsets = []appsv1alpha1.WorkloadSpreadSubset{
+		{Name: "subset-a", MaxReplicas: &intstr.IntOrString{Type: intstr.Int, IntVal: 3}},
+		{Name: "subset-b"},
+	}
+	workloadSpread.Status.SubsetStatuses = nil
+	cloneSet := cloneSetDemo.DeepCopy()
+	cloneSet.Spec.Replicas = pointer.Int32(5)
+	cloneSet.Status.UpdateRevision = fmt.Sprintf("%s-%s", cloneSet.Name, "7c9d8f6b5")
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(workloadSpread, cloneSet).
+		WithIndex(&corev1.Pod{}, fieldindex.IndexNameForOwnerRefUID, func(obj client.Object) []string {
+			var owners []string
+			for _, ref := range obj.GetOwnerReferences() {
+				owners = append(owners, string(ref.UID))
+			}
+			return owners
+		}).Build()
+	for _, pod := range pods {
+		if err := fakeClient.Create(context.TODO(), pod); err != nil {
+			t.Fatalf("create pod failed: %s", err.Error())
+		}
+	}
+	reconciler := ReconcileWorkloadSpread{
+		Client:           fakeClient,
+		recorder:         record.NewFakeRecorder(10),
+		controllerFinder: &controllerfinder.ControllerFinder{Client: fakeClient},
+	}
+	if err := reconciler.syncWorkloadSpread(workloadSpread); err != nil {
+		t.Fatalf("sync WorkloadSpread failed: %s", err.Error())
+	}
+
+	latestWorkloadSpread, _ := getLatestWorkloadSpread(fakeClient, workloadSpread)
+	if latestWorkloadSpread.Status.UpdateRevision != "7c9d8f6b5" {
+		t.Fatalf("expect updateRevision 7c9d8f6b5, but got %s", latestWorkloadSpread.Status.UpdateRevision)
+	}
+	expectRevisionStatuses := []appsv1alpha1.WorkloadSpreadSubsetRevisionStatus{
+		{
+			Name:                 "subset-a",
+			ReadyReplicas:        3,
+			UpdatedReplicas:      2,
+			UpdatedReadyReplicas: 1,
+			Revisions: []appsv1alpha1.WorkloadSpreadRevisionReplicas{
+				{Revision: "5f8b9c6d7", Replicas: 2, ReadyReplicas: 2},
+				{Revision: "7c9d8f6b5", Replicas: 2, ReadyReplicas: 1},
+			},
+		},
+		{
+			Name:                 "subset-b",
+			ReadyReplicas:        2,
+			UpdatedReplicas:      1,
+			UpdatedReadyReplicas: 1,
+			Revisions: []appsv1alpha1.WorkloadSpreadRevisionReplicas{
+				{Revision: "5f8b9c6d7", Replicas: 1, ReadyReplicas: 1},
+				{Revision: "7c9d8f6b5", Replicas: 1, ReadyReplicas: 1},
+			},
+		},
+	}
+	if !apiequality.Semantic.DeepEqual(latestWorkloadSpread.Status.SubsetRevisionStatuses, expectRevisionStatuses) {
+		t.Fatalf("expect subsetRevisionStatuses %v, but got %v", expectRevisionStatuses, latestWorkloadSpread.Status.SubsetRevisionStatuses)
+	}
+
+	subsetStatuses := latestWorkloadSpread.Status.SubsetStatuses
+	if condition := GetWorkloadSpreadSubsetCondition(&subsetStatuses[0], appsv1alpha1.SubsetOverfilled); condition == nil ||
+		condition.Status != corev1.ConditionTrue {
+		t.Fatalf("expect subset-a overfilled, but got %v", subsetStatuses[0].Conditions)
+	}
+	if condition := GetWorkloadSpreadSubsetCondition(&subsetStatuses[1], appsv1alpha1.SubsetOverfilled); condition != nil {
+		t.Fatalf("expect subset-b not overfilled, but got %v", subsetStatuses[1].Conditions)
+	}
+}
@@ -25,7 +25,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/klog/v2"
 	kubecontroller "k8s.io/kubernetes/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -123,6 +122,15 @@ func (r *ReconcileWorkloadSpread) updateDeletionCostBySubset(ws *appsv1alpha1.Wo
 //     maxReplicas    10            10           nil
 //     pods number    20            20           20
 //     deletion-cost (300,-100)    (200,-200)    100
+//
+// For weighted subsets, maxReplicas is the share of the subset by weight, and the extra Pods get deletion-cost
+// -100 * (the number of extra Pods left in the subset when it is deleted), so each scale-in step deletes a Pod
+// from the most over-weight subset. E.g., the workload is scaled from 9 to 6 replicas:
+//
+//	name           subset-a          subset-b   subset-c
+//	weight            3                 2          1
+//	pods number       5                 2          2
+//	deletion-cost (300x3,-200,-100)  (200x2)    (100,-100)
 func (r *ReconcileWorkloadSpread) syncSubsetPodDeletionCost(
 	ws *appsv1alpha1.WorkloadSpread,
 	subset *appsv1alpha1.WorkloadSpreadSubset,
@@ -148,18 +156,16 @@ func (r *ReconcileWorkloadSpread) syncSubsetPodDeletionCost(
 	if subset == nil {
 		// for the scene of FakeSubsetName, where the pods don't match any subset and will be deleted preferentially.
 		negativePods = activePods
-	} else if subset.MaxReplicas == nil {
-		// maxReplicas is nil, which means there is no limit to the number of Pods in this subset.
-		positivePods = activePods
 	} else {
-		subsetMaxReplicas, err := intstr.GetValueFromIntOrPercent(subset.MaxReplicas, int(workloadReplicas), true)
-		if err != nil || subsetMaxReplicas < 0 {
+		subsetMaxReplicas, err := wsutil.GetSubsetMaxReplicas(ws, subset, workloadReplicas)
+		if err != nil {
 			klog.Errorf("failed to get maxReplicas value from subset (%s) of WorkloadSpread (%s/%s)",
 				subset.Name, ws.Namespace, ws.Name)
 			return nil
 		}
 
-		if replicas <= subsetMaxReplicas {
+		// subsetMaxReplicas = -1 means there is no limit to the number of Pods in this subset.
+		if subsetMaxReplicas < 0 || replicas <= subsetMaxReplicas {
 			positivePods = activePods
 		} else {
 			// Pods are classified two class, the one is more healthy and it's size = subsetMaxReplicas, so
@@ -185,6 +191,17 @@ func (r *ReconcileWorkloadSpread) syncSubsetPodDeletionCost(
 	if err != nil {
 		return err
 	}
+	if subset != nil && wsutil.IsWeightedWorkloadSpread(ws) {
+		// negativePods is sorted from the most preferred to delete, and the i-th Pod is deleted
+		// when the subset still has len(negativePods)-i extra Pods.
+		for i := range negativePods {
+			deletionCostStr := strconv.Itoa(wsutil.PodDeletionCostNegative * (len(negativePods) - i))
+			if err = r.updateDeletionCostForSubsetPods(ws, subset, negativePods[i:i+1], deletionCostStr); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 	return r.updateDeletionCostForSubsetPods(ws, subset, negativePods, strconv.Itoa(wsutil.PodDeletionCostNegative*(subsetIndex+1)))
 }
 
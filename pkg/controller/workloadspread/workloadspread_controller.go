@@ -35,7 +35,6 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	kubecontroller "k8s.io/kubernetes/pkg/controller"
@@ -97,6 +96,7 @@ var (
 	controllerKindRS        = appsv1.SchemeGroupVersion.WithKind("ReplicaSet")
 	controllerKindDep       = appsv1.SchemeGroupVersion.WithKind("Deployment")
 	controllerKindJob       = batchv1.SchemeGroupVersion.WithKind("Job")
+	controllerKruiseKindBJ  = appsv1alpha1.SchemeGroupVersion.WithKind("BroadcastJob")
 )
 
 // this is a short cut for any sub-functions to notify the reconcile how long to wait to requeue
@@ -157,6 +157,12 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for desired changes to BroadcastJob
+	err = c.Watch(&source.Kind{Type: &appsv1alpha1.BroadcastJob{}}, &workloadEventHandler{Reader: mgr.GetCache()})
+	if err != nil {
+		return err
+	}
+
 	// Watch for replicas changes to other CRD
 	whiteList, err := configuration.GetWSWatchCustomWorkloadWhiteList(mgr.GetClient())
 	if err != nil {
@@ -201,6 +207,7 @@ type ReconcileWorkloadSpread struct {
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
 // +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps.kruise.io,resources=broadcastjobs,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;update;patch;delete
 
 func (r *ReconcileWorkloadSpread) Reconcile(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
@@ -275,6 +282,45 @@ func (r *ReconcileWorkloadSpread) getPodJob(ref *appsv1alpha1.TargetReference, n
 	return matchedPods, *(job.Spec.Parallelism), nil
 }
 
+func (r *ReconcileWorkloadSpread) getPodBroadcastJob(ref *appsv1alpha1.TargetReference, namespace string) ([]*corev1.Pod, int32, error) {
+	ok, err := wsutil.VerifyGroupKind(ref, controllerKruiseKindBJ.Kind, []string{controllerKruiseKindBJ.Group})
+	if err != nil || !ok {
+		return nil, -1, err
+	}
+
+	job := &appsv1alpha1.BroadcastJob{}
+	err = r.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: ref.Name}, job)
+	if err != nil {
+		// when error is NotFound, it is ok here.
+		if errors.IsNotFound(err) {
+			klog.V(3).Infof("cannot find BroadcastJob (%s/%s)", namespace, ref.Name)
+			return nil, 0, nil
+		}
+		return nil, -1, err
+	}
+
+	// BroadcastJob has no selector, so its Pods are listed by owner only.
+	podList := &corev1.PodList{}
+	listOption := &client.ListOptions{
+		Namespace:     namespace,
+		FieldSelector: fields.SelectorFromSet(fields.Set{fieldindex.IndexNameForOwnerRefUID: string(job.UID)}),
+	}
+	err = r.List(context.TODO(), podList, listOption)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	matchedPods := make([]*corev1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		matchedPods = append(matchedPods, &podList.Items[i])
+	}
+	replicas, err := wsutil.GetBroadcastJobReplicas(r.Client, job)
+	if err != nil {
+		return nil, -1, err
+	}
+	return matchedPods, replicas, nil
+}
+
 // getPodsForWorkloadSpread returns Pods managed by the WorkloadSpread object.
 // return two parameters
 // 1. podList for workloadSpread
@@ -292,6 +338,8 @@ func (r *ReconcileWorkloadSpread) getPodsForWorkloadSpread(ws *appsv1alpha1.Work
 	switch targetRef.Kind {
 	case controllerKindJob.Kind:
 		pods, workloadReplicas, err = r.getPodJob(targetRef, ws.Namespace)
+	case controllerKruiseKindBJ.Kind:
+		pods, workloadReplicas, err = r.getPodBroadcastJob(targetRef, ws.Namespace)
 	default:
 		pods, workloadReplicas, err = r.controllerFinder.GetPodsForRef(targetRef.APIVersion, targetRef.Kind, ws.Namespace, targetRef.Name, false)
 	}
@@ -340,6 +388,12 @@ func (r *ReconcileWorkloadSpread) syncWorkloadSpread(ws *appsv1alpha1.WorkloadSp
 		return nil
 	}
 
+	// count the Pods of each revision in each subset
+	r.calculateSubsetRevisionStatuses(ws, status, subsetPodMap, workloadReplicas)
+
+	// choose Pods to move out of over-full subsets
+	rebalancePods := r.calculateRebalance(ws, status, subsetPodMap, workloadReplicas)
+
 	// update status
 	err = r.UpdateWorkloadSpreadStatus(ws, status)
 	if err != nil {
@@ -347,7 +401,12 @@ func (r *ReconcileWorkloadSpread) syncWorkloadSpread(ws *appsv1alpha1.WorkloadSp
 	}
 
 	// clean up unschedulable Pods
-	return r.cleanupUnscheduledPods(ws, scheduleFailedPodMap)
+	if err = r.cleanupUnscheduledPods(ws, scheduleFailedPodMap); err != nil {
+		return err
+	}
+
+	// delete Pods in over-full subsets, and they will be recreated into under-filled subsets
+	return r.rebalancePods(ws, rebalancePods)
 }
 
 func getInjectWorkloadSpreadFromPod(pod *corev1.Pod) *wsutil.InjectWorkloadSpread {
@@ -397,8 +456,11 @@ func (r *ReconcileWorkloadSpread) groupPodBySubset(ws *appsv1alpha1.WorkloadSpre
 	subsetMissingReplicas := make(map[string]int)
 	for _, subset := range ws.Spec.Subsets {
 		podMap[subset.Name] = []*corev1.Pod{}
-		subsetMissingReplicas[subset.Name], _ = intstr.GetScaledValueFromIntOrPercent(
-			intstr.ValueOrDefault(subset.MaxReplicas, intstr.FromInt(math.MaxInt32)), int(replicas), true)
+		subsetMaxReplicas, _ := wsutil.GetSubsetMaxReplicas(ws, &subset, replicas)
+		if subsetMaxReplicas < 0 {
+			subsetMaxReplicas = math.MaxInt32
+		}
+		subsetMissingReplicas[subset.Name] = subsetMaxReplicas
 	}
 
 	// count managed pods for each subset
@@ -643,18 +705,12 @@ func (r *ReconcileWorkloadSpread) calculateWorkloadSpreadSubsetStatus(ws *appsv1
 	subsetStatus.CreatingPods = make(map[string]metav1.Time)
 	subsetStatus.DeletingPods = make(map[string]metav1.Time)
 
-	var err error
-	var subsetMaxReplicas int
-	if subset.MaxReplicas == nil {
-		// MaxReplicas is nil, which means there is no limit for subset replicas, using -1 to represent it.
-		subsetMaxReplicas = -1
-	} else {
-		subsetMaxReplicas, err = intstr.GetValueFromIntOrPercent(subset.MaxReplicas, int(workloadReplicas), true)
-		if err != nil || subsetMaxReplicas < 0 {
-			klog.Errorf("failed to get maxReplicas value from subset (%s) of WorkloadSpread (%s/%s)",
-				subset.Name, ws.Namespace, ws.Name)
-			return nil
-		}
+	// subsetMaxReplicas is -1 if there is no limit for subset replicas.
+	subsetMaxReplicas, err := wsutil.GetSubsetMaxReplicas(ws, subset, workloadReplicas)
+	if err != nil {
+		klog.Errorf("failed to get maxReplicas value from subset (%s) of WorkloadSpread (%s/%s)",
+			subset.Name, ws.Namespace, ws.Name)
+		return nil
 	}
 	// initialize missingReplicas to subsetMaxReplicas
 	subsetStatus.MissingReplicas = int32(subsetMaxReplicas)
@@ -683,6 +683,45 @@ func TestSubsetPodDeletionCost(t *testing.T) {
 				return pods
 			},
 		},
+		{
+			name: "weight is 1:1, workload replicas is 5, active pods number is 5, (pod-3, pod-4) are unhealthy",
+			getPods: func() []*corev1.Pod {
+				pods := make([]*corev1.Pod, 5)
+				for i := range pods {
+					pods[i] = podDemo.DeepCopy()
+					pods[i].Name = fmt.Sprintf("test-pods-%d", i)
+					pods[i].Status.Phase = corev1.PodRunning
+				}
+				pods[3].Spec.NodeName = ""
+				pods[4].Status.Phase = corev1.PodPending
+				return pods
+			},
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				workloadSpread := workloadSpreadDemo.DeepCopy()
+				workloadSpread.Spec.Subsets = []appsv1alpha1.WorkloadSpreadSubset{
+					{Name: "subset-a", Weight: utilpointer.Int32(1)},
+					{Name: "subset-b", Weight: utilpointer.Int32(1)},
+				}
+				return workloadSpread
+			},
+			expectPods: func() []*corev1.Pod {
+				pods := make([]*corev1.Pod, 5)
+				for i := range pods {
+					pods[i] = podDemo.DeepCopy()
+					pods[i].Annotations = map[string]string{
+						PodDeletionCostAnnotation: "200",
+					}
+					pods[i].Name = fmt.Sprintf("test-pods-%d", i)
+				}
+				pods[3].Annotations = map[string]string{
+					PodDeletionCostAnnotation: "-200",
+				}
+				pods[4].Annotations = map[string]string{
+					PodDeletionCostAnnotation: "-100",
+				}
+				return pods
+			},
+		},
 	}
 	for _, cs := range cases {
 		t.Run(cs.name, func(t *testing.T) {
@@ -1436,6 +1475,14 @@ func TestWorkloadSpreadReconcile(t *testing.T) {
 				workloadSpread.Status.SubsetStatuses[0].Name = "subset-a"
 				workloadSpread.Status.SubsetStatuses[0].MissingReplicas = 0
 				workloadSpread.Status.SubsetStatuses[0].Replicas = 6
+				workloadSpread.Status.SubsetStatuses[0].Conditions = []appsv1alpha1.WorkloadSpreadSubsetCondition{
+					{
+						Type:    appsv1alpha1.SubsetOverfilled,
+						Status:  corev1.ConditionTrue,
+						Reason:  "RollingUpdateSurge",
+						Message: "subset has more replicas than maxReplicas 3 during rolling update",
+					},
+				}
 				workloadSpread.Status.SubsetStatuses[1].Name = "subset-b"
 				workloadSpread.Status.SubsetStatuses[1].MissingReplicas = -1
 				workloadSpread.Status.SubsetStatuses[1].Replicas = 4
@@ -1582,6 +1629,11 @@ func TestWorkloadSpreadReconcile(t *testing.T) {
 			}
 
 			latestStatus := latestWorkloadSpread.Status.SubsetStatuses
+			for i := range latestStatus {
+				for j := range latestStatus[i].Conditions {
+					latestStatus[i].Conditions[j].LastTransitionTime = metav1.Time{}
+				}
+			}
 			by, _ := json.Marshal(latestStatus)
 			fmt.Println(string(by))
 
@@ -2260,6 +2312,99 @@ func TestManagerExistingPods(t *testing.T) {
 	}
 }
 
+func TestWorkloadSpreadReconcileForBroadcastJob(t *testing.T) {
+	broadcastJob := &appsv1alpha1.BroadcastJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "broadcastjob-test",
+			Namespace: "default",
+			UID:       types.UID("b03eb001-27eb-4713-b634-7c46f6861758"),
+		},
+	}
+	// Status.Desired of BroadcastJob is not updated yet, the replicas are calculated from the nodes.
+	var nodes []client.Object
+	for i := 0; i < 4; i++ {
+		nodes = append(nodes, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)}})
+	}
+	cases := []struct {
+		name                  string
+		succeeded             int
+		expectReplicas        int32
+		expectMissingReplicas int32
+	}{
+		{
+			name:                  "2 running pods and 1 succeeded pod, maxReplicas = 50%",
+			succeeded:             1,
+			expectReplicas:        2,
+			expectMissingReplicas: 0,
+		},
+		{
+			name:                  "1 running pod and 2 succeeded pods, maxReplicas = 50%",
+			succeeded:             2,
+			expectReplicas:        1,
+			expectMissingReplicas: 1,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			workloadSpread := workloadSpreadDemo.DeepCopy()
+			workloadSpread.Spec.TargetReference = &appsv1alpha1.TargetReference{
+				APIVersion: controllerKruiseKindBJ.GroupVersion().String(),
+				Kind:       controllerKruiseKindBJ.Kind,
+				Name:       broadcastJob.Name,
+			}
+			maxReplicas := intstr.FromString("50%")
+			workloadSpread.Spec.Subsets[0].MaxReplicas = &maxReplicas
+
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(workloadSpread, broadcastJob.DeepCopy()).WithObjects(nodes...).
+				WithIndex(&corev1.Pod{}, fieldindex.IndexNameForOwnerRefUID, func(obj client.Object) []string {
+					var owners []string
+					for _, ref := range obj.GetOwnerReferences() {
+						owners = append(owners, string(ref.UID))
+					}
+					return owners
+				}).Build()
+			for i := 0; i < 3; i++ {
+				pod := podDemo.DeepCopy()
+				pod.Name = fmt.Sprintf("test-pod-%d", i)
+				pod.OwnerReferences[0].APIVersion = controllerKruiseKindBJ.GroupVersion().String()
+				pod.OwnerReferences[0].Kind = controllerKruiseKindBJ.Kind
+				pod.OwnerReferences[0].Name = broadcastJob.Name
+				pod.OwnerReferences[0].UID = broadcastJob.UID
+				pod.Annotations[wsutil.MatchedWorkloadSpreadSubsetAnnotations] = `{"name":"test-workloadSpread","subset":"subset-a"}`
+				pod.Status.Phase = corev1.PodRunning
+				if i < cs.succeeded {
+					pod.Status.Phase = corev1.PodSucceeded
+				}
+				if err := fakeClient.Create(context.TODO(), pod); err != nil {
+					t.Fatalf("create pod failed: %s", err.Error())
+				}
+			}
+
+			reconciler := ReconcileWorkloadSpread{
+				Client:           fakeClient,
+				recorder:         record.NewFakeRecorder(10),
+				controllerFinder: &controllerfinder.ControllerFinder{Client: fakeClient},
+			}
+			if err := reconciler.syncWorkloadSpread(workloadSpread); err != nil {
+				t.Fatalf("sync WorkloadSpread failed: %s", err.Error())
+			}
+
+			latestWorkloadSpread, err := getLatestWorkloadSpread(fakeClient, workloadSpread)
+			if err != nil {
+				t.Fatalf("get latest WorkloadSpread failed: %s", err.Error())
+			}
+			subsetStatus := latestWorkloadSpread.Status.SubsetStatuses[0]
+			if subsetStatus.Replicas != cs.expectReplicas {
+				t.Fatalf("expect replicas %d, but got %d", cs.expectReplicas, subsetStatus.Replicas)
+			}
+			if subsetStatus.MissingReplicas != cs.expectMissingReplicas {
+				t.Fatalf("expect missingReplicas %d, but got %d", cs.expectMissingReplicas, subsetStatus.MissingReplicas)
+			}
+		})
+	}
+}
+
 func getLatestWorkloadSpread(client client.Client, workloadSpread *appsv1alpha1.WorkloadSpread) (*appsv1alpha1.WorkloadSpread, error) {
 	newWorkloadSpread := &appsv1alpha1.WorkloadSpread{}
 	key := types.NamespacedName{
@@ -128,6 +128,10 @@ func (w workloadEventHandler) Update(evt event.UpdateEvent, q workqueue.RateLimi
 		oldReplicas = *evt.ObjectOld.(*batchv1.Job).Spec.Parallelism
 		newReplicas = *evt.ObjectNew.(*batchv1.Job).Spec.Parallelism
 		gvk = controllerKindJob
+	case *appsv1alpha1.BroadcastJob:
+		oldReplicas = evt.ObjectOld.(*appsv1alpha1.BroadcastJob).Status.Desired
+		newReplicas = evt.ObjectNew.(*appsv1alpha1.BroadcastJob).Status.Desired
+		gvk = controllerKruiseKindBJ
 	case *appsv1.StatefulSet:
 		oldReplicas = *evt.ObjectOld.(*appsv1.StatefulSet).Spec.Replicas
 		newReplicas = *evt.ObjectNew.(*appsv1.StatefulSet).Spec.Replicas
@@ -185,6 +189,8 @@ func (w *workloadEventHandler) handleWorkload(q workqueue.RateLimitingInterface,
 		gvk = controllerKindRS
 	case *batchv1.Job:
 		gvk = controllerKindJob
+	case *appsv1alpha1.BroadcastJob:
+		gvk = controllerKruiseKindBJ
 	case *appsv1.StatefulSet:
 		gvk = controllerKindSts
 	case *appsv1beta1.StatefulSet:
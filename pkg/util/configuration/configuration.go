@@ -19,9 +19,12 @@ package configuration
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"sync"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
 	"github.com/openkruise/kruise/pkg/util"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -69,6 +72,24 @@ func GetPPSWatchCustomWorkloadWhiteList(client client.Client) (*CustomWorkloadWh
 	return whiteList, nil
 }
 
+func GetScaleCustomWorkloadWhiteList(client client.Reader) (*CustomWorkloadWhiteList, error) {
+	whiteList := &CustomWorkloadWhiteList{Workloads: make([]schema.GroupVersionKind, 0)}
+	data, err := getKruiseConfiguration(client)
+	if err != nil {
+		return nil, err
+	} else if len(data) == 0 {
+		return whiteList, nil
+	}
+	value, ok := data[ScaleCustomWorkloadWhiteListKey]
+	if !ok {
+		return whiteList, nil
+	}
+	if err = json.Unmarshal([]byte(value), whiteList); err != nil {
+		return nil, err
+	}
+	return whiteList, nil
+}
+
 func GetWSWatchCustomWorkloadWhiteList(client client.Reader) (WSCustomWorkloadWhiteList, error) {
 	whiteList := WSCustomWorkloadWhiteList{}
 	data, err := getKruiseConfiguration(client)
@@ -87,6 +108,106 @@ func GetWSWatchCustomWorkloadWhiteList(client client.Reader) (WSCustomWorkloadWh
 	return whiteList, nil
 }
 
+func GetUDCustomWorkloadWhiteList(client client.Reader) (UDCustomWorkloadWhiteList, error) {
+	whiteList := UDCustomWorkloadWhiteList{}
+	data, err := getKruiseConfiguration(client)
+	if err != nil {
+		return whiteList, err
+	} else if len(data) == 0 {
+		return whiteList, nil
+	}
+	value, ok := data[UDCustomWorkloadWhiteListKey]
+	if !ok {
+		return whiteList, nil
+	}
+	if err = json.Unmarshal([]byte(value), &whiteList); err != nil {
+		return whiteList, err
+	}
+	return whiteList, nil
+}
+
+// pubDisruptiveFieldsCache caches the parsed pub disruptive fields with the resourceVersion of kruise configuration,
+// since they are checked on every update of labels and annotations of the pods protected by pub.
+var pubDisruptiveFieldsCache struct {
+	sync.Mutex
+	resourceVersion string
+	fields          *policyv1alpha1.PubDisruptiveFields
+}
+
+// GetPUBDisruptiveFields returns the pod labels and annotations whose changes are disruptive for all PodUnavailableBudgets.
+func GetPUBDisruptiveFields(c client.Reader) (*policyv1alpha1.PubDisruptiveFields, error) {
+	cfg := &corev1.ConfigMap{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: util.GetKruiseNamespace(), Name: KruiseConfigurationName}, cfg); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	pubDisruptiveFieldsCache.Lock()
+	defer pubDisruptiveFieldsCache.Unlock()
+	if cfg.ResourceVersion != "" && cfg.ResourceVersion == pubDisruptiveFieldsCache.resourceVersion {
+		return pubDisruptiveFieldsCache.fields, nil
+	}
+	var fields *policyv1alpha1.PubDisruptiveFields
+	if value, ok := cfg.Data[PUBDisruptiveFieldsKey]; ok {
+		fields = &policyv1alpha1.PubDisruptiveFields{}
+		if err := json.Unmarshal([]byte(value), fields); err != nil {
+			return nil, err
+		}
+	}
+	pubDisruptiveFieldsCache.resourceVersion = cfg.ResourceVersion
+	pubDisruptiveFieldsCache.fields = fields
+	return fields, nil
+}
+
+// GetDeletionProtectionResources returns the resources protected by the deletion protection webhook.
+func GetDeletionProtectionResources(client client.Reader) (*DeletionProtectionResourceList, error) {
+	data, err := getKruiseConfiguration(client)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeletionProtectionResources(data)
+}
+
+// ParseDeletionProtectionResources parses the resources protected by the deletion protection webhook
+// from the data of kruise configuration.
+func ParseDeletionProtectionResources(data map[string]string) (*DeletionProtectionResourceList, error) {
+	resources := &DeletionProtectionResourceList{}
+	value, ok := data[DeletionProtectionResourcesKey]
+	if !ok {
+		return resources, nil
+	}
+	if err := json.Unmarshal([]byte(value), resources); err != nil {
+		return nil, err
+	}
+	for _, r := range resources.Resources {
+		if r.Version == "" || r.Kind == "" || r.Resource == "" {
+			return nil, fmt.Errorf("invalid deletion protection resource %v, version, kind and resource are required", r)
+		}
+	}
+	return resources, nil
+}
+
+// GetDeletionProtectionApprovers returns the approvers of deletion of resources labeled with AlwaysWithApproval.
+func GetDeletionProtectionApprovers(client client.Reader) (*DeletionProtectionApprovers, error) {
+	approvers := &DeletionProtectionApprovers{}
+	data, err := getKruiseConfiguration(client)
+	if err != nil {
+		return nil, err
+	} else if len(data) == 0 {
+		return approvers, nil
+	}
+	value, ok := data[DeletionProtectionApproversKey]
+	if !ok {
+		return approvers, nil
+	}
+	if err = json.Unmarshal([]byte(value), approvers); err != nil {
+		return nil, err
+	}
+	return approvers, nil
+}
+
 func getKruiseConfiguration(c client.Reader) (map[string]string, error) {
 	cfg := &corev1.ConfigMap{}
 	err := c.Get(context.TODO(), client.ObjectKey{Namespace: util.GetKruiseNamespace(), Name: KruiseConfigurationName}, cfg)
@@ -19,6 +19,7 @@ package configuration
 import (
 	"github.com/openkruise/kruise/apis/apps/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
@@ -27,6 +28,17 @@ const (
 	SidecarSetPatchPodMetadataWhiteListKey = "SidecarSet_PatchPodMetadata_WhiteList"
 	PPSWatchCustomWorkloadWhiteList        = "PPS_Watch_Custom_Workload_WhiteList"
 	WSWatchCustomWorkloadWhiteList         = "WorkloadSpread_Watch_Custom_Workload_WhiteList"
+	UDCustomWorkloadWhiteListKey           = "UnitedDeployment_Custom_Workload_WhiteList"
+	PUBDisruptiveFieldsKey                 = "PodUnavailableBudget_Disruptive_Fields"
+	// ScaleCustomWorkloadWhiteListKey is the custom workloads which control other workloads, such as Argo Rollout
+	// controlling ReplicaSets, and whose replicas are resolved through the scale subresource.
+	ScaleCustomWorkloadWhiteListKey = "Scale_Custom_Workload_WhiteList"
+	// DeletionProtectionResourcesKey is the resources protected by the deletion protection webhook
+	// in addition to the builtin supported ones, such as PersistentVolumeClaims and ConfigMaps.
+	DeletionProtectionResourcesKey = "ResourcesDeletionProtection_Resources"
+	// DeletionProtectionApproversKey is the users and groups who are allowed to approve the deletion of resources
+	// labeled with AlwaysWithApproval.
+	DeletionProtectionApproversKey = "ResourcesDeletionProtection_Approvers"
 )
 
 type SidecarSetPatchMetadataWhiteList struct {
@@ -87,3 +99,90 @@ type CustomWorkload struct {
 	// ReplicasPath is the replicas field path of this type of workload, such as "spec.replicas"
 	ReplicasPath string `json:"replicasPath,omitempty"`
 }
+
+type UDCustomWorkloadWhiteList struct {
+	Workloads []UDCustomWorkload `json:"workloads,omitempty"`
+}
+
+// UDCustomWorkload describes how UnitedDeployment manages a custom workload as its subset.
+// All the paths are dot-separated field paths of the workload object.
+// The white list is only loaded when kruise-manager starts, so kruise-manager has to be restarted after it is changed.
+// And kruise-manager has to be granted the permissions to get, list, watch, create, update and delete the workload,
+// and to get and update its scale subresource.
+type UDCustomWorkload struct {
+	schema.GroupVersionKind `json:",inline"`
+	// ReplicasPath is the replicas field path of this type of workload, defaults to "spec.replicas".
+	// It is only used to create the workload and to read the replicas of workload without the scale subresource,
+	// the replicas of existing workloads are read and updated through the scale subresource.
+	ReplicasPath string `json:"replicasPath,omitempty"`
+	// PartitionPath is the partition field path of this type of workload, such as "spec.updateStrategy.partition".
+	// If empty, the partitions of subsets will not be set to the workloads.
+	PartitionPath string `json:"partitionPath,omitempty"`
+	// SelectorPath is the label selector field path of this type of workload, defaults to "spec.selector"
+	SelectorPath string `json:"selectorPath,omitempty"`
+	// TemplatePath is the pod template field path of this type of workload, defaults to "spec.template"
+	TemplatePath string `json:"templatePath,omitempty"`
+	// StatusReplicasPath is the status replicas field path of this type of workload, defaults to "status.replicas"
+	StatusReplicasPath string `json:"statusReplicasPath,omitempty"`
+	// StatusReadyReplicasPath is the status ready replicas field path of this type of workload,
+	// defaults to "status.readyReplicas"
+	StatusReadyReplicasPath string `json:"statusReadyReplicasPath,omitempty"`
+	// ObservedGenerationPath is the observed generation field path of this type of workload,
+	// defaults to "status.observedGeneration"
+	ObservedGenerationPath string `json:"observedGenerationPath,omitempty"`
+}
+
+func (p *UDCustomWorkloadWhiteList) Get(gvk schema.GroupVersionKind) *UDCustomWorkload {
+	for i := range p.Workloads {
+		if p.Workloads[i].GroupVersionKind == gvk {
+			return &p.Workloads[i]
+		}
+	}
+	return nil
+}
+
+type DeletionProtectionResourceList struct {
+	Resources []DeletionProtectionResource `json:"resources,omitempty"`
+}
+
+// DeletionProtectionResource describes a kind of resources protected by the deletion protection webhook.
+type DeletionProtectionResource struct {
+	schema.GroupVersionKind `json:",inline"`
+	// Resource is the plural resource name of this kind, such as "persistentvolumeclaims", which is used in webhook rules.
+	Resource string `json:"resource"`
+	// ReplicasPath is the replicas field path of this kind, such as "spec.replicas". The resources labeled with
+	// Cascading are forbidden to be deleted if the replicas is larger than 0.
+	// It is ignored for PersistentVolumeClaim, PersistentVolume, ConfigMap and Secret which have builtin Cascading
+	// semantics, and the resources of other kinds are always forbidden to be deleted for Cascading if it is empty.
+	ReplicasPath string `json:"replicasPath,omitempty"`
+}
+
+func (p *DeletionProtectionResourceList) Get(gvk schema.GroupVersionKind) *DeletionProtectionResource {
+	for i := range p.Resources {
+		if p.Resources[i].GroupVersionKind == gvk {
+			return &p.Resources[i]
+		}
+	}
+	return nil
+}
+
+type DeletionProtectionApprovers struct {
+	Users  []string `json:"users,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+func (p *DeletionProtectionApprovers) IsApprover(userInfo authenticationv1.UserInfo) bool {
+	for _, user := range p.Users {
+		if user == userInfo.Username {
+			return true
+		}
+	}
+	for _, group := range p.Groups {
+		for _, g := range userInfo.Groups {
+			if group == g {
+				return true
+			}
+		}
+	}
+	return false
+}
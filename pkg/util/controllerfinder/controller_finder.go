@@ -43,6 +43,9 @@ import (
 
 var Finder *ControllerFinder
 
+// maxCustomWorkloadOwnerDepth is the max levels of custom workloads to be resolved from the owner of pods.
+const maxCustomWorkloadOwnerDepth = 3
+
 func InitControllerFinder(mgr manager.Manager) error {
 	Finder = &ControllerFinder{
 		Client: mgr.GetClient(),
@@ -111,6 +114,8 @@ func (r *ControllerFinder) GetExpectedScaleForPods(pods []*corev1.Pod) (int32, e
 	// A mapping from controllers to their scale.
 	podRefs := sets.NewString()
 	controllerScale := map[types.UID]int32{}
+	// the whitelist is loaded once for all pods
+	var whiteList *configuration.CustomWorkloadWhiteList
 	for _, pod := range pods {
 		ref := metav1.GetControllerOf(pod)
 		// ref has already been got, so there is no need to get again
@@ -125,6 +130,17 @@ func (r *ControllerFinder) GetExpectedScaleForPods(pods []*corev1.Pod) (int32, e
 		} else if workload == nil || !workload.Metadata.DeletionTimestamp.IsZero() {
 			continue
 		}
+		// the workload may be controlled by a custom workload, e.g. ReplicaSet controlled by Argo Rollout
+		if whiteList == nil {
+			if whiteList, err = configuration.GetScaleCustomWorkloadWhiteList(r.Client); err != nil {
+				return 0, err
+			}
+		}
+		if workload, err = r.getCustomWorkloadOwner(workload, pod.Namespace, whiteList); err != nil {
+			return 0, err
+		} else if workload == nil || !workload.Metadata.DeletionTimestamp.IsZero() {
+			continue
+		}
 		controllerScale[workload.UID] = workload.Scale
 	}
 	// 2. Add up all the controllers.
@@ -445,6 +461,67 @@ func (r *ControllerFinder) getPodStatefulSetLike(ref ControllerReference, namesp
 	return scaleSelector, nil
 }
 
+// getCustomWorkloadOwner returns the top-level custom workload which controls the workload directly or indirectly,
+// only the custom workloads in the whitelist will be resolved through the scale subresource.
+// It returns the workload itself if it is not controlled by any custom workload in the whitelist.
+func (r *ControllerFinder) getCustomWorkloadOwner(workload *ScaleAndSelector, namespace string,
+	whiteList *configuration.CustomWorkloadWhiteList) (*ScaleAndSelector, error) {
+	if len(whiteList.Workloads) == 0 {
+		return workload, nil
+	}
+	for i := 0; i < maxCustomWorkloadOwnerDepth; i++ {
+		ownerReferences, err := r.getWorkloadOwnerReferences(workload, namespace)
+		if err != nil {
+			return nil, err
+		}
+		controllerRef := metav1.GetControllerOfNoCopy(&metav1.ObjectMeta{OwnerReferences: ownerReferences})
+		if controllerRef == nil {
+			break
+		}
+		gv, err := schema.ParseGroupVersion(controllerRef.APIVersion)
+		if err != nil || !whiteList.IsValid(metav1.GroupKind{Group: gv.Group, Kind: controllerRef.Kind}) {
+			break
+		}
+		ref := ControllerReference{
+			APIVersion: controllerRef.APIVersion,
+			Kind:       controllerRef.Kind,
+			Name:       controllerRef.Name,
+			UID:        controllerRef.UID,
+		}
+		owner, err := r.getScaleController(ref, namespace)
+		if err != nil {
+			return nil, err
+		} else if owner == nil {
+			break
+		}
+		workload = owner
+	}
+	return workload, nil
+}
+
+// getWorkloadOwnerReferences returns the ownerReferences of the workload. The metadata of custom workloads
+// resolved through the scale subresource is the metadata of Scale, which has no ownerReferences,
+// so the workload itself is fetched to get them.
+func (r *ControllerFinder) getWorkloadOwnerReferences(workload *ScaleAndSelector, namespace string) ([]metav1.OwnerReference, error) {
+	if len(workload.Metadata.OwnerReferences) > 0 || workload.APIVersion == "" ||
+		isValidGroupVersionKind(workload.APIVersion, workload.Kind) {
+		return workload.Metadata.OwnerReferences, nil
+	}
+	object := &unstructured.Unstructured{}
+	object.SetAPIVersion(workload.APIVersion)
+	object.SetKind(workload.Kind)
+	if err := r.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: workload.Name}, object); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if workload.UID != "" && object.GetUID() != workload.UID {
+		return nil, nil
+	}
+	return object.GetOwnerReferences(), nil
+}
+
 func getSpecReplicas(workload *unstructured.Unstructured) (int32, error) {
 	obj := workload.UnstructuredContent()
 	if val, found, err := unstructured.NestedInt64(obj, "spec", "replicas"); err == nil && found {
@@ -19,7 +19,26 @@ package controllerfinder
 import (
 	"testing"
 
+	apps "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	scalefake "k8s.io/client-go/scale/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openkruise/kruise/pkg/util"
+	"github.com/openkruise/kruise/pkg/util/configuration"
+	"github.com/openkruise/kruise/pkg/util/fieldindex"
 )
 
 func Test_getSpecReplicas(t *testing.T) {
@@ -82,3 +101,217 @@ func Test_getSpecReplicas(t *testing.T) {
 		})
 	}
 }
+
+func TestGetExpectedScaleForPodsWithCustomWorkloadOwner(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	rolloutGVK := schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}
+	appGVK := schema.GroupVersionKind{Group: "apps.example.io", Version: "v1", Kind: "App"}
+
+	newReplicaSet := func(name string, uid types.UID, replicas int32) *apps.ReplicaSet {
+		return &apps.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      name,
+				UID:       uid,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: rolloutGVK.GroupVersion().String(),
+					Kind:       rolloutGVK.Kind,
+					Name:       "rollout-demo",
+					UID:        "rollout-uid",
+					Controller: pointer.Bool(true),
+				}},
+			},
+			Spec: apps.ReplicaSetSpec{Replicas: pointer.Int32(replicas)},
+		}
+	}
+	newPod := func(name, rsName string, rsUID types.UID) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      name,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       rsName,
+				UID:        rsUID,
+				Controller: pointer.Bool(true),
+			}},
+		}}
+	}
+	pods := []*corev1.Pod{newPod("pod-0", "rs-stable", "rs-stable-uid"), newPod("pod-1", "rs-canary", "rs-canary-uid")}
+
+	rollout := &unstructured.Unstructured{}
+	rollout.SetGroupVersionKind(rolloutGVK)
+	rollout.SetNamespace("default")
+	rollout.SetName("rollout-demo")
+	rollout.SetUID("rollout-uid")
+	rollout.SetOwnerReferences([]metav1.OwnerReference{{
+		APIVersion: appGVK.GroupVersion().String(),
+		Kind:       appGVK.Kind,
+		Name:       "app-demo",
+		UID:        "app-uid",
+		Controller: pointer.Bool(true),
+	}})
+
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{rolloutGVK.GroupVersion(), appGVK.GroupVersion()})
+	mapper.Add(rolloutGVK, meta.RESTScopeNamespace)
+	mapper.Add(appGVK, meta.RESTScopeNamespace)
+	scaleClient := &scalefake.FakeScaleClient{}
+	scaleClient.AddReactor("get", "rollouts", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &autoscalingv1.Scale{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "rollout-demo", UID: "rollout-uid"},
+			Spec:       autoscalingv1.ScaleSpec{Replicas: 5},
+			Status:     autoscalingv1.ScaleStatus{Selector: "app=demo"},
+		}, nil
+	})
+	scaleClient.AddReactor("get", "apps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &autoscalingv1.Scale{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app-demo", UID: "app-uid"},
+			Spec:       autoscalingv1.ScaleSpec{Replicas: 7},
+			Status:     autoscalingv1.ScaleStatus{Selector: "app=demo"},
+		}, nil
+	})
+
+	cases := []struct {
+		name        string
+		whiteList   string
+		expectCount int32
+	}{
+		{
+			name:        "rollout is not in whitelist, sum replicas of replicaSets",
+			expectCount: 3,
+		},
+		{
+			name:        "rollout is in whitelist, use replicas of rollout",
+			whiteList:   `{"workloads":[{"group":"argoproj.io","version":"v1alpha1","kind":"Rollout"}]}`,
+			expectCount: 5,
+		},
+		{
+			name:        "rollout and its owner app are in whitelist, use replicas of app",
+			whiteList:   `{"workloads":[{"group":"argoproj.io","version":"v1alpha1","kind":"Rollout"},{"group":"apps.example.io","version":"v1","kind":"App"}]}`,
+			expectCount: 7,
+		},
+	}
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			objects := []client.Object{newReplicaSet("rs-stable", "rs-stable-uid", 2), newReplicaSet("rs-canary", "rs-canary-uid", 1), rollout.DeepCopy()}
+			if cs.whiteList != "" {
+				objects = append(objects, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: util.GetKruiseNamespace(), Name: configuration.KruiseConfigurationName},
+					Data:       map[string]string{configuration.ScaleCustomWorkloadWhiteListKey: cs.whiteList},
+				})
+			}
+			finder := &ControllerFinder{
+				Client:          fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+				mapper:          mapper,
+				scaleNamespacer: scaleClient,
+			}
+			count, err := finder.GetExpectedScaleForPods(pods)
+			if err != nil {
+				t.Fatalf("GetExpectedScaleForPods failed: %s", err.Error())
+			}
+			if count != cs.expectCount {
+				t.Fatalf("expect count %d, but got %d", cs.expectCount, count)
+			}
+		})
+	}
+}
+
+func TestGetPodsForRefWithCustomWorkloadOwner(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	rolloutGVK := schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}
+
+	newOwnerRef := func(apiVersion, kind, name string, uid types.UID) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{APIVersion: apiVersion, Kind: kind, Name: name, UID: uid, Controller: pointer.Bool(true)}}
+	}
+	newReplicaSet := func(name string, uid types.UID, ownerReferences []metav1.OwnerReference) *apps.ReplicaSet {
+		return &apps.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, UID: uid, OwnerReferences: ownerReferences},
+			Spec:       apps.ReplicaSetSpec{Replicas: pointer.Int32(1)},
+		}
+	}
+	newPod := func(name, rsName string, rsUID types.UID) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       "default",
+				Name:            name,
+				Labels:          map[string]string{"app": "demo"},
+				OwnerReferences: newOwnerRef("apps/v1", "ReplicaSet", rsName, rsUID),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	rolloutRef := newOwnerRef(rolloutGVK.GroupVersion().String(), rolloutGVK.Kind, "rollout-demo", "rollout-uid")
+
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{rolloutGVK.GroupVersion()})
+	mapper.Add(rolloutGVK, meta.RESTScopeNamespace)
+	scaleClient := &scalefake.FakeScaleClient{}
+	scaleClient.AddReactor("get", "rollouts", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, &autoscalingv1.Scale{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "rollout-demo", UID: "rollout-uid"},
+			Spec:       autoscalingv1.ScaleSpec{Replicas: 2},
+			Status:     autoscalingv1.ScaleStatus{Selector: "app=demo"},
+		}, nil
+	})
+
+	cases := []struct {
+		name       string
+		whiteList  string
+		expectPods sets.String
+	}{
+		{
+			name:       "rollout is not in whitelist, all pods matching the selector",
+			expectPods: sets.NewString("pod-0", "pod-1", "pod-2"),
+		},
+		{
+			name:       "rollout is in whitelist, only pods controlled by rollout",
+			whiteList:  `{"workloads":[{"group":"argoproj.io","version":"v1alpha1","kind":"Rollout"}]}`,
+			expectPods: sets.NewString("pod-0", "pod-1"),
+		},
+	}
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			objects := []client.Object{
+				newReplicaSet("rs-stable", "rs-stable-uid", rolloutRef),
+				newReplicaSet("rs-canary", "rs-canary-uid", rolloutRef),
+				newReplicaSet("rs-other", "rs-other-uid", nil),
+				newPod("pod-0", "rs-stable", "rs-stable-uid"),
+				newPod("pod-1", "rs-canary", "rs-canary-uid"),
+				newPod("pod-2", "rs-other", "rs-other-uid"),
+			}
+			if cs.whiteList != "" {
+				objects = append(objects, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: util.GetKruiseNamespace(), Name: configuration.KruiseConfigurationName},
+					Data:       map[string]string{configuration.ScaleCustomWorkloadWhiteListKey: cs.whiteList},
+				})
+			}
+			finder := &ControllerFinder{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).
+					WithIndex(&corev1.Pod{}, fieldindex.IndexNameForOwnerRefUID, func(obj client.Object) []string {
+						var owners []string
+						for _, ref := range obj.GetOwnerReferences() {
+							owners = append(owners, string(ref.UID))
+						}
+						return owners
+					}).Build(),
+				mapper:          mapper,
+				scaleNamespacer: scaleClient,
+			}
+			pods, replicas, err := finder.GetPodsForRef(rolloutGVK.GroupVersion().String(), rolloutGVK.Kind, "default", "rollout-demo", true)
+			if err != nil {
+				t.Fatalf("GetPodsForRef failed: %s", err.Error())
+			}
+			if replicas != 2 {
+				t.Fatalf("expect replicas 2, but got %d", replicas)
+			}
+			podNames := sets.NewString()
+			for _, pod := range pods {
+				podNames.Insert(pod.Name)
+			}
+			if !podNames.Equal(cs.expectPods) {
+				t.Fatalf("expect pods %v, but got %v", cs.expectPods.List(), podNames.List())
+			}
+		})
+	}
+}
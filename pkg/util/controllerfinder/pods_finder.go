@@ -21,11 +21,13 @@ import (
 
 	"github.com/openkruise/kruise/pkg/util"
 	utilclient "github.com/openkruise/kruise/pkg/util/client"
+	"github.com/openkruise/kruise/pkg/util/configuration"
 	"github.com/openkruise/kruise/pkg/util/fieldindex"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
 	kubecontroller "k8s.io/kubernetes/pkg/controller"
@@ -37,6 +39,9 @@ func (r *ControllerFinder) GetPodsForRef(apiVersion, kind, ns, name string, acti
 	var workloadUIDs []types.UID
 	var workloadReplicas int32
 	var labelSelector *metav1.LabelSelector
+	// the uid of custom workload in the scale whitelist, whose Pods are controlled by other workloads
+	var customWorkloadUID types.UID
+	var whiteList *configuration.CustomWorkloadWhiteList
 	switch kind {
 	// ReplicaSet
 	case ControllerKindRS.Kind:
@@ -90,6 +95,12 @@ func (r *ControllerFinder) GetPodsForRef(apiVersion, kind, ns, name string, acti
 		workloadReplicas = obj.Scale
 		labelSelector = obj.Selector
 		workloadUIDs = append(workloadUIDs, obj.UID)
+		if whiteList, err = configuration.GetScaleCustomWorkloadWhiteList(r.Client); err != nil {
+			return nil, -1, err
+		}
+		if gv, err := schema.ParseGroupVersion(apiVersion); err == nil && whiteList.IsValid(metav1.GroupKind{Group: gv.Group, Kind: kind}) {
+			customWorkloadUID = obj.UID
+		}
 	}
 	if workloadReplicas == 0 {
 		return nil, workloadReplicas, nil
@@ -139,10 +150,51 @@ func (r *ControllerFinder) GetPodsForRef(apiVersion, kind, ns, name string, acti
 		if err != nil {
 			return nil, -1, err
 		}
+		// the label selector may match Pods of other workloads, so only keep the Pods
+		// whose owners are controlled by the custom workload.
+		if customWorkloadUID != "" {
+			matchedPods, err = r.filterPodsOfCustomWorkload(matchedPods, customWorkloadUID, ns, whiteList)
+			if err != nil {
+				return nil, -1, err
+			}
+		}
 	}
 	return matchedPods, workloadReplicas, nil
 }
 
+// filterPodsOfCustomWorkload returns the Pods which are controlled by the custom workload with the uid,
+// through the owners of Pods resolved by getCustomWorkloadOwner with the whitelist.
+func (r *ControllerFinder) filterPodsOfCustomWorkload(pods []*corev1.Pod, uid types.UID, ns string,
+	whiteList *configuration.CustomWorkloadWhiteList) ([]*corev1.Pod, error) {
+	// the uid of the controller of Pods -> the uid of the top-level custom workload
+	ownerUIDs := map[types.UID]types.UID{}
+	filteredPods := make([]*corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		ref := metav1.GetControllerOfNoCopy(pod)
+		if ref == nil {
+			continue
+		}
+		ownerUID, ok := ownerUIDs[ref.UID]
+		if !ok {
+			workload, err := r.GetScaleAndSelectorForRef(ref.APIVersion, ref.Kind, ns, ref.Name, ref.UID)
+			if err != nil {
+				return nil, err
+			}
+			if workload != nil {
+				if workload, err = r.getCustomWorkloadOwner(workload, ns, whiteList); err != nil {
+					return nil, err
+				}
+				ownerUID = workload.UID
+			}
+			ownerUIDs[ref.UID] = ownerUID
+		}
+		if ownerUID == uid {
+			filteredPods = append(filteredPods, pod)
+		}
+	}
+	return filteredPods, nil
+}
+
 func (r *ControllerFinder) getReplicaSetsForObject(scale *ScaleAndSelector) ([]appsv1.ReplicaSet, error) {
 	// List ReplicaSets owned by this Deployment
 	rsList := &appsv1.ReplicaSetList{}
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadspread
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	schedulecorev1 "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	utilclient "github.com/openkruise/kruise/pkg/util/client"
+)
+
+// GetBroadcastJobReplicas returns the number of nodes that the BroadcastJob is going to run Pods on.
+// Status.Desired of BroadcastJob is only updated after its first Pods are created, so before that the replicas
+// are calculated from the nodes which match the node affinity of its Pod template, tolerated by the Pod and ready if required.
+func GetBroadcastJobReplicas(reader client.Reader, job *appsv1alpha1.BroadcastJob) (int32, error) {
+	if job.Status.Desired > 0 {
+		return job.Status.Desired, nil
+	}
+	nodeList := &corev1.NodeList{}
+	if err := reader.List(context.TODO(), nodeList, utilclient.DisableDeepCopy); err != nil {
+		return 0, err
+	}
+
+	mockPod := &corev1.Pod{ObjectMeta: job.Spec.Template.ObjectMeta, Spec: job.Spec.Template.Spec}
+	requiredNodeAffinity := nodeaffinity.GetRequiredNodeAffinity(mockPod)
+	podToleratesUnschedulable := schedulecorev1.TolerationsTolerateTaint(mockPod.Spec.Tolerations, &corev1.Taint{
+		Key:    corev1.TaintNodeUnschedulable,
+		Effect: corev1.TaintEffectNoSchedule,
+	})
+	var replicas int32
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		if job.Spec.SkipNotReadyNodes && !isNodeReady(node) {
+			continue
+		}
+		if len(mockPod.Spec.NodeName) != 0 && mockPod.Spec.NodeName != node.Name {
+			continue
+		}
+		if match, _ := requiredNodeAffinity.Match(node); !match {
+			continue
+		}
+		if node.Spec.Unschedulable && !podToleratesUnschedulable {
+			continue
+		}
+		if _, untolerated := schedulecorev1.FindMatchingUntoleratedTaint(node.Spec.Taints, mockPod.Spec.Tolerations, func(t *corev1.Taint) bool {
+			return t.Effect == corev1.TaintEffectNoSchedule || t.Effect == corev1.TaintEffectNoExecute
+		}); untolerated {
+			continue
+		}
+		replicas++
+	}
+	return replicas, nil
+}
+
+// getBroadcastJobPodSubset returns the first subset whose RequiredNodeSelectorTerm matches the node that
+// the BroadcastJob Pod is pinned to. Such Pod can only run on that node, so its subset is decided by the
+// node rather than the replicas of subsets. An empty name is returned if no subset matches the node.
+func (h *Handler) getBroadcastJobPodSubset(ws *appsv1alpha1.WorkloadSpread, pod *corev1.Pod) (string, error) {
+	nodeName := getPinnedNodeName(pod)
+	if nodeName == "" {
+		return "", nil
+	}
+	node := &corev1.Node{}
+	if err := h.Get(context.TODO(), client.ObjectKey{Name: nodeName}, node); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	for i := range ws.Spec.Subsets {
+		subset := &ws.Spec.Subsets[i]
+		if subset.RequiredNodeSelectorTerm == nil {
+			return subset.Name, nil
+		}
+		nodeSelector, err := nodeaffinity.NewNodeSelector(&corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{*subset.RequiredNodeSelectorTerm}})
+		if err != nil {
+			return "", err
+		}
+		if nodeSelector.Match(node) {
+			return subset.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// getBroadcastJobSuitableSubset returns the status of the subset that the node of the BroadcastJob Pod belongs to.
+// Nil is returned if the subset has no room left, so that the Pod is not counted in any subset.
+func (h *Handler) getBroadcastJobSuitableSubset(ws *appsv1alpha1.WorkloadSpread, pod *corev1.Pod,
+	subsetStatuses []appsv1alpha1.WorkloadSpreadSubsetStatus) (*appsv1alpha1.WorkloadSpreadSubsetStatus, error) {
+	subsetName, err := h.getBroadcastJobPodSubset(ws, pod)
+	if err != nil || subsetName == "" {
+		return nil, err
+	}
+	subset := getSpecificSubset(subsetStatuses, subsetName)
+	if subset != nil && subset.MissingReplicas == 0 {
+		klog.V(3).Infof("WorkloadSpread (%s/%s) subset %s of the node of BroadcastJob Pod (%s/%s) is full",
+			ws.Namespace, ws.Name, subsetName, pod.Namespace, pod.Name)
+		return nil, nil
+	}
+	return subset, nil
+}
+
+// getPinnedNodeName returns the node name that the Pod is bound to by spec.nodeName or
+// by the metadata.name matchFields of its required node affinity.
+func getPinnedNodeName(pod *corev1.Pod) string {
+	if pod.Spec.NodeName != "" {
+		return pod.Spec.NodeName
+	}
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil ||
+		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return ""
+	}
+	for _, term := range pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, req := range term.MatchFields {
+			if req.Key == metav1.ObjectNameField && req.Operator == corev1.NodeSelectorOpIn && len(req.Values) == 1 {
+				return req.Values[0]
+			}
+		}
+	}
+	return ""
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
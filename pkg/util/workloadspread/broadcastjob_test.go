@@ -0,0 +1,238 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadspread
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func newBroadcastJobNode(name, zone string, ready bool, taints ...corev1.Taint) *corev1.Node {
+	status := corev1.ConditionTrue
+	if !ready {
+		status = corev1.ConditionFalse
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"zone": zone}},
+		Spec:       corev1.NodeSpec{Taints: taints},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}}},
+	}
+}
+
+func TestGetBroadcastJobReplicas(t *testing.T) {
+	nodes := []client.Object{
+		newBroadcastJobNode("node-a1", "a", true),
+		newBroadcastJobNode("node-a2", "a", false),
+		newBroadcastJobNode("node-b1", "b", true),
+		newBroadcastJobNode("node-b2", "b", true, corev1.Taint{Key: "dedicated", Effect: corev1.TaintEffectNoSchedule}),
+	}
+	cases := []struct {
+		name     string
+		getJob   func() *appsv1alpha1.BroadcastJob
+		expected int32
+	}{
+		{
+			name: "all nodes without taints",
+			getJob: func() *appsv1alpha1.BroadcastJob {
+				return &appsv1alpha1.BroadcastJob{}
+			},
+			expected: 3,
+		},
+		{
+			name: "skip not ready nodes",
+			getJob: func() *appsv1alpha1.BroadcastJob {
+				return &appsv1alpha1.BroadcastJob{Spec: appsv1alpha1.BroadcastJobSpec{SkipNotReadyNodes: true}}
+			},
+			expected: 2,
+		},
+		{
+			name: "node selector and toleration",
+			getJob: func() *appsv1alpha1.BroadcastJob {
+				job := &appsv1alpha1.BroadcastJob{}
+				job.Spec.Template.Spec.NodeSelector = map[string]string{"zone": "b"}
+				job.Spec.Template.Spec.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}
+				return job
+			},
+			expected: 2,
+		},
+		{
+			name: "desired in status",
+			getJob: func() *appsv1alpha1.BroadcastJob {
+				return &appsv1alpha1.BroadcastJob{Status: appsv1alpha1.BroadcastJobStatus{Desired: 5}}
+			},
+			expected: 5,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(nodes...).Build()
+			replicas, err := GetBroadcastJobReplicas(reader, cs.getJob())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if replicas != cs.expected {
+				t.Fatalf("expect replicas %d, but got %d", cs.expected, replicas)
+			}
+		})
+	}
+}
+
+func TestGetBroadcastJobPodSubset(t *testing.T) {
+	maxReplicas := intstr.FromInt(1)
+	ws := &appsv1alpha1.WorkloadSpread{
+		Spec: appsv1alpha1.WorkloadSpreadSpec{
+			Subsets: []appsv1alpha1.WorkloadSpreadSubset{
+				{
+					Name: "subset-a",
+					RequiredNodeSelectorTerm: &corev1.NodeSelectorTerm{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}},
+					},
+					MaxReplicas: &maxReplicas,
+				},
+				{
+					Name: "subset-b",
+					RequiredNodeSelectorTerm: &corev1.NodeSelectorTerm{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"b"}}},
+					},
+				},
+			},
+		},
+	}
+	newPinnedPod := func(nodeName string) *corev1.Pod {
+		return &corev1.Pod{Spec: corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchFields: []corev1.NodeSelectorRequirement{{Key: metav1.ObjectNameField, Operator: corev1.NodeSelectorOpIn, Values: []string{nodeName}}},
+			}}},
+		}}}}
+	}
+	cases := []struct {
+		name     string
+		pod      *corev1.Pod
+		expected string
+	}{
+		{
+			name:     "pod pinned to node in zone a",
+			pod:      newPinnedPod("node-a1"),
+			expected: "subset-a",
+		},
+		{
+			name:     "pod pinned to node in zone b",
+			pod:      newPinnedPod("node-b1"),
+			expected: "subset-b",
+		},
+		{
+			name:     "pod pinned to node in no subset",
+			pod:      newPinnedPod("node-c1"),
+			expected: "",
+		},
+		{
+			name:     "pod pinned to node not found",
+			pod:      newPinnedPod("node-d1"),
+			expected: "",
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			h := &Handler{fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				newBroadcastJobNode("node-a1", "a", true),
+				newBroadcastJobNode("node-b1", "b", true),
+				newBroadcastJobNode("node-c1", "c", true),
+			).Build()}
+			subset, err := h.getBroadcastJobPodSubset(ws, cs.pod)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if subset != cs.expected {
+				t.Fatalf("expect subset %q, but got %q", cs.expected, subset)
+			}
+		})
+	}
+}
+
+func TestGetBroadcastJobSuitableSubset(t *testing.T) {
+	ws := &appsv1alpha1.WorkloadSpread{
+		Spec: appsv1alpha1.WorkloadSpreadSpec{
+			Subsets: []appsv1alpha1.WorkloadSpreadSubset{
+				{
+					Name: "subset-a",
+					RequiredNodeSelectorTerm: &corev1.NodeSelectorTerm{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}},
+					},
+				},
+				{Name: "subset-b"},
+			},
+		},
+	}
+	cases := []struct {
+		name           string
+		nodeName       string
+		missingOfA     int32
+		expectedSubset string
+	}{
+		{
+			name:           "subset of the node has room",
+			nodeName:       "node-a1",
+			missingOfA:     1,
+			expectedSubset: "subset-a",
+		},
+		{
+			name:       "subset of the node is full",
+			nodeName:   "node-a1",
+			missingOfA: 0,
+		},
+		{
+			name:           "subset without limit",
+			nodeName:       "node-b1",
+			missingOfA:     0,
+			expectedSubset: "subset-b",
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			h := &Handler{fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				newBroadcastJobNode("node-a1", "a", true),
+				newBroadcastJobNode("node-b1", "b", true),
+			).Build()}
+			subsetStatuses := []appsv1alpha1.WorkloadSpreadSubsetStatus{
+				{Name: "subset-a", MissingReplicas: cs.missingOfA},
+				{Name: "subset-b", MissingReplicas: -1},
+			}
+			pod := &corev1.Pod{Spec: corev1.PodSpec{NodeName: cs.nodeName}}
+			subset, err := h.getBroadcastJobSuitableSubset(ws, pod, subsetStatuses)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var subsetName string
+			if subset != nil {
+				subsetName = subset.Name
+			}
+			if subsetName != cs.expectedSubset {
+				t.Fatalf("expect subset %q, but got %q", cs.expectedSubset, subsetName)
+			}
+		})
+	}
+}
@@ -0,0 +1,665 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadspread
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	toolscache "k8s.io/client-go/tools/cache"
+	schedulecorev1 "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+	"k8s.io/klog/v2"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+	kubecontroller "k8s.io/kubernetes/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	utilclient "github.com/openkruise/kruise/pkg/util/client"
+	"github.com/openkruise/kruise/pkg/util/fieldindex"
+)
+
+// subsetCapacityTTL bounds the staleness of a cached subset capacity in case some events are missed.
+// An expired capacity is still used while it is recalculated in the background.
+const subsetCapacityTTL = 5 * time.Minute
+
+// assumedPodTimeout is how long the requests of an admitted Pod are held on its subset before the Pod is seen
+// by the informer, the same as the timeout of CreatingPods in the WorkloadSpread controller.
+const assumedPodTimeout = 30 * time.Second
+
+// nodeCapacity is the allocatable of a node and the requests of active Pods on it.
+type nodeCapacity struct {
+	allocatable corev1.ResourceList
+	taints      []corev1.Taint
+	// pods is the requests of active Pods on the node keyed by Pod UID, so a Pod is never counted twice.
+	pods      map[types.UID]corev1.ResourceList
+	requested corev1.ResourceList
+}
+
+type subsetCapacity struct {
+	// spec is the RequiredNodeSelectorTerm of the subset that this capacity was calculated with.
+	spec     string
+	selector *nodeaffinity.NodeSelector
+	nodes    map[string]*nodeCapacity
+	expireAt time.Time
+}
+
+// assumedPod is a Pod admitted into a subset but not bound to a node yet, whose requests are held on a node
+// of the subset so that a burst of creations does not see the same free capacity.
+type assumedPod struct {
+	key      string
+	nodeName string
+	requests corev1.ResourceList
+	// expireAt is zero once the Pod is seen by the informer, then it is held until the Pod is bound or deleted.
+	expireAt time.Time
+}
+
+// SubsetCapacityCache caches the node capacity of each subset, which is updated in place by node and Pod events.
+type SubsetCapacityCache struct {
+	sync.RWMutex
+	subsets map[string]*subsetCapacity
+	// nodeSubsets indexes the keys of the cached subsets by the names of their nodes.
+	nodeSubsets map[string]sets.String
+	// assumedPods is keyed by the namespace and the name, or the generated UID, of the Pods.
+	assumedPods map[string]*assumedPod
+	// refreshing is the keys of subsets whose capacity is being recalculated in the background.
+	refreshing sets.String
+}
+
+var subsetCapacityCache = newSubsetCapacityCache()
+
+func newSubsetCapacityCache() *SubsetCapacityCache {
+	return &SubsetCapacityCache{
+		subsets:     map[string]*subsetCapacity{},
+		nodeSubsets: map[string]sets.String{},
+		assumedPods: map[string]*assumedPod{},
+		refreshing:  sets.NewString(),
+	}
+}
+
+var registerCapacityHandlersOnce sync.Once
+
+func subsetCapacityKey(ws *appsv1alpha1.WorkloadSpread, subsetName string) string {
+	return ws.Namespace + "/" + ws.Name + "/" + subsetName
+}
+
+// assumedPodKey returns the key of the Pod in assumedPods, which is the generated UID recorded in
+// the Pod annotation if the Pod had no name when it was admitted.
+func assumedPodKey(pod *corev1.Pod) string {
+	podID := pod.Name
+	if str := pod.Annotations[MatchedWorkloadSpreadSubsetAnnotations]; str != "" {
+		injectWS := &InjectWorkloadSpread{}
+		if err := json.Unmarshal([]byte(str), injectWS); err == nil && injectWS.UID != "" {
+			podID = injectWS.UID
+		}
+	}
+	return pod.Namespace + "/" + podID
+}
+
+// assumedPodUID is the key of the requests of an assumed Pod in the node.
+func assumedPodUID(podKey string) types.UID {
+	return types.UID("assumed/" + podKey)
+}
+
+func newNodeCapacity(node *corev1.Node, pods []corev1.Pod) *nodeCapacity {
+	n := &nodeCapacity{
+		allocatable: node.Status.Allocatable.DeepCopy(),
+		taints:      node.Spec.Taints,
+		pods:        map[types.UID]corev1.ResourceList{},
+		requested:   corev1.ResourceList{},
+	}
+	for i := range pods {
+		if kubecontroller.IsPodActive(&pods[i]) {
+			n.addPod(&pods[i])
+		}
+	}
+	return n
+}
+
+func (n *nodeCapacity) addPod(pod *corev1.Pod) {
+	requests, _ := resourcehelper.PodRequestsAndLimits(pod)
+	n.addRequests(pod.UID, requests)
+}
+
+func (n *nodeCapacity) addRequests(uid types.UID, requests corev1.ResourceList) {
+	n.removePod(uid)
+	n.pods[uid] = requests
+	for name, quantity := range requests {
+		requested := n.requested[name]
+		requested.Add(quantity)
+		n.requested[name] = requested
+	}
+}
+
+func (n *nodeCapacity) removePod(uid types.UID) {
+	requests, ok := n.pods[uid]
+	if !ok {
+		return
+	}
+	delete(n.pods, uid)
+	for name, quantity := range requests {
+		requested := n.requested[name]
+		requested.Sub(quantity)
+		n.requested[name] = requested
+	}
+}
+
+// fits returns true if the node has enough free resources for requests and a slot for one more Pod.
+func (n *nodeCapacity) fits(requests corev1.ResourceList) bool {
+	if pods, ok := n.allocatable[corev1.ResourcePods]; ok && pods.Value() <= int64(len(n.pods)) {
+		return false
+	}
+	for name, quantity := range requests {
+		if quantity.IsZero() {
+			continue
+		}
+		available, ok := n.allocatable[name]
+		if !ok {
+			return false
+		}
+		available = available.DeepCopy()
+		available.Sub(n.requested[name])
+		if available.Cmp(quantity) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// findFitNode returns the name of a node that tolerates the Pod and fits requests, or "" if there is none.
+func (capacity *subsetCapacity) findFitNode(requests corev1.ResourceList, tolerations []corev1.Toleration) string {
+	for name, node := range capacity.nodes {
+		if _, untolerated := schedulecorev1.FindMatchingUntoleratedTaint(node.taints, tolerations, func(t *corev1.Taint) bool {
+			return t.Effect == corev1.TaintEffectNoSchedule || t.Effect == corev1.TaintEffectNoExecute
+		}); untolerated {
+			continue
+		}
+		if node.fits(requests) {
+			return name
+		}
+	}
+	return ""
+}
+
+// get returns the cached capacity of the subset and whether it should be recalculated.
+func (c *SubsetCapacityCache) get(key, spec string) (*subsetCapacity, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	capacity := c.subsets[key]
+	if capacity == nil || capacity.spec != spec {
+		return nil, true
+	}
+	return capacity, time.Now().After(capacity.expireAt)
+}
+
+// set replaces the capacity of the subset, the Pods assumed in the subset are held on the new capacity.
+func (c *SubsetCapacityCache) set(key string, capacity *subsetCapacity) {
+	c.Lock()
+	defer c.Unlock()
+	c.removeSubset(key)
+	c.subsets[key] = capacity
+	for nodeName := range capacity.nodes {
+		c.indexNode(nodeName, key)
+	}
+	for podKey, assumed := range c.assumedPods {
+		if assumed.key != key {
+			continue
+		}
+		if node, ok := capacity.nodes[assumed.nodeName]; ok {
+			node.addRequests(assumedPodUID(podKey), assumed.requests)
+		} else {
+			delete(c.assumedPods, podKey)
+		}
+	}
+}
+
+// removeSubset drops the capacity of the subset from the cache and the node index. The caller must hold the lock.
+func (c *SubsetCapacityCache) removeSubset(key string) {
+	capacity, ok := c.subsets[key]
+	if !ok {
+		return
+	}
+	delete(c.subsets, key)
+	for nodeName := range capacity.nodes {
+		c.unindexNode(nodeName, key)
+	}
+}
+
+func (c *SubsetCapacityCache) indexNode(nodeName, key string) {
+	keys, ok := c.nodeSubsets[nodeName]
+	if !ok {
+		keys = sets.NewString()
+		c.nodeSubsets[nodeName] = keys
+	}
+	keys.Insert(key)
+}
+
+func (c *SubsetCapacityCache) unindexNode(nodeName, key string) {
+	keys, ok := c.nodeSubsets[nodeName]
+	if !ok {
+		return
+	}
+	keys.Delete(key)
+	if keys.Len() == 0 {
+		delete(c.nodeSubsets, nodeName)
+	}
+}
+
+// forgetAssumedPod releases the requests held for an assumed Pod. The caller must hold the lock.
+func (c *SubsetCapacityCache) forgetAssumedPod(podKey string) {
+	assumed, ok := c.assumedPods[podKey]
+	if !ok {
+		return
+	}
+	delete(c.assumedPods, podKey)
+	if capacity, ok := c.subsets[assumed.key]; ok {
+		if node, ok := capacity.nodes[assumed.nodeName]; ok {
+			node.removePod(assumedPodUID(podKey))
+		}
+	}
+}
+
+// expireAssumedPods releases the assumed Pods that have not been seen in time, e.g., rejected by the apiserver.
+// The caller must hold the lock.
+func (c *SubsetCapacityCache) expireAssumedPods(now time.Time) {
+	for podKey, assumed := range c.assumedPods {
+		if !assumed.expireAt.IsZero() && now.After(assumed.expireAt) {
+			c.forgetAssumedPod(podKey)
+		}
+	}
+}
+
+// AssumePod holds the requests of a Pod admitted into the subset on a node that fits it, until the Pod is bound
+// or deleted. It does nothing if the capacity of the subset is not cached or no node fits the Pod.
+func (c *SubsetCapacityCache) AssumePod(key, podKey string, requests corev1.ResourceList, tolerations []corev1.Toleration) {
+	c.Lock()
+	defer c.Unlock()
+	c.expireAssumedPods(time.Now())
+	c.forgetAssumedPod(podKey)
+	capacity, ok := c.subsets[key]
+	if !ok {
+		return
+	}
+	nodeName := capacity.findFitNode(requests, tolerations)
+	if nodeName == "" {
+		return
+	}
+	capacity.nodes[nodeName].addRequests(assumedPodUID(podKey), requests)
+	c.assumedPods[podKey] = &assumedPod{
+		key:      key,
+		nodeName: nodeName,
+		requests: requests,
+		expireAt: time.Now().Add(assumedPodTimeout),
+	}
+}
+
+// startRefreshing returns false if the subset is already being recalculated.
+func (c *SubsetCapacityCache) startRefreshing(key string) bool {
+	c.Lock()
+	defer c.Unlock()
+	if c.refreshing.Has(key) {
+		return false
+	}
+	c.refreshing.Insert(key)
+	return true
+}
+
+func (c *SubsetCapacityCache) finishRefreshing(key string) {
+	c.Lock()
+	defer c.Unlock()
+	c.refreshing.Delete(key)
+}
+
+// UpdatePod adds the requests of an active Pod to its node in the cached subsets, or removes them otherwise.
+// An assumed Pod is released once it is bound or no longer active.
+func (c *SubsetCapacityCache) UpdatePod(pod *corev1.Pod) {
+	active := kubecontroller.IsPodActive(pod)
+	c.Lock()
+	defer c.Unlock()
+	if len(c.assumedPods) > 0 {
+		c.expireAssumedPods(time.Now())
+		podKey := assumedPodKey(pod)
+		if assumed, ok := c.assumedPods[podKey]; ok {
+			if pod.Spec.NodeName == "" && active {
+				assumed.expireAt = time.Time{}
+			} else {
+				c.forgetAssumedPod(podKey)
+			}
+		}
+	}
+	if pod.Spec.NodeName == "" {
+		return
+	}
+	for key := range c.nodeSubsets[pod.Spec.NodeName] {
+		node, ok := c.subsets[key].nodes[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+		if active {
+			node.addPod(pod)
+		} else {
+			node.removePod(pod.UID)
+		}
+	}
+}
+
+// DeletePod removes the requests of the Pod from its node in the cached subsets.
+func (c *SubsetCapacityCache) DeletePod(pod *corev1.Pod) {
+	c.Lock()
+	defer c.Unlock()
+	if len(c.assumedPods) > 0 {
+		c.forgetAssumedPod(assumedPodKey(pod))
+	}
+	if pod.Spec.NodeName == "" {
+		return
+	}
+	for key := range c.nodeSubsets[pod.Spec.NodeName] {
+		if node, ok := c.subsets[key].nodes[pod.Spec.NodeName]; ok {
+			node.removePod(pod.UID)
+		}
+	}
+}
+
+// UpdateNode updates the node in the cached subsets. The node is added to the subsets that it newly matches,
+// with the Pods on it listed from reader, and removed from the subsets that it no longer matches.
+func (c *SubsetCapacityCache) UpdateNode(reader client.Reader, node *corev1.Node) error {
+	c.Lock()
+	defer c.Unlock()
+	var pods []corev1.Pod
+	var podsListed bool
+	for key, capacity := range c.subsets {
+		if node.Spec.Unschedulable || !capacity.selector.Match(node) {
+			delete(capacity.nodes, node.Name)
+			c.unindexNode(node.Name, key)
+			continue
+		}
+		if existing, ok := capacity.nodes[node.Name]; ok {
+			existing.allocatable = node.Status.Allocatable.DeepCopy()
+			existing.taints = node.Spec.Taints
+			continue
+		}
+		if !podsListed {
+			podList := &corev1.PodList{}
+			if err := reader.List(context.TODO(), podList, client.MatchingFields{fieldindex.IndexNameForPodNodeName: node.Name}, utilclient.DisableDeepCopy); err != nil {
+				return err
+			}
+			pods, podsListed = podList.Items, true
+		}
+		capacity.nodes[node.Name] = newNodeCapacity(node, pods)
+		c.indexNode(node.Name, key)
+	}
+	return nil
+}
+
+// DeleteNode removes the node from the cached subsets.
+func (c *SubsetCapacityCache) DeleteNode(nodeName string) {
+	c.Lock()
+	defer c.Unlock()
+	for key := range c.nodeSubsets[nodeName] {
+		delete(c.subsets[key].nodes, nodeName)
+	}
+	delete(c.nodeSubsets, nodeName)
+}
+
+// DeleteWorkloadSpread drops the cached capacities of all subsets of the WorkloadSpread.
+func (c *SubsetCapacityCache) DeleteWorkloadSpread(namespace, name string) {
+	c.Lock()
+	defer c.Unlock()
+	c.pruneSubsets(namespace+"/"+name+"/", sets.NewString())
+}
+
+// PruneSubsets drops the cached capacities of the subsets that have been removed from the WorkloadSpread.
+func (c *SubsetCapacityCache) PruneSubsets(ws *appsv1alpha1.WorkloadSpread) {
+	subsetNames := sets.NewString()
+	for i := range ws.Spec.Subsets {
+		subsetNames.Insert(ws.Spec.Subsets[i].Name)
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.pruneSubsets(ws.Namespace+"/"+ws.Name+"/", subsetNames)
+}
+
+func (c *SubsetCapacityCache) pruneSubsets(prefix string, subsetNames sets.String) {
+	for key := range c.subsets {
+		if strings.HasPrefix(key, prefix) && !subsetNames.Has(strings.TrimPrefix(key, prefix)) {
+			c.removeSubset(key)
+		}
+	}
+	for podKey, assumed := range c.assumedPods {
+		if _, ok := c.subsets[assumed.key]; !ok {
+			delete(c.assumedPods, podKey)
+		}
+	}
+}
+
+// InvalidateAll drops all cached capacities.
+func (c *SubsetCapacityCache) InvalidateAll() {
+	c.Lock()
+	defer c.Unlock()
+	c.subsets = map[string]*subsetCapacity{}
+	c.nodeSubsets = map[string]sets.String{}
+	c.assumedPods = map[string]*assumedPod{}
+}
+
+// RegisterSubsetCapacityHandlers keeps the subset capacity cache up to date with node, Pod and WorkloadSpread events
+// of the informer cache.
+func RegisterSubsetCapacityHandlers(c cache.Cache) error {
+	var err error
+	registerCapacityHandlersOnce.Do(func() {
+		var nodeInformer, podInformer, wsInformer cache.Informer
+		if nodeInformer, err = c.GetInformer(context.TODO(), &corev1.Node{}); err != nil {
+			return
+		}
+		updateNode := func(node *corev1.Node) {
+			if err := subsetCapacityCache.UpdateNode(c, node); err != nil {
+				klog.Errorf("Failed to update node %s in subset capacity cache, invalidate all: %v", node.Name, err)
+				subsetCapacityCache.InvalidateAll()
+			}
+		}
+		nodeInformer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				if node, ok := obj.(*corev1.Node); ok {
+					updateNode(node)
+				}
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldNode, oldOK := oldObj.(*corev1.Node)
+				newNode, newOK := newObj.(*corev1.Node)
+				if !oldOK || !newOK {
+					return
+				}
+				if !reflect.DeepEqual(oldNode.Labels, newNode.Labels) ||
+					!reflect.DeepEqual(oldNode.Status.Allocatable, newNode.Status.Allocatable) ||
+					!reflect.DeepEqual(oldNode.Spec.Taints, newNode.Spec.Taints) ||
+					oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable {
+					updateNode(newNode)
+				}
+			},
+			DeleteFunc: func(obj interface{}) {
+				if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				if node, ok := obj.(*corev1.Node); ok {
+					subsetCapacityCache.DeleteNode(node.Name)
+				}
+			},
+		})
+
+		if podInformer, err = c.GetInformer(context.TODO(), &corev1.Pod{}); err != nil {
+			return
+		}
+		podInformer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				if pod, ok := obj.(*corev1.Pod); ok {
+					subsetCapacityCache.UpdatePod(pod)
+				}
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldPod, oldOK := oldObj.(*corev1.Pod)
+				newPod, newOK := newObj.(*corev1.Pod)
+				if !oldOK || !newOK {
+					return
+				}
+				if oldPod.Spec.NodeName != newPod.Spec.NodeName ||
+					kubecontroller.IsPodActive(oldPod) != kubecontroller.IsPodActive(newPod) {
+					subsetCapacityCache.UpdatePod(newPod)
+				}
+			},
+			DeleteFunc: func(obj interface{}) {
+				if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				if pod, ok := obj.(*corev1.Pod); ok {
+					subsetCapacityCache.DeletePod(pod)
+				}
+			},
+		})
+
+		if wsInformer, err = c.GetInformer(context.TODO(), &appsv1alpha1.WorkloadSpread{}); err != nil {
+			return
+		}
+		wsInformer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldWS, oldOK := oldObj.(*appsv1alpha1.WorkloadSpread)
+				newWS, newOK := newObj.(*appsv1alpha1.WorkloadSpread)
+				if !oldOK || !newOK {
+					return
+				}
+				if !reflect.DeepEqual(oldWS.Spec.Subsets, newWS.Spec.Subsets) {
+					subsetCapacityCache.PruneSubsets(newWS)
+				}
+			},
+			DeleteFunc: func(obj interface{}) {
+				if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				if ws, ok := obj.(*appsv1alpha1.WorkloadSpread); ok {
+					subsetCapacityCache.DeleteWorkloadSpread(ws.Namespace, ws.Name)
+				}
+			},
+		})
+	})
+	return err
+}
+
+// isSimulationScheduleEnabled returns true if the webhook should check whether the subsets can fit the Pod.
+func isSimulationScheduleEnabled(ws *appsv1alpha1.WorkloadSpread) bool {
+	strategy := ws.Spec.ScheduleStrategy
+	return strategy.Type == appsv1alpha1.AdaptiveWorkloadSpreadScheduleStrategyType &&
+		strategy.Adaptive != nil && !strategy.Adaptive.DisableSimulationSchedule
+}
+
+// subsetCanFitPod returns true if any node matched by the subset has enough free resources for the Pod.
+// Only subsets with RequiredNodeSelectorTerm are checked, because the other subsets may match all nodes.
+func (h *Handler) subsetCanFitPod(ws *appsv1alpha1.WorkloadSpread, subset *appsv1alpha1.WorkloadSpreadSubset, pod *corev1.Pod) (bool, error) {
+	if subset.RequiredNodeSelectorTerm == nil {
+		return true, nil
+	}
+	capacity, err := h.getSubsetCapacity(ws, subset)
+	if err != nil {
+		return false, err
+	}
+
+	requests, _ := resourcehelper.PodRequestsAndLimits(pod)
+	// the nodes are updated in place by events.
+	subsetCapacityCache.RLock()
+	defer subsetCapacityCache.RUnlock()
+	return capacity.findFitNode(requests, subsetTolerations(subset, pod)) != "", nil
+}
+
+func subsetTolerations(subset *appsv1alpha1.WorkloadSpreadSubset, pod *corev1.Pod) []corev1.Toleration {
+	return append(append([]corev1.Toleration{}, pod.Spec.Tolerations...), subset.Tolerations...)
+}
+
+// assumePod holds the requests of the Pod admitted into the subset in the capacity cache, so that the following
+// creations are simulated with the capacity left. podID is the name of the Pod, or the UID generated for it.
+func (h *Handler) assumePod(ws *appsv1alpha1.WorkloadSpread, subsetName, podID string, pod *corev1.Pod) {
+	if podID == "" || !isSimulationScheduleEnabled(ws) {
+		return
+	}
+	for i := range ws.Spec.Subsets {
+		subset := &ws.Spec.Subsets[i]
+		if subset.Name != subsetName || subset.RequiredNodeSelectorTerm == nil {
+			continue
+		}
+		requests, _ := resourcehelper.PodRequestsAndLimits(pod)
+		subsetCapacityCache.AssumePod(subsetCapacityKey(ws, subsetName), pod.Namespace+"/"+podID, requests, subsetTolerations(subset, pod))
+		return
+	}
+}
+
+// getSubsetCapacity returns the cached capacity of the subset. The nodes and Pods are only listed when the subset
+// is seen for the first time or its RequiredNodeSelectorTerm changes, an expired capacity is recalculated in the
+// background, so that the WorkloadSpread lock is not held for relisting.
+func (h *Handler) getSubsetCapacity(ws *appsv1alpha1.WorkloadSpread, subset *appsv1alpha1.WorkloadSpreadSubset) (*subsetCapacity, error) {
+	specBytes, _ := json.Marshal(subset.RequiredNodeSelectorTerm)
+	key := subsetCapacityKey(ws, subset.Name)
+	capacity, expired := subsetCapacityCache.get(key, string(specBytes))
+	if capacity != nil {
+		if expired && subsetCapacityCache.startRefreshing(key) {
+			term := subset.RequiredNodeSelectorTerm.DeepCopy()
+			go func() {
+				defer subsetCapacityCache.finishRefreshing(key)
+				if _, err := h.calculateSubsetCapacity(key, string(specBytes), term); err != nil {
+					klog.Errorf("Failed to refresh capacity of subset %s: %v", key, err)
+				}
+			}()
+		}
+		return capacity, nil
+	}
+	return h.calculateSubsetCapacity(key, string(specBytes), subset.RequiredNodeSelectorTerm)
+}
+
+func (h *Handler) calculateSubsetCapacity(key, spec string, term *corev1.NodeSelectorTerm) (*subsetCapacity, error) {
+	nodeSelector, err := nodeaffinity.NewNodeSelector(&corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{*term}})
+	if err != nil {
+		return nil, err
+	}
+	nodeList := &corev1.NodeList{}
+	if err = h.List(context.TODO(), nodeList, utilclient.DisableDeepCopy); err != nil {
+		return nil, err
+	}
+
+	capacity := &subsetCapacity{
+		spec:     spec,
+		selector: nodeSelector,
+		nodes:    map[string]*nodeCapacity{},
+		expireAt: time.Now().Add(subsetCapacityTTL),
+	}
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		if node.Spec.Unschedulable || !nodeSelector.Match(node) {
+			continue
+		}
+		podList := &corev1.PodList{}
+		if err = h.List(context.TODO(), podList, client.MatchingFields{fieldindex.IndexNameForPodNodeName: node.Name}, utilclient.DisableDeepCopy); err != nil {
+			return nil, err
+		}
+		capacity.nodes[node.Name] = newNodeCapacity(node, podList.Items)
+	}
+	subsetCapacityCache.set(key, capacity)
+	klog.V(5).Infof("Calculated capacity of subset %s with %d nodes", key, len(capacity.nodes))
+	return capacity, nil
+}
@@ -0,0 +1,368 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadspread
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util/fieldindex"
+)
+
+func newCapacityNode(name, zone string, cpu string, taints ...corev1.Taint) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"zone": zone}},
+		Spec:       corev1.NodeSpec{Taints: taints},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse(cpu),
+				corev1.ResourcePods: resource.MustParse("110"),
+			},
+		},
+	}
+}
+
+func newCapacityPod(name, nodeName, cpu string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{{
+				Name: "main",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func newCapacityWorkloadSpread() *appsv1alpha1.WorkloadSpread {
+	zoneTerm := func(zone string) *corev1.NodeSelectorTerm {
+		return &corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+			{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{zone}},
+		}}
+	}
+	return &appsv1alpha1.WorkloadSpread{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-ws"},
+		Spec: appsv1alpha1.WorkloadSpreadSpec{
+			Subsets: []appsv1alpha1.WorkloadSpreadSubset{
+				{Name: "subset-a", RequiredNodeSelectorTerm: zoneTerm("a")},
+				{Name: "subset-b", RequiredNodeSelectorTerm: zoneTerm("b")},
+			},
+			ScheduleStrategy: appsv1alpha1.WorkloadSpreadScheduleStrategy{
+				Type:     appsv1alpha1.AdaptiveWorkloadSpreadScheduleStrategyType,
+				Adaptive: &appsv1alpha1.AdaptiveWorkloadSpreadStrategy{},
+			},
+		},
+	}
+}
+
+func newCapacityHandler(objects ...client.Object) *Handler {
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).
+		WithIndex(&corev1.Pod{}, fieldindex.IndexNameForPodNodeName, func(obj client.Object) []string {
+			return []string{obj.(*corev1.Pod).Spec.NodeName}
+		}).Build()
+	return &Handler{Client: c}
+}
+
+func TestSubsetCanFitPod(t *testing.T) {
+	noSchedule := corev1.Taint{Key: "dedicated", Value: "test", Effect: corev1.TaintEffectNoSchedule}
+	cases := []struct {
+		name      string
+		objects   []client.Object
+		podCPU    string
+		tolerate  bool
+		expectFit []bool
+	}{
+		{
+			name: "both subsets have enough resources",
+			objects: []client.Object{
+				newCapacityNode("node-a", "a", "4"),
+				newCapacityNode("node-b", "b", "4"),
+			},
+			podCPU:    "2",
+			expectFit: []bool{true, true},
+		},
+		{
+			name: "subset-a is full of running Pods",
+			objects: []client.Object{
+				newCapacityNode("node-a", "a", "4"),
+				newCapacityNode("node-b", "b", "4"),
+				newCapacityPod("pod-1", "node-a", "3"),
+			},
+			podCPU:    "2",
+			expectFit: []bool{false, true},
+		},
+		{
+			name: "succeeded Pods do not take resources",
+			objects: []client.Object{
+				newCapacityNode("node-a", "a", "4"),
+				newCapacityNode("node-b", "b", "4"),
+				func() client.Object {
+					pod := newCapacityPod("pod-1", "node-a", "3")
+					pod.Status.Phase = corev1.PodSucceeded
+					return pod
+				}(),
+			},
+			podCPU:    "2",
+			expectFit: []bool{true, true},
+		},
+		{
+			name: "node of subset-b has untolerated taint",
+			objects: []client.Object{
+				newCapacityNode("node-a", "a", "4"),
+				newCapacityNode("node-b", "b", "4", noSchedule),
+			},
+			podCPU:    "2",
+			expectFit: []bool{true, false},
+		},
+		{
+			name: "Pod tolerates the taint of subset-b",
+			objects: []client.Object{
+				newCapacityNode("node-a", "a", "4"),
+				newCapacityNode("node-b", "b", "4", noSchedule),
+			},
+			podCPU:    "2",
+			tolerate:  true,
+			expectFit: []bool{true, true},
+		},
+		{
+			name: "resources are split across nodes",
+			objects: []client.Object{
+				newCapacityNode("node-a1", "a", "1"),
+				newCapacityNode("node-a2", "a", "1"),
+				newCapacityNode("node-b", "b", "4"),
+			},
+			podCPU:    "2",
+			expectFit: []bool{false, true},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			subsetCapacityCache.InvalidateAll()
+			h := newCapacityHandler(cs.objects...)
+			ws := newCapacityWorkloadSpread()
+			pod := newCapacityPod("test-pod", "", cs.podCPU)
+			if cs.tolerate {
+				pod.Spec.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}
+			}
+			for i := range ws.Spec.Subsets {
+				fit, err := h.subsetCanFitPod(ws, &ws.Spec.Subsets[i], pod)
+				if err != nil {
+					t.Fatalf("simulate schedule failed: %s", err.Error())
+				}
+				if fit != cs.expectFit[i] {
+					t.Fatalf("expect subset %s fit %v, but got %v", ws.Spec.Subsets[i].Name, cs.expectFit[i], fit)
+				}
+			}
+		})
+	}
+}
+
+func TestGetSuitableSubsetWithSimulation(t *testing.T) {
+	subsetCapacityCache.InvalidateAll()
+	h := newCapacityHandler(
+		newCapacityNode("node-a", "a", "4"),
+		newCapacityNode("node-b", "b", "4"),
+		newCapacityPod("pod-1", "node-a", "3"),
+	)
+	ws := newCapacityWorkloadSpread()
+	pod := newCapacityPod("test-pod", "", "2")
+	subsetStatuses := []appsv1alpha1.WorkloadSpreadSubsetStatus{
+		{Name: "subset-a", MissingReplicas: -1},
+		{Name: "subset-b", MissingReplicas: -1},
+	}
+
+	if subset := h.getSuitableSubset(ws, pod, subsetStatuses); subset == nil || subset.Name != "subset-b" {
+		t.Fatalf("expect subset-b, but got %v", subset)
+	}
+
+	// fall back to the first subset with room if no subset can fit the Pod.
+	pod = newCapacityPod("test-pod", "", "8")
+	if subset := h.getSuitableSubset(ws, pod, subsetStatuses); subset == nil || subset.Name != "subset-a" {
+		t.Fatalf("expect subset-a, but got %v", subset)
+	}
+
+	// no simulation if it is disabled.
+	ws.Spec.ScheduleStrategy.Adaptive.DisableSimulationSchedule = true
+	pod = newCapacityPod("test-pod", "", "2")
+	if subset := h.getSuitableSubset(ws, pod, subsetStatuses); subset == nil || subset.Name != "subset-a" {
+		t.Fatalf("expect subset-a, but got %v", subset)
+	}
+}
+
+func TestSubsetCapacityCacheUpdate(t *testing.T) {
+	subsetCapacityCache.InvalidateAll()
+	nodeA := newCapacityNode("node-a", "a", "4")
+	h := newCapacityHandler(
+		nodeA,
+		newCapacityNode("node-b", "b", "4"),
+	)
+	ws := newCapacityWorkloadSpread()
+	pod := newCapacityPod("test-pod", "", "2")
+	expectFit := func(expected ...bool) {
+		t.Helper()
+		for i := range ws.Spec.Subsets {
+			fit, err := h.subsetCanFitPod(ws, &ws.Spec.Subsets[i], pod)
+			if err != nil {
+				t.Fatalf("simulate schedule failed: %s", err.Error())
+			}
+			if fit != expected[i] {
+				t.Fatalf("expect subset %s fit %v, but got %v", ws.Spec.Subsets[i].Name, expected[i], fit)
+			}
+		}
+	}
+	expectFit(true, true)
+	if len(subsetCapacityCache.subsets) != 2 {
+		t.Fatalf("expect 2 cached subsets, but got %d", len(subsetCapacityCache.subsets))
+	}
+
+	// Pods bound to the node take its resources in place, and the same Pod is never counted twice.
+	running := newCapacityPod("pod-1", "node-a", "3")
+	running.UID = "pod-1"
+	subsetCapacityCache.UpdatePod(running)
+	subsetCapacityCache.UpdatePod(running)
+	expectFit(false, true)
+	capacityA := subsetCapacityCache.subsets[subsetCapacityKey(ws, "subset-a")]
+	if len(capacityA.nodes["node-a"].pods) != 1 {
+		t.Fatalf("expect 1 Pod on node-a, but got %d", len(capacityA.nodes["node-a"].pods))
+	}
+
+	// completed Pods release their resources.
+	succeeded := running.DeepCopy()
+	succeeded.Status.Phase = corev1.PodSucceeded
+	subsetCapacityCache.UpdatePod(succeeded)
+	expectFit(true, true)
+	subsetCapacityCache.UpdatePod(running)
+	subsetCapacityCache.DeletePod(running)
+	expectFit(true, true)
+
+	// nodes move between subsets when their labels change.
+	nodeA.Labels["zone"] = "b"
+	if err := subsetCapacityCache.UpdateNode(h.Client, nodeA); err != nil {
+		t.Fatalf("update node failed: %s", err.Error())
+	}
+	if len(capacityA.nodes) != 0 {
+		t.Fatalf("expect node-a removed from subset-a, but got %v", capacityA.nodes)
+	}
+	capacityB := subsetCapacityCache.subsets[subsetCapacityKey(ws, "subset-b")]
+	if _, ok := capacityB.nodes["node-a"]; !ok || len(capacityB.nodes) != 2 {
+		t.Fatalf("expect node-a added to subset-b, but got %v", capacityB.nodes)
+	}
+	expectFit(false, true)
+	subsetCapacityCache.DeleteNode("node-a")
+	if _, ok := capacityB.nodes["node-a"]; ok {
+		t.Fatalf("expect node-a removed from subset-b")
+	}
+
+	// the cached capacity is not used once the subset term changes.
+	ws.Spec.Subsets[1].RequiredNodeSelectorTerm.MatchExpressions[0].Values = []string{"a"}
+	capacity, err := h.getSubsetCapacity(ws, &ws.Spec.Subsets[1])
+	if err != nil {
+		t.Fatalf("get subset capacity failed: %s", err.Error())
+	}
+	if _, ok := capacity.nodes["node-a"]; !ok || len(capacity.nodes) != 1 {
+		t.Fatalf("expect subset-b recalculated with node-a, but got %v", capacity.nodes)
+	}
+
+	subsetCapacityCache.InvalidateAll()
+	if len(subsetCapacityCache.subsets) != 0 {
+		t.Fatalf("expect all subsets invalidated")
+	}
+}
+
+func TestSubsetCapacityCacheAssumeAndEvict(t *testing.T) {
+	subsetCapacityCache.InvalidateAll()
+	h := newCapacityHandler(
+		newCapacityNode("node-a", "a", "4"),
+		newCapacityNode("node-b", "b", "4"),
+	)
+	ws := newCapacityWorkloadSpread()
+	expectFit := func(pod *corev1.Pod, expected ...bool) {
+		t.Helper()
+		for i := range ws.Spec.Subsets {
+			fit, err := h.subsetCanFitPod(ws, &ws.Spec.Subsets[i], pod)
+			if err != nil {
+				t.Fatalf("simulate schedule failed: %s", err.Error())
+			}
+			if fit != expected[i] {
+				t.Fatalf("expect subset %s fit %v, but got %v", ws.Spec.Subsets[i].Name, expected[i], fit)
+			}
+		}
+	}
+	pod := newCapacityPod("", "", "3")
+	expectFit(pod, true, true)
+	if keys := subsetCapacityCache.nodeSubsets["node-a"]; !keys.Equal(sets.NewString(subsetCapacityKey(ws, "subset-a"))) {
+		t.Fatalf("expect node-a indexed by subset-a, but got %v", keys)
+	}
+
+	// an admitted Pod holds its requests until it is bound, so the next creation sees the capacity left.
+	h.assumePod(ws, "subset-a", "generated-uid", pod)
+	expectFit(pod, false, true)
+	created := pod.DeepCopy()
+	created.Name = "pod-1"
+	created.UID = "pod-1"
+	created.Annotations = map[string]string{MatchedWorkloadSpreadSubsetAnnotations: `{"name":"test-ws","subset":"subset-a","uid":"generated-uid"}`}
+	subsetCapacityCache.UpdatePod(created)
+	if assumed := subsetCapacityCache.assumedPods["default/generated-uid"]; assumed == nil || !assumed.expireAt.IsZero() {
+		t.Fatalf("expect the pending Pod still assumed without expiration, but got %v", assumed)
+	}
+	expectFit(pod, false, true)
+	created.Spec.NodeName = "node-a"
+	subsetCapacityCache.UpdatePod(created)
+	if len(subsetCapacityCache.assumedPods) != 0 {
+		t.Fatalf("expect the bound Pod no longer assumed, but got %v", subsetCapacityCache.assumedPods)
+	}
+	expectFit(pod, false, true)
+	subsetCapacityCache.DeletePod(created)
+	expectFit(pod, true, true)
+
+	// an admitted Pod that is never seen is released after the timeout.
+	h.assumePod(ws, "subset-a", "pod-2", pod)
+	expectFit(pod, false, true)
+	subsetCapacityCache.Lock()
+	subsetCapacityCache.expireAssumedPods(time.Now().Add(assumedPodTimeout + time.Second))
+	subsetCapacityCache.Unlock()
+	expectFit(pod, true, true)
+
+	// removed subsets and deleted WorkloadSpreads are evicted.
+	subsetCapacityCache.PruneSubsets(&appsv1alpha1.WorkloadSpread{
+		ObjectMeta: ws.ObjectMeta,
+		Spec:       appsv1alpha1.WorkloadSpreadSpec{Subsets: ws.Spec.Subsets[1:]},
+	})
+	if _, ok := subsetCapacityCache.subsets[subsetCapacityKey(ws, "subset-a")]; ok || len(subsetCapacityCache.subsets) != 1 {
+		t.Fatalf("expect subset-a evicted, but got %v", subsetCapacityCache.subsets)
+	}
+	if _, ok := subsetCapacityCache.nodeSubsets["node-a"]; ok {
+		t.Fatalf("expect node-a unindexed, but got %v", subsetCapacityCache.nodeSubsets)
+	}
+	subsetCapacityCache.DeleteWorkloadSpread(ws.Namespace, ws.Name)
+	if len(subsetCapacityCache.subsets) != 0 || len(subsetCapacityCache.nodeSubsets) != 0 {
+		t.Fatalf("expect all subsets evicted, but got %v", subsetCapacityCache.subsets)
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadspread
+
+import (
+	"fmt"
+	"sort"
+
+	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/integer"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+// IsWeightedWorkloadSpread returns true if the subsets of ws are spread by weight instead of maxReplicas.
+func IsWeightedWorkloadSpread(ws *appsv1alpha1.WorkloadSpread) bool {
+	for i := range ws.Spec.Subsets {
+		if ws.Spec.Subsets[i].Weight != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// GetWeightedSubsetReplicas splits workloadReplicas among the subsets of ws in proportion to their weights.
+// It uses the largest remainder method, so the result always sums up to workloadReplicas, and ties are
+// broken by subset order, which keeps the allocation stable while the workload scales up and down.
+// For StatefulSet targets, the result matches the subsets assigned to the ordinals by GetWeightedSubsetOfOrdinal.
+func GetWeightedSubsetReplicas(ws *appsv1alpha1.WorkloadSpread, workloadReplicas int32) map[string]int32 {
+	result := make(map[string]int32, len(ws.Spec.Subsets))
+	var totalWeight int64
+	for i := range ws.Spec.Subsets {
+		subset := &ws.Spec.Subsets[i]
+		result[subset.Name] = 0
+		if subset.Weight != nil && *subset.Weight > 0 {
+			totalWeight += int64(*subset.Weight)
+		}
+	}
+	if totalWeight == 0 || workloadReplicas <= 0 {
+		return result
+	}
+
+	if ws.Spec.TargetReference != nil && ws.Spec.TargetReference.Kind == controllerKindSts.Kind {
+		// each cycle of totalWeight ordinals is split by the cumulative weights of subsets.
+		cycles, rest := int64(workloadReplicas)/totalWeight, int64(workloadReplicas)%totalWeight
+		var cumulative int64
+		for i := range ws.Spec.Subsets {
+			subset := &ws.Spec.Subsets[i]
+			if subset.Weight == nil || *subset.Weight <= 0 {
+				continue
+			}
+			weight := int64(*subset.Weight)
+			replicas := cycles * weight
+			if rest > cumulative {
+				replicas += integer.Int64Min(rest-cumulative, weight)
+			}
+			result[subset.Name] = int32(replicas)
+			cumulative += weight
+		}
+		return result
+	}
+
+	type remainder struct {
+		index int
+		value int64
+	}
+	remainders := make([]remainder, 0, len(ws.Spec.Subsets))
+	allocated := int32(0)
+	for i := range ws.Spec.Subsets {
+		subset := &ws.Spec.Subsets[i]
+		if subset.Weight == nil || *subset.Weight <= 0 {
+			continue
+		}
+		product := int64(workloadReplicas) * int64(*subset.Weight)
+		replicas := int32(product / totalWeight)
+		result[subset.Name] = replicas
+		allocated += replicas
+		remainders = append(remainders, remainder{index: i, value: product % totalWeight})
+	}
+	sort.SliceStable(remainders, func(i, j int) bool {
+		return remainders[i].value > remainders[j].value
+	})
+	for i := 0; allocated < workloadReplicas && i < len(remainders); i++ {
+		result[ws.Spec.Subsets[remainders[i].index].Name]++
+		allocated++
+	}
+	return result
+}
+
+// GetSubsetMaxReplicas returns the max replicas of the subset for the given workload replicas,
+// -1 means there is no limit for the subset.
+func GetSubsetMaxReplicas(ws *appsv1alpha1.WorkloadSpread, subset *appsv1alpha1.WorkloadSpreadSubset, workloadReplicas int32) (int, error) {
+	if IsWeightedWorkloadSpread(ws) {
+		replicas, ok := GetWeightedSubsetReplicas(ws, workloadReplicas)[subset.Name]
+		if !ok {
+			return 0, fmt.Errorf("subset %s not found in WorkloadSpread %s/%s", subset.Name, ws.Namespace, ws.Name)
+		}
+		return int(replicas), nil
+	}
+	if subset.MaxReplicas == nil {
+		return -1, nil
+	}
+	maxReplicas, err := intstrutil.GetScaledValueFromIntOrPercent(subset.MaxReplicas, int(workloadReplicas), true)
+	if err != nil {
+		return 0, err
+	}
+	if maxReplicas < 0 {
+		return 0, fmt.Errorf("subset %s maxReplicas is negative", subset.Name)
+	}
+	return maxReplicas, nil
+}
+
+// GetWeightedSubsetOfOrdinal returns the subset that the StatefulSet Pod with the ordinal belongs to.
+// The ordinals are assigned to the subsets in cycles of the total weight, e.g., with weights 3:2:1,
+// ordinals [0, 3) of each cycle of 6 belong to subset-a, [3, 5) to subset-b and [5, 6) to subset-c.
+// So the subset of an ordinal never changes while the StatefulSet scales. If the subset is in the skip
+// set, the next subset in the cycle is returned instead. An empty name means no subset is available.
+func GetWeightedSubsetOfOrdinal(ws *appsv1alpha1.WorkloadSpread, ordinal int, skip sets.String) string {
+	var weighted []*appsv1alpha1.WorkloadSpreadSubset
+	var totalWeight int64
+	for i := range ws.Spec.Subsets {
+		subset := &ws.Spec.Subsets[i]
+		if subset.Weight != nil && *subset.Weight > 0 {
+			weighted = append(weighted, subset)
+			totalWeight += int64(*subset.Weight)
+		}
+	}
+	if totalWeight == 0 || ordinal < 0 {
+		return ""
+	}
+
+	position := int64(ordinal) % totalWeight
+	start := 0
+	for cumulative := int64(0); start < len(weighted); start++ {
+		cumulative += int64(*weighted[start].Weight)
+		if position < cumulative {
+			break
+		}
+	}
+	for i := 0; i < len(weighted); i++ {
+		subset := weighted[(start+i)%len(weighted)]
+		if !skip.Has(subset.Name) {
+			return subset.Name
+		}
+	}
+	return ""
+}
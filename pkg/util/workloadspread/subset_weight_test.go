@@ -0,0 +1,215 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloadspread
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilpointer "k8s.io/utils/pointer"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+)
+
+func TestGetWeightedSubsetReplicas(t *testing.T) {
+	newWS := func(weights ...int32) *appsv1alpha1.WorkloadSpread {
+		ws := &appsv1alpha1.WorkloadSpread{}
+		for i, w := range weights {
+			ws.Spec.Subsets = append(ws.Spec.Subsets, appsv1alpha1.WorkloadSpreadSubset{
+				Name:   string(rune('a' + i)),
+				Weight: utilpointer.Int32(w),
+			})
+		}
+		return ws
+	}
+	newStsWS := func(weights ...int32) *appsv1alpha1.WorkloadSpread {
+		ws := newWS(weights...)
+		ws.Spec.TargetReference = &appsv1alpha1.TargetReference{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "sts"}
+		return ws
+	}
+	cases := []struct {
+		name     string
+		ws       *appsv1alpha1.WorkloadSpread
+		replicas int32
+		expected map[string]int32
+	}{
+		{
+			name:     "3:2:1 with 6 replicas",
+			ws:       newWS(3, 2, 1),
+			replicas: 6,
+			expected: map[string]int32{"a": 3, "b": 2, "c": 1},
+		},
+		{
+			name:     "3:2:1 with 12 replicas",
+			ws:       newWS(3, 2, 1),
+			replicas: 12,
+			expected: map[string]int32{"a": 6, "b": 4, "c": 2},
+		},
+		{
+			name:     "3:2:1 with 5 replicas, remainder goes to the largest fraction",
+			ws:       newWS(3, 2, 1),
+			replicas: 5,
+			expected: map[string]int32{"a": 2, "b": 2, "c": 1},
+		},
+		{
+			name:     "1:1:1 with 4 replicas, ties are broken by subset order",
+			ws:       newWS(1, 1, 1),
+			replicas: 4,
+			expected: map[string]int32{"a": 2, "b": 1, "c": 1},
+		},
+		{
+			name:     "zero weight gets no replicas",
+			ws:       newWS(1, 0, 1),
+			replicas: 3,
+			expected: map[string]int32{"a": 2, "b": 0, "c": 1},
+		},
+		{
+			name:     "zero replicas",
+			ws:       newWS(3, 2, 1),
+			replicas: 0,
+			expected: map[string]int32{"a": 0, "b": 0, "c": 0},
+		},
+		{
+			name:     "StatefulSet 3:2:1 with 12 replicas",
+			ws:       newStsWS(3, 2, 1),
+			replicas: 12,
+			expected: map[string]int32{"a": 6, "b": 4, "c": 2},
+		},
+		{
+			name:     "StatefulSet 3:2:1 with 5 replicas, remainder follows the ordinals",
+			ws:       newStsWS(3, 2, 1),
+			replicas: 5,
+			expected: map[string]int32{"a": 3, "b": 2, "c": 0},
+		},
+		{
+			name:     "StatefulSet 1:0:1 with 3 replicas",
+			ws:       newStsWS(1, 0, 1),
+			replicas: 3,
+			expected: map[string]int32{"a": 2, "b": 0, "c": 1},
+		},
+	}
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			got := GetWeightedSubsetReplicas(cs.ws, cs.replicas)
+			if !reflect.DeepEqual(got, cs.expected) {
+				t.Fatalf("expected %v, got %v", cs.expected, got)
+			}
+		})
+	}
+}
+
+func TestGetSubsetMaxReplicas(t *testing.T) {
+	ws := &appsv1alpha1.WorkloadSpread{
+		Spec: appsv1alpha1.WorkloadSpreadSpec{
+			Subsets: []appsv1alpha1.WorkloadSpreadSubset{
+				{Name: "subset-a", MaxReplicas: &intstr.IntOrString{Type: intstr.String, StrVal: "50%"}},
+				{Name: "subset-b", MaxReplicas: &intstr.IntOrString{Type: intstr.Int, IntVal: 3}},
+				{Name: "subset-c"},
+			},
+		},
+	}
+	expected := []int{5, 3, -1}
+	for i := range ws.Spec.Subsets {
+		got, err := GetSubsetMaxReplicas(ws, &ws.Spec.Subsets[i], 9)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != expected[i] {
+			t.Fatalf("subset %s: expected %d, got %d", ws.Spec.Subsets[i].Name, expected[i], got)
+		}
+	}
+
+	ws.Spec.Subsets[0].MaxReplicas = nil
+	ws.Spec.Subsets[0].Weight = utilpointer.Int32(2)
+	ws.Spec.Subsets[1].MaxReplicas = nil
+	ws.Spec.Subsets[1].Weight = utilpointer.Int32(1)
+	got, err := GetSubsetMaxReplicas(ws, &ws.Spec.Subsets[0], 9)
+	if err != nil || got != 6 {
+		t.Fatalf("expected 6 for weighted subset, got %d, err %v", got, err)
+	}
+	// the subset without weight gets no replicas in a weighted WorkloadSpread
+	got, err = GetSubsetMaxReplicas(ws, &ws.Spec.Subsets[2], 9)
+	if err != nil || got != 0 {
+		t.Fatalf("expected 0 for subset without weight, got %d, err %v", got, err)
+	}
+}
+
+func TestGetWeightedSubsetOfOrdinal(t *testing.T) {
+	ws := &appsv1alpha1.WorkloadSpread{
+		Spec: appsv1alpha1.WorkloadSpreadSpec{
+			TargetReference: &appsv1alpha1.TargetReference{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "sts"},
+			Subsets: []appsv1alpha1.WorkloadSpreadSubset{
+				{Name: "subset-a", Weight: utilpointer.Int32(3)},
+				{Name: "subset-b", Weight: utilpointer.Int32(2)},
+				{Name: "subset-c", Weight: utilpointer.Int32(1)},
+			},
+		},
+	}
+	cases := []struct {
+		name     string
+		replicas int
+		skip     sets.String
+		expected map[string]int32
+	}{
+		{
+			name:     "6 replicas",
+			replicas: 6,
+			expected: map[string]int32{"subset-a": 3, "subset-b": 2, "subset-c": 1},
+		},
+		{
+			name:     "12 replicas",
+			replicas: 12,
+			expected: map[string]int32{"subset-a": 6, "subset-b": 4, "subset-c": 2},
+		},
+		{
+			name:     "12 replicas with subset-b unschedulable",
+			replicas: 12,
+			skip:     sets.NewString("subset-b"),
+			expected: map[string]int32{"subset-a": 6, "subset-c": 6},
+		},
+		{
+			name:     "all subsets unschedulable",
+			replicas: 2,
+			skip:     sets.NewString("subset-a", "subset-b", "subset-c"),
+			expected: map[string]int32{"": 2},
+		},
+	}
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			got := map[string]int32{}
+			for ordinal := 0; ordinal < cs.replicas; ordinal++ {
+				got[GetWeightedSubsetOfOrdinal(ws, ordinal, cs.skip)]++
+			}
+			if !reflect.DeepEqual(got, cs.expected) {
+				t.Fatalf("expected %v, got %v", cs.expected, got)
+			}
+			if cs.skip.Len() == 0 && !reflect.DeepEqual(got, GetWeightedSubsetReplicas(ws, int32(cs.replicas))) {
+				t.Fatalf("subsets of ordinals %v mismatch the weighted subset replicas", got)
+			}
+		})
+	}
+
+	// scaling up from 6 to 12 replicas keeps the subsets of the existing ordinals
+	expected := []string{"subset-a", "subset-a", "subset-a", "subset-b", "subset-b", "subset-c"}
+	for ordinal := 0; ordinal < 12; ordinal++ {
+		if got := GetWeightedSubsetOfOrdinal(ws, ordinal, nil); got != expected[ordinal%6] {
+			t.Fatalf("ordinal %d: expected %s, got %s", ordinal, expected[ordinal%6], got)
+		}
+	}
+}
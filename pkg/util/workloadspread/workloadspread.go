@@ -35,7 +35,6 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
-	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/apimachinery/pkg/util/uuid"
@@ -73,6 +72,7 @@ var (
 	controllerKruiseKindAlphaSts = appsv1alpha1.SchemeGroupVersion.WithKind("StatefulSet")
 	controllerKruiseKindBetaSts  = appsv1beta1.SchemeGroupVersion.WithKind("StatefulSet")
 	controllerKindJob            = batchv1.SchemeGroupVersion.WithKind("Job")
+	controllerKruiseKindBJ       = appsv1alpha1.SchemeGroupVersion.WithKind("BroadcastJob")
 	controllerKindRS             = appsv1.SchemeGroupVersion.WithKind("ReplicaSet")
 	controllerKindDep            = appsv1.SchemeGroupVersion.WithKind("Deployment")
 	controllerKindSts            = appsv1.SchemeGroupVersion.WithKind("StatefulSet")
@@ -114,6 +114,7 @@ var (
 		{Kind: controllerKruiseKindCS.Kind, Groups: []string{controllerKruiseKindCS.Group}},
 		{Kind: controllerKindRS.Kind, Groups: []string{controllerKindRS.Group}},
 		{Kind: controllerKindJob.Kind, Groups: []string{controllerKindJob.Group}},
+		{Kind: controllerKruiseKindBJ.Kind, Groups: []string{controllerKruiseKindBJ.Group}},
 		{Kind: controllerKindSts.Kind, Groups: []string{controllerKindSts.Group, controllerKruiseKindAlphaSts.Group, controllerKruiseKindBetaSts.Group}},
 	}
 	workloadsInWhiteListInitialized = false
@@ -311,6 +312,12 @@ func (h *Handler) mutatingPod(matchedWS *appsv1alpha1.WorkloadSpread,
 		}
 		klog.V(3).Infof("inject Pod(%s/%s) subset(%s) data for WorkloadSpread(%s/%s)",
 			pod.Namespace, podName, suitableSubsetName, matchedWS.Namespace, matchedWS.Name)
+
+		podID := generatedUID
+		if podID == "" {
+			podID = pod.Name
+		}
+		h.assumePod(matchedWS, suitableSubsetName, podID, pod)
 	}
 
 	klog.V(3).Infof("handler operation[%s] Pod(%s/%s) generatedUID(%s) for WorkloadSpread(%s/%s) done",
@@ -349,6 +356,21 @@ func (h *Handler) acquireSuitableSubset(matchedWS *appsv1alpha1.WorkloadSpread,
 		// the pods with order within [0, 5) will be assigned to subset-a;
 		// the pods with order within [5, 10) will be assigned to subset-b;
 		// the pods with order within [10, inf) will be assigned to subset-c.
+		// For weighted subsets, the ordinals are assigned to the subsets in cycles of the total weight,
+		// so that the subsets of existing pods are kept while the workload scales.
+		_, orderID := getParentNameAndOrdinal(pod)
+		if IsWeightedWorkloadSpread(matchedWS) {
+			unschedulable := sets.NewString()
+			for i := range matchedWS.Spec.Subsets {
+				subsetName := matchedWS.Spec.Subsets[i].Name
+				cond := getSubsetCondition(matchedWS, subsetName, appsv1alpha1.SubsetSchedulable)
+				if cond != nil && cond.Status == corev1.ConditionFalse {
+					unschedulable.Insert(subsetName)
+				}
+			}
+			suitableSubsetName = GetWeightedSubsetOfOrdinal(matchedWS, orderID, unschedulable)
+			break
+		}
 		currentThresholdID := int64(0)
 		for _, subset := range matchedWS.Spec.Subsets {
 			cond := getSubsetCondition(matchedWS, subset.Name, appsv1alpha1.SubsetSchedulable)
@@ -361,7 +383,6 @@ func (h *Handler) acquireSuitableSubset(matchedWS *appsv1alpha1.WorkloadSpread,
 			}
 			// currently, we do not support reserveOrdinals feature for advanced statefulSet
 			currentThresholdID += int64(subsetReplicasLimit)
-			_, orderID := getParentNameAndOrdinal(pod)
 			if int64(orderID) < currentThresholdID {
 				suitableSubsetName = subset.Name
 				break
@@ -504,7 +525,14 @@ func (h *Handler) updateSubsetForPod(ws *appsv1alpha1.WorkloadSpread,
 			}
 		}
 
-		suitableSubset = h.getSuitableSubset(subsetStatuses)
+		if ws.Spec.TargetReference != nil && ws.Spec.TargetReference.Kind == controllerKruiseKindBJ.Kind {
+			// BroadcastJob Pod has been pinned to a node, the subset it belongs to is decided by the node.
+			if suitableSubset, err = h.getBroadcastJobSuitableSubset(ws, pod, subsetStatuses); err != nil {
+				return false, nil, "", err
+			}
+		} else {
+			suitableSubset = h.getSuitableSubset(ws, pod, subsetStatuses)
+		}
 		if suitableSubset == nil {
 			klog.Warningf("WorkloadSpread (%s/%s) don't have a suitable subset for Pod (%s) when creating",
 				ws.Namespace, ws.Name, pod.Name)
@@ -548,7 +576,14 @@ func (h *Handler) updateSubsetForPod(ws *appsv1alpha1.WorkloadSpread,
 		}
 		suitableSubset.DeletingPods[pod.Name] = metav1.Time{Time: time.Now()}
 		if suitableSubset.MissingReplicas >= 0 {
-			suitableSubset.MissingReplicas++
+			// deleting a Pod in an over-full subset, e.g., when rebalancing, leaves no room for new Pods.
+			freed, err := h.isSlotFreedByDeletion(ws, suitableSubset)
+			if err != nil {
+				return false, nil, "", err
+			}
+			if freed {
+				suitableSubset.MissingReplicas++
+			}
 		}
 	default:
 		return false, nil, "", nil
@@ -565,6 +600,34 @@ func (h *Handler) updateSubsetForPod(ws *appsv1alpha1.WorkloadSpread,
 	return true, suitableSubset, generatedUID, nil
 }
 
+// isSlotFreedByDeletion returns true if the subset has fewer Pods than its maxReplicas after the deletions
+// recorded in subsetStatus.DeletingPods. Only the WorkloadSpreads with rebalance strategy delete Pods from
+// over-full subsets on purpose, so the others always free a slot.
+func (h *Handler) isSlotFreedByDeletion(ws *appsv1alpha1.WorkloadSpread, subsetStatus *appsv1alpha1.WorkloadSpreadSubsetStatus) (bool, error) {
+	if ws.Spec.RebalanceStrategy == nil || subsetStatus.MissingReplicas > 0 {
+		return true, nil
+	}
+	var subset *appsv1alpha1.WorkloadSpreadSubset
+	for i := range ws.Spec.Subsets {
+		if ws.Spec.Subsets[i].Name == subsetStatus.Name {
+			subset = &ws.Spec.Subsets[i]
+			break
+		}
+	}
+	if subset == nil {
+		return true, nil
+	}
+	workloadReplicas, err := h.getWorkloadReplicas(ws)
+	if err != nil {
+		return false, err
+	}
+	maxReplicas, err := GetSubsetMaxReplicas(ws, subset, workloadReplicas)
+	if err != nil || maxReplicas < 0 {
+		return true, nil
+	}
+	return int(subsetStatus.Replicas)-len(subsetStatus.DeletingPods) < maxReplicas, nil
+}
+
 // return two parameters
 // 1. isRecord(bool) 2. SubsetStatus
 func isPodRecordedInSubset(subsetStatuses []appsv1alpha1.WorkloadSpreadSubsetStatus, podName string) (bool, *appsv1alpha1.WorkloadSpreadSubsetStatus) {
@@ -662,7 +725,14 @@ func getSpecificSubset(subsetStatuses []appsv1alpha1.WorkloadSpreadSubsetStatus,
 	return nil
 }
 
-func (h *Handler) getSuitableSubset(subsetStatuses []appsv1alpha1.WorkloadSpreadSubsetStatus) *appsv1alpha1.WorkloadSpreadSubsetStatus {
+// getSuitableSubset returns the first schedulable subset that has room for the Pod. If the scheduleStrategy
+// is Adaptive, it also simulates a schedule to skip the subsets whose nodes cannot fit the Pod, which does a
+// generic predicates by the cache of nodes and pods in kruise manager. There may be some errors between
+// simulation schedule and kubernetes scheduler with small probability, so it falls back to the first subset
+// with room if no subset can fit the Pod.
+func (h *Handler) getSuitableSubset(ws *appsv1alpha1.WorkloadSpread, pod *corev1.Pod,
+	subsetStatuses []appsv1alpha1.WorkloadSpreadSubsetStatus) *appsv1alpha1.WorkloadSpreadSubsetStatus {
+	var firstSubset *appsv1alpha1.WorkloadSpreadSubsetStatus
 	for i := range subsetStatuses {
 		subset := &subsetStatuses[i]
 		canSchedule := true
@@ -674,17 +744,41 @@ func (h *Handler) getSuitableSubset(subsetStatuses []appsv1alpha1.WorkloadSpread
 		}
 
 		if canSchedule && (subset.MissingReplicas > 0 || subset.MissingReplicas == -1) {
-			// TODO simulation schedule
-			// scheduleStrategy.Type = Adaptive
-			// Webhook will simulate a schedule in order to check whether Pod can run in this subset,
-			// which does a generic predicates by the cache of nodes and pods in kruise manager.
-			// There may be some errors between simulation schedule and kubernetes scheduler with small probability.
-
-			return subset
+			if !isSimulationScheduleEnabled(ws) {
+				return subset
+			}
+			if firstSubset == nil {
+				firstSubset = subset
+			}
+			if h.simulateSchedule(ws, subset.Name, pod) {
+				return subset
+			}
 		}
 	}
 
-	return nil
+	return firstSubset
+}
+
+// simulateSchedule returns true if the subset can fit the Pod or the simulation fails.
+func (h *Handler) simulateSchedule(ws *appsv1alpha1.WorkloadSpread, subsetName string, pod *corev1.Pod) bool {
+	for i := range ws.Spec.Subsets {
+		subset := &ws.Spec.Subsets[i]
+		if subset.Name != subsetName {
+			continue
+		}
+		fit, err := h.subsetCanFitPod(ws, subset, pod)
+		if err != nil {
+			klog.Warningf("WorkloadSpread (%s/%s) failed to simulate schedule for subset %s: %s",
+				ws.Namespace, ws.Name, subsetName, err.Error())
+			return true
+		}
+		if !fit {
+			klog.V(3).Infof("WorkloadSpread (%s/%s) subset %s cannot fit Pod (%s/%s), skip it",
+				ws.Namespace, ws.Name, subsetName, pod.Namespace, pod.Name)
+		}
+		return fit
+	}
+	return true
 }
 
 func (h *Handler) isReferenceEqual(target *appsv1alpha1.TargetReference, owner *metav1.OwnerReference, namespace string) bool {
@@ -798,12 +892,8 @@ func (h *Handler) initializedSubsetStatuses(ws *appsv1alpha1.WorkloadSpread) ([]
 	for i := range ws.Spec.Subsets {
 		subset := ws.Spec.Subsets[i]
 		subsetStatus := appsv1alpha1.WorkloadSpreadSubsetStatus{Name: subset.Name}
-		if subset.MaxReplicas == nil {
-			subsetStatus.MissingReplicas = -1
-		} else {
-			missingReplicas, _ := intstrutil.GetScaledValueFromIntOrPercent(subset.MaxReplicas, int(replicas), true)
-			subsetStatus.MissingReplicas = int32(missingReplicas)
-		}
+		missingReplicas, _ := GetSubsetMaxReplicas(ws, &subset, replicas)
+		subsetStatus.MissingReplicas = int32(missingReplicas)
 		subsetStatuses = append(subsetStatuses, subsetStatus)
 	}
 	return subsetStatuses, nil
@@ -832,6 +922,9 @@ func (h *Handler) getWorkloadReplicas(ws *appsv1alpha1.WorkloadSpread) (int32, e
 		return *o.Spec.Replicas, nil
 	case *batchv1.Job:
 		return *o.Spec.Parallelism, nil
+	case *appsv1alpha1.BroadcastJob:
+		// BroadcastJob runs one Pod per desired node, so the number of desired nodes is its replicas.
+		return GetBroadcastJobReplicas(h.Client, o)
 	case *appsv1alpha1.CloneSet:
 		return *o.Spec.Replicas, nil
 	case *appsv1beta1.StatefulSet:
@@ -852,6 +945,8 @@ func GenerateEmptyWorkloadObject(gvk schema.GroupVersionKind, key types.Namespac
 		object = &appsv1.StatefulSet{}
 	case controllerKindJob:
 		object = &batchv1.Job{}
+	case controllerKruiseKindBJ:
+		object = &appsv1alpha1.BroadcastJob{}
 	case controllerKruiseKindCS:
 		object = &appsv1alpha1.CloneSet{}
 	case controllerKruiseKindAlphaSts, controllerKruiseKindBetaSts:
@@ -1919,6 +1919,69 @@ func TestGetWorkloadVersion(t *testing.T) {
 	}
 }
 
+func TestIsSlotFreedByDeletion(t *testing.T) {
+	ws := workloadSpreadDemo.DeepCopy()
+	ws.Spec.TargetReference.Name = cloneset.Name
+	ws.Spec.Subsets = []appsv1alpha1.WorkloadSpreadSubset{
+		{Name: "subset-a", MaxReplicas: &intstr.IntOrString{Type: intstr.Int, IntVal: 3}},
+	}
+	ws.Spec.RebalanceStrategy = &appsv1alpha1.WorkloadSpreadRebalanceStrategy{}
+	h := Handler{fake.NewClientBuilder().WithScheme(scheme).WithObjects(cloneset.DeepCopy()).Build()}
+
+	cases := []struct {
+		name         string
+		subsetStatus appsv1alpha1.WorkloadSpreadSubsetStatus
+		noRebalance  bool
+		expectFreed  bool
+	}{
+		{
+			name:         "subset is under-filled",
+			subsetStatus: appsv1alpha1.WorkloadSpreadSubsetStatus{Name: "subset-a", Replicas: 1, MissingReplicas: 2},
+			expectFreed:  true,
+		},
+		{
+			name: "subset is full",
+			subsetStatus: appsv1alpha1.WorkloadSpreadSubsetStatus{Name: "subset-a", Replicas: 3,
+				DeletingPods: map[string]metav1.Time{"pod-1": metav1.Now()}},
+			expectFreed: true,
+		},
+		{
+			name: "subset is over-full",
+			subsetStatus: appsv1alpha1.WorkloadSpreadSubsetStatus{Name: "subset-a", Replicas: 5,
+				DeletingPods: map[string]metav1.Time{"pod-1": metav1.Now()}},
+			expectFreed: false,
+		},
+		{
+			name: "subset is over-full, but enough Pods are being deleted",
+			subsetStatus: appsv1alpha1.WorkloadSpreadSubsetStatus{Name: "subset-a", Replicas: 5,
+				DeletingPods: map[string]metav1.Time{"pod-1": metav1.Now(), "pod-2": metav1.Now(), "pod-3": metav1.Now()}},
+			expectFreed: true,
+		},
+		{
+			name: "subset is over-full without rebalance strategy",
+			subsetStatus: appsv1alpha1.WorkloadSpreadSubsetStatus{Name: "subset-a", Replicas: 5,
+				DeletingPods: map[string]metav1.Time{"pod-1": metav1.Now()}},
+			noRebalance: true,
+			expectFreed: true,
+		},
+	}
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			ws := ws.DeepCopy()
+			if cs.noRebalance {
+				ws.Spec.RebalanceStrategy = nil
+			}
+			freed, err := h.isSlotFreedByDeletion(ws, &cs.subsetStatus)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if freed != cs.expectFreed {
+				t.Fatalf("expect freed %v, but got %v", cs.expectFreed, freed)
+			}
+		})
+	}
+}
+
 func setWorkloadSpreadSubset(workloadSpread *appsv1alpha1.WorkloadSpread) {
 	for i := range workloadSpread.Status.SubsetStatuses {
 		subset := &workloadSpread.Status.SubsetStatuses[i]
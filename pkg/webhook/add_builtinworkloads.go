@@ -17,9 +17,14 @@ limitations under the License.
 package webhook
 
 import (
+	"github.com/openkruise/kruise/pkg/features"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
 	"github.com/openkruise/kruise/pkg/webhook/builtinworkloads/validating"
 )
 
 func init() {
 	addHandlers(validating.HandlerMap)
+	addHandlersWithGate(validating.UpdateProtectionHandlerMap, func() (enabled bool) {
+		return utilfeature.DefaultFeatureGate.Enabled(features.ResourcesDeletionProtection)
+	})
 }
@@ -18,8 +18,10 @@ package webhook
 
 import (
 	"github.com/openkruise/kruise/pkg/webhook/containerrecreaterequest/mutating"
+	"github.com/openkruise/kruise/pkg/webhook/containerrecreaterequest/validating"
 )
 
 func init() {
 	addHandlers(mutating.HandlerMap)
+	addHandlers(validating.HandlerMap)
 }
@@ -28,6 +28,9 @@ import (
 	"github.com/openkruise/kruise/pkg/webhook/util/convertor"
 	v1 "k8s.io/api/core/v1"
 	genericvalidation "k8s.io/apimachinery/pkg/api/validation"
+	metavalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	validationutil "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	corevalidation "k8s.io/kubernetes/pkg/apis/core/validation"
@@ -90,6 +93,21 @@ func validateBroadcastJobSpec(spec *appsv1alpha1.BroadcastJobSpec, fldPath *fiel
 		}
 	default:
 	}
+	allErrs = append(allErrs, validateTopologyParallelism(spec.TopologyParallelism, fldPath.Child("topologyParallelism"))...)
+	if spec.FailurePolicy.BackoffSeconds != nil && *spec.FailurePolicy.BackoffSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("failurePolicy").Child("backoffSeconds"),
+			*spec.FailurePolicy.BackoffSeconds, "backoffSeconds must be non-negative"))
+	}
+	if spec.FailurePolicy.RecreateFailedPods {
+		if spec.Template.Spec.RestartPolicy != v1.RestartPolicyNever {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("failurePolicy").Child("recreateFailedPods"),
+				spec.FailurePolicy.RecreateFailedPods, "recreateFailedPods can just work with pod restartPolicy Never"))
+		}
+		if spec.FailurePolicy.RestartLimit <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("failurePolicy").Child("restartLimit"),
+				spec.FailurePolicy.RestartLimit, "restartLimit must be positive if recreateFailedPods is true"))
+		}
+	}
 	coreTemplate, err := convertor.ConvertPodTemplateSpec(&spec.Template)
 	if err != nil {
 		allErrs = append(allErrs, field.Invalid(fldPath.Root(), spec.Template, fmt.Sprintf("Convert_v1_PodTemplateSpec_To_core_PodTemplateSpec failed: %v", err)))
@@ -111,6 +129,28 @@ func validateBroadcastJobSpec(spec *appsv1alpha1.BroadcastJobSpec, fldPath *fiel
 	return append(allErrs, corevalidation.ValidatePodTemplateSpec(coreTemplate, fldPath.Child("template"), webhookutil.DefaultPodValidationOptions)...)
 }
 
+func validateTopologyParallelism(topologyParallelism []appsv1alpha1.BroadcastJobTopologyParallelism, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	topologyKeys := sets.NewString()
+	for i, tp := range topologyParallelism {
+		idxPath := fldPath.Index(i)
+		if tp.TopologyKey == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("topologyKey"), "topologyKey is required"))
+		} else if topologyKeys.Has(tp.TopologyKey) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("topologyKey"), tp.TopologyKey))
+		} else {
+			allErrs = append(allErrs, metavalidation.ValidateLabelName(tp.TopologyKey, idxPath.Child("topologyKey"))...)
+		}
+		topologyKeys.Insert(tp.TopologyKey)
+		maxParallelism, err := intstr.GetScaledValueFromIntOrPercent(&tp.MaxParallelism, 100, true)
+		if err != nil || maxParallelism <= 0 {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("maxParallelism"), tp.MaxParallelism.String(),
+				"maxParallelism must be a positive integer or percentage"))
+		}
+	}
+	return allErrs
+}
+
 func validateBroadcastJobName(name string, prefix bool) (allErrs []string) {
 	if !validateBroadcastJobNameRegex.MatchString(name) {
 		allErrs = append(allErrs, validationutil.RegexError(validateBroadcastJobNameMsg, validBroadcastJobNameFmt, "example-com"))
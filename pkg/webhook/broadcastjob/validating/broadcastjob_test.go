@@ -17,12 +17,14 @@ limitations under the License.
 package validating
 
 import (
+	"strings"
 	"testing"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -58,3 +60,65 @@ func TestValidateBroadcastJobSpec(t *testing.T) {
 	assert.Equal(t, fieldErrorList[2].Field, "spec.template.spec.restartPolicy")
 	assert.Equal(t, fieldErrorList[3].Field, "spec.template.metadata.labels")
 }
+
+func TestValidateTopologyParallelism(t *testing.T) {
+	topologyParallelism := []appsv1alpha1.BroadcastJobTopologyParallelism{
+		{TopologyKey: "rack", MaxParallelism: intstr.FromInt(2)},
+		{TopologyKey: "", MaxParallelism: intstr.FromString("10%")},
+		{TopologyKey: "rack", MaxParallelism: intstr.FromInt(1)},
+		{TopologyKey: "zone", MaxParallelism: intstr.FromInt(0)},
+		{TopologyKey: "region", MaxParallelism: intstr.FromString("x%")},
+	}
+	fieldErrorList := validateTopologyParallelism(topologyParallelism, field.NewPath("spec", "topologyParallelism"))
+	assert.Equal(t, 4, len(fieldErrorList))
+	assert.Equal(t, "spec.topologyParallelism[1].topologyKey", fieldErrorList[0].Field)
+	assert.Equal(t, field.ErrorTypeDuplicate, fieldErrorList[1].Type)
+	assert.Equal(t, "spec.topologyParallelism[3].maxParallelism", fieldErrorList[2].Field)
+	assert.Equal(t, "spec.topologyParallelism[4].maxParallelism", fieldErrorList[3].Field)
+}
+
+func TestValidateRecreateFailedPods(t *testing.T) {
+	cases := []struct {
+		name          string
+		restartPolicy v1.RestartPolicy
+		restartLimit  int32
+		expectFields  []string
+	}{
+		{
+			name:          "restartPolicy Never and positive restartLimit",
+			restartPolicy: v1.RestartPolicyNever,
+			restartLimit:  3,
+		},
+		{
+			name:          "restartPolicy OnFailure",
+			restartPolicy: v1.RestartPolicyOnFailure,
+			restartLimit:  3,
+			expectFields:  []string{"spec.failurePolicy.recreateFailedPods"},
+		},
+		{
+			name:          "zero restartLimit",
+			restartPolicy: v1.RestartPolicyNever,
+			expectFields:  []string{"spec.failurePolicy.restartLimit"},
+		},
+	}
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			spec := &appsv1alpha1.BroadcastJobSpec{
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{
+						RestartPolicy: cs.restartPolicy,
+						Containers:    []v1.Container{{Name: "main", Image: "busybox"}},
+					},
+				},
+				FailurePolicy: appsv1alpha1.FailurePolicy{RestartLimit: cs.restartLimit, RecreateFailedPods: true},
+			}
+			var fields []string
+			for _, err := range validateBroadcastJobSpec(spec, field.NewPath("spec")) {
+				if strings.HasPrefix(err.Field, "spec.failurePolicy") {
+					fields = append(fields, err.Field)
+				}
+			}
+			assert.Equal(t, cs.expectFields, fields)
+		})
+	}
+}
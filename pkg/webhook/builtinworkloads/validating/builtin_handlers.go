@@ -27,15 +27,26 @@ import (
 	apps "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 // WorkloadHandler handles built-in workloads, e.g. Deployment, ReplicaSet, StatefulSet
 type WorkloadHandler struct {
+	Client client.Client
+
 	// Decoder decodes objects
 	Decoder *admission.Decoder
 }
 
+var _ inject.Client = &WorkloadHandler{}
+
+func (h *WorkloadHandler) InjectClient(c client.Client) error {
+	h.Client = c
+	return nil
+}
+
 func (h *WorkloadHandler) InjectDecoder(d *admission.Decoder) error {
 	h.Decoder = d
 	return nil
@@ -43,6 +54,9 @@ func (h *WorkloadHandler) InjectDecoder(d *admission.Decoder) error {
 
 // Handle handles admission requests.
 func (h *WorkloadHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation == admissionv1.Update {
+		return h.validateUpdate(req)
+	}
 	if req.Operation != admissionv1.Delete || req.SubResource != "" {
 		return admission.ValidationResponse(true, "")
 	}
@@ -80,10 +94,62 @@ func (h *WorkloadHandler) Handle(ctx context.Context, req admission.Request) adm
 		return admission.ValidationResponse(true, "")
 	}
 
-	if err := deletionprotection.ValidateWorkloadDeletion(metaObj, replicas); err != nil {
-		deletionprotection.WorkloadDeletionProtectionMetrics.WithLabelValues(fmt.Sprintf("%s_%s_%s", req.Kind.Kind, metaObj.GetNamespace(), metaObj.GetName()), req.UserInfo.Username).Add(1)
+	// the approval is not supported for builtin workloads, for the webhook can not check the updates of approval
+	if err := deletionprotection.ValidateWorkloadDeletion(metaObj, replicas, ""); err != nil {
+		deletionprotection.WorkloadDeletionProtectionMetrics.WithLabelValues(fmt.Sprintf("%s_%s_%s", req.Kind.Kind, metaObj.GetNamespace(), metaObj.GetName()), req.UserInfo.Username, deletionprotection.ResultDenied).Add(1)
 		util.LoggerProtectionInfo(util.ProtectionEventDeletionProtection, req.Kind.Kind, metaObj.GetNamespace(), metaObj.GetName(), req.UserInfo.Username)
 		return admission.Errored(http.StatusForbidden, err)
 	}
 	return admission.ValidationResponse(true, "")
 }
+
+// validateUpdate validates the updates of Deployment and StatefulSet, including their scale subresource.
+func (h *WorkloadHandler) validateUpdate(req admission.Request) admission.Response {
+	var err error
+	var kind, namespace, name string
+	switch {
+	case req.SubResource == "scale":
+		var workload client.Object
+		switch req.Resource.Resource {
+		case "deployments":
+			workload, kind = &apps.Deployment{}, "Deployment"
+		case "statefulsets":
+			workload, kind = &apps.StatefulSet{}, "StatefulSet"
+		default:
+			return admission.ValidationResponse(true, "")
+		}
+		namespace, name = req.Namespace, req.Name
+		err = deletionprotection.ValidateWorkloadScale(h.Client, workload, req.OldObject.Raw, req.Object.Raw)
+	case req.SubResource != "":
+		return admission.ValidationResponse(true, "")
+	case req.Kind.Kind == "Deployment":
+		obj, oldObj := &apps.Deployment{}, &apps.Deployment{}
+		if err := h.Decoder.Decode(req, obj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if err := h.Decoder.DecodeRaw(req.OldObject, oldObj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		kind, namespace, name = req.Kind.Kind, oldObj.Namespace, oldObj.Name
+		err = deletionprotection.ValidateWorkloadUpdate(oldObj, oldObj.Spec.Replicas, obj.Spec.Replicas, oldObj.Spec.Selector, obj.Spec.Selector)
+	case req.Kind.Kind == "StatefulSet":
+		obj, oldObj := &apps.StatefulSet{}, &apps.StatefulSet{}
+		if err := h.Decoder.Decode(req, obj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if err := h.Decoder.DecodeRaw(req.OldObject, oldObj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		kind, namespace, name = req.Kind.Kind, oldObj.Namespace, oldObj.Name
+		err = deletionprotection.ValidateWorkloadUpdate(oldObj, oldObj.Spec.Replicas, obj.Spec.Replicas, oldObj.Spec.Selector, obj.Spec.Selector)
+	default:
+		return admission.ValidationResponse(true, "")
+	}
+
+	if err != nil {
+		deletionprotection.WorkloadUpdateProtectionMetrics.WithLabelValues(fmt.Sprintf("%s_%s_%s", kind, namespace, name), req.UserInfo.Username).Add(1)
+		util.LoggerProtectionInfo(util.ProtectionEventDeletionProtection, kind, namespace, name, req.UserInfo.Username)
+		return admission.Errored(http.StatusForbidden, err)
+	}
+	return admission.ValidationResponse(true, "")
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apps "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+)
+
+func newScaleRequest(resource metav1.GroupVersionResource, name string, oldReplicas, replicas int32) admission.Request {
+	newScale := func(replicas int32) []byte {
+		raw, _ := json.Marshal(&autoscalingv1.Scale{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       autoscalingv1.ScaleSpec{Replicas: replicas},
+		})
+		return raw
+	}
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation:   admissionv1.Update,
+		SubResource: "scale",
+		Namespace:   "default",
+		Name:        name,
+		Resource:    resource,
+		Object:      runtime.RawExtension{Raw: newScale(replicas)},
+		OldObject:   runtime.RawExtension{Raw: newScale(oldReplicas)},
+	}}
+}
+
+func newProtectedObjectMeta(name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: "default",
+		Name:      name,
+		Labels:    map[string]string{policyv1alpha1.DeletionProtectionKey: policyv1alpha1.DeletionProtectionTypeCascadingStrict},
+	}
+}
+
+func TestHandleScale(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(apps.AddToScheme(scheme))
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&apps.Deployment{ObjectMeta: newProtectedObjectMeta("demo")}, &apps.StatefulSet{ObjectMeta: newProtectedObjectMeta("demo")}).Build()
+	decoder, _ := admission.NewDecoder(scheme)
+	handler := &WorkloadHandler{Client: fakeClient, Decoder: decoder}
+
+	cases := []struct {
+		name          string
+		resource      metav1.GroupVersionResource
+		oldReplicas   int32
+		replicas      int32
+		expectAllowed bool
+	}{
+		{
+			name:          "scale down Deployment",
+			resource:      metav1.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			oldReplicas:   3,
+			replicas:      1,
+			expectAllowed: true,
+		},
+		{
+			name:        "scale Deployment to zero",
+			resource:    metav1.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			oldReplicas: 3,
+			replicas:    0,
+		},
+		{
+			name:          "scale down StatefulSet",
+			resource:      metav1.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"},
+			oldReplicas:   3,
+			replicas:      1,
+			expectAllowed: true,
+		},
+		{
+			name:        "scale StatefulSet to zero",
+			resource:    metav1.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"},
+			oldReplicas: 3,
+			replicas:    0,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			resp := handler.Handle(context.TODO(), newScaleRequest(cs.resource, "demo", cs.oldReplicas, cs.replicas))
+			if resp.Allowed != cs.expectAllowed {
+				t.Fatalf("expect allowed %v, but got %v", cs.expectAllowed, resp.Result)
+			}
+			if !resp.Allowed && resp.Result.Code != http.StatusForbidden {
+				t.Fatalf("expect status code 403, but got %d", resp.Result.Code)
+			}
+		})
+	}
+}
@@ -16,7 +16,11 @@ limitations under the License.
 
 package validating
 
-import "sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+import (
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	webhookconfiguration "github.com/openkruise/kruise/pkg/webhook/util/configuration"
+)
 
 // +kubebuilder:webhook:path=/validate-apps-deployment,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1;v1beta1,groups=apps,resources=deployments,verbs=delete,versions=v1,name=vbuiltindeployment.kb.io
 
@@ -31,4 +35,10 @@ var (
 		"validate-apps-replicaset":  &WorkloadHandler{},
 		"validate-apps-statefulset": &WorkloadHandler{},
 	}
+
+	// UpdateProtectionHandlerMap contains the handler for updates and scaling of Deployment and StatefulSet,
+	// whose webhook rules are generated by webhook controller only if ResourcesDeletionProtection is enabled.
+	UpdateProtectionHandlerMap = map[string]admission.Handler{
+		webhookconfiguration.BuiltinWorkloadsUpdateProtectionPath: &WorkloadHandler{},
+	}
 )
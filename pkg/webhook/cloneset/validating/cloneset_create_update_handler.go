@@ -43,6 +43,17 @@ var _ admission.Handler = &CloneSetCreateUpdateHandler{}
 
 // Handle handles admission requests.
 func (h *CloneSetCreateUpdateHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.AdmissionRequest.SubResource == "scale" {
+		if req.AdmissionRequest.Operation == admissionv1.Update {
+			if err := deletionprotection.ValidateWorkloadScale(h.Client, &appsv1alpha1.CloneSet{}, req.OldObject.Raw, req.Object.Raw); err != nil {
+				deletionprotection.WorkloadUpdateProtectionMetrics.WithLabelValues(fmt.Sprintf("CloneSet_%s_%s", req.Namespace, req.Name), req.UserInfo.Username).Add(1)
+				util.LoggerProtectionInfo(util.ProtectionEventDeletionProtection, "CloneSet", req.Namespace, req.Name, req.UserInfo.Username)
+				return admission.Errored(http.StatusForbidden, err)
+			}
+		}
+		return admission.ValidationResponse(true, "")
+	}
+
 	obj := &appsv1alpha1.CloneSet{}
 	oldObj := &appsv1alpha1.CloneSet{}
 
@@ -55,6 +66,9 @@ func (h *CloneSetCreateUpdateHandler) Handle(ctx context.Context, req admission.
 		if allErrs := h.validateCloneSet(obj, nil); len(allErrs) > 0 {
 			return admission.Errored(http.StatusUnprocessableEntity, allErrs.ToAggregate())
 		}
+		if err := deletionprotection.ValidateDeletionApprovalChange(h.Client, nil, obj, req.UserInfo, deletionprotection.WorkloadDeletionProtectionMetrics); err != nil {
+			return admission.Errored(http.StatusForbidden, err)
+		}
 	case admissionv1.Update:
 		err := h.Decoder.Decode(req, obj)
 		if err != nil {
@@ -67,6 +81,14 @@ func (h *CloneSetCreateUpdateHandler) Handle(ctx context.Context, req admission.
 		if allErrs := h.validateCloneSetUpdate(obj, oldObj); len(allErrs) > 0 {
 			return admission.Errored(http.StatusUnprocessableEntity, allErrs.ToAggregate())
 		}
+		if err := deletionprotection.ValidateDeletionApprovalChange(h.Client, oldObj, obj, req.UserInfo, deletionprotection.WorkloadDeletionProtectionMetrics); err != nil {
+			return admission.Errored(http.StatusForbidden, err)
+		}
+		if err := deletionprotection.ValidateWorkloadUpdate(oldObj, oldObj.Spec.Replicas, obj.Spec.Replicas, oldObj.Spec.Selector, obj.Spec.Selector); err != nil {
+			deletionprotection.WorkloadUpdateProtectionMetrics.WithLabelValues(fmt.Sprintf("%s_%s_%s", req.Kind.Kind, oldObj.GetNamespace(), oldObj.GetName()), req.UserInfo.Username).Add(1)
+			util.LoggerProtectionInfo(util.ProtectionEventDeletionProtection, req.Kind.Kind, oldObj.GetNamespace(), oldObj.GetName(), req.UserInfo.Username)
+			return admission.Errored(http.StatusForbidden, err)
+		}
 	case admissionv1.Delete:
 		if len(req.OldObject.Raw) == 0 {
 			klog.Warningf("Skip to validate CloneSet %s/%s deletion for no old object, maybe because of Kubernetes version < 1.16", req.Namespace, req.Name)
@@ -75,8 +97,8 @@ func (h *CloneSetCreateUpdateHandler) Handle(ctx context.Context, req admission.
 		if err := h.Decoder.DecodeRaw(req.AdmissionRequest.OldObject, oldObj); err != nil {
 			return admission.Errored(http.StatusBadRequest, err)
 		}
-		if err := deletionprotection.ValidateWorkloadDeletion(oldObj, oldObj.Spec.Replicas); err != nil {
-			deletionprotection.WorkloadDeletionProtectionMetrics.WithLabelValues(fmt.Sprintf("%s_%s_%s", req.Kind.Kind, oldObj.GetNamespace(), oldObj.GetName()), req.UserInfo.Username).Add(1)
+		if err := deletionprotection.ValidateWorkloadDeletion(oldObj, oldObj.Spec.Replicas, req.UserInfo.Username); err != nil {
+			deletionprotection.WorkloadDeletionProtectionMetrics.WithLabelValues(fmt.Sprintf("%s_%s_%s", req.Kind.Kind, oldObj.GetNamespace(), oldObj.GetName()), req.UserInfo.Username, deletionprotection.ResultDenied).Add(1)
 			util.LoggerProtectionInfo(util.ProtectionEventDeletionProtection, req.Kind.Kind, oldObj.GetNamespace(), oldObj.GetName(), req.UserInfo.Username)
 			return admission.Errored(http.StatusForbidden, err)
 		}
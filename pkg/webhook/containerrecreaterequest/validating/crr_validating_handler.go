@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	kubecontroller "k8s.io/kubernetes/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
+)
+
+// ContainerRecreateRequestHandler validates ContainerRecreateRequest
+type ContainerRecreateRequestHandler struct {
+	Client  client.Client
+	Decoder *admission.Decoder
+}
+
+// Handle handles admission requests.
+// Recreating containers makes an active pod unavailable, so the creation has to acquire the budget of PodUnavailableBudget.
+// It is acquired after all mutating webhooks have admitted the request, so that a request rejected by them does not hold the budget.
+func (h *ContainerRecreateRequestHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.AdmissionRequest.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+	obj := &appsv1alpha1.ContainerRecreateRequest{}
+	if err := h.Decoder.Decode(req, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	pod := &v1.Pod{}
+	if err := h.Client.Get(ctx, types.NamespacedName{Namespace: obj.Namespace, Name: obj.Spec.PodName}, pod); err != nil {
+		if errors.IsNotFound(err) {
+			return admission.Errored(http.StatusBadRequest, fmt.Errorf("no found Pod named %s", obj.Spec.PodName))
+		}
+		return admission.Errored(http.StatusInternalServerError, fmt.Errorf("failed to find Pod %s: %v", obj.Spec.PodName, err))
+	}
+	// Inactive pods, which can only be the ones terminated by SidecarTerminator here, are not counted by pub.
+	if !kubecontroller.IsPodActive(pod) {
+		return admission.Allowed("")
+	}
+	allowed, reason, err := pubcontrol.AcquirePodDisruption(pod, req.UserInfo.Username, req.DryRun != nil && *req.DryRun)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	} else if !allowed {
+		return admission.Errored(http.StatusForbidden, fmt.Errorf("not allowed to recreate containers of Pod %s by PodUnavailableBudget: %s", pod.Name, reason))
+	}
+	return admission.Allowed("")
+}
+
+var _ inject.Client = &ContainerRecreateRequestHandler{}
+
+// InjectClient injects the client into the ContainerRecreateRequestHandler
+func (h *ContainerRecreateRequestHandler) InjectClient(c client.Client) error {
+	h.Client = c
+	return nil
+}
+
+var _ admission.DecoderInjector = &ContainerRecreateRequestHandler{}
+
+// InjectDecoder injects the decoder into the ContainerRecreateRequestHandler
+func (h *ContainerRecreateRequestHandler) InjectDecoder(d *admission.Decoder) error {
+	h.Decoder = d
+	return nil
+}
@@ -2,6 +2,7 @@ package validating
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 
@@ -11,6 +12,8 @@ import (
 	metavalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	appsvalidation "k8s.io/kubernetes/pkg/apis/apps/validation"
@@ -73,6 +76,7 @@ func validateDaemonSetSpec(spec *appsv1alpha1.DaemonSetSpec, fldPath *field.Path
 	}
 	allErrs = append(allErrs, corevalidation.ValidateNonnegativeField(int64(spec.MinReadySeconds), fldPath.Child("minReadySeconds"))...)
 
+	allErrs = append(allErrs, validateNodeOverrides(spec, selector, fldPath.Child("nodeOverrides"))...)
 	allErrs = append(allErrs, validateDaemonSetUpdateStrategy(&spec.UpdateStrategy, fldPath.Child("updateStrategy"))...)
 	if spec.RevisionHistoryLimit != nil {
 		// zero is a valid RevisionHistoryLimit
@@ -87,6 +91,46 @@ func validateDaemonSetSpec(spec *appsv1alpha1.DaemonSetSpec, fldPath *field.Path
 	return allErrs
 }
 
+func validateNodeOverrides(spec *appsv1alpha1.DaemonSetSpec, selector labels.Selector, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(spec.NodeOverrides) == 0 {
+		return allErrs
+	}
+	templateBytes, err := json.Marshal(spec.Template)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Root(), spec.Template, fmt.Sprintf("failed to marshal template: %v", err)))
+		return allErrs
+	}
+
+	for i, override := range spec.NodeOverrides {
+		idxPath := fldPath.Index(i)
+		if override.NodeSelector == nil {
+			allErrs = append(allErrs, field.Required(idxPath.Child("nodeSelector"), ""))
+		} else {
+			allErrs = append(allErrs, metavalidation.ValidateLabelSelector(override.NodeSelector, metavalidation.LabelSelectorValidationOptions{}, idxPath.Child("nodeSelector"))...)
+		}
+
+		if len(override.Patch.Raw) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath.Child("patch"), ""))
+			continue
+		}
+		patchedBytes, err := strategicpatch.StrategicMergePatch(templateBytes, override.Patch.Raw, &corev1.PodTemplateSpec{})
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("patch"), string(override.Patch.Raw), fmt.Sprintf("failed to apply patch to template: %v", err)))
+			continue
+		}
+		patched := &corev1.PodTemplateSpec{}
+		if err = json.Unmarshal(patchedBytes, patched); err != nil {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("patch"), string(override.Patch.Raw), fmt.Sprintf("failed to unmarshal patched template: %v", err)))
+			continue
+		}
+		if selector != nil && !selector.Matches(labels.Set(patched.Labels)) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("patch"), string(override.Patch.Raw), "`selector` does not match patched template `labels`"))
+		}
+	}
+	return allErrs
+}
+
 func validateDaemonSetUpdateStrategy(strategy *appsv1alpha1.DaemonSetUpdateStrategy, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	switch strategy.Type {
@@ -145,6 +189,79 @@ func validateRollingUpdateDaemonSet(rollingUpdate *appsv1alpha1.RollingUpdateDae
 		allErrs = append(allErrs, corevalidation.ValidateNonnegativeField(int64(*rollingUpdate.Partition), fldPath.Child("rollingUpdate").Child("partition"))...)
 	}
 
+	if len(rollingUpdate.Waves) > 0 {
+		allErrs = append(allErrs, validateUpdateWaves(rollingUpdate, fldPath.Child("waves"))...)
+	}
+
+	if rollingUpdate.NodeDrain != nil {
+		allErrs = append(allErrs, validateNodeDrain(rollingUpdate.NodeDrain, fldPath.Child("nodeDrain"))...)
+	}
+
+	if rollingUpdate.FailureBudget != nil {
+		allErrs = append(allErrs, validateFailureBudget(rollingUpdate.FailureBudget, fldPath.Child("failureBudget"))...)
+	}
+
+	return allErrs
+}
+
+func validateNodeDrain(nodeDrain *appsv1alpha1.DaemonSetNodeDrainPolicy, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if nodeDrain.TaintKey != "" {
+		for _, msg := range validation.IsQualifiedName(nodeDrain.TaintKey) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("taintKey"), nodeDrain.TaintKey, msg))
+		}
+	}
+	if nodeDrain.DependentPodSelector != nil {
+		allErrs = append(allErrs, metavalidation.ValidateLabelSelector(nodeDrain.DependentPodSelector, metavalidation.LabelSelectorValidationOptions{}, fldPath.Child("dependentPodSelector"))...)
+	}
+	allErrs = append(allErrs, corevalidation.ValidateNonnegativeField(int64(nodeDrain.TimeoutSeconds), fldPath.Child("timeoutSeconds"))...)
+	return allErrs
+}
+
+func validateUpdateWaves(rollingUpdate *appsv1alpha1.RollingUpdateDaemonSet, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if rollingUpdate.Selector != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "waves can not be set together with selector"))
+	}
+	if rollingUpdate.Partition != nil && *rollingUpdate.Partition != 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "waves can not be set together with partition"))
+	}
+
+	names := sets.NewString()
+	for i, wave := range rollingUpdate.Waves {
+		idxPath := fldPath.Index(i)
+		if wave.Name == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), ""))
+		} else if names.Has(wave.Name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), wave.Name))
+		}
+		names.Insert(wave.Name)
+
+		if wave.NodeSelector == nil && wave.MaxUpdated == nil {
+			allErrs = append(allErrs, field.Required(idxPath, "either nodeSelector or maxUpdated must be set"))
+		}
+		if wave.NodeSelector != nil {
+			allErrs = append(allErrs, metavalidation.ValidateLabelSelector(wave.NodeSelector, metavalidation.LabelSelectorValidationOptions{}, idxPath.Child("nodeSelector"))...)
+		}
+		if wave.MaxUpdated != nil {
+			allErrs = append(allErrs, appsvalidation.ValidatePositiveIntOrPercent(*wave.MaxUpdated, idxPath.Child("maxUpdated"))...)
+			allErrs = append(allErrs, appsvalidation.IsNotMoreThan100Percent(*wave.MaxUpdated, idxPath.Child("maxUpdated"))...)
+		}
+		allErrs = append(allErrs, corevalidation.ValidateNonnegativeField(int64(wave.SoakSeconds), idxPath.Child("soakSeconds"))...)
+	}
+	return allErrs
+}
+
+func validateFailureBudget(budget *appsv1alpha1.DaemonSetUpdateFailureBudget, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if budget.MaxFailed != nil {
+		allErrs = append(allErrs, appsvalidation.ValidatePositiveIntOrPercent(*budget.MaxFailed, fldPath.Child("maxFailed"))...)
+		allErrs = append(allErrs, appsvalidation.IsNotMoreThan100Percent(*budget.MaxFailed, fldPath.Child("maxFailed"))...)
+	}
+	if budget.ProgressDeadlineSeconds != nil && *budget.ProgressDeadlineSeconds <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("progressDeadlineSeconds"), *budget.ProgressDeadlineSeconds, "must be greater than 0"))
+	}
 	return allErrs
 }
 
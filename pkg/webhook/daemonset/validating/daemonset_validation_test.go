@@ -25,8 +25,12 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	utilpointer "k8s.io/utils/pointer"
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 )
@@ -240,3 +244,196 @@ func TestValidateDaemonSetUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateUpdateWaves(t *testing.T) {
+	maxUpdated := intstr.FromString("50%")
+	invalidMaxUpdated := intstr.FromString("150%")
+	canarySelector := &metav1.LabelSelector{MatchLabels: map[string]string{"node-type": "canary"}}
+
+	for _, c := range []struct {
+		Title         string
+		RollingUpdate *appsv1alpha1.RollingUpdateDaemonSet
+		ExpectErrors  int
+	}{
+		{
+			Title: "valid waves",
+			RollingUpdate: &appsv1alpha1.RollingUpdateDaemonSet{
+				Waves: []appsv1alpha1.DaemonSetUpdateWave{
+					{Name: "canary", NodeSelector: canarySelector, SoakSeconds: 600},
+					{Name: "half", MaxUpdated: &maxUpdated},
+				},
+			},
+		},
+		{
+			Title: "waves with selector",
+			RollingUpdate: &appsv1alpha1.RollingUpdateDaemonSet{
+				Selector: canarySelector,
+				Waves: []appsv1alpha1.DaemonSetUpdateWave{
+					{Name: "canary", NodeSelector: canarySelector},
+				},
+			},
+			ExpectErrors: 1,
+		},
+		{
+			Title: "duplicated wave names",
+			RollingUpdate: &appsv1alpha1.RollingUpdateDaemonSet{
+				Waves: []appsv1alpha1.DaemonSetUpdateWave{
+					{Name: "canary", NodeSelector: canarySelector},
+					{Name: "canary", MaxUpdated: &maxUpdated},
+				},
+			},
+			ExpectErrors: 1,
+		},
+		{
+			Title: "invalid wave",
+			RollingUpdate: &appsv1alpha1.RollingUpdateDaemonSet{
+				Waves: []appsv1alpha1.DaemonSetUpdateWave{
+					{Name: "empty"},
+					{Name: "too-many", MaxUpdated: &invalidMaxUpdated, SoakSeconds: -1},
+				},
+			},
+			ExpectErrors: 3,
+		},
+	} {
+		errs := validateUpdateWaves(c.RollingUpdate, field.NewPath("waves"))
+		if len(errs) != c.ExpectErrors {
+			t.Fatalf("case: %s, expected %d errors, got: %v", c.Title, c.ExpectErrors, errs)
+		}
+	}
+}
+
+func TestValidateUpdateWavesFieldPath(t *testing.T) {
+	canarySelector := &metav1.LabelSelector{MatchLabels: map[string]string{"node-type": "canary"}}
+	strategy := &appsv1alpha1.DaemonSetUpdateStrategy{
+		Type: appsv1alpha1.RollingUpdateDaemonSetStrategyType,
+		RollingUpdate: &appsv1alpha1.RollingUpdateDaemonSet{
+			MaxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+			Partition:      utilpointer.Int32Ptr(1),
+			Waves: []appsv1alpha1.DaemonSetUpdateWave{
+				{Name: "canary", NodeSelector: canarySelector},
+			},
+		},
+	}
+	errs := validateDaemonSetUpdateStrategy(strategy, field.NewPath("spec", "updateStrategy"))
+	if len(errs) != 1 || errs[0].Field != "spec.updateStrategy.rollingUpdate.waves" {
+		t.Fatalf("expected one error on spec.updateStrategy.rollingUpdate.waves, got: %v", errs)
+	}
+}
+
+func TestValidateNodeDrain(t *testing.T) {
+	for _, c := range []struct {
+		Title        string
+		NodeDrain    *appsv1alpha1.DaemonSetNodeDrainPolicy
+		ExpectErrors int
+	}{
+		{
+			Title: "valid node drain",
+			NodeDrain: &appsv1alpha1.DaemonSetNodeDrainPolicy{
+				TaintKey:             appsv1alpha1.DefaultDaemonSetNodeDrainTaintKey,
+				DependentPodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"depends-on": "csi"}},
+				TimeoutSeconds:       300,
+			},
+		},
+		{
+			Title: "invalid taint key",
+			NodeDrain: &appsv1alpha1.DaemonSetNodeDrainPolicy{
+				TaintKey: "invalid key",
+			},
+			ExpectErrors: 1,
+		},
+		{
+			Title: "invalid selector and timeout",
+			NodeDrain: &appsv1alpha1.DaemonSetNodeDrainPolicy{
+				DependentPodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"depends-on": "-csi"}},
+				TimeoutSeconds:       -1,
+			},
+			ExpectErrors: 2,
+		},
+	} {
+		errs := validateNodeDrain(c.NodeDrain, field.NewPath("nodeDrain"))
+		if len(errs) != c.ExpectErrors {
+			t.Fatalf("case: %s, expected %d errors, got: %v", c.Title, c.ExpectErrors, errs)
+		}
+	}
+}
+
+func TestValidateFailureBudget(t *testing.T) {
+	maxFailed := intstr.FromInt(0)
+	invalidMaxFailed := intstr.FromString("200%")
+
+	for _, c := range []struct {
+		Title         string
+		FailureBudget *appsv1alpha1.DaemonSetUpdateFailureBudget
+		ExpectErrors  int
+	}{
+		{
+			Title:         "valid failure budget",
+			FailureBudget: &appsv1alpha1.DaemonSetUpdateFailureBudget{MaxFailed: &maxFailed, ProgressDeadlineSeconds: utilpointer.Int32Ptr(600)},
+		},
+		{
+			Title:         "invalid maxFailed",
+			FailureBudget: &appsv1alpha1.DaemonSetUpdateFailureBudget{MaxFailed: &invalidMaxFailed},
+			ExpectErrors:  1,
+		},
+		{
+			Title:         "invalid progressDeadlineSeconds",
+			FailureBudget: &appsv1alpha1.DaemonSetUpdateFailureBudget{ProgressDeadlineSeconds: utilpointer.Int32Ptr(0)},
+			ExpectErrors:  1,
+		},
+	} {
+		errs := validateFailureBudget(c.FailureBudget, field.NewPath("failureBudget"))
+		if len(errs) != c.ExpectErrors {
+			t.Fatalf("case: %s, expected %d errors, got: %v", c.Title, c.ExpectErrors, errs)
+		}
+	}
+}
+
+func TestValidateNodeOverrides(t *testing.T) {
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "foo"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "main", Image: "foo:v1"}}},
+	}
+	nodeSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"node-type": "gpu"}}
+	selector := labels.SelectorFromSet(map[string]string{"app": "foo"})
+
+	for _, c := range []struct {
+		Title         string
+		NodeOverrides []appsv1alpha1.DaemonSetNodeOverride
+		ExpectErrors  int
+	}{
+		{
+			Title: "valid node overrides",
+			NodeOverrides: []appsv1alpha1.DaemonSetNodeOverride{{
+				NodeSelector: nodeSelector,
+				Patch:        runtime.RawExtension{Raw: []byte(`{"spec":{"containers":[{"name":"main","resources":{"limits":{"memory":"1Gi"}}}]}}`)},
+			}},
+		},
+		{
+			Title:         "missing nodeSelector and patch",
+			NodeOverrides: []appsv1alpha1.DaemonSetNodeOverride{{}},
+			ExpectErrors:  2,
+		},
+		{
+			Title: "invalid patch",
+			NodeOverrides: []appsv1alpha1.DaemonSetNodeOverride{{
+				NodeSelector: nodeSelector,
+				Patch:        runtime.RawExtension{Raw: []byte(`{"spec":`)},
+			}},
+			ExpectErrors: 1,
+		},
+		{
+			Title: "patched labels not match selector",
+			NodeOverrides: []appsv1alpha1.DaemonSetNodeOverride{{
+				NodeSelector: nodeSelector,
+				Patch:        runtime.RawExtension{Raw: []byte(`{"metadata":{"labels":{"app":"bar"}}}`)},
+			}},
+			ExpectErrors: 1,
+		},
+	} {
+		spec := &appsv1alpha1.DaemonSetSpec{Template: template, NodeOverrides: c.NodeOverrides}
+		errs := validateNodeOverrides(spec, selector, field.NewPath("nodeOverrides"))
+		if len(errs) != c.ExpectErrors {
+			t.Fatalf("case: %s, expected %d errors, got: %v", c.Title, c.ExpectErrors, errs)
+		}
+	}
+}
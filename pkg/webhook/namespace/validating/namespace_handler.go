@@ -53,7 +53,7 @@ func (h *NamespaceHandler) Handle(ctx context.Context, req admission.Request) ad
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 	if err := deletionprotection.ValidateNamespaceDeletion(h.Client, obj); err != nil {
-		deletionprotection.NamespaceDeletionProtectionMetrics.WithLabelValues(obj.Name, req.UserInfo.Username).Add(1)
+		deletionprotection.NamespaceDeletionProtectionMetrics.WithLabelValues(obj.Name, req.UserInfo.Username, deletionprotection.ResultDenied).Add(1)
 		util.LoggerProtectionInfo(util.ProtectionEventDeletionProtection, "Namespace", "", obj.Name, req.UserInfo.Username)
 		return admission.Errored(http.StatusForbidden, err)
 	}
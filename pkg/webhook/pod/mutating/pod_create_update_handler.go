@@ -22,6 +22,7 @@ import (
 	"net/http"
 
 	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -29,6 +30,7 @@ import (
 	"github.com/openkruise/kruise/pkg/features"
 	"github.com/openkruise/kruise/pkg/util/controllerfinder"
 	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	wsutil "github.com/openkruise/kruise/pkg/util/workloadspread"
 )
 
 // PodCreateHandler handles Pod
@@ -136,6 +138,16 @@ func (h *PodCreateHandler) InjectClient(c client.Client) error {
 	return nil
 }
 
+var _ inject.Cache = &PodCreateHandler{}
+
+// InjectCache registers the handlers that keep the WorkloadSpread subset capacity cache up to date
+func (h *PodCreateHandler) InjectCache(c cache.Cache) error {
+	if !utilfeature.DefaultFeatureGate.Enabled(features.WorkloadSpread) {
+		return nil
+	}
+	return wsutil.RegisterSubsetCapacityHandlers(c)
+}
+
 var _ admission.DecoderInjector = &PodCreateHandler{}
 
 // InjectDecoder injects the decoder into the PodCreateHandler
@@ -0,0 +1,305 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
+	"github.com/openkruise/kruise/pkg/controller/podunavailablebudget"
+)
+
+const (
+	// Path is the path of PodUnavailableBudget simulation endpoint in webhook server.
+	Path = "/simulate-podunavailablebudget"
+
+	maxRequestBodyBytes = 3 * 1024 * 1024
+)
+
+// SimulationRequest contains the pod operations to be simulated in order, such as the pods to be evicted by a node drain.
+type SimulationRequest struct {
+	// Operation is the default operation of pods, defaults to EVICT.
+	Operation policyv1alpha1.PubOperation `json:"operation,omitempty"`
+	Pods      []SimulationPod             `json:"pods"`
+}
+
+type SimulationPod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Operation overrides the default operation of request.
+	Operation policyv1alpha1.PubOperation `json:"operation,omitempty"`
+}
+
+type SimulationResponse struct {
+	Pods                  []SimulationPodResult `json:"pods"`
+	PodUnavailableBudgets []SimulationPubResult `json:"podUnavailableBudgets"`
+}
+
+type SimulationPodResult struct {
+	Namespace string                      `json:"namespace"`
+	Name      string                      `json:"name"`
+	Operation policyv1alpha1.PubOperation `json:"operation"`
+	Allowed   bool                        `json:"allowed"`
+	Reason    string                      `json:"reason,omitempty"`
+	// PodUnavailableBudget is the name of pub which has checked the operation.
+	PodUnavailableBudget string `json:"podUnavailableBudget,omitempty"`
+}
+
+type SimulationPubResult struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// UnavailableAllowed is the current quota of pub before the simulation.
+	UnavailableAllowed int32 `json:"unavailableAllowed"`
+	// RemainingUnavailableAllowed is the quota of pub left after the allowed operations.
+	RemainingUnavailableAllowed int32 `json:"remainingUnavailableAllowed"`
+	// RemainingTopologyUnavailableAllowed is the quota of each topology domain left after the allowed operations.
+	RemainingTopologyUnavailableAllowed map[string]int32 `json:"remainingTopologyUnavailableAllowed,omitempty"`
+	AllowedPods                         []string         `json:"allowedPods,omitempty"`
+	DeniedPods                          []string         `json:"deniedPods,omitempty"`
+	// NextRecheckTime is the earliest time at which pub controller will release the quota held by the recorded
+	// disrupted or unavailable pods, it is set only if some operations are denied. The quota will not be
+	// available until the disrupted pods are replaced by ready ones, so callers should simulate again after it.
+	NextRecheckTime *metav1.Time `json:"nextRecheckTime,omitempty"`
+}
+
+// Handler simulates pod operations against PodUnavailableBudgets without recording anything,
+// so that callers such as drain tooling can forecast the disruptions before acting.
+// The caller is authenticated by its bearer token with TokenReview, and it must be allowed
+// to evict pods in the namespaces of the requested pods, which is checked by SubjectAccessReview.
+type Handler struct {
+	Client client.Reader
+	// ReviewClient creates TokenReviews and SubjectAccessReviews against apiserver.
+	ReviewClient client.Writer
+}
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, fmt.Sprintf("method %s is not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	userInfo, err := h.authenticate(r)
+	if err != nil {
+		klog.ErrorS(err, "Failed to authenticate podUnavailableBudget simulation request")
+		http.Error(w, "failed to authenticate request", http.StatusInternalServerError)
+		return
+	} else if userInfo == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	req := &SimulationRequest{}
+	if err = json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)).Decode(req); err != nil {
+		http.Error(w, fmt.Sprintf("failed to decode request: %v", err), http.StatusBadRequest)
+		return
+	}
+	namespaces := sets.NewString()
+	for _, p := range req.Pods {
+		namespaces.Insert(p.Namespace)
+	}
+	for _, namespace := range namespaces.List() {
+		allowed, err := h.authorize(userInfo, namespace)
+		if err != nil {
+			klog.ErrorS(err, "Failed to authorize podUnavailableBudget simulation request", "user", userInfo.Username, "namespace", namespace)
+			http.Error(w, "failed to authorize request", http.StatusInternalServerError)
+			return
+		} else if !allowed {
+			http.Error(w, fmt.Sprintf("user %q cannot create resource \"pods/eviction\" in namespace %q", userInfo.Username, namespace), http.StatusForbidden)
+			return
+		}
+	}
+	resp, err := h.simulate(req)
+	if err != nil {
+		klog.ErrorS(err, "Failed to simulate pod operations for podUnavailableBudget")
+		if errors.IsBadRequest(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		klog.ErrorS(err, "Failed to write podUnavailableBudget simulation response")
+	}
+}
+
+// authenticate returns the user of the bearer token in request, or nil if the token is missing or not authenticated.
+func (h *Handler) authenticate(r *http.Request) (*authenticationv1.UserInfo, error) {
+	auth := strings.TrimSpace(r.Header.Get("Authorization"))
+	parts := strings.SplitN(auth, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") || strings.TrimSpace(parts[1]) == "" {
+		return nil, nil
+	}
+	review := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: strings.TrimSpace(parts[1])}}
+	if err := h.ReviewClient.Create(r.Context(), review); err != nil {
+		return nil, err
+	}
+	if !review.Status.Authenticated {
+		return nil, nil
+	}
+	return &review.Status.User, nil
+}
+
+// authorize returns whether the user is allowed to evict pods in the namespace.
+func (h *Handler) authorize(userInfo *authenticationv1.UserInfo, namespace string) (bool, error) {
+	extra := make(map[string]authorizationv1.ExtraValue, len(userInfo.Extra))
+	for k, v := range userInfo.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   userInfo.Username,
+			Groups: userInfo.Groups,
+			UID:    userInfo.UID,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   namespace,
+				Verb:        "create",
+				Resource:    "pods",
+				Subresource: "eviction",
+			},
+		},
+	}
+	if err := h.ReviewClient.Create(context.TODO(), review); err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+func (h *Handler) simulate(req *SimulationRequest) (*SimulationResponse, error) {
+	defaultOperation := req.Operation
+	if defaultOperation == "" {
+		defaultOperation = policyv1alpha1.PubEvictOperation
+	}
+	resp := &SimulationResponse{Pods: make([]SimulationPodResult, len(req.Pods))}
+	var operations []pubcontrol.SimulatedPodOperation
+	// the index of response pods for each operation
+	var indexes []int
+	for i, p := range req.Pods {
+		operation := p.Operation
+		if operation == "" {
+			operation = defaultOperation
+		}
+		switch operation {
+		case policyv1alpha1.PubDeleteOperation, policyv1alpha1.PubEvictOperation, policyv1alpha1.PubUpdateOperation:
+		default:
+			return nil, errors.NewBadRequest(fmt.Sprintf("unsupported operation %s of pod %s/%s", operation, p.Namespace, p.Name))
+		}
+		resp.Pods[i] = SimulationPodResult{Namespace: p.Namespace, Name: p.Name, Operation: operation, Allowed: true}
+
+		pod := &corev1.Pod{}
+		if err := h.Client.Get(context.TODO(), types.NamespacedName{Namespace: p.Namespace, Name: p.Name}, pod); err != nil {
+			if errors.IsNotFound(err) {
+				resp.Pods[i].Reason = "pod not found"
+				continue
+			}
+			return nil, err
+		}
+		operations = append(operations, pubcontrol.SimulatedPodOperation{Pod: pod, Operation: operation})
+		indexes = append(indexes, i)
+	}
+
+	results, pubs, err := pubcontrol.SimulatePodsOperation(operations)
+	if err != nil {
+		return nil, err
+	}
+	pubResults := map[types.NamespacedName]*SimulationPubResult{}
+	for i, result := range results {
+		if result.Pub == nil {
+			continue
+		}
+		podResult := &resp.Pods[indexes[i]]
+		podResult.Allowed = result.Allowed
+		podResult.Reason = result.Reason
+		podResult.PodUnavailableBudget = result.Pub.Name
+
+		key := types.NamespacedName{Namespace: result.Pub.Namespace, Name: result.Pub.Name}
+		pubResult, ok := pubResults[key]
+		if !ok {
+			pubResult = &SimulationPubResult{
+				Namespace:          key.Namespace,
+				Name:               key.Name,
+				UnavailableAllowed: pubs[key].Status.UnavailableAllowed,
+			}
+			pubResults[key] = pubResult
+		}
+		// result.Pub is shared by all the operations of the same pub, which holds the final quota
+		pubResult.RemainingUnavailableAllowed = result.Pub.Status.UnavailableAllowed
+		pubResult.RemainingTopologyUnavailableAllowed = result.Pub.Status.TopologyUnavailableAllowed
+		if result.Allowed {
+			pubResult.AllowedPods = append(pubResult.AllowedPods, result.Pod.Name)
+		} else {
+			pubResult.DeniedPods = append(pubResult.DeniedPods, result.Pod.Name)
+			pubResult.NextRecheckTime = getNextRecheckTime(pubs[key], time.Now())
+		}
+	}
+
+	resp.PodUnavailableBudgets = make([]SimulationPubResult, 0, len(pubResults))
+	for _, pubResult := range pubResults {
+		resp.PodUnavailableBudgets = append(resp.PodUnavailableBudgets, *pubResult)
+	}
+	sort.Slice(resp.PodUnavailableBudgets, func(i, j int) bool {
+		if resp.PodUnavailableBudgets[i].Namespace != resp.PodUnavailableBudgets[j].Namespace {
+			return resp.PodUnavailableBudgets[i].Namespace < resp.PodUnavailableBudgets[j].Namespace
+		}
+		return resp.PodUnavailableBudgets[i].Name < resp.PodUnavailableBudgets[j].Name
+	})
+	return resp, nil
+}
+
+// getNextRecheckTime returns the earliest time at which the recorded pods of pub will be expired by pub controller,
+// it is the same as the requeue time of pub controller.
+func getNextRecheckTime(pub *policyv1alpha1.PodUnavailableBudget, now time.Time) *metav1.Time {
+	var recheckTime *time.Time
+	record := func(expected time.Time) {
+		if expected.Before(now) {
+			expected = now
+		}
+		if recheckTime == nil || expected.Before(*recheckTime) {
+			recheckTime = &expected
+		}
+	}
+	for _, disruptionTime := range pub.Status.DisruptedPods {
+		record(disruptionTime.Add(podunavailablebudget.DeletionTimeout))
+	}
+	for _, unavailableTime := range pub.Status.UnavailablePods {
+		record(unavailableTime.Add(podunavailablebudget.UpdatedDelayCheckTime))
+	}
+	if recheckTime == nil {
+		return nil
+	}
+	return &metav1.Time{Time: *recheckTime}
+}
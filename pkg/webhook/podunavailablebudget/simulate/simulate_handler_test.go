@@ -0,0 +1,233 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/control/pubcontrol"
+	"github.com/openkruise/kruise/pkg/controller/podunavailablebudget"
+	"github.com/openkruise/kruise/pkg/util/controllerfinder"
+)
+
+var scheme *runtime.Scheme
+
+func init() {
+	scheme = runtime.NewScheme()
+	utilruntime.Must(policyv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(corev1.AddToScheme(scheme))
+}
+
+func newPod(name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        name,
+			Labels:      map[string]string{"app": "nginx"},
+			Annotations: map[string]string{pubcontrol.PodRelatedPubAnnotation: "pub-test"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "nginx", Image: "nginx:v1"}},
+		},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "nginx", Image: "nginx:v1", ImageID: "nginx@sha256:a9286defaba7b3a519d585ba0e37d0b2cbee74ebfe590960b0b1d6a5e97d1e1d", Ready: true},
+			},
+		},
+	}
+}
+
+const testToken = "test-token"
+
+// fakeReviewClient authenticates testToken as user "drainer", which is allowed to evict pods in the allowed namespaces.
+type fakeReviewClient struct {
+	client.Writer
+	allowedNamespaces []string
+}
+
+func (c *fakeReviewClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	switch review := obj.(type) {
+	case *authenticationv1.TokenReview:
+		if review.Spec.Token == testToken {
+			review.Status.Authenticated = true
+			review.Status.User = authenticationv1.UserInfo{Username: "drainer"}
+		}
+	case *authorizationv1.SubjectAccessReview:
+		attrs := review.Spec.ResourceAttributes
+		if review.Spec.User == "drainer" && attrs.Verb == "create" && attrs.Resource == "pods" && attrs.Subresource == "eviction" {
+			for _, namespace := range c.allowedNamespaces {
+				review.Status.Allowed = review.Status.Allowed || namespace == attrs.Namespace
+			}
+		}
+	default:
+		return fmt.Errorf("unexpected object %T", obj)
+	}
+	return nil
+}
+
+func newSimulationRequest(token string, req *SimulationRequest) *http.Request {
+	body, _ := json.Marshal(req)
+	r := httptest.NewRequest(http.MethodPost, Path, bytes.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return r
+}
+
+func TestSimulate(t *testing.T) {
+	disruptedTime := time.Now().Add(-5 * time.Second)
+	pub := &policyv1alpha1.PodUnavailableBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pub-test"},
+		Spec: policyv1alpha1.PodUnavailableBudgetSpec{
+			Selector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "nginx"}},
+			MaxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 3},
+		},
+		Status: policyv1alpha1.PodUnavailableBudgetStatus{
+			DisruptedPods:      map[string]metav1.Time{"pod-disrupted": {Time: disruptedTime}},
+			UnavailableAllowed: 2,
+			DesiredAvailable:   3,
+		},
+	}
+	objects := []client.Object{pub}
+	for i := 0; i < 4; i++ {
+		objects = append(objects, newPod(fmt.Sprintf("pod-%d", i)))
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	pubcontrol.InitPubControl(fakeClient, &controllerfinder.ControllerFinder{Client: fakeClient}, record.NewFakeRecorder(10))
+	handler := &Handler{Client: fakeClient, ReviewClient: &fakeReviewClient{allowedNamespaces: []string{"default"}}}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, newSimulationRequest(testToken, &SimulationRequest{Pods: []SimulationPod{
+		{Namespace: "default", Name: "pod-0"},
+		{Namespace: "default", Name: "pod-1", Operation: policyv1alpha1.PubDeleteOperation},
+		{Namespace: "default", Name: "pod-2"},
+		{Namespace: "default", Name: "pod-not-found"},
+	}}))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expect status code 200, but got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	resp := &SimulationResponse{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), resp); err != nil {
+		t.Fatalf("failed to decode response: %s", err.Error())
+	}
+
+	expectAllowed := []bool{true, true, false, true}
+	for i := range resp.Pods {
+		if resp.Pods[i].Allowed != expectAllowed[i] {
+			t.Fatalf("expect pod %s allowed %v, but got %v", resp.Pods[i].Name, expectAllowed[i], resp.Pods[i].Allowed)
+		}
+	}
+	if resp.Pods[1].Operation != policyv1alpha1.PubDeleteOperation || resp.Pods[0].Operation != policyv1alpha1.PubEvictOperation {
+		t.Fatalf("unexpected operations of pods: %v", resp.Pods)
+	}
+	if resp.Pods[3].Reason != "pod not found" || resp.Pods[3].PodUnavailableBudget != "" {
+		t.Fatalf("unexpected result of not found pod: %v", resp.Pods[3])
+	}
+	if len(resp.PodUnavailableBudgets) != 1 {
+		t.Fatalf("expect 1 pub result, but got %v", resp.PodUnavailableBudgets)
+	}
+	pubResult := resp.PodUnavailableBudgets[0]
+	if pubResult.UnavailableAllowed != 2 || pubResult.RemainingUnavailableAllowed != 0 {
+		t.Fatalf("unexpected quota of pub result: %v", pubResult)
+	}
+	if !reflect.DeepEqual(pubResult.AllowedPods, []string{"pod-0", "pod-1"}) || !reflect.DeepEqual(pubResult.DeniedPods, []string{"pod-2"}) {
+		t.Fatalf("unexpected pods of pub result: %v", pubResult)
+	}
+	expectRecheckTime := disruptedTime.Add(podunavailablebudget.DeletionTimeout)
+	if pubResult.NextRecheckTime == nil || pubResult.NextRecheckTime.Unix() != expectRecheckTime.Unix() {
+		t.Fatalf("expect next recheck time %v, but got %v", expectRecheckTime, pubResult.NextRecheckTime)
+	}
+
+	// unsupported operation
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, newSimulationRequest(testToken, &SimulationRequest{Operation: "RESIZE", Pods: []SimulationPod{{Namespace: "default", Name: "pod-0"}}}))
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expect status code 400, but got %d", recorder.Code)
+	}
+}
+
+func TestSimulateAuth(t *testing.T) {
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newPod("pod-0")).Build()
+	pubcontrol.InitPubControl(fakeClient, &controllerfinder.ControllerFinder{Client: fakeClient}, record.NewFakeRecorder(10))
+	handler := &Handler{Client: fakeClient, ReviewClient: &fakeReviewClient{allowedNamespaces: []string{"default"}}}
+
+	cases := []struct {
+		name       string
+		token      string
+		namespaces []string
+		expectCode int
+	}{
+		{
+			name:       "missing token",
+			namespaces: []string{"default"},
+			expectCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid token",
+			token:      "invalid-token",
+			namespaces: []string{"default"},
+			expectCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "not allowed to evict pods in namespace",
+			token:      testToken,
+			namespaces: []string{"default", "kube-system"},
+			expectCode: http.StatusForbidden,
+		},
+		{
+			name:       "allowed to evict pods in namespace",
+			token:      testToken,
+			namespaces: []string{"default"},
+			expectCode: http.StatusOK,
+		},
+	}
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			req := &SimulationRequest{}
+			for _, namespace := range cs.namespaces {
+				req.Pods = append(req.Pods, SimulationPod{Namespace: namespace, Name: "pod-0"})
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, newSimulationRequest(cs.token, req))
+			if recorder.Code != cs.expectCode {
+				t.Fatalf("expect status code %d, but got %d: %s", cs.expectCode, recorder.Code, recorder.Body.String())
+			}
+		})
+	}
+}
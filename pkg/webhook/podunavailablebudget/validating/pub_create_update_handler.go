@@ -25,6 +25,7 @@ import (
 	"strings"
 
 	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/controller/podunavailablebudget"
 	"github.com/openkruise/kruise/pkg/features"
 	"github.com/openkruise/kruise/pkg/util"
 	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
@@ -33,6 +34,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	metavalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	appsvalidation "k8s.io/kubernetes/pkg/apis/apps/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -153,16 +157,91 @@ func validatePodUnavailableBudgetSpec(obj *policyv1alpha1.PodUnavailableBudget,
 		}
 	}
 
-	if spec.MaxUnavailable == nil && spec.MinAvailable == nil {
+	allErrs = append(allErrs, validatePubBudget(spec.MaxUnavailable, spec.MinAvailable, fldPath)...)
+
+	if spec.TopologyBudget != nil {
+		allErrs = append(allErrs, validatePubTopologyBudget(spec.TopologyBudget, fldPath.Child("topologyBudget"))...)
+	}
+	if spec.DisruptiveFields != nil {
+		allErrs = append(allErrs, validatePubDisruptiveKeys(spec.DisruptiveFields.LabelKeys, fldPath.Child("disruptiveFields", "labelKeys"))...)
+		allErrs = append(allErrs, validatePubDisruptiveKeys(spec.DisruptiveFields.AnnotationKeys, fldPath.Child("disruptiveFields", "annotationKeys"))...)
+	}
+	allErrs = append(allErrs, validatePubSchedules(spec.Schedules, fldPath.Child("schedules"))...)
+	return allErrs
+}
+
+func validatePubBudget(maxUnavailable, minAvailable *intstr.IntOrString, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if maxUnavailable == nil && minAvailable == nil {
 		allErrs = append(allErrs, field.Required(fldPath.Child("maxUnavailable, minAvailable"), "no maxUnavailable or minAvailable defined in PodUnavailableBudget"))
-	} else if spec.MaxUnavailable != nil && spec.MinAvailable != nil {
+	} else if maxUnavailable != nil && minAvailable != nil {
 		allErrs = append(allErrs, field.Required(fldPath.Child("maxUnavailable, minAvailable"), "maxUnavailable and minAvailable are mutually exclusive"))
-	} else if spec.MaxUnavailable != nil {
-		allErrs = append(allErrs, appsvalidation.ValidatePositiveIntOrPercent(*spec.MaxUnavailable, fldPath.Child("maxUnavailable"))...)
-		allErrs = append(allErrs, appsvalidation.IsNotMoreThan100Percent(*spec.MaxUnavailable, fldPath.Child("maxUnavailable"))...)
+	} else if maxUnavailable != nil {
+		allErrs = append(allErrs, appsvalidation.ValidatePositiveIntOrPercent(*maxUnavailable, fldPath.Child("maxUnavailable"))...)
+		allErrs = append(allErrs, appsvalidation.IsNotMoreThan100Percent(*maxUnavailable, fldPath.Child("maxUnavailable"))...)
+	} else {
+		allErrs = append(allErrs, appsvalidation.ValidatePositiveIntOrPercent(*minAvailable, fldPath.Child("minAvailable"))...)
+		allErrs = append(allErrs, appsvalidation.IsNotMoreThan100Percent(*minAvailable, fldPath.Child("minAvailable"))...)
+	}
+	return allErrs
+}
+
+func validatePubSchedules(schedules []policyv1alpha1.PubSchedule, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	names := sets.NewString()
+	for i := range schedules {
+		schedule := &schedules[i]
+		idxPath := fldPath.Index(i)
+		if schedule.Name == "" {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), "no name defined in schedule"))
+		} else if names.Has(schedule.Name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), schedule.Name))
+		}
+		names.Insert(schedule.Name)
+
+		if strings.Contains(schedule.Schedule, "TZ") {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("schedule"), schedule.Schedule, "TZ or CRON_TZ is not allowed in schedule, please use timeZone field"))
+		} else if schedule.TimeZone != nil && (*schedule.TimeZone == "" || strings.EqualFold(*schedule.TimeZone, "Local")) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("timeZone"), *schedule.TimeZone, "timeZone must be an explicit time zone as defined in https://www.iana.org/time-zones"))
+		} else if _, err := podunavailablebudget.ParsePubSchedule(schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("schedule"), schedule.Schedule, err.Error()))
+		}
+		if schedule.Duration.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("duration"), schedule.Duration.Duration.String(), "duration must be positive"))
+		}
+		allErrs = append(allErrs, validatePubBudget(schedule.MaxUnavailable, schedule.MinAvailable, idxPath)...)
+	}
+	return allErrs
+}
+
+// validatePubDisruptiveKeys checks the keys are qualified names, or prefixes ending with "*".
+func validatePubDisruptiveKeys(keys []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, key := range keys {
+		prefix := strings.TrimSuffix(key, "*")
+		if prefix == "" || strings.Contains(prefix, "*") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), key, "key must be non-empty, and '*' is only allowed at the end"))
+		} else if prefix == key {
+			for _, msg := range validation.IsQualifiedName(key) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i), key, msg))
+			}
+		}
+	}
+	return allErrs
+}
+
+func validatePubTopologyBudget(budget *policyv1alpha1.PubTopologyBudget, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if budget.TopologyKey == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("topologyKey"), "no topologyKey defined in topologyBudget"))
+	} else {
+		allErrs = append(allErrs, metavalidation.ValidateLabelName(budget.TopologyKey, fldPath.Child("topologyKey"))...)
+	}
+	if budget.MaxUnavailable == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("maxUnavailable"), "no maxUnavailable defined in topologyBudget"))
 	} else {
-		allErrs = append(allErrs, appsvalidation.ValidatePositiveIntOrPercent(*spec.MinAvailable, fldPath.Child("minAvailable"))...)
-		allErrs = append(allErrs, appsvalidation.IsNotMoreThan100Percent(*spec.MinAvailable, fldPath.Child("minAvailable"))...)
+		allErrs = append(allErrs, appsvalidation.ValidatePositiveIntOrPercent(*budget.MaxUnavailable, fldPath.Child("maxUnavailable"))...)
+		allErrs = append(allErrs, appsvalidation.IsNotMoreThan100Percent(*budget.MaxUnavailable, fldPath.Child("maxUnavailable"))...)
 	}
 	return allErrs
 }
@@ -19,6 +19,7 @@ package validating
 import (
 	"context"
 	"testing"
+	"time"
 
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 
@@ -27,6 +28,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	utilpointer "k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
@@ -138,6 +140,123 @@ func TestValidatingPub(t *testing.T) {
 			},
 			expectErrList: 1,
 		},
+		{
+			name: "valid pub, TopologyBudget",
+			pub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Spec.Selector = nil
+				pub.Spec.MinAvailable = nil
+				pub.Spec.TopologyBudget = &policyv1alpha1.PubTopologyBudget{
+					TopologyKey:    "topology.kubernetes.io/zone",
+					MaxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+				}
+				return pub
+			},
+			expectErrList: 0,
+		},
+		{
+			name: "invalid pub, TopologyBudget without topologyKey and maxUnavailable",
+			pub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Spec.Selector = nil
+				pub.Spec.MinAvailable = nil
+				pub.Spec.TopologyBudget = &policyv1alpha1.PubTopologyBudget{}
+				return pub
+			},
+			expectErrList: 2,
+		},
+		{
+			name: "invalid pub, TopologyBudget maxUnavailable is negative",
+			pub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Spec.Selector = nil
+				pub.Spec.MinAvailable = nil
+				pub.Spec.TopologyBudget = &policyv1alpha1.PubTopologyBudget{
+					TopologyKey:    "topology.kubernetes.io/zone",
+					MaxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: -1},
+				}
+				return pub
+			},
+			expectErrList: 1,
+		},
+		{
+			name: "valid pub, DisruptiveFields",
+			pub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Spec.Selector = nil
+				pub.Spec.MinAvailable = nil
+				pub.Spec.DisruptiveFields = &policyv1alpha1.PubDisruptiveFields{
+					LabelKeys:      []string{"gate.example.com/*"},
+					AnnotationKeys: []string{"proxy.example.com/offline"},
+				}
+				return pub
+			},
+			expectErrList: 0,
+		},
+		{
+			name: "invalid pub, DisruptiveFields keys",
+			pub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Spec.Selector = nil
+				pub.Spec.MinAvailable = nil
+				pub.Spec.DisruptiveFields = &policyv1alpha1.PubDisruptiveFields{
+					LabelKeys:      []string{"*"},
+					AnnotationKeys: []string{"proxy.*.com/offline", "invalid key"},
+				}
+				return pub
+			},
+			expectErrList: 3,
+		},
+		{
+			name: "valid pub, Schedules",
+			pub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Spec.Selector = nil
+				pub.Spec.MinAvailable = nil
+				pub.Spec.Schedules = []policyv1alpha1.PubSchedule{
+					{
+						Name:           "business-hours",
+						Schedule:       "0 9 * * 1-5",
+						Duration:       metav1.Duration{Duration: 8 * time.Hour},
+						TimeZone:       utilpointer.String("UTC"),
+						MaxUnavailable: &intstr.IntOrString{Type: intstr.String, StrVal: "10%"},
+					},
+				}
+				return pub
+			},
+			expectErrList: 0,
+		},
+		{
+			name: "invalid pub, Schedules",
+			pub: func() *policyv1alpha1.PodUnavailableBudget {
+				pub := pubDemo.DeepCopy()
+				pub.Spec.Selector = nil
+				pub.Spec.MinAvailable = nil
+				pub.Spec.Schedules = []policyv1alpha1.PubSchedule{
+					{
+						Name:           "business-hours",
+						Schedule:       "0 9 * * 1-5",
+						Duration:       metav1.Duration{Duration: 8 * time.Hour},
+						MaxUnavailable: &intstr.IntOrString{Type: intstr.String, StrVal: "10%"},
+					},
+					{
+						Name:           "business-hours",
+						Schedule:       "CRON_TZ=UTC 0 9 * * 6",
+						MaxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+						MinAvailable:   &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+					},
+					{
+						Name:         "night",
+						Schedule:     "invalid",
+						Duration:     metav1.Duration{Duration: time.Hour},
+						TimeZone:     utilpointer.String("UTC"),
+						MinAvailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+					},
+				}
+				return pub
+			},
+			expectErrList: 5,
+		},
 		{
 			name: "invalid pub feature-gate annotation",
 			pub: func() *policyv1alpha1.PodUnavailableBudget {
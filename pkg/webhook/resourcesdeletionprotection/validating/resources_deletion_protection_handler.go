@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/openkruise/kruise/pkg/util"
+	"github.com/openkruise/kruise/pkg/util/configuration"
+	"github.com/openkruise/kruise/pkg/webhook/util/deletionprotection"
+)
+
+// ResourcesDeletionProtectionHandler validates the deletion of resources configured in kruise-configuration,
+// and the changes of their deletion approvals.
+type ResourcesDeletionProtectionHandler struct {
+	Client client.Client
+}
+
+var _ admission.Handler = &ResourcesDeletionProtectionHandler{}
+
+// Handle handles admission requests.
+func (h *ResourcesDeletionProtectionHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.AdmissionRequest.SubResource != "" {
+		return admission.ValidationResponse(true, "")
+	}
+	switch req.AdmissionRequest.Operation {
+	case admissionv1.Create, admissionv1.Update:
+		return h.validateApprovalChange(req)
+	case admissionv1.Delete:
+	default:
+		return admission.ValidationResponse(true, "")
+	}
+	if len(req.OldObject.Raw) == 0 {
+		klog.Warningf("Skip to validate %s %s/%s deletion for no old object, maybe because of Kubernetes version < 1.16", req.Kind.Kind, req.Namespace, req.Name)
+		return admission.ValidationResponse(true, "")
+	}
+
+	resources, err := configuration.GetDeletionProtectionResources(h.Client)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	resource := resources.Get(schema.GroupVersionKind{Group: req.Kind.Group, Version: req.Kind.Version, Kind: req.Kind.Kind})
+	if resource == nil {
+		return admission.ValidationResponse(true, "")
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(req.OldObject.Raw); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := deletionprotection.ValidateResourceDeletion(h.Client, obj, resource, req.UserInfo.Username); err != nil {
+		deletionprotection.ResourceDeletionProtectionMetrics.WithLabelValues(fmt.Sprintf("%s_%s_%s", req.Kind.Kind, obj.GetNamespace(), obj.GetName()), req.UserInfo.Username, deletionprotection.ResultDenied).Add(1)
+		util.LoggerProtectionInfo(util.ProtectionEventDeletionProtection, req.Kind.Kind, obj.GetNamespace(), obj.GetName(), req.UserInfo.Username)
+		return admission.Errored(http.StatusForbidden, err)
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+func (h *ResourcesDeletionProtectionHandler) validateApprovalChange(req admission.Request) admission.Response {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(req.Object.Raw); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	var oldObj metav1.Object
+	if req.AdmissionRequest.Operation == admissionv1.Update {
+		old := &unstructured.Unstructured{}
+		if err := old.UnmarshalJSON(req.OldObject.Raw); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		oldObj = old
+	}
+	if err := deletionprotection.ValidateDeletionApprovalChange(h.Client, oldObj, obj, req.UserInfo, deletionprotection.ResourceDeletionProtectionMetrics); err != nil {
+		return admission.Errored(http.StatusForbidden, err)
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+var _ inject.Client = &ResourcesDeletionProtectionHandler{}
+
+func (h *ResourcesDeletionProtectionHandler) InjectClient(c client.Client) error {
+	h.Client = c
+	return nil
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	webhookconfiguration "github.com/openkruise/kruise/pkg/webhook/util/configuration"
+)
+
+// The webhook rules of this handler are generated by webhook controller from the resources
+// configured in kruise-configuration, so there is no kubebuilder webhook marker for it.
+
+var (
+	// HandlerMap contains admission webhook handlers
+	HandlerMap = map[string]admission.Handler{
+		webhookconfiguration.ResourcesDeletionProtectionPath: &ResourcesDeletionProtectionHandler{},
+	}
+)
@@ -30,6 +30,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 
+	"github.com/openkruise/kruise/pkg/features"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+	pubsimulate "github.com/openkruise/kruise/pkg/webhook/podunavailablebudget/simulate"
 	webhookutil "github.com/openkruise/kruise/pkg/webhook/util"
 	webhookcontroller "github.com/openkruise/kruise/pkg/webhook/util/controller"
 	"github.com/openkruise/kruise/pkg/webhook/util/health"
@@ -100,6 +103,12 @@ func SetupWithManager(mgr manager.Manager) error {
 	// register health handler
 	server.Register("/healthz", &health.Handler{})
 
+	// register podUnavailableBudget simulation handler
+	if utilfeature.DefaultFeatureGate.Enabled(features.PodUnavailableBudgetDeleteGate) ||
+		utilfeature.DefaultFeatureGate.Enabled(features.PodUnavailableBudgetUpdateGate) {
+		server.Register(pubsimulate.Path, &pubsimulate.Handler{Client: mgr.GetClient(), ReviewClient: mgr.GetClient()})
+	}
+
 	return nil
 }
 
@@ -27,16 +27,14 @@ import (
 	"github.com/openkruise/kruise/pkg/webhook/util/deletionprotection"
 	admissionv1 "k8s.io/api/admission/v1"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 // StatefulSetCreateUpdateHandler handles StatefulSet
 type StatefulSetCreateUpdateHandler struct {
-	// To use the client, you need to do the following:
-	// - uncomment it
-	// - import sigs.k8s.io/controller-runtime/pkg/client
-	// - uncomment the InjectClient method at the bottom of this file.
-	// Client  client.Client
+	Client client.Client
 
 	// Decoder decodes objects
 	Decoder *admission.Decoder
@@ -46,6 +44,17 @@ var _ admission.Handler = &StatefulSetCreateUpdateHandler{}
 
 // Handle handles admission requests.
 func (h *StatefulSetCreateUpdateHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.AdmissionRequest.SubResource == "scale" {
+		if req.AdmissionRequest.Operation == admissionv1.Update {
+			if err := deletionprotection.ValidateWorkloadScale(h.Client, &appsv1beta1.StatefulSet{}, req.OldObject.Raw, req.Object.Raw); err != nil {
+				deletionprotection.WorkloadUpdateProtectionMetrics.WithLabelValues(fmt.Sprintf("StatefulSet_%s_%s", req.Namespace, req.Name), req.UserInfo.Username).Add(1)
+				util.LoggerProtectionInfo(util.ProtectionEventDeletionProtection, "StatefulSet", req.Namespace, req.Name, req.UserInfo.Username)
+				return admission.Errored(http.StatusForbidden, err)
+			}
+		}
+		return admission.ValidationResponse(true, "")
+	}
+
 	obj := &appsv1beta1.StatefulSet{}
 	oldObj := &appsv1beta1.StatefulSet{}
 
@@ -57,6 +66,9 @@ func (h *StatefulSetCreateUpdateHandler) Handle(ctx context.Context, req admissi
 		if allErrs := validateStatefulSet(obj); len(allErrs) > 0 {
 			return admission.Errored(http.StatusUnprocessableEntity, allErrs.ToAggregate())
 		}
+		if err := deletionprotection.ValidateDeletionApprovalChange(h.Client, nil, obj, req.UserInfo, deletionprotection.WorkloadDeletionProtectionMetrics); err != nil {
+			return admission.Errored(http.StatusForbidden, err)
+		}
 	case admissionv1.Update:
 		if err := h.decodeObject(req, obj); err != nil {
 			return admission.Errored(http.StatusBadRequest, err)
@@ -70,6 +82,14 @@ func (h *StatefulSetCreateUpdateHandler) Handle(ctx context.Context, req admissi
 		if allErrs := append(validationErrorList, updateErrorList...); len(allErrs) > 0 {
 			return admission.Errored(http.StatusUnprocessableEntity, allErrs.ToAggregate())
 		}
+		if err := deletionprotection.ValidateDeletionApprovalChange(h.Client, oldObj, obj, req.UserInfo, deletionprotection.WorkloadDeletionProtectionMetrics); err != nil {
+			return admission.Errored(http.StatusForbidden, err)
+		}
+		if err := deletionprotection.ValidateWorkloadUpdate(oldObj, oldObj.Spec.Replicas, obj.Spec.Replicas, oldObj.Spec.Selector, obj.Spec.Selector); err != nil {
+			deletionprotection.WorkloadUpdateProtectionMetrics.WithLabelValues(fmt.Sprintf("%s_%s_%s", req.Kind.Kind, oldObj.GetNamespace(), oldObj.GetName()), req.UserInfo.Username).Add(1)
+			util.LoggerProtectionInfo(util.ProtectionEventDeletionProtection, req.Kind.Kind, oldObj.GetNamespace(), oldObj.GetName(), req.UserInfo.Username)
+			return admission.Errored(http.StatusForbidden, err)
+		}
 
 		if obj.Spec.UpdateStrategy.RollingUpdate != nil &&
 			obj.Spec.UpdateStrategy.RollingUpdate.PodUpdatePolicy == appsv1beta1.InPlaceOnlyPodUpdateStrategyType {
@@ -86,8 +106,8 @@ func (h *StatefulSetCreateUpdateHandler) Handle(ctx context.Context, req admissi
 		if err := h.decodeOldObject(req, oldObj); err != nil {
 			return admission.Errored(http.StatusBadRequest, err)
 		}
-		if err := deletionprotection.ValidateWorkloadDeletion(oldObj, oldObj.Spec.Replicas); err != nil {
-			deletionprotection.WorkloadDeletionProtectionMetrics.WithLabelValues(fmt.Sprintf("%s_%s_%s", req.Kind.Kind, oldObj.GetNamespace(), oldObj.GetName()), req.UserInfo.Username).Add(1)
+		if err := deletionprotection.ValidateWorkloadDeletion(oldObj, oldObj.Spec.Replicas, req.UserInfo.Username); err != nil {
+			deletionprotection.WorkloadDeletionProtectionMetrics.WithLabelValues(fmt.Sprintf("%s_%s_%s", req.Kind.Kind, oldObj.GetNamespace(), oldObj.GetName()), req.UserInfo.Username, deletionprotection.ResultDenied).Add(1)
 			util.LoggerProtectionInfo(util.ProtectionEventDeletionProtection, req.Kind.Kind, oldObj.GetNamespace(), oldObj.GetName(), req.UserInfo.Username)
 			return admission.Errored(http.StatusForbidden, err)
 		}
@@ -132,13 +152,13 @@ func (h *StatefulSetCreateUpdateHandler) decodeOldObject(req admission.Request,
 	return nil
 }
 
-//var _ inject.Client = &StatefulSetCreateUpdateHandler{}
-//
-//// InjectClient injects the client into the StatefulSetCreateUpdateHandler
-//func (h *StatefulSetCreateUpdateHandler) InjectClient(c client.Client) error {
-//	h.Client = c
-//	return nil
-//}
+var _ inject.Client = &StatefulSetCreateUpdateHandler{}
+
+// InjectClient injects the client into the StatefulSetCreateUpdateHandler
+func (h *StatefulSetCreateUpdateHandler) InjectClient(c client.Client) error {
+	h.Client = c
+	return nil
+}
 
 var _ admission.DecoderInjector = &StatefulSetCreateUpdateHandler{}
 
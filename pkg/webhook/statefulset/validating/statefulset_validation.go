@@ -150,6 +150,31 @@ func validateRollingUpdateStatefulSetStrategyType(spec *appsv1beta1.StatefulSetS
 		// validate the `spec.UpdateStrategy.RollingUpdate.UnorderedUpdate` related fields
 		allErrs = append(allErrs, validateRollingUpdateStatefulSetStrategyTypeUnorderedUpdate(spec, fldPath)...)
 
+		// validate the `spec.UpdateStrategy.RollingUpdate.SnapshotBeforeUpdate` related fields
+		allErrs = append(allErrs, validateSnapshotBeforeUpdate(spec, fldPath.Child("updateStrategy").Child("rollingUpdate").Child("snapshotBeforeUpdate"))...)
+	}
+	return allErrs
+}
+
+func validateSnapshotBeforeUpdate(spec *appsv1beta1.StatefulSetSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	snapshot := spec.UpdateStrategy.RollingUpdate.SnapshotBeforeUpdate
+	if snapshot == nil {
+		return allErrs
+	}
+	if len(spec.VolumeClaimTemplates) == 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "snapshotBeforeUpdate requires volumeClaimTemplates"))
+	}
+	if snapshot.VolumeSnapshotClassName == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("volumeSnapshotClassName"), ""))
+	} else {
+		for _, msg := range apivalidation.ValidateClassName(snapshot.VolumeSnapshotClassName, false) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("volumeSnapshotClassName"), snapshot.VolumeSnapshotClassName, msg))
+		}
+	}
+	if snapshot.SnapshotsToKeep != nil && *snapshot.SnapshotsToKeep < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("snapshotsToKeep"), *snapshot.SnapshotsToKeep, "must be greater than or equal to 1"))
 	}
 	return allErrs
 }
@@ -410,6 +410,42 @@ func TestValidateStatefulSet(t *testing.T) {
 				},
 			},
 		},
+		"snapshot before update without volumeClaimTemplates": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc-123", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1beta1.StatefulSetSpec{
+				PodManagementPolicy: apps.OrderedReadyPodManagement,
+				Selector:            &metav1.LabelSelector{MatchLabels: validLabels},
+				Template:            validPodTemplate.Template,
+				Replicas:            &val3,
+				UpdateStrategy: appsv1beta1.StatefulSetUpdateStrategy{Type: apps.RollingUpdateStatefulSetStrategyType,
+					RollingUpdate: &appsv1beta1.RollingUpdateStatefulSetStrategy{
+						Partition:            &val1,
+						PodUpdatePolicy:      appsv1beta1.RecreatePodUpdateStrategyType,
+						MaxUnavailable:       &maxUnavailable1,
+						MinReadySeconds:      utilpointer.Int32Ptr(1),
+						SnapshotBeforeUpdate: &appsv1beta1.SnapshotBeforeUpdateStrategy{VolumeSnapshotClassName: "csi-snapclass"},
+					},
+				},
+			},
+		},
+		"snapshot before update without volumeSnapshotClassName": {
+			ObjectMeta: metav1.ObjectMeta{Name: "abc-123", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1beta1.StatefulSetSpec{
+				PodManagementPolicy: apps.OrderedReadyPodManagement,
+				Selector:            &metav1.LabelSelector{MatchLabels: validLabels},
+				Template:            validPodTemplate.Template,
+				Replicas:            &val3,
+				UpdateStrategy: appsv1beta1.StatefulSetUpdateStrategy{Type: apps.RollingUpdateStatefulSetStrategyType,
+					RollingUpdate: &appsv1beta1.RollingUpdateStatefulSetStrategy{
+						Partition:            &val1,
+						PodUpdatePolicy:      appsv1beta1.RecreatePodUpdateStrategyType,
+						MaxUnavailable:       &maxUnavailable1,
+						MinReadySeconds:      utilpointer.Int32Ptr(1),
+						SnapshotBeforeUpdate: &appsv1beta1.SnapshotBeforeUpdateStrategy{SnapshotsToKeep: utilpointer.Int32Ptr(0)},
+					},
+				},
+			},
+		},
 		"empty pod management policy": {
 			ObjectMeta: metav1.ObjectMeta{Name: "abc-123", Namespace: metav1.NamespaceDefault},
 			Spec: appsv1beta1.StatefulSetSpec{
@@ -469,6 +505,9 @@ func TestValidateStatefulSet(t *testing.T) {
 					field != "spec.updateStrategy.rollingUpdate.maxUnavailable" &&
 					field != "spec.updateStrategy.rollingUpdate.minReadySeconds" &&
 					field != "spec.updateStrategy.rollingUpdate.podUpdatePolicy" &&
+					field != "spec.updateStrategy.rollingUpdate.snapshotBeforeUpdate" &&
+					field != "spec.updateStrategy.rollingUpdate.snapshotBeforeUpdate.volumeSnapshotClassName" &&
+					field != "spec.updateStrategy.rollingUpdate.snapshotBeforeUpdate.snapshotsToKeep" &&
 					field != "spec.template.spec.readinessGates" &&
 					field != "spec.podManagementPolicy" &&
 					field != "spec.template.spec.activeDeadlineSeconds" {
@@ -20,7 +20,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
-// +kubebuilder:webhook:path=/validate-apps-kruise-io-statefulset,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1;v1beta1,groups=apps.kruise.io,resources=statefulsets,verbs=create;update;delete,versions=v1alpha1;v1beta1,name=vstatefulset.kb.io
+// +kubebuilder:webhook:path=/validate-apps-kruise-io-statefulset,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1;v1beta1,groups=apps.kruise.io,resources=statefulsets;statefulsets/scale,verbs=create;update;delete,versions=v1alpha1;v1beta1,name=vstatefulset.kb.io
 
 var (
 	// HandlerMap contains admission webhook handlers
@@ -23,19 +23,20 @@ import (
 
 	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
 	"github.com/openkruise/kruise/pkg/util"
+	"github.com/openkruise/kruise/pkg/util/configuration"
 	"github.com/openkruise/kruise/pkg/webhook/util/deletionprotection"
 	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 // UnitedDeploymentCreateUpdateHandler handles UnitedDeployment
 type UnitedDeploymentCreateUpdateHandler struct {
-	// To use the client, you need to do the following:
-	// - uncomment it
-	// - import sigs.k8s.io/controller-runtime/pkg/client
-	// - uncomment the InjectClient method at the bottom of this file.
-	// Client  client.Client
+	Client client.Client
 
 	// Decoder decodes objects
 	Decoder *admission.Decoder
@@ -45,6 +46,17 @@ var _ admission.Handler = &UnitedDeploymentCreateUpdateHandler{}
 
 // Handle handles admission requests.
 func (h *UnitedDeploymentCreateUpdateHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.AdmissionRequest.SubResource == "scale" {
+		if req.AdmissionRequest.Operation == admissionv1.Update {
+			if err := deletionprotection.ValidateWorkloadScale(h.Client, &appsv1alpha1.UnitedDeployment{}, req.OldObject.Raw, req.Object.Raw); err != nil {
+				deletionprotection.WorkloadUpdateProtectionMetrics.WithLabelValues(fmt.Sprintf("UnitedDeployment_%s_%s", req.Namespace, req.Name), req.UserInfo.Username).Add(1)
+				util.LoggerProtectionInfo(util.ProtectionEventDeletionProtection, "UnitedDeployment", req.Namespace, req.Name, req.UserInfo.Username)
+				return admission.Errored(http.StatusForbidden, err)
+			}
+		}
+		return admission.ValidationResponse(true, "")
+	}
+
 	obj := &appsv1alpha1.UnitedDeployment{}
 	oldObj := &appsv1alpha1.UnitedDeployment{}
 
@@ -53,9 +65,12 @@ func (h *UnitedDeploymentCreateUpdateHandler) Handle(ctx context.Context, req ad
 		if err := h.Decoder.Decode(req, obj); err != nil {
 			return admission.Errored(http.StatusBadRequest, err)
 		}
-		if allErrs := validateUnitedDeployment(obj); len(allErrs) > 0 {
+		if allErrs := append(validateUnitedDeployment(obj), h.validateCustomWorkloadWhiteList(obj, nil)...); len(allErrs) > 0 {
 			return admission.Errored(http.StatusUnprocessableEntity, allErrs.ToAggregate())
 		}
+		if err := deletionprotection.ValidateDeletionApprovalChange(h.Client, nil, obj, req.UserInfo, deletionprotection.WorkloadDeletionProtectionMetrics); err != nil {
+			return admission.Errored(http.StatusForbidden, err)
+		}
 	case admissionv1.Update:
 		if err := h.Decoder.Decode(req, obj); err != nil {
 			return admission.Errored(http.StatusBadRequest, err)
@@ -66,9 +81,18 @@ func (h *UnitedDeploymentCreateUpdateHandler) Handle(ctx context.Context, req ad
 
 		validationErrorList := validateUnitedDeployment(obj)
 		updateErrorList := ValidateUnitedDeploymentUpdate(obj, oldObj)
+		updateErrorList = append(updateErrorList, h.validateCustomWorkloadWhiteList(obj, oldObj)...)
 		if allErrs := append(validationErrorList, updateErrorList...); len(allErrs) > 0 {
 			return admission.Errored(http.StatusUnprocessableEntity, allErrs.ToAggregate())
 		}
+		if err := deletionprotection.ValidateDeletionApprovalChange(h.Client, oldObj, obj, req.UserInfo, deletionprotection.WorkloadDeletionProtectionMetrics); err != nil {
+			return admission.Errored(http.StatusForbidden, err)
+		}
+		if err := deletionprotection.ValidateWorkloadUpdate(oldObj, oldObj.Spec.Replicas, obj.Spec.Replicas, oldObj.Spec.Selector, obj.Spec.Selector); err != nil {
+			deletionprotection.WorkloadUpdateProtectionMetrics.WithLabelValues(fmt.Sprintf("%s_%s_%s", req.Kind.Kind, oldObj.GetNamespace(), oldObj.GetName()), req.UserInfo.Username).Add(1)
+			util.LoggerProtectionInfo(util.ProtectionEventDeletionProtection, req.Kind.Kind, oldObj.GetNamespace(), oldObj.GetName(), req.UserInfo.Username)
+			return admission.Errored(http.StatusForbidden, err)
+		}
 	case admissionv1.Delete:
 		if len(req.OldObject.Raw) == 0 {
 			klog.Warningf("Skip to validate UnitedDeployment %s/%s deletion for no old object, maybe because of Kubernetes version < 1.16", req.Namespace, req.Name)
@@ -77,8 +101,8 @@ func (h *UnitedDeploymentCreateUpdateHandler) Handle(ctx context.Context, req ad
 		if err := h.Decoder.DecodeRaw(req.AdmissionRequest.OldObject, oldObj); err != nil {
 			return admission.Errored(http.StatusBadRequest, err)
 		}
-		if err := deletionprotection.ValidateWorkloadDeletion(oldObj, oldObj.Spec.Replicas); err != nil {
-			deletionprotection.WorkloadDeletionProtectionMetrics.WithLabelValues(fmt.Sprintf("%s_%s_%s", req.Kind.Kind, oldObj.GetNamespace(), oldObj.GetName()), req.UserInfo.Username).Add(1)
+		if err := deletionprotection.ValidateWorkloadDeletion(oldObj, oldObj.Spec.Replicas, req.UserInfo.Username); err != nil {
+			deletionprotection.WorkloadDeletionProtectionMetrics.WithLabelValues(fmt.Sprintf("%s_%s_%s", req.Kind.Kind, oldObj.GetNamespace(), oldObj.GetName()), req.UserInfo.Username, deletionprotection.ResultDenied).Add(1)
 			util.LoggerProtectionInfo(util.ProtectionEventDeletionProtection, req.Kind.Kind, oldObj.GetNamespace(), oldObj.GetName(), req.UserInfo.Username)
 			return admission.Errored(http.StatusForbidden, err)
 		}
@@ -87,6 +111,53 @@ func (h *UnitedDeploymentCreateUpdateHandler) Handle(ctx context.Context, req ad
 	return admission.ValidationResponse(true, "")
 }
 
+// validateCustomWorkloadWhiteList checks that the custom workload of the template is in the white list of UnitedDeployment.
+// It is only checked when the custom workload is changed, so that the existing UnitedDeployments can still be updated
+// after their workloads are removed from the white list. And Auto update strategy is only allowed for the custom
+// workloads with partitionPath, because the subsets are updated one by one through their partitions.
+func (h *UnitedDeploymentCreateUpdateHandler) validateCustomWorkloadWhiteList(obj, oldObj *appsv1alpha1.UnitedDeployment) field.ErrorList {
+	template := obj.Spec.Template.CustomWorkloadTemplate
+	if template == nil {
+		return nil
+	}
+	workloadChanged := oldObj == nil || oldObj.Spec.Template.CustomWorkloadTemplate == nil ||
+		oldObj.Spec.Template.CustomWorkloadTemplate.APIVersion != template.APIVersion ||
+		oldObj.Spec.Template.CustomWorkloadTemplate.Kind != template.Kind
+	autoUpdate := obj.Spec.UpdateStrategy.Type == appsv1alpha1.AutoUpdateStrategyType
+	if !workloadChanged && !autoUpdate {
+		return nil
+	}
+
+	fldPath := field.NewPath("spec", "template", "customWorkloadTemplate")
+	whiteList, err := configuration.GetUDCustomWorkloadWhiteList(h.Client)
+	if err != nil {
+		return field.ErrorList{field.InternalError(fldPath, err)}
+	}
+	gvk := schema.FromAPIVersionAndKind(template.APIVersion, template.Kind)
+	workload := whiteList.Get(gvk)
+	if workload == nil {
+		if !workloadChanged {
+			return nil
+		}
+		return field.ErrorList{field.Invalid(fldPath.Child("kind"), template.Kind,
+			fmt.Sprintf("custom workload %s is not in %s of kruise-configuration, and kruise-manager has to be restarted after it is added",
+				gvk.String(), configuration.UDCustomWorkloadWhiteListKey))}
+	}
+	if autoUpdate && workload.PartitionPath == "" {
+		return field.ErrorList{field.Forbidden(field.NewPath("spec", "updateStrategy", "type"),
+			fmt.Sprintf("%s update strategy is not supported for custom workload %s without partitionPath", appsv1alpha1.AutoUpdateStrategyType, gvk.String()))}
+	}
+	return nil
+}
+
+var _ inject.Client = &UnitedDeploymentCreateUpdateHandler{}
+
+// InjectClient injects the client into the UnitedDeploymentCreateUpdateHandler
+func (h *UnitedDeploymentCreateUpdateHandler) InjectClient(c client.Client) error {
+	h.Client = c
+	return nil
+}
+
 var _ admission.DecoderInjector = &UnitedDeploymentCreateUpdateHandler{}
 
 // InjectDecoder injects the decoder into the UnitedDeploymentCreateUpdateHandler
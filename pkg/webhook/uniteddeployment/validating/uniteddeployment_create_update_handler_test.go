@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util"
+	"github.com/openkruise/kruise/pkg/util/configuration"
+)
+
+func TestValidateCustomWorkloadWhiteList(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	whiteList := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: util.GetKruiseNamespace(), Name: configuration.KruiseConfigurationName},
+		Data: map[string]string{
+			configuration.UDCustomWorkloadWhiteListKey: `{"workloads":[{"group":"argoproj.io","version":"v1alpha1","kind":"Rollout"},` +
+				`{"group":"apps.example.io","version":"v1","kind":"PartitionedApp","partitionPath":"spec.partition"}]}`,
+		},
+	}
+	handler := &UnitedDeploymentCreateUpdateHandler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(whiteList).Build()}
+	newUnitedDeployment := func(apiVersion, kind string) *appsv1alpha1.UnitedDeployment {
+		ud := &appsv1alpha1.UnitedDeployment{}
+		ud.Spec.Template.CustomWorkloadTemplate = &appsv1alpha1.CustomWorkloadTemplateSpec{APIVersion: apiVersion, Kind: kind}
+		return ud
+	}
+	newAutoUpdate := func(ud *appsv1alpha1.UnitedDeployment) *appsv1alpha1.UnitedDeployment {
+		ud.Spec.UpdateStrategy.Type = appsv1alpha1.AutoUpdateStrategyType
+		return ud
+	}
+
+	cases := []struct {
+		name        string
+		obj         *appsv1alpha1.UnitedDeployment
+		oldObj      *appsv1alpha1.UnitedDeployment
+		expectError bool
+	}{
+		{
+			name: "custom workload in white list",
+			obj:  newUnitedDeployment("argoproj.io/v1alpha1", "Rollout"),
+		},
+		{
+			name:        "custom workload not in white list",
+			obj:         newUnitedDeployment("apps.example.io/v1", "App"),
+			expectError: true,
+		},
+		{
+			name:        "change to custom workload not in white list",
+			obj:         newUnitedDeployment("apps.example.io/v1", "App"),
+			oldObj:      newUnitedDeployment("argoproj.io/v1alpha1", "Rollout"),
+			expectError: true,
+		},
+		{
+			name:   "unchanged custom workload removed from white list",
+			obj:    newUnitedDeployment("apps.example.io/v1", "App"),
+			oldObj: newUnitedDeployment("apps.example.io/v1", "App"),
+		},
+		{
+			name:        "auto update for custom workload without partitionPath",
+			obj:         newAutoUpdate(newUnitedDeployment("argoproj.io/v1alpha1", "Rollout")),
+			oldObj:      newUnitedDeployment("argoproj.io/v1alpha1", "Rollout"),
+			expectError: true,
+		},
+		{
+			name: "auto update for custom workload with partitionPath",
+			obj:  newAutoUpdate(newUnitedDeployment("apps.example.io/v1", "PartitionedApp")),
+		},
+	}
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			errs := handler.validateCustomWorkloadWhiteList(cs.obj, cs.oldObj)
+			if (len(errs) > 0) != cs.expectError {
+				t.Fatalf("expect error %v, but got %v", cs.expectError, errs)
+			}
+		})
+	}
+}
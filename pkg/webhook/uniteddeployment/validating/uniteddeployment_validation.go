@@ -17,15 +17,18 @@ limitations under the License.
 package validating
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	unversionedvalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	appsvalidation "k8s.io/kubernetes/pkg/apis/apps/validation"
@@ -64,6 +67,9 @@ func validateUnitedDeploymentSpec(spec *appsv1alpha1.UnitedDeploymentSpec, fldPa
 	}
 
 	allErrs = append(allErrs, validateSubsetReplicas(spec.Replicas, spec.Topology.Subsets, fldPath.Child("topology", "subsets"))...)
+	allErrs = append(allErrs, validateScheduleStrategy(spec, fldPath.Child("topology", "scheduleStrategy"))...)
+	allErrs = append(allErrs, validateSubsetStates(spec.Topology.Subsets, fldPath.Child("topology", "subsets"))...)
+	allErrs = append(allErrs, validateSubsetOverrides(spec.Topology.Subsets, getTemplatePodSpec(&spec.Template), fldPath.Child("topology", "subsets"))...)
 
 	subSetNames := sets.String{}
 	for i, subset := range spec.Topology.Subsets {
@@ -114,6 +120,26 @@ func validateUnitedDeploymentSpec(spec *appsv1alpha1.UnitedDeploymentSpec, fldPa
 		}
 	}
 
+	// Deployment has no partition, so its subsets can not be updated one by one.
+	if spec.UpdateStrategy.Type == appsv1alpha1.AutoUpdateStrategyType && spec.Template.DeploymentTemplate != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("updateStrategy", "type"), fmt.Sprintf("%s update strategy is not supported for deploymentTemplate", appsv1alpha1.AutoUpdateStrategyType)))
+	}
+
+	if autoUpdate := spec.UpdateStrategy.AutoUpdate; autoUpdate != nil {
+		autoUpdatePath := fldPath.Child("updateStrategy", "autoUpdate")
+		if spec.UpdateStrategy.Type != appsv1alpha1.AutoUpdateStrategyType {
+			allErrs = append(allErrs, field.Forbidden(autoUpdatePath, fmt.Sprintf("autoUpdate is only allowed for %s update strategy", appsv1alpha1.AutoUpdateStrategyType)))
+		}
+		if autoUpdate.MaxParallelSubsets != nil && *autoUpdate.MaxParallelSubsets < 1 {
+			allErrs = append(allErrs, field.Invalid(autoUpdatePath.Child("maxParallelSubsets"), *autoUpdate.MaxParallelSubsets, "should be greater than 0"))
+		}
+		for subset := range autoUpdate.Priorities {
+			if !subSetNames.Has(subset) {
+				allErrs = append(allErrs, field.Invalid(autoUpdatePath.Child("priorities"), autoUpdate.Priorities, fmt.Sprintf("subset %s does not exist", subset)))
+			}
+		}
+	}
+
 	return allErrs
 }
 
@@ -208,6 +234,141 @@ func validateSubsetReplicas(expectedReplicas *int32, subsets []appsv1alpha1.Subs
 	return errList
 }
 
+func validateSubsetStates(subsets []appsv1alpha1.Subset, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	var hasActive, hasDraining bool
+	for i, subset := range subsets {
+		switch subset.State {
+		case "", appsv1alpha1.SubsetStateActive:
+			hasActive = true
+		case appsv1alpha1.SubsetStateDraining:
+			hasDraining = true
+		case appsv1alpha1.SubsetStateDisabled:
+		default:
+			allErrs = append(allErrs, field.NotSupported(fldPath.Index(i).Child("state"), subset.State,
+				[]string{string(appsv1alpha1.SubsetStateActive), string(appsv1alpha1.SubsetStateDraining), string(appsv1alpha1.SubsetStateDisabled)}))
+		}
+	}
+	if hasDraining && !hasActive {
+		allErrs = append(allErrs, field.Invalid(fldPath, len(subsets), "at least one Active subset is required to drain subsets"))
+	}
+	return allErrs
+}
+
+// getTemplatePodSpec returns the pod spec of the subset template, or nil if it is unknown, e.g., for custom workloads.
+func getTemplatePodSpec(template *appsv1alpha1.SubsetTemplate) *v1.PodSpec {
+	switch {
+	case template.StatefulSetTemplate != nil:
+		return &template.StatefulSetTemplate.Spec.Template.Spec
+	case template.AdvancedStatefulSetTemplate != nil:
+		return &template.AdvancedStatefulSetTemplate.Spec.Template.Spec
+	case template.CloneSetTemplate != nil:
+		return &template.CloneSetTemplate.Spec.Template.Spec
+	case template.DeploymentTemplate != nil:
+		return &template.DeploymentTemplate.Spec.Template.Spec
+	}
+	return nil
+}
+
+func validateSubsetOverrides(subsets []appsv1alpha1.Subset, podSpec *v1.PodSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	var containerNames sets.String
+	if podSpec != nil {
+		containerNames = sets.NewString()
+		for _, c := range podSpec.Containers {
+			containerNames.Insert(c.Name)
+		}
+		for _, c := range podSpec.InitContainers {
+			containerNames.Insert(c.Name)
+		}
+	}
+
+	for i, subset := range subsets {
+		overrides := subset.Overrides
+		if overrides == nil {
+			continue
+		}
+		overridesPath := fldPath.Index(i).Child("overrides")
+		if overrides.ServiceAccountName != "" {
+			for _, msg := range apivalidation.ValidateServiceAccountName(overrides.ServiceAccountName, false) {
+				allErrs = append(allErrs, field.Invalid(overridesPath.Child("serviceAccountName"), overrides.ServiceAccountName, msg))
+			}
+		}
+		if overrides.PriorityClassName != "" {
+			for _, msg := range apivalidation.ValidatePriorityClassName(overrides.PriorityClassName, false) {
+				allErrs = append(allErrs, field.Invalid(overridesPath.Child("priorityClassName"), overrides.PriorityClassName, msg))
+			}
+		}
+		for j, secret := range overrides.ImagePullSecrets {
+			if secret.Name == "" {
+				allErrs = append(allErrs, field.Required(overridesPath.Child("imagePullSecrets").Index(j).Child("name"), ""))
+			}
+		}
+
+		overriddenContainers := sets.NewString()
+		for j, containerOverride := range overrides.Containers {
+			containerPath := overridesPath.Child("containers").Index(j)
+			if containerOverride.Name == "" {
+				allErrs = append(allErrs, field.Required(containerPath.Child("name"), ""))
+			} else if overriddenContainers.Has(containerOverride.Name) {
+				allErrs = append(allErrs, field.Duplicate(containerPath.Child("name"), containerOverride.Name))
+			} else if containerNames != nil && !containerNames.Has(containerOverride.Name) {
+				allErrs = append(allErrs, field.NotFound(containerPath.Child("name"), containerOverride.Name))
+			}
+			overriddenContainers.Insert(containerOverride.Name)
+
+			coreResources := &core.ResourceRequirements{}
+			if err := corev1.Convert_v1_ResourceRequirements_To_core_ResourceRequirements(&containerOverride.Resources, coreResources, nil); err != nil {
+				allErrs = append(allErrs, field.Invalid(containerPath.Child("resources"), containerOverride.Resources, fmt.Sprintf("Convert_v1_ResourceRequirements_To_core_ResourceRequirements failed: %v", err)))
+			} else {
+				allErrs = append(allErrs, apivalidation.ValidateResourceRequirements(coreResources, nil, containerPath.Child("resources"), webhookutil.DefaultPodValidationOptions)...)
+			}
+
+			coreEnv := make([]core.EnvVar, len(containerOverride.Env))
+			for k := range containerOverride.Env {
+				if err := corev1.Convert_v1_EnvVar_To_core_EnvVar(&containerOverride.Env[k], &coreEnv[k], nil); err != nil {
+					allErrs = append(allErrs, field.Invalid(containerPath.Child("env").Index(k), containerOverride.Env[k], fmt.Sprintf("Convert_v1_EnvVar_To_core_EnvVar failed: %v", err)))
+				}
+			}
+			allErrs = append(allErrs, apivalidation.ValidateEnv(coreEnv, containerPath.Child("env"), webhookutil.DefaultPodValidationOptions)...)
+		}
+	}
+	return allErrs
+}
+
+func validateScheduleStrategy(spec *appsv1alpha1.UnitedDeploymentSpec, fldPath *field.Path) field.ErrorList {
+	var errList field.ErrorList
+	strategy := &spec.Topology.ScheduleStrategy
+	switch strategy.Type {
+	case "", appsv1alpha1.FixedUnitedDeploymentScheduleStrategyType:
+		if strategy.Adaptive != nil {
+			errList = append(errList, field.Forbidden(fldPath.Child("adaptive"), "adaptive is only allowed for Adaptive type"))
+		}
+	case appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType:
+		if spec.Replicas == nil {
+			errList = append(errList, field.Required(fldPath.Root().Child("replicas"), "replicas must be set for Adaptive schedule strategy"))
+		}
+		for i, subset := range spec.Topology.Subsets {
+			if subset.Replicas != nil {
+				errList = append(errList, field.Forbidden(fldPath.Root().Child("topology", "subsets").Index(i).Child("replicas"),
+					"subset replicas is not allowed for Adaptive schedule strategy, use minReplicas/maxReplicas instead"))
+			}
+		}
+		if strategy.Adaptive != nil {
+			if strategy.Adaptive.RescheduleCriticalSeconds != nil && *strategy.Adaptive.RescheduleCriticalSeconds <= 0 {
+				errList = append(errList, field.Invalid(fldPath.Child("adaptive", "rescheduleCriticalSeconds"), *strategy.Adaptive.RescheduleCriticalSeconds, "must be greater than 0"))
+			}
+			if strategy.Adaptive.UnschedulableLastSeconds != nil && *strategy.Adaptive.UnschedulableLastSeconds < 0 {
+				errList = append(errList, field.Invalid(fldPath.Child("adaptive", "unschedulableLastSeconds"), *strategy.Adaptive.UnschedulableLastSeconds, "must be greater than or equal to 0"))
+			}
+		}
+	default:
+		errList = append(errList, field.NotSupported(fldPath.Child("type"), strategy.Type,
+			[]string{string(appsv1alpha1.FixedUnitedDeploymentScheduleStrategyType), string(appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType)}))
+	}
+	return errList
+}
+
 // validateUnitedDeployment validates a UnitedDeployment.
 func validateUnitedDeployment(unitedDeployment *appsv1alpha1.UnitedDeployment) field.ErrorList {
 	allErrs := apivalidation.ValidateObjectMeta(&unitedDeployment.ObjectMeta, true, apimachineryvalidation.NameIsDNSSubdomain, field.NewPath("metadata"))
@@ -295,10 +456,13 @@ func validateSubsetTemplate(template *appsv1alpha1.SubsetTemplate, selector labe
 	if template.DeploymentTemplate != nil {
 		templateCount++
 	}
+	if template.CustomWorkloadTemplate != nil {
+		templateCount++
+	}
 	if templateCount < 1 {
-		allErrs = append(allErrs, field.Required(fldPath, "should provide one of statefulSetTemplate, advancedStatefulSetTemplate, cloneSetTemplate, or deploymentTemplate"))
+		allErrs = append(allErrs, field.Required(fldPath, "should provide one of statefulSetTemplate, advancedStatefulSetTemplate, cloneSetTemplate, deploymentTemplate, or customWorkloadTemplate"))
 	} else if templateCount > 1 {
-		allErrs = append(allErrs, field.Invalid(fldPath, template, "should provide only one of statefulSetTemplate, advancedStatefulSetTemplate, cloneSetTemplate, or deploymentTemplate"))
+		allErrs = append(allErrs, field.Invalid(fldPath, template, "should provide only one of statefulSetTemplate, advancedStatefulSetTemplate, cloneSetTemplate, deploymentTemplate, or customWorkloadTemplate"))
 	}
 
 	if template.StatefulSetTemplate != nil {
@@ -340,6 +504,12 @@ func validateSubsetTemplate(template *appsv1alpha1.SubsetTemplate, selector labe
 			return allErrs
 		}
 		allErrs = append(allErrs, appsvalidation.ValidatePodTemplateSpecForReplicaSet(coreTemplate, selector, 0, fldPath.Child("deploymentTemplate", "spec", "template"), webhookutil.DefaultPodValidationOptions)...)
+	} else if template.CustomWorkloadTemplate != nil {
+		labels := labels.Set(template.CustomWorkloadTemplate.Labels)
+		if !selector.Matches(labels) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("customWorkloadTemplate", "metadata", "labels"), template.CustomWorkloadTemplate.Labels, "`selector` does not match template `labels`"))
+		}
+		allErrs = append(allErrs, validateCustomWorkload(template.CustomWorkloadTemplate, fldPath.Child("customWorkloadTemplate"))...)
 	}
 
 	return allErrs
@@ -381,6 +551,25 @@ func validateDeployment(deployment *appsv1alpha1.DeploymentTemplateSpec, fldPath
 	return allErrs
 }
 
+func validateCustomWorkload(workload *appsv1alpha1.CustomWorkloadTemplateSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if workload.APIVersion == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("apiVersion"), ""))
+	} else if _, err := schema.ParseGroupVersion(workload.APIVersion); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("apiVersion"), workload.APIVersion, err.Error()))
+	}
+	if workload.Kind == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("kind"), ""))
+	}
+	if len(workload.Spec.Raw) > 0 {
+		spec := map[string]interface{}{}
+		if err := json.Unmarshal(workload.Spec.Raw, &spec); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("spec"), string(workload.Spec.Raw), "spec should be a json object"))
+		}
+	}
+	return allErrs
+}
+
 func validateStatefulSetUpdate(statefulSet, oldStatefulSet *appsv1alpha1.StatefulSetTemplateSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	restoreReplicas := statefulSet.Spec.Replicas
@@ -24,7 +24,9 @@ import (
 
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/pointer"
@@ -1132,3 +1134,345 @@ func setTestDefault(obj *appsv1alpha1.UnitedDeployment) {
 		*obj.Spec.RevisionHistoryLimit = 10
 	}
 }
+
+func TestValidateScheduleStrategy(t *testing.T) {
+	replicas := intstr.FromInt(2)
+	cases := []struct {
+		name        string
+		spec        appsv1alpha1.UnitedDeploymentSpec
+		errorLength int
+	}{
+		{
+			name: "valid adaptive",
+			spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: pointer.Int32(4),
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{{Name: "subset-a"}, {Name: "subset-b"}},
+					ScheduleStrategy: appsv1alpha1.UnitedDeploymentScheduleStrategy{
+						Type: appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType,
+						Adaptive: &appsv1alpha1.AdaptiveUnitedDeploymentStrategy{
+							RescheduleCriticalSeconds: pointer.Int32(30),
+							UnschedulableLastSeconds:  pointer.Int32(300),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "adaptive without replicas",
+			spec: appsv1alpha1.UnitedDeploymentSpec{
+				Topology: appsv1alpha1.Topology{
+					Subsets: []appsv1alpha1.Subset{{Name: "subset-a", Replicas: &replicas}, {Name: "subset-b"}},
+					ScheduleStrategy: appsv1alpha1.UnitedDeploymentScheduleStrategy{
+						Type: appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType,
+					},
+				},
+			},
+			errorLength: 2,
+		},
+		{
+			name: "invalid adaptive seconds",
+			spec: appsv1alpha1.UnitedDeploymentSpec{
+				Replicas: pointer.Int32(4),
+				Topology: appsv1alpha1.Topology{
+					ScheduleStrategy: appsv1alpha1.UnitedDeploymentScheduleStrategy{
+						Type: appsv1alpha1.AdaptiveUnitedDeploymentScheduleStrategyType,
+						Adaptive: &appsv1alpha1.AdaptiveUnitedDeploymentStrategy{
+							RescheduleCriticalSeconds: pointer.Int32(0),
+							UnschedulableLastSeconds:  pointer.Int32(-1),
+						},
+					},
+				},
+			},
+			errorLength: 2,
+		},
+		{
+			name: "adaptive with fixed type",
+			spec: appsv1alpha1.UnitedDeploymentSpec{
+				Topology: appsv1alpha1.Topology{
+					ScheduleStrategy: appsv1alpha1.UnitedDeploymentScheduleStrategy{
+						Type:     appsv1alpha1.FixedUnitedDeploymentScheduleStrategyType,
+						Adaptive: &appsv1alpha1.AdaptiveUnitedDeploymentStrategy{},
+					},
+				},
+			},
+			errorLength: 1,
+		},
+		{
+			name: "unknown type",
+			spec: appsv1alpha1.UnitedDeploymentSpec{
+				Topology: appsv1alpha1.Topology{
+					ScheduleStrategy: appsv1alpha1.UnitedDeploymentScheduleStrategy{Type: "Unknown"},
+				},
+			},
+			errorLength: 1,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			errList := validateScheduleStrategy(&cs.spec, field.NewPath("spec", "topology", "scheduleStrategy"))
+			if len(errList) != cs.errorLength {
+				t.Errorf("expected %d errors, got %v", cs.errorLength, errList)
+			}
+		})
+	}
+}
+
+func TestValidateCustomWorkloadTemplate(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "demo"}}
+	cases := []struct {
+		name        string
+		template    *appsv1alpha1.CustomWorkloadTemplateSpec
+		errorLength int
+	}{
+		{
+			name: "valid custom workload",
+			template: &appsv1alpha1.CustomWorkloadTemplateSpec{
+				APIVersion: "argoproj.io/v1alpha1",
+				Kind:       "Rollout",
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "demo"}},
+				Spec:       runtime.RawExtension{Raw: []byte(`{"template":{"metadata":{"labels":{"app":"demo"}}}}`)},
+			},
+		},
+		{
+			name: "missing apiVersion and kind",
+			template: &appsv1alpha1.CustomWorkloadTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "demo"}},
+			},
+			errorLength: 2,
+		},
+		{
+			name: "labels not match selector",
+			template: &appsv1alpha1.CustomWorkloadTemplateSpec{
+				APIVersion: "argoproj.io/v1alpha1",
+				Kind:       "Rollout",
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "other"}},
+			},
+			errorLength: 1,
+		},
+		{
+			name: "invalid spec",
+			template: &appsv1alpha1.CustomWorkloadTemplateSpec{
+				APIVersion: "argoproj.io/v1alpha1",
+				Kind:       "Rollout",
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "demo"}},
+				Spec:       runtime.RawExtension{Raw: []byte(`[1]`)},
+			},
+			errorLength: 1,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			sel, _ := metav1.LabelSelectorAsSelector(selector)
+			template := &appsv1alpha1.SubsetTemplate{CustomWorkloadTemplate: cs.template}
+			errList := validateSubsetTemplate(template, sel, field.NewPath("spec", "template"))
+			if len(errList) != cs.errorLength {
+				t.Errorf("expected %d errors, got %v", cs.errorLength, errList)
+			}
+		})
+	}
+}
+
+func TestValidateAutoUpdateStrategy(t *testing.T) {
+	validLabels := map[string]string{"app": "demo"}
+	newSpec := func(strategy appsv1alpha1.UnitedDeploymentUpdateStrategy) *appsv1alpha1.UnitedDeploymentSpec {
+		return &appsv1alpha1.UnitedDeploymentSpec{
+			Replicas: pointer.Int32(4),
+			Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+			Template: appsv1alpha1.SubsetTemplate{
+				CustomWorkloadTemplate: &appsv1alpha1.CustomWorkloadTemplateSpec{
+					APIVersion: "example.io/v1",
+					Kind:       "Rollout",
+					ObjectMeta: metav1.ObjectMeta{Labels: validLabels},
+				},
+			},
+			Topology: appsv1alpha1.Topology{
+				Subsets: []appsv1alpha1.Subset{{Name: "subset-a"}, {Name: "subset-b"}},
+			},
+			UpdateStrategy: strategy,
+		}
+	}
+
+	cases := []struct {
+		name        string
+		strategy    appsv1alpha1.UnitedDeploymentUpdateStrategy
+		errorLength int
+	}{
+		{
+			name: "valid auto update",
+			strategy: appsv1alpha1.UnitedDeploymentUpdateStrategy{
+				Type: appsv1alpha1.AutoUpdateStrategyType,
+				AutoUpdate: &appsv1alpha1.AutoUpdate{
+					MaxParallelSubsets: pointer.Int32(2),
+					Priorities:         map[string]int32{"subset-b": 10},
+				},
+			},
+		},
+		{
+			name: "auto update with manual type",
+			strategy: appsv1alpha1.UnitedDeploymentUpdateStrategy{
+				Type:       appsv1alpha1.ManualUpdateStrategyType,
+				AutoUpdate: &appsv1alpha1.AutoUpdate{},
+			},
+			errorLength: 1,
+		},
+		{
+			name: "invalid maxParallelSubsets and priorities",
+			strategy: appsv1alpha1.UnitedDeploymentUpdateStrategy{
+				Type: appsv1alpha1.AutoUpdateStrategyType,
+				AutoUpdate: &appsv1alpha1.AutoUpdate{
+					MaxParallelSubsets: pointer.Int32(0),
+					Priorities:         map[string]int32{"subset-c": 10},
+				},
+			},
+			errorLength: 2,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			errList := validateUnitedDeploymentSpec(newSpec(cs.strategy), field.NewPath("spec"))
+			if len(errList) != cs.errorLength {
+				t.Errorf("expected %d errors, got %v", cs.errorLength, errList)
+			}
+		})
+	}
+
+	t.Run("auto update with deployment template", func(t *testing.T) {
+		spec := newSpec(appsv1alpha1.UnitedDeploymentUpdateStrategy{Type: appsv1alpha1.AutoUpdateStrategyType})
+		spec.Template.CustomWorkloadTemplate = nil
+		spec.Template.DeploymentTemplate = &appsv1alpha1.DeploymentTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: validLabels},
+			Spec: apps.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: validLabels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: validLabels},
+					Spec: corev1.PodSpec{
+						RestartPolicy: corev1.RestartPolicyAlways,
+						DNSPolicy:     corev1.DNSClusterFirst,
+						Containers:    []corev1.Container{{Name: "test", Image: "test", ImagePullPolicy: "IfNotPresent", TerminationMessagePolicy: corev1.TerminationMessageReadFile}},
+					},
+				},
+			},
+		}
+		errList := validateUnitedDeploymentSpec(spec, field.NewPath("spec"))
+		if len(errList) != 1 || errList[0].Field != "spec.updateStrategy.type" {
+			t.Errorf("expected error of spec.updateStrategy.type, got %v", errList)
+		}
+	})
+}
+
+func TestValidateSubsetStates(t *testing.T) {
+	cases := []struct {
+		name        string
+		states      []appsv1alpha1.SubsetState
+		errorLength int
+	}{
+		{
+			name:   "valid states",
+			states: []appsv1alpha1.SubsetState{"", appsv1alpha1.SubsetStateDraining, appsv1alpha1.SubsetStateDisabled},
+		},
+		{
+			name:   "all disabled",
+			states: []appsv1alpha1.SubsetState{appsv1alpha1.SubsetStateDisabled, appsv1alpha1.SubsetStateDisabled},
+		},
+		{
+			name:        "draining without active subsets",
+			states:      []appsv1alpha1.SubsetState{appsv1alpha1.SubsetStateDraining, appsv1alpha1.SubsetStateDisabled},
+			errorLength: 1,
+		},
+		{
+			name:        "unknown state",
+			states:      []appsv1alpha1.SubsetState{appsv1alpha1.SubsetStateActive, "Unknown"},
+			errorLength: 1,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			var subsets []appsv1alpha1.Subset
+			for i, state := range cs.states {
+				subsets = append(subsets, appsv1alpha1.Subset{Name: fmt.Sprintf("subset-%d", i), State: state})
+			}
+			errList := validateSubsetStates(subsets, field.NewPath("spec", "topology", "subsets"))
+			if len(errList) != cs.errorLength {
+				t.Errorf("expected %d errors, got %v", cs.errorLength, errList)
+			}
+		})
+	}
+}
+
+func TestValidateSubsetOverrides(t *testing.T) {
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "main"}}, InitContainers: []corev1.Container{{Name: "init"}}}
+	cases := []struct {
+		name        string
+		overrides   *appsv1alpha1.SubsetOverrides
+		podSpec     *corev1.PodSpec
+		errorLength int
+	}{
+		{
+			name: "valid overrides",
+			overrides: &appsv1alpha1.SubsetOverrides{
+				ServiceAccountName: "sa-a",
+				PriorityClassName:  "high",
+				ImagePullSecrets:   []corev1.LocalObjectReference{{Name: "secret"}},
+				Containers: []appsv1alpha1.SubsetContainerOverride{
+					{
+						Name: "main",
+						Env:  []corev1.EnvVar{{Name: "ZONE", Value: "a"}},
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+							Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+						},
+					},
+					{Name: "init", Env: []corev1.EnvVar{{Name: "ZONE", Value: "a"}}},
+				},
+			},
+			podSpec: podSpec,
+		},
+		{
+			name: "invalid names",
+			overrides: &appsv1alpha1.SubsetOverrides{
+				ServiceAccountName: "SA_A",
+				PriorityClassName:  "High_Priority",
+				ImagePullSecrets:   []corev1.LocalObjectReference{{}},
+			},
+			podSpec:     podSpec,
+			errorLength: 3,
+		},
+		{
+			name: "invalid containers",
+			overrides: &appsv1alpha1.SubsetOverrides{
+				Containers: []appsv1alpha1.SubsetContainerOverride{
+					{Name: "main", Env: []corev1.EnvVar{{Name: ""}}},
+					{Name: "main"},
+					{Name: "sidecar"},
+					{Name: "init", Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+						Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+					}},
+				},
+			},
+			podSpec:     podSpec,
+			errorLength: 4,
+		},
+		{
+			name: "unknown containers of custom workload",
+			overrides: &appsv1alpha1.SubsetOverrides{
+				Containers: []appsv1alpha1.SubsetContainerOverride{{Name: "sidecar"}},
+			},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			subsets := []appsv1alpha1.Subset{{Name: "subset-a", Overrides: cs.overrides}}
+			errList := validateSubsetOverrides(subsets, cs.podSpec, field.NewPath("spec", "topology", "subsets"))
+			if len(errList) != cs.errorLength {
+				t.Errorf("expected %d errors, got %v", cs.errorLength, errList)
+			}
+		})
+	}
+}
@@ -29,15 +29,28 @@ import (
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util/configuration"
 	webhookutil "github.com/openkruise/kruise/pkg/webhook/util"
 )
 
 const (
 	mutatingWebhookConfigurationName   = "kruise-mutating-webhook-configuration"
 	validatingWebhookConfigurationName = "kruise-validating-webhook-configuration"
+
+	// ResourcesDeletionProtectionPath is the path of the webhook for the resources configured to be protected,
+	// whose rules are generated from kruise configuration instead of the template.
+	ResourcesDeletionProtectionPath = "/validate-resources-deletion-protection"
+	resourcesDeletionProtectionName = "vresourcesdeletionprotection.kb.io"
+
+	// BuiltinWorkloadsUpdateProtectionPath is the path of the webhook for updates and scaling of builtin Deployment
+	// and StatefulSet, whose rules are generated instead of the template to limit the requests sent to the webhook.
+	BuiltinWorkloadsUpdateProtectionPath = "/validate-builtin-workloads-update-protection"
+	builtinWorkloadsUpdateProtectionName = "vbuiltinworkloadsupdateprotection.kb.io"
+	builtinWorkloadsScaleProtectionName  = "vbuiltinworkloadsscaleprotection.kb.io"
 )
 
-func Ensure(kubeClient clientset.Interface, handlers map[string]admission.Handler, caBundle []byte) error {
+func Ensure(kubeClient clientset.Interface, handlers map[string]admission.Handler, caBundle []byte, protectedResources []configuration.DeletionProtectionResource) error {
 	mutatingConfig, err := kubeClient.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(context.TODO(), mutatingWebhookConfigurationName, metav1.GetOptions{})
 	if err != nil {
 		return fmt.Errorf("not found MutatingWebhookConfiguration %s", mutatingWebhookConfigurationName)
@@ -86,6 +99,10 @@ func Ensure(kubeClient clientset.Interface, handlers map[string]admission.Handle
 	var validatingWHs []admissionregistrationv1.ValidatingWebhook
 	for i := range validatingTemplate {
 		wh := &validatingTemplate[i]
+		switch wh.Name {
+		case resourcesDeletionProtectionName, builtinWorkloadsUpdateProtectionName, builtinWorkloadsScaleProtectionName:
+			continue
+		}
 		wh.ClientConfig.CABundle = caBundle
 		path, err := getPath(&wh.ClientConfig)
 		if err != nil {
@@ -106,6 +123,12 @@ func Ensure(kubeClient clientset.Interface, handlers map[string]admission.Handle
 
 		validatingWHs = append(validatingWHs, *wh)
 	}
+	if _, ok := handlers[ResourcesDeletionProtectionPath]; ok && len(protectedResources) > 0 {
+		validatingWHs = append(validatingWHs, newResourcesDeletionProtectionWebhook(protectedResources, caBundle))
+	}
+	if _, ok := handlers[BuiltinWorkloadsUpdateProtectionPath]; ok {
+		validatingWHs = append(validatingWHs, newBuiltinWorkloadsUpdateProtectionWebhooks(caBundle)...)
+	}
 	validatingConfig.Webhooks = validatingWHs
 
 	if !reflect.DeepEqual(mutatingConfig, oldMutatingConfig) {
@@ -123,6 +146,83 @@ func Ensure(kubeClient clientset.Interface, handlers map[string]admission.Handle
 	return nil
 }
 
+// newResourcesDeletionProtectionWebhook generates the webhook for deletion of the protected resources,
+// only the resources with deletion protection label will be sent to the webhook.
+func newResourcesDeletionProtectionWebhook(resources []configuration.DeletionProtectionResource, caBundle []byte) admissionregistrationv1.ValidatingWebhook {
+	wh := newGeneratedValidatingWebhook(resourcesDeletionProtectionName, ResourcesDeletionProtectionPath, caBundle)
+	wh.ObjectSelector = newDeletionProtectionObjectSelector()
+	for _, r := range resources {
+		wh.Rules = append(wh.Rules, admissionregistrationv1.RuleWithOperations{
+			// creations and updates are validated for the approval of deletion protection
+			Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update, admissionregistrationv1.Delete},
+			Rule: admissionregistrationv1.Rule{
+				APIGroups:   []string{r.Group},
+				APIVersions: []string{r.Version},
+				Resources:   []string{r.Resource},
+			},
+		})
+	}
+	return wh
+}
+
+// newBuiltinWorkloadsUpdateProtectionWebhooks generates the webhooks for updates and scaling of Deployment and StatefulSet.
+// Only the workloads with deletion protection label will be sent to the webhook for updates, but the scale requests
+// carry no labels of workloads, so all of them will be sent to the webhook. The failure policy of the scale webhook
+// is Ignore, so that scaling in the cluster, e.g., by HPA, does not fail when kruise webhook is unavailable, at the
+// cost of not protecting workloads from being scaled to zero through the scale subresource during that time.
+func newBuiltinWorkloadsUpdateProtectionWebhooks(caBundle []byte) []admissionregistrationv1.ValidatingWebhook {
+	newRule := func(resources ...string) admissionregistrationv1.RuleWithOperations {
+		return admissionregistrationv1.RuleWithOperations{
+			Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Update},
+			Rule: admissionregistrationv1.Rule{
+				APIGroups:   []string{"apps"},
+				APIVersions: []string{"v1"},
+				Resources:   resources,
+			},
+		}
+	}
+	updateWH := newGeneratedValidatingWebhook(builtinWorkloadsUpdateProtectionName, BuiltinWorkloadsUpdateProtectionPath, caBundle)
+	updateWH.ObjectSelector = newDeletionProtectionObjectSelector()
+	updateWH.Rules = []admissionregistrationv1.RuleWithOperations{newRule("deployments", "statefulsets")}
+
+	scaleWH := newGeneratedValidatingWebhook(builtinWorkloadsScaleProtectionName, BuiltinWorkloadsUpdateProtectionPath, caBundle)
+	scaleWH.Rules = []admissionregistrationv1.RuleWithOperations{newRule("deployments/scale", "statefulsets/scale")}
+	ignore := admissionregistrationv1.Ignore
+	scaleWH.FailurePolicy = &ignore
+	return []admissionregistrationv1.ValidatingWebhook{updateWH, scaleWH}
+}
+
+func newGeneratedValidatingWebhook(name, path string, caBundle []byte) admissionregistrationv1.ValidatingWebhook {
+	failurePolicy := admissionregistrationv1.Fail
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	wh := admissionregistrationv1.ValidatingWebhook{
+		Name: name,
+		ClientConfig: admissionregistrationv1.WebhookClientConfig{
+			Service: &admissionregistrationv1.ServiceReference{
+				Namespace: webhookutil.GetNamespace(),
+				Name:      webhookutil.GetServiceName(),
+				Path:      &path,
+			},
+			CABundle: caBundle,
+		},
+		FailurePolicy:           &failurePolicy,
+		SideEffects:             &sideEffects,
+		AdmissionReviewVersions: []string{"v1", "v1beta1"},
+	}
+	if host := webhookutil.GetHost(); len(host) > 0 {
+		convertClientConfig(&wh.ClientConfig, host, webhookutil.GetPort())
+	}
+	return wh
+}
+
+func newDeletionProtectionObjectSelector() *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: policyv1alpha1.DeletionProtectionKey, Operator: metav1.LabelSelectorOpExists},
+		},
+	}
+}
+
 func getPath(clientConfig *admissionregistrationv1.WebhookClientConfig) (string, error) {
 	if clientConfig.Service != nil {
 		return *clientConfig.Service.Path, nil
@@ -28,6 +28,7 @@ import (
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	apiextensionsinformers "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions/apiextensions/v1"
 	apiextensionslisters "k8s.io/apiextensions-apiserver/pkg/client/listers/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -35,6 +36,7 @@ import (
 	admissionregistrationinformers "k8s.io/client-go/informers/admissionregistration/v1"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	clientset "k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
@@ -42,6 +44,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	extclient "github.com/openkruise/kruise/pkg/client"
+	"github.com/openkruise/kruise/pkg/util"
+	kruiseconfiguration "github.com/openkruise/kruise/pkg/util/configuration"
 	webhookutil "github.com/openkruise/kruise/pkg/webhook/util"
 	"github.com/openkruise/kruise/pkg/webhook/util/configuration"
 	"github.com/openkruise/kruise/pkg/webhook/util/crd"
@@ -76,6 +80,7 @@ type Controller struct {
 	crdClient       apiextensionsclientset.Interface
 	crdInformer     cache.SharedIndexInformer
 	crdLister       apiextensionslisters.CustomResourceDefinitionLister
+	configMapLister corelisters.ConfigMapNamespaceLister
 	synced          []cache.InformerSynced
 
 	queue workqueue.RateLimitingInterface
@@ -110,6 +115,31 @@ func New(cfg *rest.Config, handlers map[string]admission.Handler) (*Controller,
 		},
 	})
 
+	configMapInformer := coreinformers.New(c.informerFactory, util.GetKruiseNamespace(), nil).ConfigMaps()
+	configMapInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			cm := obj.(*v1.ConfigMap)
+			if cm.Name == kruiseconfiguration.KruiseConfigurationName {
+				klog.Infof("ConfigMap %s added", kruiseconfiguration.KruiseConfigurationName)
+				c.queue.Add("")
+			}
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			cm := cur.(*v1.ConfigMap)
+			if cm.Name == kruiseconfiguration.KruiseConfigurationName {
+				klog.Infof("ConfigMap %s updated", kruiseconfiguration.KruiseConfigurationName)
+				c.queue.Add("")
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if cm, ok := obj.(*v1.ConfigMap); ok && cm.Name != kruiseconfiguration.KruiseConfigurationName {
+				return
+			}
+			c.queue.Add("")
+		},
+	})
+	c.configMapLister = configMapInformer.Lister().ConfigMaps(util.GetKruiseNamespace())
+
 	admissionRegistrationInformer.MutatingWebhookConfigurations().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			conf := obj.(*admissionregistrationv1.MutatingWebhookConfiguration)
@@ -166,6 +196,7 @@ func New(cfg *rest.Config, handlers map[string]admission.Handler) (*Controller,
 
 	c.synced = []cache.InformerSynced{
 		secretInformer.Informer().HasSynced,
+		configMapInformer.Informer().HasSynced,
 		admissionRegistrationInformer.MutatingWebhookConfigurations().Informer().HasSynced,
 		admissionRegistrationInformer.ValidatingWebhookConfigurations().Informer().HasSynced,
 		c.crdInformer.HasSynced,
@@ -255,7 +286,7 @@ func (c *Controller) sync() error {
 		return fmt.Errorf("failed to write certs to dir: %v", err)
 	}
 
-	if err := configuration.Ensure(c.kubeClient, c.handlers, certs.CACert); err != nil {
+	if err := configuration.Ensure(c.kubeClient, c.handlers, certs.CACert, c.getDeletionProtectionResources()); err != nil {
 		return fmt.Errorf("failed to ensure configuration: %v", err)
 	}
 
@@ -268,3 +299,21 @@ func (c *Controller) sync() error {
 	})
 	return nil
 }
+
+// getDeletionProtectionResources returns the resources configured to be protected from deletion,
+// the invalid configuration is ignored so that it will not block the other webhooks.
+func (c *Controller) getDeletionProtectionResources() []kruiseconfiguration.DeletionProtectionResource {
+	cm, err := c.configMapLister.Get(kruiseconfiguration.KruiseConfigurationName)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			klog.Errorf("Failed to get ConfigMap %s: %v", kruiseconfiguration.KruiseConfigurationName, err)
+		}
+		return nil
+	}
+	resources, err := kruiseconfiguration.ParseDeletionProtectionResources(cm.Data)
+	if err != nil {
+		klog.Errorf("Failed to parse %s in ConfigMap %s: %v", kruiseconfiguration.DeletionProtectionResourcesKey, kruiseconfiguration.KruiseConfigurationName, err)
+		return nil
+	}
+	return resources.Resources
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deletionprotection
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/features"
+	"github.com/openkruise/kruise/pkg/util/configuration"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+)
+
+// validateDeletionApproval validates the deletion of object labeled with AlwaysWithApproval requested by the user.
+// Empty requester means the approval is not supported for the object, for its webhook can not check the approval annotation.
+// The deletion allowed by approval is counted in protectionMetrics with result Overridden.
+func validateDeletionApproval(obj metav1.Object, requester string, protectionMetrics *prometheus.CounterVec) error {
+	val := policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval
+	if requester == "" {
+		return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s and approval is not supported for this resource", policyv1alpha1.DeletionProtectionKey, val)
+	}
+	approval, err := parseDeletionApproval(obj)
+	if err != nil {
+		return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s and invalid approval: %v", policyv1alpha1.DeletionProtectionKey, val, err)
+	} else if approval == nil {
+		return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s without approval %s", policyv1alpha1.DeletionProtectionKey, val, policyv1alpha1.DeletionProtectionApprovalKey)
+	}
+	if approval.Requester != requester {
+		return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s and approval is for %s", policyv1alpha1.DeletionProtectionKey, val, approval.Requester)
+	}
+	if !time.Now().Before(approval.ExpireTime.Time) {
+		return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s and approval expired at %s", policyv1alpha1.DeletionProtectionKey, val, approval.ExpireTime.UTC().Format(time.RFC3339))
+	}
+	protectionMetrics.WithLabelValues(kindNamespaceName(obj), requester, ResultOverridden).Add(1)
+	klog.InfoS("Deletion protection is overridden by approval", "kind", kindOf(obj), "namespace", obj.GetNamespace(), "name", obj.GetName(), "requester", requester)
+	return nil
+}
+
+// ValidateDeletionApprovalChange validates the creation or update of object, only the approvers configured in
+// kruise configuration can set the approval annotation, or change the label from AlwaysWithApproval.
+// oldObj is nil for creation. The change denied for non-approvers is counted in protectionMetrics with result Denied.
+func ValidateDeletionApprovalChange(c client.Reader, oldObj, newObj metav1.Object, userInfo authenticationv1.UserInfo,
+	protectionMetrics *prometheus.CounterVec) error {
+	if !utilfeature.DefaultFeatureGate.Enabled(features.ResourcesDeletionProtection) {
+		return nil
+	}
+	var oldProtection, oldApproval string
+	if oldObj != nil {
+		oldProtection = oldObj.GetLabels()[policyv1alpha1.DeletionProtectionKey]
+		oldApproval = oldObj.GetAnnotations()[policyv1alpha1.DeletionProtectionApprovalKey]
+	}
+	newProtection := newObj.GetLabels()[policyv1alpha1.DeletionProtectionKey]
+	newApproval := newObj.GetAnnotations()[policyv1alpha1.DeletionProtectionApprovalKey]
+
+	// anyone can revoke the approval
+	approvalSet := newApproval != "" && newApproval != oldApproval
+	protectionRemoved := oldProtection == policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval && newProtection != oldProtection
+	if !approvalSet && !protectionRemoved {
+		return nil
+	}
+
+	approvers, err := configuration.GetDeletionProtectionApprovers(c)
+	if err != nil {
+		return fmt.Errorf("failed to get deletion protection approvers: %v", err)
+	}
+	if !approvers.IsApprover(userInfo) {
+		protectionMetrics.WithLabelValues(kindNamespaceName(newObj), userInfo.Username, ResultDenied).Add(1)
+		if protectionRemoved {
+			return fmt.Errorf("forbidden to change %s from %s by %s who is not an approver", policyv1alpha1.DeletionProtectionKey, oldProtection, userInfo.Username)
+		}
+		return fmt.Errorf("forbidden to set %s by %s who is not an approver", policyv1alpha1.DeletionProtectionApprovalKey, userInfo.Username)
+	}
+	if approvalSet {
+		if _, err = parseDeletionApproval(newObj); err != nil {
+			return fmt.Errorf("invalid %s: %v", policyv1alpha1.DeletionProtectionApprovalKey, err)
+		}
+	}
+	return nil
+}
+
+func parseDeletionApproval(obj metav1.Object) (*policyv1alpha1.DeletionProtectionApproval, error) {
+	value, ok := obj.GetAnnotations()[policyv1alpha1.DeletionProtectionApprovalKey]
+	if !ok {
+		return nil, nil
+	}
+	approval := &policyv1alpha1.DeletionProtectionApproval{}
+	if err := json.Unmarshal([]byte(value), approval); err != nil {
+		return nil, err
+	}
+	if approval.Requester == "" || approval.ExpireTime.IsZero() {
+		return nil, fmt.Errorf("requester and expireTime are required")
+	}
+	return approval, nil
+}
+
+func kindOf(obj metav1.Object) string {
+	if accessor, err := meta.TypeAccessor(obj); err == nil {
+		return accessor.GetKind()
+	}
+	return ""
+}
+
+func kindNamespaceName(obj metav1.Object) string {
+	return fmt.Sprintf("%s_%s_%s", kindOf(obj), obj.GetNamespace(), obj.GetName())
+}
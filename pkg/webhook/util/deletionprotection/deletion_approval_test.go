@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deletionprotection
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util"
+	"github.com/openkruise/kruise/pkg/util/configuration"
+)
+
+func newApproval(requester string, expireTime time.Time) string {
+	return fmt.Sprintf(`{"requester":"%s","expireTime":"%s"}`, requester, expireTime.UTC().Format(time.RFC3339))
+}
+
+func TestValidateWorkloadDeletionWithApproval(t *testing.T) {
+	cases := []struct {
+		name        string
+		approval    string
+		requester   string
+		expectError bool
+	}{
+		{
+			name:        "without approval",
+			requester:   "alice",
+			expectError: true,
+		},
+		{
+			name:      "approved",
+			approval:  newApproval("alice", time.Now().Add(time.Hour)),
+			requester: "alice",
+		},
+		{
+			name:        "approved for another user",
+			approval:    newApproval("bob", time.Now().Add(time.Hour)),
+			requester:   "alice",
+			expectError: true,
+		},
+		{
+			name:        "approval expired",
+			approval:    newApproval("alice", time.Now().Add(-time.Minute)),
+			requester:   "alice",
+			expectError: true,
+		},
+		{
+			name:        "approval not supported",
+			approval:    newApproval("alice", time.Now().Add(time.Hour)),
+			requester:   "",
+			expectError: true,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			obj := &appsv1alpha1.CloneSet{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "test",
+				Labels:    map[string]string{policyv1alpha1.DeletionProtectionKey: policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval},
+			}}
+			if cs.approval != "" {
+				obj.Annotations = map[string]string{policyv1alpha1.DeletionProtectionApprovalKey: cs.approval}
+			}
+			err := ValidateWorkloadDeletion(obj, nil, cs.requester)
+			if (err != nil) != cs.expectError {
+				t.Fatalf("expect error %v, but got %v", cs.expectError, err)
+			}
+		})
+	}
+}
+
+func TestValidateDeletionApprovalChange(t *testing.T) {
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: util.GetKruiseNamespace(), Name: configuration.KruiseConfigurationName},
+		Data:       map[string]string{configuration.DeletionProtectionApproversKey: `{"users":["admin"],"groups":["sre"]}`},
+	}
+	fakeClient := fake.NewClientBuilder().WithObjects(cm).Build()
+	approval := newApproval("alice", time.Now().Add(time.Hour))
+	newObj := func(protection, approval string) *appsv1alpha1.CloneSet {
+		obj := &appsv1alpha1.CloneSet{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"}}
+		if protection != "" {
+			obj.Labels = map[string]string{policyv1alpha1.DeletionProtectionKey: protection}
+		}
+		if approval != "" {
+			obj.Annotations = map[string]string{policyv1alpha1.DeletionProtectionApprovalKey: approval}
+		}
+		return obj
+	}
+
+	cases := []struct {
+		name        string
+		oldObj      *appsv1alpha1.CloneSet
+		newObj      *appsv1alpha1.CloneSet
+		userInfo    authenticationv1.UserInfo
+		expectError bool
+	}{
+		{
+			name:     "approval set by approver user",
+			oldObj:   newObj(policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval, ""),
+			newObj:   newObj(policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval, approval),
+			userInfo: authenticationv1.UserInfo{Username: "admin"},
+		},
+		{
+			name:     "approval set by approver group",
+			oldObj:   newObj(policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval, ""),
+			newObj:   newObj(policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval, approval),
+			userInfo: authenticationv1.UserInfo{Username: "bob", Groups: []string{"dev", "sre"}},
+		},
+		{
+			name:        "approval set by non-approver",
+			oldObj:      newObj(policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval, ""),
+			newObj:      newObj(policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval, approval),
+			userInfo:    authenticationv1.UserInfo{Username: "alice"},
+			expectError: true,
+		},
+		{
+			name:        "approval created by non-approver",
+			newObj:      newObj(policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval, approval),
+			userInfo:    authenticationv1.UserInfo{Username: "alice"},
+			expectError: true,
+		},
+		{
+			name:        "invalid approval set by approver",
+			oldObj:      newObj(policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval, ""),
+			newObj:      newObj(policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval, `{"requester":"alice"}`),
+			userInfo:    authenticationv1.UserInfo{Username: "admin"},
+			expectError: true,
+		},
+		{
+			name:     "approval revoked by non-approver",
+			oldObj:   newObj(policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval, approval),
+			newObj:   newObj(policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval, ""),
+			userInfo: authenticationv1.UserInfo{Username: "alice"},
+		},
+		{
+			name:        "protection removed by non-approver",
+			oldObj:      newObj(policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval, ""),
+			newObj:      newObj("", ""),
+			userInfo:    authenticationv1.UserInfo{Username: "alice"},
+			expectError: true,
+		},
+		{
+			name:     "protection changed by non-approver",
+			oldObj:   newObj(policyv1alpha1.DeletionProtectionTypeAlways, ""),
+			newObj:   newObj(policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval, ""),
+			userInfo: authenticationv1.UserInfo{Username: "alice"},
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			var oldObj metav1.Object
+			if cs.oldObj != nil {
+				oldObj = cs.oldObj
+			}
+			err := ValidateDeletionApprovalChange(fakeClient, oldObj, cs.newObj, cs.userInfo, WorkloadDeletionProtectionMetrics)
+			if (err != nil) != cs.expectError {
+				t.Fatalf("expect error %v, but got %v", cs.expectError, err)
+			}
+		})
+	}
+}
@@ -33,14 +33,18 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func ValidateWorkloadDeletion(obj metav1.Object, replicas *int32) error {
+// ValidateWorkloadDeletion validates the deletion of workload, requester is the user requesting the deletion
+// and it should be empty if the workload webhook can not check the approval annotation.
+func ValidateWorkloadDeletion(obj metav1.Object, replicas *int32, requester string) error {
 	if !utilfeature.DefaultFeatureGate.Enabled(features.ResourcesDeletionProtection) || obj == nil || obj.GetDeletionTimestamp() != nil {
 		return nil
 	}
 	switch val := obj.GetLabels()[policyv1alpha1.DeletionProtectionKey]; val {
 	case policyv1alpha1.DeletionProtectionTypeAlways:
 		return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s", policyv1alpha1.DeletionProtectionKey, val)
-	case policyv1alpha1.DeletionProtectionTypeCascading:
+	case policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval:
+		return validateDeletionApproval(obj, requester, WorkloadDeletionProtectionMetrics)
+	case policyv1alpha1.DeletionProtectionTypeCascading, policyv1alpha1.DeletionProtectionTypeCascadingStrict:
 		if replicas != nil && *replicas > 0 {
 			return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s and replicas %d>0", policyv1alpha1.DeletionProtectionKey, val, *replicas)
 		}
@@ -54,7 +58,7 @@ func ValidateServiceDeletion(service *v1.Service) error {
 		return nil
 	}
 	switch val := service.Labels[policyv1alpha1.DeletionProtectionKey]; val {
-	case policyv1alpha1.DeletionProtectionTypeAlways:
+	case policyv1alpha1.DeletionProtectionTypeAlways, policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval:
 		return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s", policyv1alpha1.DeletionProtectionKey, val)
 	default:
 	}
@@ -66,7 +70,7 @@ func ValidateIngressDeletion(obj metav1.Object) error {
 		return nil
 	}
 	switch val := obj.GetLabels()[policyv1alpha1.DeletionProtectionKey]; val {
-	case policyv1alpha1.DeletionProtectionTypeAlways:
+	case policyv1alpha1.DeletionProtectionTypeAlways, policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval:
 		return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s", policyv1alpha1.DeletionProtectionKey, val)
 	default:
 	}
@@ -78,7 +82,7 @@ func ValidateNamespaceDeletion(c client.Client, namespace *v1.Namespace) error {
 		return nil
 	}
 	switch val := namespace.Labels[policyv1alpha1.DeletionProtectionKey]; val {
-	case policyv1alpha1.DeletionProtectionTypeAlways:
+	case policyv1alpha1.DeletionProtectionTypeAlways, policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval:
 		return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s", policyv1alpha1.DeletionProtectionKey, val)
 	case policyv1alpha1.DeletionProtectionTypeCascading:
 		pods := v1.PodList{}
@@ -120,7 +124,7 @@ func ValidateCRDDeletion(c client.Client, obj metav1.Object, gvk schema.GroupVer
 		return nil
 	}
 	switch val := obj.GetLabels()[policyv1alpha1.DeletionProtectionKey]; val {
-	case policyv1alpha1.DeletionProtectionTypeAlways:
+	case policyv1alpha1.DeletionProtectionTypeAlways, policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval:
 		return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s", policyv1alpha1.DeletionProtectionKey, val)
 	case policyv1alpha1.DeletionProtectionTypeCascading:
 		if !utilfeature.DefaultFeatureGate.Enabled(features.DeletionProtectionForCRDCascadingGate) {
@@ -21,25 +21,46 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
+const (
+	// ResultDenied is the result label of the deletions, and the approval changes, denied by deletion protection.
+	ResultDenied = "Denied"
+	// ResultOverridden is the result label of the deletions allowed by approval for AlwaysWithApproval.
+	ResultOverridden = "Overridden"
+)
+
 var (
 	NamespaceDeletionProtectionMetrics = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "namespace_deletion_protection",
 			Help: "Namespace Deletion Protection",
-		}, []string{"name", "username"},
+		}, []string{"name", "username", "result"},
 	)
 
 	CRDDeletionProtectionMetrics = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "crd_deletion_protection",
 			Help: "CustomResourceDefinition Deletion Protection",
-		}, []string{"name", "username"},
+		}, []string{"name", "username", "result"},
 	)
 
 	WorkloadDeletionProtectionMetrics = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "workload_deletion_protection",
 			Help: "Workload Deletion Protection",
+		}, []string{"kind_namespace_name", "username", "result"},
+	)
+
+	ResourceDeletionProtectionMetrics = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "resource_deletion_protection",
+			Help: "Configured Resource Deletion Protection",
+		}, []string{"kind_namespace_name", "username", "result"},
+	)
+
+	WorkloadUpdateProtectionMetrics = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "workload_update_protection",
+			Help: "Workload Scale To Zero And Selector Update Protection",
 		}, []string{"kind_namespace_name", "username"},
 	)
 )
@@ -48,4 +69,6 @@ func init() {
 	metrics.Registry.MustRegister(NamespaceDeletionProtectionMetrics)
 	metrics.Registry.MustRegister(CRDDeletionProtectionMetrics)
 	metrics.Registry.MustRegister(WorkloadDeletionProtectionMetrics)
+	metrics.Registry.MustRegister(ResourceDeletionProtectionMetrics)
+	metrics.Registry.MustRegister(WorkloadUpdateProtectionMetrics)
 }
@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deletionprotection
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kubecontroller "k8s.io/kubernetes/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/features"
+	utilclient "github.com/openkruise/kruise/pkg/util/client"
+	"github.com/openkruise/kruise/pkg/util/configuration"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+)
+
+// ValidateResourceDeletion validates the deletion of resources configured in kruise configuration.
+// For Cascading, PersistentVolumeClaim is protected if it is mounted by active pods, PersistentVolume is protected
+// if it is bound, ConfigMap and Secret are protected if they are referenced by active pods, and the resources of
+// other kinds are protected if the replicas in ReplicasPath is larger than 0.
+// requester is the user requesting the deletion, which is checked with the approval for AlwaysWithApproval.
+func ValidateResourceDeletion(c client.Client, obj *unstructured.Unstructured, resource *configuration.DeletionProtectionResource, requester string) error {
+	if !utilfeature.DefaultFeatureGate.Enabled(features.ResourcesDeletionProtection) || obj.GetDeletionTimestamp() != nil {
+		return nil
+	}
+	switch val := obj.GetLabels()[policyv1alpha1.DeletionProtectionKey]; val {
+	case policyv1alpha1.DeletionProtectionTypeAlways:
+		return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s", policyv1alpha1.DeletionProtectionKey, val)
+	case policyv1alpha1.DeletionProtectionTypeAlwaysWithApproval:
+		return validateDeletionApproval(obj, requester, ResourceDeletionProtectionMetrics)
+	case policyv1alpha1.DeletionProtectionTypeCascading:
+		if resource.Group == v1.GroupName {
+			switch resource.Kind {
+			case "PersistentVolumeClaim", "ConfigMap", "Secret":
+				return validateReferencedByActivePods(c, obj, resource.Kind)
+			case "PersistentVolume":
+				phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+				if phase == string(v1.VolumeBound) {
+					return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s and PersistentVolume is bound", policyv1alpha1.DeletionProtectionKey, val)
+				}
+				return nil
+			}
+		}
+		if resource.ReplicasPath == "" {
+			return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s and no replicasPath configured for %s", policyv1alpha1.DeletionProtectionKey, val, resource.Kind)
+		}
+		replicas, found, err := unstructured.NestedInt64(obj.Object, strings.Split(resource.ReplicasPath, ".")...)
+		if err != nil {
+			return fmt.Errorf("forbidden by ResourcesProtectionDeletion for invalid replicas in %s: %v", resource.ReplicasPath, err)
+		}
+		if found && replicas > 0 {
+			return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s and replicas %d>0", policyv1alpha1.DeletionProtectionKey, val, replicas)
+		}
+	default:
+	}
+	return nil
+}
+
+func validateReferencedByActivePods(c client.Client, obj *unstructured.Unstructured, kind string) error {
+	pods := v1.PodList{}
+	if err := c.List(context.TODO(), &pods, client.InNamespace(obj.GetNamespace()), utilclient.DisableDeepCopy); err != nil {
+		return fmt.Errorf("forbidden by ResourcesProtectionDeletion for list pods error: %v", err)
+	}
+	var activeCount int
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if kubecontroller.IsPodActive(pod) && isPodReferencing(pod, kind, obj.GetName()) {
+			activeCount++
+		}
+	}
+	if activeCount > 0 {
+		return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s and referenced by active pods %d>0",
+			policyv1alpha1.DeletionProtectionKey, policyv1alpha1.DeletionProtectionTypeCascading, activeCount)
+	}
+	return nil
+}
+
+// isPodReferencing returns whether the pod mounts the PersistentVolumeClaim, or references the ConfigMap or Secret
+// in its volumes, environments or image pull secrets.
+func isPodReferencing(pod *v1.Pod, kind, name string) bool {
+	for i := range pod.Spec.Volumes {
+		volume := &pod.Spec.Volumes[i]
+		switch kind {
+		case "PersistentVolumeClaim":
+			if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == name {
+				return true
+			}
+		case "ConfigMap":
+			if volume.ConfigMap != nil && volume.ConfigMap.Name == name {
+				return true
+			}
+		case "Secret":
+			if volume.Secret != nil && volume.Secret.SecretName == name {
+				return true
+			}
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if (kind == "ConfigMap" && source.ConfigMap != nil && source.ConfigMap.Name == name) ||
+					(kind == "Secret" && source.Secret != nil && source.Secret.Name == name) {
+					return true
+				}
+			}
+		}
+	}
+	if kind == "PersistentVolumeClaim" {
+		return false
+	}
+	if kind == "Secret" {
+		for _, ref := range pod.Spec.ImagePullSecrets {
+			if ref.Name == name {
+				return true
+			}
+		}
+	}
+
+	containers := make([]v1.Container, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+	containers = append(containers, pod.Spec.InitContainers...)
+	containers = append(containers, pod.Spec.Containers...)
+	for i := range containers {
+		for _, envFrom := range containers[i].EnvFrom {
+			if (kind == "ConfigMap" && envFrom.ConfigMapRef != nil && envFrom.ConfigMapRef.Name == name) ||
+				(kind == "Secret" && envFrom.SecretRef != nil && envFrom.SecretRef.Name == name) {
+				return true
+			}
+		}
+		for _, env := range containers[i].Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if (kind == "ConfigMap" && env.ValueFrom.ConfigMapKeyRef != nil && env.ValueFrom.ConfigMapKeyRef.Name == name) ||
+				(kind == "Secret" && env.ValueFrom.SecretKeyRef != nil && env.ValueFrom.SecretKeyRef.Name == name) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deletionprotection
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/util/configuration"
+)
+
+func TestValidateResourceDeletion(t *testing.T) {
+	newObj := func(apiVersion, kind, name, protection string, fields map[string]interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: fields}
+		if obj.Object == nil {
+			obj.Object = map[string]interface{}{}
+		}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetNamespace("default")
+		obj.SetName(name)
+		obj.SetLabels(map[string]string{policyv1alpha1.DeletionProtectionKey: protection})
+		return obj
+	}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod-0"},
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{
+				{Name: "data", VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc-used"}}},
+			},
+			Containers: []v1.Container{{
+				Name:    "main",
+				EnvFrom: []v1.EnvFromSource{{ConfigMapRef: &v1.ConfigMapEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "cm-used"}}}},
+			}},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+	pvcResource := &configuration.DeletionProtectionResource{
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "PersistentVolumeClaim"}, Resource: "persistentvolumeclaims"}
+	cmResource := &configuration.DeletionProtectionResource{
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, Resource: "configmaps"}
+	pvResource := &configuration.DeletionProtectionResource{
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "PersistentVolume"}, Resource: "persistentvolumes"}
+	crResource := &configuration.DeletionProtectionResource{
+		GroupVersionKind: schema.GroupVersionKind{Group: "example.io", Version: "v1", Kind: "Foo"}, Resource: "foos", ReplicasPath: "spec.replicas"}
+
+	cases := []struct {
+		name        string
+		obj         *unstructured.Unstructured
+		resource    *configuration.DeletionProtectionResource
+		expectError bool
+	}{
+		{
+			name:        "always",
+			obj:         newObj("v1", "ConfigMap", "cm-unused", policyv1alpha1.DeletionProtectionTypeAlways, nil),
+			resource:    cmResource,
+			expectError: true,
+		},
+		{
+			name:        "cascading pvc mounted by active pod",
+			obj:         newObj("v1", "PersistentVolumeClaim", "pvc-used", policyv1alpha1.DeletionProtectionTypeCascading, nil),
+			resource:    pvcResource,
+			expectError: true,
+		},
+		{
+			name:     "cascading pvc not mounted",
+			obj:      newObj("v1", "PersistentVolumeClaim", "pvc-unused", policyv1alpha1.DeletionProtectionTypeCascading, nil),
+			resource: pvcResource,
+		},
+		{
+			name:        "cascading configmap referenced by active pod",
+			obj:         newObj("v1", "ConfigMap", "cm-used", policyv1alpha1.DeletionProtectionTypeCascading, nil),
+			resource:    cmResource,
+			expectError: true,
+		},
+		{
+			name:     "cascading configmap not referenced",
+			obj:      newObj("v1", "ConfigMap", "cm-unused", policyv1alpha1.DeletionProtectionTypeCascading, nil),
+			resource: cmResource,
+		},
+		{
+			name: "cascading bound pv",
+			obj: newObj("v1", "PersistentVolume", "pv", policyv1alpha1.DeletionProtectionTypeCascading,
+				map[string]interface{}{"status": map[string]interface{}{"phase": "Bound"}}),
+			resource:    pvResource,
+			expectError: true,
+		},
+		{
+			name: "cascading custom resource with replicas",
+			obj: newObj("example.io/v1", "Foo", "foo", policyv1alpha1.DeletionProtectionTypeCascading,
+				map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(1)}}),
+			resource:    crResource,
+			expectError: true,
+		},
+		{
+			name: "cascading custom resource without replicas",
+			obj: newObj("example.io/v1", "Foo", "foo", policyv1alpha1.DeletionProtectionTypeCascading,
+				map[string]interface{}{"spec": map[string]interface{}{"replicas": int64(0)}}),
+			resource: crResource,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod).Build()
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			err := ValidateResourceDeletion(fakeClient, cs.obj, cs.resource, "")
+			if (err != nil) != cs.expectError {
+				t.Fatalf("expect error %v, but got %v", cs.expectError, err)
+			}
+		})
+	}
+}
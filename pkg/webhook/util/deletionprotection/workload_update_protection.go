@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deletionprotection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+	"github.com/openkruise/kruise/pkg/features"
+	utilfeature "github.com/openkruise/kruise/pkg/util/feature"
+)
+
+// ValidateWorkloadUpdate validates the update of workload labeled with CascadingStrict, which is forbidden
+// to be scaled from >0 to 0 or to change its selector. The nil replicas are defaulted to 1.
+func ValidateWorkloadUpdate(oldObj metav1.Object, oldReplicas, newReplicas *int32, oldSelector, newSelector *metav1.LabelSelector) error {
+	if !utilfeature.DefaultFeatureGate.Enabled(features.ResourcesDeletionProtection) || oldObj.GetDeletionTimestamp() != nil {
+		return nil
+	}
+	val := oldObj.GetLabels()[policyv1alpha1.DeletionProtectionKey]
+	if val != policyv1alpha1.DeletionProtectionTypeCascadingStrict {
+		return nil
+	}
+	if from, to := getReplicas(oldReplicas), getReplicas(newReplicas); from > 0 && to == 0 {
+		return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s and scaling replicas from %d to 0", policyv1alpha1.DeletionProtectionKey, val, from)
+	}
+	if !apiequality.Semantic.DeepEqual(oldSelector, newSelector) {
+		return fmt.Errorf("forbidden by ResourcesProtectionDeletion for %s=%s and changing selector", policyv1alpha1.DeletionProtectionKey, val)
+	}
+	return nil
+}
+
+// ValidateWorkloadScale validates the update of scale subresource of workload. The Scale object has no labels
+// of the workload, so the workload is got by client only if it is scaled to 0.
+func ValidateWorkloadScale(c client.Reader, workload client.Object, oldRaw, newRaw []byte) error {
+	if !utilfeature.DefaultFeatureGate.Enabled(features.ResourcesDeletionProtection) {
+		return nil
+	}
+	oldScale := &autoscalingv1.Scale{}
+	newScale := &autoscalingv1.Scale{}
+	if err := json.Unmarshal(oldRaw, oldScale); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(newRaw, newScale); err != nil {
+		return err
+	}
+	if oldScale.Spec.Replicas == 0 || newScale.Spec.Replicas > 0 {
+		return nil
+	}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: newScale.Namespace, Name: newScale.Name}, workload); err != nil {
+		return fmt.Errorf("failed to get workload %s/%s: %v", newScale.Namespace, newScale.Name, err)
+	}
+	return ValidateWorkloadUpdate(workload, &oldScale.Spec.Replicas, &newScale.Spec.Replicas, nil, nil)
+}
+
+func getReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
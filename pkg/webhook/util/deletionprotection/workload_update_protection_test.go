@@ -0,0 +1,194 @@
+/*
+Copyright 2024 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deletionprotection
+
+import (
+	"encoding/json"
+	"testing"
+
+	apps "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	utilpointer "k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openkruise/kruise/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openkruise/kruise/apis/apps/v1beta1"
+	policyv1alpha1 "github.com/openkruise/kruise/apis/policy/v1alpha1"
+)
+
+func TestValidateWorkloadUpdate(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "demo"}}
+	cases := []struct {
+		name        string
+		protection  string
+		oldReplicas *int32
+		newReplicas *int32
+		newSelector *metav1.LabelSelector
+		expectError bool
+	}{
+		{
+			name:        "scale to zero",
+			protection:  policyv1alpha1.DeletionProtectionTypeCascadingStrict,
+			oldReplicas: utilpointer.Int32(3),
+			newReplicas: utilpointer.Int32(0),
+			newSelector: selector,
+			expectError: true,
+		},
+		{
+			name:        "scale to zero from defaulted replicas",
+			protection:  policyv1alpha1.DeletionProtectionTypeCascadingStrict,
+			newReplicas: utilpointer.Int32(0),
+			newSelector: selector,
+			expectError: true,
+		},
+		{
+			name:        "scale down",
+			protection:  policyv1alpha1.DeletionProtectionTypeCascadingStrict,
+			oldReplicas: utilpointer.Int32(3),
+			newReplicas: utilpointer.Int32(1),
+			newSelector: selector,
+		},
+		{
+			name:        "scale up from zero",
+			protection:  policyv1alpha1.DeletionProtectionTypeCascadingStrict,
+			oldReplicas: utilpointer.Int32(0),
+			newReplicas: utilpointer.Int32(1),
+			newSelector: selector,
+		},
+		{
+			name:        "change selector",
+			protection:  policyv1alpha1.DeletionProtectionTypeCascadingStrict,
+			oldReplicas: utilpointer.Int32(3),
+			newReplicas: utilpointer.Int32(3),
+			newSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "other"}},
+			expectError: true,
+		},
+		{
+			name:        "scale to zero with Cascading",
+			protection:  policyv1alpha1.DeletionProtectionTypeCascading,
+			oldReplicas: utilpointer.Int32(3),
+			newReplicas: utilpointer.Int32(0),
+			newSelector: selector,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			oldObj := &apps.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "demo",
+				Labels:    map[string]string{policyv1alpha1.DeletionProtectionKey: cs.protection},
+			}}
+			err := ValidateWorkloadUpdate(oldObj, cs.oldReplicas, cs.newReplicas, selector, cs.newSelector)
+			if (err != nil) != cs.expectError {
+				t.Fatalf("expect error %v, but got %v", cs.expectError, err)
+			}
+		})
+	}
+}
+
+func TestValidateWorkloadScale(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(appsv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(appsv1beta1.AddToScheme(scheme))
+	protected := metav1.ObjectMeta{
+		Namespace: "default",
+		Name:      "demo",
+		Labels:    map[string]string{policyv1alpha1.DeletionProtectionKey: policyv1alpha1.DeletionProtectionTypeCascadingStrict},
+	}
+	newScale := func(replicas int32) []byte {
+		scale := &autoscalingv1.Scale{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "demo"},
+			Spec:       autoscalingv1.ScaleSpec{Replicas: replicas},
+		}
+		raw, _ := json.Marshal(scale)
+		return raw
+	}
+
+	cases := []struct {
+		name        string
+		workload    client.Object
+		oldReplicas int32
+		replicas    int32
+		expectError bool
+	}{
+		{
+			name:        "scale down Deployment",
+			workload:    &apps.Deployment{ObjectMeta: protected},
+			oldReplicas: 3,
+			replicas:    1,
+		},
+		{
+			name:        "scale Deployment to zero",
+			workload:    &apps.Deployment{ObjectMeta: protected},
+			oldReplicas: 3,
+			expectError: true,
+		},
+		{
+			name:        "scale StatefulSet to zero",
+			workload:    &apps.StatefulSet{ObjectMeta: protected},
+			oldReplicas: 3,
+			expectError: true,
+		},
+		{
+			name:        "scale down CloneSet",
+			workload:    &appsv1alpha1.CloneSet{ObjectMeta: protected},
+			oldReplicas: 3,
+			replicas:    1,
+		},
+		{
+			name:        "scale CloneSet to zero",
+			workload:    &appsv1alpha1.CloneSet{ObjectMeta: protected},
+			oldReplicas: 3,
+			expectError: true,
+		},
+		{
+			name:        "scale Advanced StatefulSet to zero",
+			workload:    &appsv1beta1.StatefulSet{ObjectMeta: protected},
+			oldReplicas: 3,
+			expectError: true,
+		},
+		{
+			name:        "scale UnitedDeployment to zero",
+			workload:    &appsv1alpha1.UnitedDeployment{ObjectMeta: protected},
+			oldReplicas: 3,
+			expectError: true,
+		},
+		{
+			name:        "scale unprotected CloneSet to zero",
+			workload:    &appsv1alpha1.CloneSet{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "demo"}},
+			oldReplicas: 3,
+		},
+	}
+
+	for _, cs := range cases {
+		t.Run(cs.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cs.workload).Build()
+			empty := cs.workload.DeepCopyObject().(client.Object)
+			err := ValidateWorkloadScale(fakeClient, empty, newScale(cs.oldReplicas), newScale(cs.replicas))
+			if (err != nil) != cs.expectError {
+				t.Fatalf("expect error %v, but got %v", cs.expectError, err)
+			}
+		})
+	}
+}
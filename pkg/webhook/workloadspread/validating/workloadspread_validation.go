@@ -55,6 +55,7 @@ var (
 	controllerKindRS             = appsv1.SchemeGroupVersion.WithKind("ReplicaSet")
 	controllerKindDep            = appsv1.SchemeGroupVersion.WithKind("Deployment")
 	controllerKindJob            = batchv1.SchemeGroupVersion.WithKind("Job")
+	controllerKruiseKindBJ       = appsv1alpha1.SchemeGroupVersion.WithKind("BroadcastJob")
 	controllerKruiseKindBetaSts  = appsvbeta1.SchemeGroupVersion.WithKind("StatefulSet")
 	controllerKruiseKindAlphaSts = appsv1alpha1.SchemeGroupVersion.WithKind("StatefulSet")
 )
@@ -147,6 +148,16 @@ func validateWorkloadSpreadSpec(h *WorkloadSpreadCreateUpdateHandler, obj *appsv
 						workloadTemplate = set
 					}
 				}
+			case controllerKruiseKindBJ.Kind:
+				ok, err := verifyGroupKind(spec.TargetReference, controllerKruiseKindBJ.Kind, []string{controllerKruiseKindBJ.Group})
+				if !ok || err != nil {
+					allErrs = append(allErrs, field.Invalid(fldPath.Child("targetRef"), spec.TargetReference, "TargetReference is not valid for BroadcastJob."))
+				} else {
+					set := &appsv1alpha1.BroadcastJob{}
+					if getErr := h.Client.Get(context.TODO(), client.ObjectKey{Name: spec.TargetReference.Name, Namespace: obj.Namespace}, set); getErr == nil {
+						workloadTemplate = set
+					}
+				}
 			case controllerKindSts.Kind:
 				ok, err := verifyGroupKind(spec.TargetReference, controllerKindSts.Kind, []string{controllerKindSts.Group, controllerKruiseKindAlphaSts.Group, controllerKruiseKindBetaSts.Group})
 				if !ok || err != nil {
@@ -212,6 +223,17 @@ func validateWorkloadSpreadSpec(h *WorkloadSpreadCreateUpdateHandler, obj *appsv
 		}
 	}
 
+	if spec.RebalanceStrategy != nil {
+		if spec.RebalanceStrategy.MaxDeletionsPerInterval != nil && *spec.RebalanceStrategy.MaxDeletionsPerInterval < 1 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("rebalanceStrategy").Child("maxDeletionsPerInterval"),
+				*spec.RebalanceStrategy.MaxDeletionsPerInterval, "maxDeletionsPerInterval < 1 is not permitted"))
+		}
+		if spec.RebalanceStrategy.IntervalSeconds != nil && *spec.RebalanceStrategy.IntervalSeconds < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("rebalanceStrategy").Child("intervalSeconds"),
+				*spec.RebalanceStrategy.IntervalSeconds, "intervalSeconds < 0 is not permitted"))
+		}
+	}
+
 	return allErrs
 }
 
@@ -300,6 +322,8 @@ func validateWorkloadSpreadSubsets(ws *appsv1alpha1.WorkloadSpread, subsets []ap
 					podSpec = workloadTemplate.(*appsv1.ReplicaSet).Spec.Template
 				case controllerKindJob:
 					podSpec = workloadTemplate.(*batchv1.Job).Spec.Template
+				case controllerKruiseKindBJ:
+					podSpec = workloadTemplate.(*appsv1alpha1.BroadcastJob).Spec.Template
 				case controllerKindSts:
 					podSpec = workloadTemplate.(*appsv1.StatefulSet).Spec.Template
 				}
@@ -355,6 +379,43 @@ func validateWorkloadSpreadSubsets(ws *appsv1alpha1.WorkloadSpread, subsets []ap
 	if firstMaxReplicasType != nil && *firstMaxReplicasType == intstr.String && maxReplicasSum < 100 && subsets[len(subsets)-1].MaxReplicas != nil {
 		allErrs = append(allErrs, field.Invalid(fldPath.Index(0).Child("maxReplicas"), subsets[0].MaxReplicas, "maxReplicas sum of all subsets must equal 100% when type is specified as percent"))
 	}
+	allErrs = append(allErrs, validateWorkloadSpreadSubsetWeights(subsets, fldPath)...)
+	return allErrs
+}
+
+// validateWorkloadSpreadSubsetWeights checks that if any subset has a weight, all subsets have a non-negative
+// weight without maxReplicas, and the sum of weights is positive.
+func validateWorkloadSpreadSubsetWeights(subsets []appsv1alpha1.WorkloadSpreadSubset, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	weighted := false
+	for i := range subsets {
+		if subsets[i].Weight != nil {
+			weighted = true
+			break
+		}
+	}
+	if !weighted {
+		return allErrs
+	}
+
+	var weightSum int64
+	for i, subset := range subsets {
+		if subset.MaxReplicas != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("maxReplicas"), subset.MaxReplicas, "maxReplicas and weight are mutually exclusive"))
+		}
+		if subset.Weight == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Index(i).Child("weight"), "weight must be specified for all subsets if any subset has weight"))
+			continue
+		}
+		if *subset.Weight < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("weight"), *subset.Weight, "weight must be non-negative"))
+			continue
+		}
+		weightSum += int64(*subset.Weight)
+	}
+	if len(allErrs) == 0 && weightSum == 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Index(0).Child("weight"), *subsets[0].Weight, "the sum of all subset's weight must be greater than 0"))
+	}
 	return allErrs
 }
 
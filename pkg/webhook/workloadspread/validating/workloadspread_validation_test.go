@@ -455,6 +455,33 @@ func TestValidateWorkloadSpreadCreate(t *testing.T) {
 				},
 			},
 		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ws-weighted", Namespace: metav1.NamespaceDefault},
+			Spec: appsv1alpha1.WorkloadSpreadSpec{
+				TargetReference: &targetRef,
+				Subsets: []appsv1alpha1.WorkloadSpreadSubset{
+					{
+						Name:   "subset-a",
+						Weight: pointer.Int32(3),
+					},
+					{
+						Name:   "subset-b",
+						Weight: pointer.Int32(2),
+					},
+					{
+						Name:   "subset-c",
+						Weight: pointer.Int32(1),
+					},
+				},
+				ScheduleStrategy: appsv1alpha1.WorkloadSpreadScheduleStrategy{
+					Type: appsv1alpha1.AdaptiveWorkloadSpreadScheduleStrategyType,
+					Adaptive: &appsv1alpha1.AdaptiveWorkloadSpreadStrategy{
+						RescheduleCriticalSeconds: pointer.Int32Ptr(30),
+						DisableSimulationSchedule: true,
+					},
+				},
+			},
+		},
 	}
 	for i, successCase := range successCases {
 		t.Run("success case "+strconv.Itoa(i), func(t *testing.T) {
@@ -529,6 +556,19 @@ func TestValidateWorkloadSpreadCreate(t *testing.T) {
 			},
 			errorSuffix: "spec.targetRef",
 		},
+		{
+			name: "broadcastjob group error",
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				workloadSpread := workloadSpreadDemo.DeepCopy()
+				workloadSpread.Spec.TargetReference = &appsv1alpha1.TargetReference{
+					APIVersion: "batch/v1",
+					Kind:       "BroadcastJob",
+					Name:       "demo",
+				}
+				return workloadSpread
+			},
+			errorSuffix: "spec.targetRef",
+		},
 		{
 			name: "statefulset subset is percentage",
 			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
@@ -802,6 +842,79 @@ func TestValidateWorkloadSpreadCreate(t *testing.T) {
 			},
 			errorSuffix: "spec.scheduleStrategy.adaptive",
 		},
+		{
+			name: "rebalance maxDeletionsPerInterval = 0",
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				workloadSpread := workloadSpreadDemo.DeepCopy()
+				workloadSpread.Spec.RebalanceStrategy = &appsv1alpha1.WorkloadSpreadRebalanceStrategy{
+					MaxDeletionsPerInterval: pointer.Int32(0),
+				}
+				return workloadSpread
+			},
+			errorSuffix: "spec.rebalanceStrategy.maxDeletionsPerInterval",
+		},
+		{
+			name: "rebalance intervalSeconds < 0",
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				workloadSpread := workloadSpreadDemo.DeepCopy()
+				workloadSpread.Spec.RebalanceStrategy = &appsv1alpha1.WorkloadSpreadRebalanceStrategy{
+					IntervalSeconds: pointer.Int32(-1),
+				}
+				return workloadSpread
+			},
+			errorSuffix: "spec.rebalanceStrategy.intervalSeconds",
+		},
+		{
+			name: "subset-a has both weight and maxReplicas",
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				workloadSpread := workloadSpreadDemo.DeepCopy()
+				for i := range workloadSpread.Spec.Subsets {
+					workloadSpread.Spec.Subsets[i].MaxReplicas = nil
+					workloadSpread.Spec.Subsets[i].Weight = pointer.Int32(1)
+				}
+				workloadSpread.Spec.Subsets[0].MaxReplicas = &maxReplicasDemo
+				return workloadSpread
+			},
+			errorSuffix: "spec.subsets[0].maxReplicas",
+		},
+		{
+			name: "subset-b's weight is not specified when subset-a has weight",
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				workloadSpread := workloadSpreadDemo.DeepCopy()
+				for i := range workloadSpread.Spec.Subsets {
+					workloadSpread.Spec.Subsets[i].MaxReplicas = nil
+					workloadSpread.Spec.Subsets[i].Weight = pointer.Int32(1)
+				}
+				workloadSpread.Spec.Subsets[1].Weight = nil
+				return workloadSpread
+			},
+			errorSuffix: "spec.subsets[1].weight",
+		},
+		{
+			name: "subset-a's weight < 0",
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				workloadSpread := workloadSpreadDemo.DeepCopy()
+				for i := range workloadSpread.Spec.Subsets {
+					workloadSpread.Spec.Subsets[i].MaxReplicas = nil
+					workloadSpread.Spec.Subsets[i].Weight = pointer.Int32(1)
+				}
+				workloadSpread.Spec.Subsets[0].Weight = pointer.Int32(-1)
+				return workloadSpread
+			},
+			errorSuffix: "spec.subsets[0].weight",
+		},
+		{
+			name: "the sum of weight is 0",
+			getWorkloadSpread: func() *appsv1alpha1.WorkloadSpread {
+				workloadSpread := workloadSpreadDemo.DeepCopy()
+				for i := range workloadSpread.Spec.Subsets {
+					workloadSpread.Spec.Subsets[i].MaxReplicas = nil
+					workloadSpread.Spec.Subsets[i].Weight = pointer.Int32(0)
+				}
+				return workloadSpread
+			},
+			errorSuffix: "spec.subsets[0].weight",
+		},
 	}
 
 	for _, errorCase := range errorCases {